@@ -20,9 +20,12 @@ package beam
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"reflect"
 
 	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
@@ -40,20 +43,70 @@ func init() {
 	runtime.RegisterFunction(protoDec)
 	runtime.RegisterFunction(protoEnc)
 	runtime.RegisterFunction(swapKVFn)
+	runtime.RegisterType(reflect.TypeOf((*boundedTimestampsFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*createFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*flatMapValuesFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mapValuesFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*materializeByKeyFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*materializeFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*materializeKVFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*materialized)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*reflect.Type)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*reflectx.Func)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*restoreByKeyFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*restoreFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*restoreKVFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*withTimestampsFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*boundedTimestampsFn)(nil)).Elem(), wrapMakerBoundedTimestampsFn)
 	reflectx.RegisterStructWrapper(reflect.TypeOf((*createFn)(nil)).Elem(), wrapMakerCreateFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*flatMapValuesFn)(nil)).Elem(), wrapMakerFlatMapValuesFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*mapValuesFn)(nil)).Elem(), wrapMakerMapValuesFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*materializeByKeyFn)(nil)).Elem(), wrapMakerMaterializeByKeyFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*materializeFn)(nil)).Elem(), wrapMakerMaterializeFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*materializeKVFn)(nil)).Elem(), wrapMakerMaterializeKVFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*restoreByKeyFn)(nil)).Elem(), wrapMakerRestoreByKeyFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*restoreFn)(nil)).Elem(), wrapMakerRestoreFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*restoreKVFn)(nil)).Elem(), wrapMakerRestoreKVFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*withTimestampsFn)(nil)).Elem(), wrapMakerWithTimestampsFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, typex.T, func(mtime.Time, typex.T), func(mtime.Time, typex.T)))(nil)).Elem(), funcMakerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(func(mtime.Time, typex.T), func(mtime.Time, typex.T)))(nil)).Elem(), funcMakerEmitETTypex۰TEmitETTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(int, func(*materialized) bool, func(mtime.Time, typex.T)) error)(nil)).Elem(), funcMakerIntIterMaterializedEmitETTypex۰TГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(int, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error)(nil)).Elem(), funcMakerIntIterMaterializedEmitETTypex۰XTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(mtime.Time, typex.T) (int, materialized, error))(nil)).Elem(), funcMakerMtime۰TimeTypex۰TГIntMaterializedError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Y) (int, materialized, error))(nil)).Elem(), funcMakerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Y) (typex.X, materialized, error))(nil)).Elem(), funcMakerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(reflect.Type, []byte) (typex.T, error))(nil)).Elem(), funcMakerReflect۰TypeSliceOfByteГTypex۰TError)
 	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(typex.T)) error)(nil)).Elem(), funcMakerSliceOfByteEmitTypex۰TГError)
 	reflectx.RegisterFunc(reflect.TypeOf((*func([]typex.T, func(typex.T)))(nil)).Elem(), funcMakerSliceOfTypex۰TEmitTypex۰TГ)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(string, reflect.Type, []byte) reflectx.Func)(nil)).Elem(), funcMakerStringReflect۰TypeSliceOfByteГReflectx۰Func)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) (int, typex.T))(nil)).Elem(), funcMakerTypex۰TГIntTypex۰T)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) (mtime.Time, typex.T))(nil)).Elem(), funcMakerTypex۰TГMtime۰TimeTypex۰T)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) ([]byte, error))(nil)).Elem(), funcMakerTypex۰TГSliceOfByteError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error)(nil)).Elem(), funcMakerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y, func(typex.X, typex.Z)))(nil)).Elem(), funcMakerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) typex.X)(nil)).Elem(), funcMakerTypex۰XTypex۰YГTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) (typex.X, typex.Z))(nil)).Elem(), funcMakerTypex۰XTypex۰YГTypex۰XTypex۰Z)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) typex.Y)(nil)).Elem(), funcMakerTypex۰XTypex۰YГTypex۰Y)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) (typex.Y, typex.X))(nil)).Elem(), funcMakerTypex۰XTypex۰YГTypex۰YTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(mtime.Time, typex.T))(nil)).Elem(), emitMakerETTypex۰T)
+	exec.RegisterEmitter(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Y))(nil)).Elem(), emitMakerETTypex۰XTypex۰Y)
 	exec.RegisterEmitter(reflect.TypeOf((*func(typex.T))(nil)).Elem(), emitMakerTypex۰T)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X, typex.Z))(nil)).Elem(), emitMakerTypex۰XTypex۰Z)
+	exec.RegisterInput(reflect.TypeOf((*func(*materialized) bool)(nil)).Elem(), iterMakerMaterialized)
+}
+
+func wrapMakerBoundedTimestampsFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*boundedTimestampsFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 typex.T, a2 func(mtime.Time, typex.T), a3 func(mtime.Time, typex.T)) {
+			dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+		"Setup":       reflectx.MakeFunc(func() { dfn.Setup() }),
+		"StartBundle": reflectx.MakeFunc(func(a0 func(mtime.Time, typex.T), a1 func(mtime.Time, typex.T)) { dfn.StartBundle(a0, a1) }),
+	}
 }
 
 func wrapMakerCreateFn(fn interface{}) map[string]reflectx.Func {
@@ -63,6 +116,270 @@ func wrapMakerCreateFn(fn interface{}) map[string]reflectx.Func {
 	}
 }
 
+func wrapMakerFlatMapValuesFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*flatMapValuesFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 typex.Y, a2 func(typex.X, typex.Z)) { dfn.ProcessElement(a0, a1, a2) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerMapValuesFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*mapValuesFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 typex.Y) (typex.X, typex.Z) { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerMaterializeByKeyFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*materializeByKeyFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 mtime.Time, a1 typex.X, a2 typex.Y) (typex.X, materialized, error) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerMaterializeFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*materializeFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 mtime.Time, a1 typex.T) (int, materialized, error) { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerMaterializeKVFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*materializeKVFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 mtime.Time, a1 typex.X, a2 typex.Y) (int, materialized, error) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerRestoreByKeyFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*restoreByKeyFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 func(*materialized) bool, a2 func(mtime.Time, typex.X, typex.Y)) error {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerRestoreFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*restoreFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 int, a1 func(*materialized) bool, a2 func(mtime.Time, typex.T)) error {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerRestoreKVFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*restoreKVFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 int, a1 func(*materialized) bool, a2 func(mtime.Time, typex.X, typex.Y)) error {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerWithTimestampsFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*withTimestampsFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.T) (mtime.Time, typex.T) { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ struct {
+	fn func(context.Context, typex.T, func(mtime.Time, typex.T), func(mtime.Time, typex.T))
+}
+
+func funcMakerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, typex.T, func(mtime.Time, typex.T), func(mtime.Time, typex.T)))
+	return &callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ{fn: f}
+}
+
+func (c *callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(context.Context), args[1].(typex.T), args[2].(func(mtime.Time, typex.T)), args[3].(func(mtime.Time, typex.T)))
+	return []interface{}{}
+}
+
+func (c *callerContext۰ContextTypex۰TEmitETTypex۰TEmitETTypex۰TГ) Call4x0(arg0, arg1, arg2, arg3 interface{}) {
+	c.fn(arg0.(context.Context), arg1.(typex.T), arg2.(func(mtime.Time, typex.T)), arg3.(func(mtime.Time, typex.T)))
+}
+
+type callerEmitETTypex۰TEmitETTypex۰TГ struct {
+	fn func(func(mtime.Time, typex.T), func(mtime.Time, typex.T))
+}
+
+func funcMakerEmitETTypex۰TEmitETTypex۰TГ(fn interface{}) reflectx.Func {
+	f := fn.(func(func(mtime.Time, typex.T), func(mtime.Time, typex.T)))
+	return &callerEmitETTypex۰TEmitETTypex۰TГ{fn: f}
+}
+
+func (c *callerEmitETTypex۰TEmitETTypex۰TГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerEmitETTypex۰TEmitETTypex۰TГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerEmitETTypex۰TEmitETTypex۰TГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(func(mtime.Time, typex.T)), args[1].(func(mtime.Time, typex.T)))
+	return []interface{}{}
+}
+
+func (c *callerEmitETTypex۰TEmitETTypex۰TГ) Call2x0(arg0, arg1 interface{}) {
+	c.fn(arg0.(func(mtime.Time, typex.T)), arg1.(func(mtime.Time, typex.T)))
+}
+
+type callerIntIterMaterializedEmitETTypex۰TГError struct {
+	fn func(int, func(*materialized) bool, func(mtime.Time, typex.T)) error
+}
+
+func funcMakerIntIterMaterializedEmitETTypex۰TГError(fn interface{}) reflectx.Func {
+	f := fn.(func(int, func(*materialized) bool, func(mtime.Time, typex.T)) error)
+	return &callerIntIterMaterializedEmitETTypex۰TГError{fn: f}
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰TГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰TГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰TГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(int), args[1].(func(*materialized) bool), args[2].(func(mtime.Time, typex.T)))
+	return []interface{}{out0}
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰TГError) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(int), arg1.(func(*materialized) bool), arg2.(func(mtime.Time, typex.T)))
+}
+
+type callerIntIterMaterializedEmitETTypex۰XTypex۰YГError struct {
+	fn func(int, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error
+}
+
+func funcMakerIntIterMaterializedEmitETTypex۰XTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(int, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error)
+	return &callerIntIterMaterializedEmitETTypex۰XTypex۰YГError{fn: f}
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰XTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰XTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰XTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(int), args[1].(func(*materialized) bool), args[2].(func(mtime.Time, typex.X, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerIntIterMaterializedEmitETTypex۰XTypex۰YГError) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(int), arg1.(func(*materialized) bool), arg2.(func(mtime.Time, typex.X, typex.Y)))
+}
+
+type callerMtime۰TimeTypex۰TГIntMaterializedError struct {
+	fn func(mtime.Time, typex.T) (int, materialized, error)
+}
+
+func funcMakerMtime۰TimeTypex۰TГIntMaterializedError(fn interface{}) reflectx.Func {
+	f := fn.(func(mtime.Time, typex.T) (int, materialized, error))
+	return &callerMtime۰TimeTypex۰TГIntMaterializedError{fn: f}
+}
+
+func (c *callerMtime۰TimeTypex۰TГIntMaterializedError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰TГIntMaterializedError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰TГIntMaterializedError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(mtime.Time), args[1].(typex.T))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerMtime۰TimeTypex۰TГIntMaterializedError) Call2x3(arg0, arg1 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(mtime.Time), arg1.(typex.T))
+}
+
+type callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError struct {
+	fn func(mtime.Time, typex.X, typex.Y) (int, materialized, error)
+}
+
+func funcMakerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError(fn interface{}) reflectx.Func {
+	f := fn.(func(mtime.Time, typex.X, typex.Y) (int, materialized, error))
+	return &callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError{fn: f}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(mtime.Time), args[1].(typex.X), args[2].(typex.Y))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГIntMaterializedError) Call3x3(arg0, arg1, arg2 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(mtime.Time), arg1.(typex.X), arg2.(typex.Y))
+}
+
+type callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError struct {
+	fn func(mtime.Time, typex.X, typex.Y) (typex.X, materialized, error)
+}
+
+func funcMakerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError(fn interface{}) reflectx.Func {
+	f := fn.(func(mtime.Time, typex.X, typex.Y) (typex.X, materialized, error))
+	return &callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError{fn: f}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(mtime.Time), args[1].(typex.X), args[2].(typex.Y))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XMaterializedError) Call3x3(arg0, arg1, arg2 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(mtime.Time), arg1.(typex.X), arg2.(typex.Y))
+}
+
 type callerReflect۰TypeSliceOfByteГTypex۰TError struct {
 	fn func(reflect.Type, []byte) (typex.T, error)
 }
@@ -193,6 +510,32 @@ func (c *callerTypex۰TГIntTypex۰T) Call1x2(arg0 interface{}) (interface{}, in
 	return c.fn(arg0.(typex.T))
 }
 
+type callerTypex۰TГMtime۰TimeTypex۰T struct {
+	fn func(typex.T) (mtime.Time, typex.T)
+}
+
+func funcMakerTypex۰TГMtime۰TimeTypex۰T(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.T) (mtime.Time, typex.T))
+	return &callerTypex۰TГMtime۰TimeTypex۰T{fn: f}
+}
+
+func (c *callerTypex۰TГMtime۰TimeTypex۰T) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰TГMtime۰TimeTypex۰T) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰TГMtime۰TimeTypex۰T) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.T))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰TГMtime۰TimeTypex۰T) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.T))
+}
+
 type callerTypex۰TГSliceOfByteError struct {
 	fn func(typex.T) ([]byte, error)
 }
@@ -219,6 +562,58 @@ func (c *callerTypex۰TГSliceOfByteError) Call1x2(arg0 interface{}) (interface{
 	return c.fn(arg0.(typex.T))
 }
 
+type callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError struct {
+	fn func(typex.X, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error
+}
+
+func funcMakerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, func(*materialized) bool, func(mtime.Time, typex.X, typex.Y)) error)
+	return &callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError{fn: f}
+}
+
+func (c *callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X), args[1].(func(*materialized) bool), args[2].(func(mtime.Time, typex.X, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XIterMaterializedEmitETTypex۰XTypex۰YГError) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(typex.X), arg1.(func(*materialized) bool), arg2.(func(mtime.Time, typex.X, typex.Y)))
+}
+
+type callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ struct {
+	fn func(typex.X, typex.Y, func(typex.X, typex.Z))
+}
+
+func funcMakerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, typex.Y, func(typex.X, typex.Z)))
+	return &callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ{fn: f}
+}
+
+func (c *callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(typex.X), args[1].(typex.Y), args[2].(func(typex.X, typex.Z)))
+	return []interface{}{}
+}
+
+func (c *callerTypex۰XTypex۰YEmitTypex۰XTypex۰ZГ) Call3x0(arg0, arg1, arg2 interface{}) {
+	c.fn(arg0.(typex.X), arg1.(typex.Y), arg2.(func(typex.X, typex.Z)))
+}
+
 type callerTypex۰XTypex۰YГTypex۰X struct {
 	fn func(typex.X, typex.Y) typex.X
 }
@@ -245,6 +640,32 @@ func (c *callerTypex۰XTypex۰YГTypex۰X) Call2x1(arg0, arg1 interface{}) inter
 	return c.fn(arg0.(typex.X), arg1.(typex.Y))
 }
 
+type callerTypex۰XTypex۰YГTypex۰XTypex۰Z struct {
+	fn func(typex.X, typex.Y) (typex.X, typex.Z)
+}
+
+func funcMakerTypex۰XTypex۰YГTypex۰XTypex۰Z(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, typex.Y) (typex.X, typex.Z))
+	return &callerTypex۰XTypex۰YГTypex۰XTypex۰Z{fn: f}
+}
+
+func (c *callerTypex۰XTypex۰YГTypex۰XTypex۰Z) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГTypex۰XTypex۰Z) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГTypex۰XTypex۰Z) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X), args[1].(typex.Y))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XTypex۰YГTypex۰XTypex۰Z) Call2x2(arg0, arg1 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X), arg1.(typex.Y))
+}
+
 type callerTypex۰XTypex۰YГTypex۰Y struct {
 	fn func(typex.X, typex.Y) typex.Y
 }
@@ -297,6 +718,32 @@ func (c *callerTypex۰XTypex۰YГTypex۰YTypex۰X) Call2x2(arg0, arg1 interface{
 	return c.fn(arg0.(typex.X), arg1.(typex.Y))
 }
 
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
 type emitNative struct {
 	n  exec.ElementProcessor
 	fn interface{}
@@ -318,6 +765,32 @@ func (e *emitNative) Value() interface{} {
 	return e.fn
 }
 
+func emitMakerETTypex۰T(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeETTypex۰T
+	return ret
+}
+
+func (e *emitNative) invokeETTypex۰T(t typex.EventTime, val typex.T) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: t, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerETTypex۰XTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeETTypex۰XTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeETTypex۰XTypex۰Y(t typex.EventTime, key typex.X, val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: t, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
 func emitMakerTypex۰T(n exec.ElementProcessor) exec.ReusableEmitter {
 	ret := &emitNative{n: n}
 	ret.fn = ret.invokeTypex۰T
@@ -331,4 +804,64 @@ func (e *emitNative) invokeTypex۰T(val typex.T) {
 	}
 }
 
+func emitMakerTypex۰XTypex۰Z(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰XTypex۰Z
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰XTypex۰Z(key typex.X, val typex.Z) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerMaterialized(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readMaterialized
+	return ret
+}
+
+func (v *iterNative) readMaterialized(value *materialized) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(materialized)
+	return true
+}
+
 // DO NOT MODIFY: GENERATED CODE
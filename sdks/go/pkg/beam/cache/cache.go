@@ -0,0 +1,400 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache contains a size-bounded cache shared by all bundles and DoFn
+// instances on a worker. It is meant for memoizing expensive lookups, such
+// as calls to external services, without each DoFn keeping its own global
+// map. Keys are shared by all DoFns of the worker, so DoFns should prefix
+// them, such as with the name of the lookup.
+//
+// Workers bound the cache by the --worker_cache_size flag of the launcher,
+// which a harness hook passes to them. Values may be evicted at any time,
+// so the cache must not be used to pass data between DoFns.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/hooks"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+// DefaultCapacity is the default capacity of the worker cache in bytes.
+const DefaultCapacity = 64 << 20
+
+// SizeOption is the flag that holds the capacity of the worker cache in
+// bytes. It is also the name of the harness hook that sets the capacity on
+// the workers.
+const SizeOption = "worker_cache_size"
+
+var size = flag.Int64(SizeOption, DefaultCapacity, "Capacity of the worker cache in bytes (optional).")
+
+func init() {
+	hooks.RegisterHook(SizeOption, func(opts []string) hooks.Hook {
+		return hooks.Hook{
+			Init: func(ctx context.Context) (context.Context, error) {
+				if len(opts) != 1 {
+					return ctx, nil
+				}
+				capacity, err := strconv.ParseInt(opts[0], 10, 64)
+				if err == nil && capacity < 0 {
+					err = errors.New("negative size")
+				}
+				if err != nil {
+					log.Warnf(ctx, "Invalid worker cache size %v: %v", opts[0], err)
+					return ctx, nil
+				}
+				SetCapacity(capacity)
+				log.Debugf(ctx, "Worker cache capacity: %v bytes", capacity)
+				return ctx, nil
+			},
+		}
+	})
+	runtime.RegisterInit(func() {
+		// On workers, the hook replaces the capacity by the launcher's.
+		hooks.EnableHook(SizeOption, strconv.FormatInt(*size, 10))
+		SetCapacity(*size)
+	})
+}
+
+// Sizer is implemented by values that know their approximate size in
+// bytes, which counts against the capacity of the cache.
+type Sizer interface {
+	Size() int64
+}
+
+// Cache is a concurrency-safe least recently used cache, which evicts
+// values when their total size exceeds its capacity.
+type Cache struct {
+	capacity int64
+	size     int64
+	lru      *list.List // of *entry, most recently used first
+	entries  map[string]*list.Element
+	loading  map[string]*load
+	mu       sync.Mutex
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	size    int64
+	expires time.Time // zero if the value does not expire
+}
+
+type load struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// New returns a cache with the given capacity in bytes, which must not be
+// negative.
+func New(capacity int64) *Cache {
+	checkCapacity(capacity)
+	return &Cache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		loading:  make(map[string]*load),
+	}
+}
+
+// SetCapacity changes the capacity of the cache in bytes, evicting values
+// if needed. The capacity must not be negative.
+func (c *Cache) SetCapacity(capacity int64) {
+	checkCapacity(capacity)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	c.evict()
+}
+
+func checkCapacity(capacity int64) {
+	if capacity < 0 {
+		panic(fmt.Sprintf("invalid cache capacity: %v", capacity))
+	}
+}
+
+// Get returns the value of the key and whether it is present and not
+// expired.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elm, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elm.Value.(*entry)
+	if !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		c.remove(elm)
+		return nil, false
+	}
+	c.lru.MoveToFront(elm)
+	return e.value, true
+}
+
+// Put caches the value of the key until it is evicted.
+func (c *Cache) Put(key string, value interface{}) {
+	c.PutWithTTL(key, value, 0)
+}
+
+// PutWithTTL caches the value of the key for at most the given time. A ttl
+// of zero means the value does not expire. Values larger than the capacity
+// are not cached.
+func (c *Cache) PutWithTTL(key string, value interface{}, ttl time.Duration) {
+	e := &entry{key: key, value: value, size: int64(len(key)) + sizeOf(value)}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elm, ok := c.entries[key]; ok {
+		c.remove(elm)
+	}
+	if e.size > c.capacity {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += e.size
+	c.evict()
+}
+
+// Delete removes the value of the key, if present.
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elm, ok := c.entries[key]; ok {
+		c.remove(elm)
+	}
+}
+
+// Load returns the value of the key, if present. Otherwise, it calls fn and
+// caches the value it returns for at most the given time. Concurrent loads
+// of the same key wait for a single call of fn. Errors are not cached. If fn
+// panics, the loads that wait for it return an error.
+func (c *Cache) Load(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	c.mu.Lock()
+	if l, ok := c.loading[key]; ok {
+		c.mu.Unlock()
+		<-l.done
+		return l.value, l.err
+	}
+	l := &load{done: make(chan struct{})}
+	c.loading[key] = l
+	c.mu.Unlock()
+
+	defer func() {
+		r := recover()
+		if r != nil {
+			l.value, l.err = nil, errors.Errorf("load of %v panicked: %v", key, r)
+		}
+		c.mu.Lock()
+		delete(c.loading, key)
+		c.mu.Unlock()
+		close(l.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	l.value, l.err = fn()
+	if l.err == nil {
+		c.PutWithTTL(key, l.value, ttl)
+	}
+	return l.value, l.err
+}
+
+// Len returns the number of cached values, including expired values that
+// have not been evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// evict removes the least recently used values until the cache is within
+// its capacity. It must be called with the lock held.
+func (c *Cache) evict() {
+	for c.size > c.capacity {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(elm *list.Element) {
+	e := c.lru.Remove(elm).(*entry)
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
+
+// sizeOf returns the approximate size of the value. A Sizer returns its own
+// size. Other values count their size and that of the strings, slices, maps
+// and pointers they reference, once each, so that a struct holding large
+// slices does not count as its header size.
+func sizeOf(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	default:
+		rv := reflect.ValueOf(value)
+		return int64(rv.Type().Size()) + referencedSize(rv, make(map[uintptr]bool))
+	}
+}
+
+// referencedSize returns the size of the values referenced by v, besides v
+// itself. Pointers, slices and maps in seen have been counted already.
+func referencedSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		if v.Kind() == reflect.Ptr {
+			if seen[v.Pointer()] {
+				return 0
+			}
+			seen[v.Pointer()] = true
+		}
+		e := v.Elem()
+		return int64(e.Type().Size()) + referencedSize(e, seen)
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		n := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if hasReferences(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += referencedSize(v.Index(i), seen)
+			}
+		}
+		return n
+	case reflect.Array:
+		var n int64
+		if hasReferences(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += referencedSize(v.Index(i), seen)
+			}
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		t := v.Type()
+		n := int64(v.Len()) * int64(t.Key().Size()+t.Elem().Size())
+		if hasReferences(t.Key()) || hasReferences(t.Elem()) {
+			for _, k := range v.MapKeys() {
+				n += referencedSize(k, seen) + referencedSize(v.MapIndex(k), seen)
+			}
+		}
+		return n
+	case reflect.Struct:
+		var n int64
+		for i := 0; i < v.NumField(); i++ {
+			n += referencedSize(v.Field(i), seen)
+		}
+		return n
+	default:
+		// Numbers, booleans, channels and functions are counted by the
+		// size of their type only.
+		return 0
+	}
+}
+
+// hasReferences reports whether values of type t may reference other values
+// that referencedSize counts.
+func hasReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasReferences(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasReferences(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// worker is the cache of the worker.
+var worker = New(DefaultCapacity)
+
+// SetCapacity changes the capacity of the worker cache in bytes. It is
+// called on the workers by the harness hook of the cache.
+func SetCapacity(capacity int64) {
+	worker.SetCapacity(capacity)
+}
+
+// Get returns the value of the key in the worker cache and whether it is
+// present and not expired.
+func Get(key string) (interface{}, bool) {
+	return worker.Get(key)
+}
+
+// Put caches the value of the key in the worker cache until it is evicted.
+func Put(key string, value interface{}) {
+	worker.Put(key, value)
+}
+
+// PutWithTTL caches the value of the key in the worker cache for at most
+// the given time.
+func PutWithTTL(key string, value interface{}, ttl time.Duration) {
+	worker.PutWithTTL(key, value, ttl)
+}
+
+// Delete removes the value of the key from the worker cache, if present.
+func Delete(key string) {
+	worker.Delete(key)
+}
+
+// Load returns the value of the key in the worker cache, if present.
+// Otherwise, it caches the value returned by fn. See Cache.Load.
+func Load(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	return worker.Load(key, ttl, fn)
+}
@@ -0,0 +1,207 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/hooks"
+)
+
+type sized int64
+
+func (s sized) Size() int64 {
+	return int64(s)
+}
+
+func TestCache(t *testing.T) {
+	c := New(100)
+	c.Put("a", "aaaaaaaaa")
+	c.Put("b", "bbbbbbbbb")
+	if v, ok := c.Get("a"); !ok || v != "aaaaaaaaa" {
+		t.Errorf("Get(a) = %v, %v, want aaaaaaaaa", v, ok)
+	}
+
+	// b is the least recently used value, so it is evicted first.
+	c.Put("c", sized(85))
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Get(b) succeeded, want evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Get(a) failed, want present")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Errorf("Get(c) failed, want present")
+	}
+
+	c.Put("huge", sized(1000))
+	if _, ok := c.Get("huge"); ok {
+		t.Errorf("Get(huge) succeeded, want value larger than capacity not cached")
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) succeeded after Delete")
+	}
+
+	c.SetCapacity(10)
+	if c.Len() != 0 {
+		t.Errorf("Len() = %v after shrinking, want 0", c.Len())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := New(100)
+	c.PutWithTTL("a", "x", time.Millisecond)
+	c.PutWithTTL("b", "y", time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) succeeded, want expired")
+	}
+	if v, ok := c.Get("b"); !ok || v != "y" {
+		t.Errorf("Get(b) = %v, %v, want y", v, ok)
+	}
+}
+
+func TestCacheLoad(t *testing.T) {
+	c := New(100)
+
+	var calls int32
+	release := make(chan struct{})
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "v", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.Load("k", 0, fn); err != nil || v != "v" {
+				t.Errorf("Load(k) = %v, %v, want v", v, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Load called fn %v times, want 1", n)
+	}
+
+	failed := errors.New("failed")
+	if _, err := c.Load("e", 0, func() (interface{}, error) { return nil, failed }); err != failed {
+		t.Errorf("Load(e) = %v, want %v", err, failed)
+	}
+	if _, ok := c.Get("e"); ok {
+		t.Errorf("Get(e) succeeded, want errors not cached")
+	}
+}
+
+func TestCacheLoadPanic(t *testing.T) {
+	c := New(100)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Load(k) did not panic")
+			}
+			close(done)
+		}()
+		c.Load("k", 0, func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	waited := make(chan error)
+	go func() {
+		_, err := c.Load("k", 0, func() (interface{}, error) { return "v", nil })
+		waited <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+	if err := <-waited; err == nil {
+		t.Errorf("waiting Load(k) succeeded, want the panic as an error")
+	}
+	if v, err := c.Load("k", 0, func() (interface{}, error) { return "v", nil }); err != nil || v != "v" {
+		t.Errorf("Load(k) after the panic = %v, %v, want v", v, err)
+	}
+}
+
+func TestNegativeCapacity(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("SetCapacity(-1) succeeded, want panic")
+		}
+	}()
+	New(100).SetCapacity(-1)
+}
+
+func TestSizeOf(t *testing.T) {
+	type row struct {
+		Name   string
+		Values []int64
+		Next   *row
+	}
+	r := &row{Name: "abcd", Values: make([]int64, 100)}
+	r.Next = r
+
+	if got := sizeOf(r); got < 4+800 {
+		t.Errorf("sizeOf(%T) = %v, want at least the referenced string and slice", r, got)
+	}
+	m := map[string][]byte{"k": make([]byte, 1000)}
+	if got := sizeOf(m); got < 1000 {
+		t.Errorf("sizeOf(%T) = %v, want at least 1000", m, got)
+	}
+	if got := sizeOf(sized(5)); got != 5 {
+		t.Errorf("sizeOf(sized(5)) = %v, want 5", got)
+	}
+}
+
+func TestCapacityHook(t *testing.T) {
+	defer SetCapacity(DefaultCapacity)
+
+	ctx := context.Background()
+	if err := hooks.EnableHook(SizeOption, "123"); err != nil {
+		t.Fatal(err)
+	}
+	hooks.SerializeHooksToOptions()
+	hooks.DeserializeHooksFromOptions(ctx)
+	if _, err := hooks.RunInitHooks(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	if worker.capacity != 123 {
+		t.Errorf("worker cache capacity = %v, want 123", worker.capacity)
+	}
+}
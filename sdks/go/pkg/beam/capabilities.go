@@ -0,0 +1,115 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// Capability is a feature of the model that some runners do not support.
+// Capabilities are detected in the pipeline graph.
+type Capability string
+
+const (
+	// MergingWindowsCapability is support for merging windows, such as
+	// session windows.
+	MergingWindowsCapability Capability = "merging_windows"
+	// UnboundedCapability is support for unbounded PCollections, that is,
+	// streaming.
+	UnboundedCapability Capability = "unbounded"
+)
+
+var (
+	capabilities = make(map[string]map[Capability]bool)
+)
+
+// RegisterCapabilities registers the capabilities of the named runner. The
+// pipelines that require other capabilities fail when run with the runner,
+// before they are submitted. Runners that do not register their
+// capabilities, such as runners for any portable job service, are assumed
+// to support all of them.
+func RegisterCapabilities(runner string, caps ...Capability) {
+	if _, ok := capabilities[runner]; ok {
+		panic(fmt.Sprintf("capabilities of runner %v already registered", runner))
+	}
+	m := make(map[Capability]bool)
+	for _, c := range caps {
+		m[c] = true
+	}
+	capabilities[runner] = m
+}
+
+// RunnerSupports returns whether the named runner supports the capability.
+func RunnerSupports(runner string, c Capability) bool {
+	m, ok := capabilities[runner]
+	return !ok || m[c]
+}
+
+// CheckCapabilities returns an error that lists the capabilities required
+// by the pipeline that the named runner does not support, if any: merging
+// windows and unbounded PCollections. It is called by Run.
+func CheckCapabilities(p *Pipeline, runner string) error {
+	if _, ok := capabilities[runner]; !ok {
+		return nil
+	}
+	edges, _, err := p.Build()
+	if err != nil {
+		return err
+	}
+
+	required := make(map[Capability][]string)
+	for _, edge := range edges {
+		if edge.Op == graph.WindowInto && edge.WindowFn.Kind == window.Sessions {
+			required[MergingWindowsCapability] = append(required[MergingWindowsCapability], edge.Scope().String())
+		}
+		for _, out := range edge.Output {
+			if !out.To.Bounded() {
+				required[UnboundedCapability] = append(required[UnboundedCapability], edge.Scope().String())
+				break
+			}
+		}
+	}
+
+	var problems []string
+	for c, scopes := range required {
+		if !RunnerSupports(runner, c) {
+			problems = append(problems, fmt.Sprintf("%v (required by %v)", c, strings.Join(dedup(scopes), ", ")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.Errorf("runner %v does not support:\n\t%v", runner, strings.Join(problems, "\n\t"))
+}
+
+func dedup(list []string) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
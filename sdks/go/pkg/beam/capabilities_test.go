@@ -0,0 +1,66 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+)
+
+func init() {
+	beam.RegisterCapabilities("test_limited", beam.UnboundedCapability)
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "a", "b")
+	beam.WindowInto(s.Scope("a"), window.NewSessions(time.Minute), col)
+	beam.WindowInto(s.Scope("b"), window.NewSessions(time.Minute), col)
+	beam.WindowInto(s.Scope("c"), window.NewFixedWindows(time.Minute), col)
+
+	err := beam.CheckCapabilities(p, "test_limited")
+	if err == nil {
+		t.Fatal("CheckCapabilities succeeded, want error")
+	}
+	if want := "merging_windows (required by root/a, root/b)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("CheckCapabilities = %v, want %q", err, want)
+	}
+
+	if err := beam.CheckCapabilities(p, "test_unregistered"); err != nil {
+		t.Errorf("CheckCapabilities = %v for runner without registered capabilities, want nil", err)
+	}
+}
+
+func TestRunnerSupports(t *testing.T) {
+	tests := []struct {
+		runner string
+		c      beam.Capability
+		exp    bool
+	}{
+		{"test_limited", beam.UnboundedCapability, true},
+		{"test_limited", beam.MergingWindowsCapability, false},
+		{"test_unregistered", beam.MergingWindowsCapability, true},
+	}
+	for _, test := range tests {
+		if got := beam.RunnerSupports(test.runner, test.c); got != test.exp {
+			t.Errorf("RunnerSupports(%v, %v) = %v, want %v", test.runner, test.c, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"fmt"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+)
+
+// CheckpointOptions configure Checkpoint.
+type CheckpointOptions struct {
+	// Shards is the number of groups that the elements are materialized
+	// in, which bounds the parallelism of the steps after the checkpoint.
+	// If zero, the elements are spread across workers arbitrarily.
+	Shards int
+}
+
+// Checkpoint returns a PCollection with the same elements, windows and
+// timestamps as its input, which are materialized before they are
+// processed further. It is meant to be placed before sinks that are not
+// idempotent, such as a DoFn that sends notifications:
+//
+//    results := beam.ParDo(s, &scoreFn{}, users)
+//    beam.ParDo0(s, &notifyFn{}, beam.Checkpoint(s, results, beam.CheckpointOptions{}))
+//
+// If the sink fails and is retried, it is given the materialized input
+// again, rather than results that the upstream steps computed anew and
+// that may differ if they are not deterministic, such as if they call
+// external services or use random numbers.
+//
+// Unlike Reshuffle, which runners may implement as a redistribution only,
+// Checkpoint is always expanded to a GroupByKey, as in the expansion of
+// Reshuffle. Its guarantee is that of
+// the GroupByKey of the runner, whose output is durable on runners such as
+// Dataflow.
+func Checkpoint(s Scope, col PCollection, opts CheckpointOptions) PCollection {
+	s = s.Scope("beam.Checkpoint")
+
+	if opts.Shards < 0 {
+		panic(fmt.Sprintf("invalid number of shards: %v", opts.Shards))
+	}
+	if !typex.IsKV(col.Type()) {
+		ValidateNonCompositeType(col)
+	}
+	return materialize(s, col, opts.Shards)
+}
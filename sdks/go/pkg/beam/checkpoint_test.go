@@ -0,0 +1,60 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(checkpointTime)
+	beam.RegisterFunction(formatTimestamped)
+	beam.RegisterFunction(formatTimestampedKV)
+}
+
+func checkpointTime(elm string) beam.EventTime {
+	return beam.EventTime(len(elm) * 1000)
+}
+
+func formatTimestamped(t beam.EventTime, elm string) string {
+	return fmt.Sprintf("%v@%v", elm, t.Milliseconds())
+}
+
+func formatTimestampedKV(t beam.EventTime, k, v string) string {
+	return fmt.Sprintf("%v=%v@%v", k, v, t.Milliseconds())
+}
+
+func TestCheckpoint(t *testing.T) {
+	for _, shards := range []int{0, 2} {
+		p, s := beam.NewPipelineWithRoot()
+		col := beam.WithTimestamps(s, checkpointTime, beam.Create(s, "a", "bb", "ccc"))
+		out := beam.Checkpoint(s, col, beam.CheckpointOptions{Shards: shards})
+		passert.Equals(s, beam.ParDo(s, formatTimestamped, out), "a@1000", "bb@2000", "ccc@3000")
+
+		in := beam.ParDo(s, splitKV, beam.Create(s, "a=1", "b=2"))
+		kvs := beam.Checkpoint(s, in, beam.CheckpointOptions{Shards: shards})
+		passert.Equals(s, beam.ParDo(s, formatTimestampedKV, kvs), beam.ParDo(s, formatTimestampedKV, in))
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("Checkpoint with %v shards failed: %v", shards, err)
+		}
+	}
+}
@@ -324,6 +324,16 @@ func newDoFnNode(op Opcode, g *Graph, s *Scope, u *DoFn, in []*Node, typedefs ma
 // by a runner and should set this scope's URN and Payload accordingly.
 const CombinePerKeyScope = "CombinePerKey"
 
+// Canonical names of the redistribution composite scopes. Like
+// CombinePerKeyScope, they permit the translation layer to attach the
+// corresponding portable URNs to the composite, while the GroupByKey
+// based expansion beneath remains for runners that don't recognize them.
+const (
+	ReshuffleScope               = "Reshuffle"
+	RedistributeByKeyScope       = "RedistributeByKey"
+	RedistributeArbitrarilyScope = "RedistributeArbitrarily"
+)
+
 // NewCombine inserts a new Combine edge into the graph. Combines cannot have side
 // input.
 func NewCombine(g *Graph, s *Scope, u *CombineFn, in *Node, ac *coder.Coder) (*MultiEdge, error) {
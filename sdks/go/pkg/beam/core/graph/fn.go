@@ -17,7 +17,9 @@ package graph
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam/core/funcx"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
@@ -119,7 +121,7 @@ func NewFn(fn interface{}) (*Fn, error) {
 			if m.PkgPath != "" {
 				continue // skip: unexported
 			}
-			if m.Name == "String" {
+			if m.Name == "String" || m.Name == allowedTimestampSkewName {
 				continue // skip: harmless
 			}
 
@@ -194,6 +196,27 @@ func (f *DoFn) Name() string {
 	return (*Fn)(f).Name()
 }
 
+// allowedTimestampSkewName is the name of the optional method of DoFns that
+// returns their allowed timestamp skew, which is not a processing method.
+const allowedTimestampSkewName = "AllowedTimestampSkew"
+
+// InfiniteTimestampSkew allows a DoFn to output elements with any timestamp.
+const InfiniteTimestampSkew = time.Duration(math.MaxInt64)
+
+// AllowedTimestampSkew returns the duration by which the timestamps of the
+// elements the DoFn outputs while processing an element may be earlier than
+// the timestamp of that element. It is InfiniteTimestampSkew, so outputs
+// are not checked, unless the DoFn is a struct with an
+// AllowedTimestampSkew() time.Duration method.
+func (f *DoFn) AllowedTimestampSkew() time.Duration {
+	if s, ok := f.Recv.(interface {
+		AllowedTimestampSkew() time.Duration
+	}); ok {
+		return s.AllowedTimestampSkew()
+	}
+	return InfiniteTimestampSkew
+}
+
 // TODO(herohde) 5/19/2017: we can sometimes detect whether the main input must be
 // a KV or not based on the other signatures (unless we're more loose about which
 // sideinputs are present). Bind should respect that.
@@ -36,7 +36,7 @@ type Graph struct {
 
 // New returns an empty graph with the scope set to the root.
 func New() *Graph {
-	root := &Scope{0, "root", nil}
+	root := &Scope{id: 0, Label: "root"}
 	return &Graph{root: root}
 }
 
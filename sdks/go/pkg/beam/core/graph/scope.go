@@ -24,6 +24,9 @@ type Scope struct {
 	Label string
 	// Parent is the parent scope, if nested.
 	Parent *Scope
+	// Environment is the name of the environment that the transforms in the
+	// scope run in. If empty, it is inherited from the parent.
+	Environment string
 }
 
 // ID returns the graph-local identifier for the scope.
@@ -31,6 +34,17 @@ func (s *Scope) ID() int {
 	return s.id
 }
 
+// EnvironmentName returns the name of the environment that the transforms in
+// the scope run in, or the empty string for the default environment.
+func (s *Scope) EnvironmentName() string {
+	for ; s != nil; s = s.Parent {
+		if s.Environment != "" {
+			return s.Environment
+		}
+	}
+	return ""
+}
+
 func (s *Scope) String() string {
 	if s.Parent == nil {
 		return s.Label
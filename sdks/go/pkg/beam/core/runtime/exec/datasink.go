@@ -20,24 +20,35 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"sync/atomic"
 	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/coder"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/metrics"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
 	"github.com/apache/beam/sdks/go/pkg/beam/log"
 )
 
+// MaxElementBytesOption is the pipeline option of the maximum size of the
+// encoding of the elements written by a DataSink. Larger elements are
+// dropped and counted by the counter "oversized" in the "oversize"
+// namespace, rather than failing the bundle at the runner.
+const MaxElementBytesOption = "max_element_bytes"
+
 // DataSink is a Node.
 type DataSink struct {
 	UID   UnitID
 	SID   StreamID
 	Coder *coder.Coder
 
-	enc   ElementEncoder
-	wEnc  WindowEncoder
-	w     io.WriteCloser
-	count int64
-	start time.Time
+	enc       ElementEncoder
+	wEnc      WindowEncoder
+	w         io.WriteCloser
+	count     int64
+	start     time.Time
+	max       int
+	oversized *metrics.Counter
 }
 
 func (n *DataSink) ID() UnitID {
@@ -47,6 +58,14 @@ func (n *DataSink) ID() UnitID {
 func (n *DataSink) Up(ctx context.Context) error {
 	n.enc = MakeElementEncoder(coder.SkipW(n.Coder))
 	n.wEnc = MakeWindowEncoder(n.Coder.Window)
+	if v := runtime.GlobalOptions.Get(MaxElementBytesOption); v != "" {
+		max, err := strconv.Atoi(v)
+		if err != nil || max <= 0 {
+			return fmt.Errorf("invalid %v option: %q", MaxElementBytesOption, v)
+		}
+		n.max = max
+		n.oversized = metrics.NewCounter("oversize", "oversized")
+	}
 	return nil
 }
 
@@ -73,6 +92,11 @@ func (n *DataSink) ProcessElement(ctx context.Context, value *FullValue, values
 	if err := n.enc.Encode(value, &b); err != nil {
 		return fmt.Errorf("failed to encode element %v with coder %v: %v", value, n.enc, err)
 	}
+	if n.max > 0 && b.Len() > n.max {
+		n.oversized.Inc(metrics.SetPTransformID(ctx, n.SID.Target.ID), 1)
+		log.Warnf(ctx, "DataSink %v: dropped element of %v bytes, more than the maximum of %v bytes", n.SID, b.Len(), n.max)
+		return nil
+	}
 	if _, err := n.w.Write(b.Bytes()); err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/coder"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+)
+
+// bufferManager is a DataManager that writes to a buffer.
+type bufferManager struct {
+	buf bytes.Buffer
+}
+
+func (m *bufferManager) OpenRead(ctx context.Context, id StreamID) (io.ReadCloser, error) {
+	return nil, io.EOF
+}
+
+func (m *bufferManager) OpenWrite(ctx context.Context, id StreamID) (io.WriteCloser, error) {
+	return nopCloser{&m.buf}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestDataSinkMaxElementBytes(t *testing.T) {
+	runtime.GlobalOptions.Set(MaxElementBytesOption, "20")
+	defer runtime.GlobalOptions.Set(MaxElementBytesOption, "")
+
+	ctx := context.Background()
+	sink := &DataSink{UID: 1, Coder: coder.NewW(coder.NewBytes(), coder.NewGlobalWindow())}
+	data := &bufferManager{}
+	if err := sink.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if err := sink.StartBundle(ctx, "bundle", DataContext{Data: data}); err != nil {
+		t.Fatalf("StartBundle failed: %v", err)
+	}
+	for _, elm := range []string{"small", strings.Repeat("x", 100), "tiny"} {
+		value := &FullValue{Elm: []byte(elm), Windows: window.SingleGlobalWindow}
+		if err := sink.ProcessElement(ctx, value); err != nil {
+			t.Fatalf("ProcessElement(%v) failed: %v", elm, err)
+		}
+	}
+	if err := sink.FinishBundle(ctx); err != nil {
+		t.Fatalf("FinishBundle failed: %v", err)
+	}
+
+	if got := data.buf.String(); !strings.Contains(got, "small") || !strings.Contains(got, "tiny") || strings.Contains(got, "xxx") {
+		t.Errorf("DataSink wrote %q, want the small elements only", got)
+	}
+}
+
+func TestDataSinkInvalidMaxElementBytes(t *testing.T) {
+	runtime.GlobalOptions.Set(MaxElementBytesOption, "-1")
+	defer runtime.GlobalOptions.Set(MaxElementBytesOption, "")
+
+	sink := &DataSink{UID: 1, Coder: coder.NewW(coder.NewBytes(), coder.NewGlobalWindow())}
+	if err := sink.Up(context.Background()); err == nil {
+		t.Errorf("Up succeeded, want an error for a negative maximum")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam/core/funcx"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
@@ -45,6 +46,14 @@ type ParDo struct {
 	side  SideInputReader
 	cache *cacheElm
 
+	// skew is the allowed timestamp skew of the DoFn. While an element is
+	// processed, input holds its timestamp and skewErr the first output that
+	// is too early, if any.
+	skew       time.Duration
+	processing bool
+	input      typex.EventTime
+	skewErr    error
+
 	status Status
 	err    errorx.GuardedError
 }
@@ -78,7 +87,17 @@ func (n *ParDo) Up(ctx context.Context) error {
 		return n.fail(err)
 	}
 
-	emitters, err := makeEmitters(n.Fn.ProcessElementFn(), n.Out)
+	// Outputs are checked against the timestamp of the element being
+	// processed, if the DoFn declares an allowed skew.
+	n.skew = n.Fn.AllowedTimestampSkew()
+	outs := n.Out
+	if n.skew != graph.InfiniteTimestampSkew {
+		outs = make([]Node, len(n.Out))
+		for i, out := range n.Out {
+			outs[i] = &skewCheck{Node: out, pardo: n}
+		}
+	}
+	emitters, err := makeEmitters(n.Fn.ProcessElementFn(), outs)
 	if err != nil {
 		return n.fail(err)
 	}
@@ -256,7 +275,7 @@ func (n *ParDo) invokeDataFn(ctx context.Context, ws []typex.Window, ts typex.Ev
 	if err := n.preInvoke(ctx, ws, ts); err != nil {
 		return nil, err
 	}
-	val, err := Invoke(ctx, ws, ts, fn, opt, n.cache.extra...)
+	val, err := Invoke(ctx, ws, ts, fn, opt, bundleArgs(fn, n.cache.extra)...)
 	if err != nil {
 		return nil, err
 	}
@@ -266,21 +285,73 @@ func (n *ParDo) invokeDataFn(ctx context.Context, ws []typex.Window, ts typex.Ev
 	return val, nil
 }
 
+// bundleArgs returns the side inputs and emitters of extra that the bundle
+// method fn takes. Bundle methods may omit the side inputs, which come first,
+// or take no side inputs and emitters at all.
+func bundleArgs(fn *funcx.Fn, extra []interface{}) []interface{} {
+	n := len(fn.Params(funcx.FnValue | funcx.FnIter | funcx.FnReIter | funcx.FnEmit))
+	if n >= len(extra) {
+		return extra
+	}
+	return extra[len(extra)-n:]
+}
+
 // invokeProcessFn handles the per element invocations
 func (n *ParDo) invokeProcessFn(ctx context.Context, ws []typex.Window, ts typex.EventTime, opt *MainInput) (*FullValue, error) {
 	if err := n.preInvoke(ctx, ws, ts); err != nil {
 		return nil, err
 	}
+	n.processing, n.input, n.skewErr = true, ts, nil
 	val, err := n.inv.Invoke(ctx, ws, ts, opt, n.cache.extra...)
+	n.processing = false
 	if err != nil {
 		return nil, err
 	}
+	if n.skewErr != nil {
+		return nil, n.skewErr
+	}
+	if val != nil && n.skew != graph.InfiniteTimestampSkew {
+		if err := n.checkSkew(val.Timestamp, ts); err != nil {
+			return nil, err
+		}
+	}
 	if err := n.postInvoke(); err != nil {
 		return nil, err
 	}
 	return val, nil
 }
 
+// checkSkew returns an error if the output timestamp is earlier than the
+// input timestamp by more than the allowed skew.
+func (n *ParDo) checkSkew(output, input typex.EventTime) error {
+	if output >= input || int64(input-output) <= int64(n.skew/time.Millisecond) {
+		return nil
+	}
+	return fmt.Errorf("%v output an element with timestamp %v, which is %v before the timestamp %v of its input; "+
+		"the allowed timestamp skew is %v", n.Fn.Name(), output, time.Duration(input-output)*time.Millisecond, input, n.skew)
+}
+
+// skewCheck checks the timestamps of the elements that a ParDo emits
+// while processing an element. Emitters cannot return errors, so the first
+// error is kept by the ParDo and returned when processing completes.
+type skewCheck struct {
+	Node
+	pardo *ParDo
+}
+
+func (c *skewCheck) ProcessElement(ctx context.Context, elm *FullValue, values ...ReStream) error {
+	p := c.pardo
+	if p.processing {
+		if err := p.checkSkew(elm.Timestamp, p.input); err != nil {
+			if p.skewErr == nil {
+				p.skewErr = err
+			}
+			return nil
+		}
+	}
+	return c.Node.ProcessElement(ctx, elm, values...)
+}
+
 func (n *ParDo) preInvoke(ctx context.Context, ws []typex.Window, ts typex.EventTime) error {
 	for _, e := range n.emitters {
 		if err := e.Init(ctx, ws, ts); err != nil {
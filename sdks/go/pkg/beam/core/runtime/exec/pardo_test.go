@@ -18,6 +18,7 @@ package exec
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
@@ -97,6 +98,99 @@ func TestParDo(t *testing.T) {
 	}
 }
 
+// shiftFn emits its inputs with timestamps Shift milliseconds earlier.
+type shiftFn struct {
+	Shift int64
+	Skew  time.Duration
+}
+
+func (f *shiftFn) AllowedTimestampSkew() time.Duration {
+	return f.Skew
+}
+
+func (f *shiftFn) ProcessElement(et typex.EventTime, n int, emit func(typex.EventTime, int)) {
+	emit(et-typex.EventTime(f.Shift), n)
+}
+
+// TestParDoTimestampSkew verifies that outputs earlier than their inputs
+// fail the bundle, unless the DoFn allows the skew.
+func TestParDoTimestampSkew(t *testing.T) {
+	tests := []struct {
+		fn  *shiftFn
+		err bool
+	}{
+		{&shiftFn{Shift: 0}, false},
+		{&shiftFn{Shift: 10}, true},
+		{&shiftFn{Shift: 10, Skew: 10 * time.Millisecond}, false},
+		{&shiftFn{Shift: 11, Skew: 10 * time.Millisecond}, true},
+		{&shiftFn{Shift: 1000, Skew: graph.InfiniteTimestampSkew}, false},
+	}
+	for _, test := range tests {
+		fn, err := graph.NewDoFn(test.fn)
+		if err != nil {
+			t.Fatalf("invalid function: %v", err)
+		}
+		g := graph.New()
+		nN := g.NewNode(typex.New(reflectx.Int), window.DefaultWindowingStrategy(), true)
+		edge, err := graph.NewParDo(g, g.Root(), fn, []*graph.Node{nN}, nil)
+		if err != nil {
+			t.Fatalf("invalid pardo: %v", err)
+		}
+
+		out := &CaptureNode{UID: 1}
+		pardo := &ParDo{UID: 2, Fn: edge.DoFn, Inbound: edge.Input, Out: []Node{out}}
+		n := &FixedRoot{UID: 3, Elements: makeInput(1, 2), Out: pardo}
+		p, err := NewPlan("a", []Unit{n, pardo, out})
+		if err != nil {
+			t.Fatalf("failed to construct plan: %v", err)
+		}
+
+		err = p.Execute(context.Background(), "1", DataContext{})
+		if test.err && err == nil {
+			t.Errorf("pardo(%+v) succeeded, want timestamp skew error", *test.fn)
+		}
+		if !test.err && (err != nil || len(out.Elements) != 2) {
+			t.Errorf("pardo(%+v) = %v, %v, want 2 elements", *test.fn, extractValues(out.Elements...), err)
+		}
+	}
+}
+
+// backdateFn emits its inputs with timestamps a second earlier, as function
+// DoFns that assign data timestamps do.
+func backdateFn(et typex.EventTime, n int, emit func(typex.EventTime, int)) {
+	emit(et-1000, n)
+}
+
+// TestParDoTimestampSkewUnchecked verifies that the outputs of DoFns that
+// do not declare an allowed skew are not checked.
+func TestParDoTimestampSkewUnchecked(t *testing.T) {
+	fn, err := graph.NewDoFn(backdateFn)
+	if err != nil {
+		t.Fatalf("invalid function: %v", err)
+	}
+	g := graph.New()
+	nN := g.NewNode(typex.New(reflectx.Int), window.DefaultWindowingStrategy(), true)
+	edge, err := graph.NewParDo(g, g.Root(), fn, []*graph.Node{nN}, nil)
+	if err != nil {
+		t.Fatalf("invalid pardo: %v", err)
+	}
+
+	out := &CaptureNode{UID: 1}
+	pardo := &ParDo{UID: 2, Fn: edge.DoFn, Inbound: edge.Input, Out: []Node{out}}
+	n := &FixedRoot{UID: 3, Elements: makeInput(1, 2), Out: pardo}
+	p, err := NewPlan("a", []Unit{n, pardo, out})
+	if err != nil {
+		t.Fatalf("failed to construct plan: %v", err)
+	}
+
+	if err := p.Execute(context.Background(), "1", DataContext{}); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(out.Elements) != 2 {
+		t.Errorf("pardo(backdateFn) = %v, want 2 elements", extractValues(out.Elements...))
+	}
+}
+
 func emitSumFn(n int, emit func(int)) {
 	emit(n + 1)
 }
@@ -39,6 +39,10 @@ const (
 	URNCombinePerKey = "beam:transform:combine_per_key:v1"
 	URNWindow        = "beam:transform:window:v1"
 
+	URNReshuffle               = "beam:transform:reshuffle:v1"
+	URNRedistributeByKey       = "beam:transform:redistribute_by_key:v1"
+	URNRedistributeArbitrarily = "beam:transform:redistribute_arbitrarily:v1"
+
 	// URNIterableSideInput = "beam:side_input:iterable:v1"
 	URNMultimapSideInput = "beam:side_input:multimap:v1"
 
@@ -64,10 +68,20 @@ const (
 type Options struct {
 	// Environment used to run the user code.
 	Environment pb.Environment
+	// Environments are the named environments used to run the user code of
+	// transforms in scopes with an environment, see graph.Scope.
+	Environments map[string]pb.Environment
 }
 
 // Marshal converts a graph to a model pipeline.
 func Marshal(edges []*graph.MultiEdge, opt *Options) (*pb.Pipeline, error) {
+	for _, edge := range edges {
+		if name := edge.Scope().EnvironmentName(); name != "" {
+			if _, ok := opt.Environments[name]; !ok {
+				return nil, fmt.Errorf("no environment %v for %v", name, edge)
+			}
+		}
+	}
 	tree := NewScopeTree(edges)
 
 	m := newMarshaller(opt)
@@ -139,6 +153,7 @@ func (m *marshaller) addScopeTree(s *ScopeTree) string {
 	}
 
 	m.updateIfCombineComposite(s, transform)
+	m.updateIfRedistributeComposite(s, transform)
 
 	m.transforms[id] = transform
 	return id
@@ -165,13 +180,37 @@ func (m *marshaller) updateIfCombineComposite(s *ScopeTree, transform *pb.PTrans
 				Urn:     URNJavaDoFn,
 				Payload: []byte(mustEncodeMultiEdgeBase64(edge)),
 			},
-			EnvironmentId: m.addDefaultEnv(),
+			EnvironmentId: m.addEnv(edge.Scope().EnvironmentName()),
 		},
 		AccumulatorCoderId: acID,
 	}
 	transform.Spec = &pb.FunctionSpec{Urn: URNCombinePerKey, Payload: protox.MustEncode(payload)}
 }
 
+var redistributeURNs = map[string]string{
+	graph.ReshuffleScope:               URNReshuffle,
+	graph.RedistributeByKeyScope:       URNRedistributeByKey,
+	graph.RedistributeArbitrarilyScope: URNRedistributeArbitrarily,
+}
+
+// updateIfRedistributeComposite examines the scope tree and sets the PTransform
+// Spec to the matching redistribution URN, if it's one of the Reshuffle or
+// Redistribute composites. These carry no payload. As for combine, the
+// composite must contain the GroupByKey that implements it in its default
+// representation.
+func (m *marshaller) updateIfRedistributeComposite(s *ScopeTree, transform *pb.PTransform) {
+	urn, ok := redistributeURNs[s.Scope.Name]
+	if !ok {
+		return
+	}
+	for _, edge := range s.Edges {
+		if edge.Edge.Op == graph.CoGBK {
+			transform.Spec = &pb.FunctionSpec{Urn: urn}
+			return
+		}
+	}
+}
+
 func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 	id := edgeID(edge.Edge)
 	if _, exists := m.transforms[id]; exists {
@@ -182,6 +221,7 @@ func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 		return m.expandCoGBK(edge)
 	}
 
+	env := edge.Edge.Scope().EnvironmentName()
 	inputs := make(map[string]string)
 	for i, in := range edge.Edge.Input {
 		m.addNode(in.From)
@@ -222,7 +262,7 @@ func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 								Urn: URNIterableSideInputKey,
 							})),
 						},
-						EnvironmentId: m.addDefaultEnv(),
+						EnvironmentId: m.addEnv(env),
 					},
 				}
 
@@ -249,13 +289,13 @@ func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 						Spec: &pb.FunctionSpec{
 							Urn: "foo",
 						},
-						EnvironmentId: m.addDefaultEnv(),
+						EnvironmentId: m.addEnv(env),
 					},
 					WindowMappingFn: &pb.SdkFunctionSpec{
 						Spec: &pb.FunctionSpec{
 							Urn: "bar",
 						},
-						EnvironmentId: m.addDefaultEnv(),
+						EnvironmentId: m.addEnv(env),
 					},
 				}
 
@@ -273,7 +313,7 @@ func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 					Urn:     URNJavaDoFn,
 					Payload: []byte(mustEncodeMultiEdgeBase64(edge.Edge)),
 				},
-				EnvironmentId: m.addDefaultEnv(),
+				EnvironmentId: m.addEnv(env),
 			},
 			SideInputs: si,
 		}
@@ -286,7 +326,7 @@ func (m *marshaller) addMultiEdge(edge NamedEdge) string {
 					Urn:     URNJavaDoFn,
 					Payload: []byte(mustEncodeMultiEdgeBase64(edge.Edge)),
 				},
-				EnvironmentId: m.addDefaultEnv(),
+				EnvironmentId: m.addEnv(env),
 			},
 		}
 		spec = &pb.FunctionSpec{Urn: URNParDo, Payload: protox.MustEncode(payload)}
@@ -474,6 +514,20 @@ func (m *marshaller) addDefaultEnv() string {
 	return id
 }
 
+// addEnv adds the named environment, which Marshal has checked exists, or the
+// default environment if the name is empty.
+func (m *marshaller) addEnv(name string) string {
+	if name == "" {
+		return m.addDefaultEnv()
+	}
+	id := "go-" + name
+	if _, exists := m.environments[id]; !exists {
+		env := m.opt.Environments[name]
+		m.environments[id] = &env
+	}
+	return id
+}
+
 func (m *marshaller) addWindowingStrategy(w *window.WindowingStrategy) string {
 	ws := marshalWindowingStrategy(m.coders, w)
 	return m.internWindowingStrategy(ws)
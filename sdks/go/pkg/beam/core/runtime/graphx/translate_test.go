@@ -94,3 +94,58 @@ func TestParDo(t *testing.T) {
 		t.Errorf("bad ParDo translation: %v", proto.MarshalTextString(p))
 	}
 }
+
+// TestEnvironments verifies that transforms in a scope with an environment
+// run in that environment.
+func TestEnvironments(t *testing.T) {
+	g := graph.New()
+	dofn, err := graph.NewDoFn(pickFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := g.NewNode(intT(), window.DefaultWindowingStrategy(), true)
+	in.Coder = intCoder()
+	gpu := g.NewScope(g.Root(), "gpu")
+	gpu.Environment = "gpu"
+	e, err := graph.NewParDo(g, g.NewScope(gpu, "pick"), dofn, []*graph.Node{in}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Output[0].To.Coder = intCoder()
+	e.Output[1].To.Coder = intCoder()
+
+	edges, _, err := g.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graphx.Marshal(edges, &graphx.Options{}); err == nil {
+		t.Error("Marshal succeeded without the gpu environment, want error")
+	}
+
+	opt := &graphx.Options{
+		Environment:  pb.Environment{Urn: "beam:env:docker:v1"},
+		Environments: map[string]pb.Environment{"gpu": {Urn: "beam:env:docker:v1", Payload: []byte("gpu")}},
+	}
+	p, err := graphx.Marshal(edges, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, transform := range p.GetComponents().GetTransforms() {
+		if transform.GetSpec().GetUrn() != graphx.URNParDo {
+			continue
+		}
+		found = true
+		var payload pb.ParDoPayload
+		if err := proto.Unmarshal(transform.GetSpec().GetPayload(), &payload); err != nil {
+			t.Fatal(err)
+		}
+		id := payload.GetDoFn().GetEnvironmentId()
+		if env := p.GetComponents().GetEnvironments()[id]; string(env.GetPayload()) != "gpu" {
+			t.Errorf("ParDo runs in environment %v: %v, want gpu", id, env)
+		}
+	}
+	if !found {
+		t.Errorf("no ParDo in %v", proto.MarshalTextString(p))
+	}
+}
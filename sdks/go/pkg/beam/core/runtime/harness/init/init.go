@@ -68,6 +68,14 @@ func hook() {
 			fmt.Fprintf(os.Stderr, "Failed to parse pipeline options '%v': %v", *options, err)
 			os.Exit(1)
 		}
+		if len(opt.Parameters) > 0 {
+			if opt.Options.Options == nil {
+				opt.Options.Options = make(map[string]string)
+			}
+			for name, value := range opt.Parameters {
+				opt.Options.Options[runtime.ParameterKey(name)] = value
+			}
+		}
 		runtime.GlobalOptions.Import(opt.Options)
 	}
 
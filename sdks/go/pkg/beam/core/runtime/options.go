@@ -66,6 +66,16 @@ type RawOptionsWrapper struct {
 	Runner      string     `json:"beam:option:runner:v1"`
 	AppName     string     `json:"beam:option:app_name:v1"`
 	Experiments []string   `json:"beam:option:experiments:v1"`
+
+	// Parameters override the values of template parameters by name. They
+	// allow a launcher to run a constructed pipeline with different values.
+	Parameters map[string]string `json:"beam:option:go_parameters:v1,omitempty"`
+}
+
+// ParameterKey returns the key of the template parameter with the given
+// name in the options.
+func ParameterKey(name string) string {
+	return "beam:parameter:" + name
 }
 
 // Import imports the options from previously exported data and makes the
@@ -89,6 +99,15 @@ func (o *Options) Get(key string) string {
 	return o.opt[key]
 }
 
+// Lookup returns the value of the key and whether it has been set.
+func (o *Options) Lookup(key string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	v, ok := o.opt[key]
+	return v, ok
+}
+
 // Set defines the value of the given key. If the key is already defined, it
 // panics.
 func (o *Options) Set(key, value string) {
@@ -0,0 +1,342 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	bqstorage "cloud.google.com/go/bigquery/storage/apiv1beta1"
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+	"github.com/linkedin/goavro"
+	storagepb "google.golang.org/genproto/googleapis/cloud/bigquery/storage/v1beta1"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*createReadSessionFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*readStreamFn)(nil)).Elem())
+}
+
+// StorageReadOptions configure a read with the BigQuery Storage API.
+type StorageReadOptions struct {
+	// SelectedFields are the names of the columns to read. Nested fields
+	// may be selected as "a.b". If empty, the top-level fields of the
+	// schema type are read.
+	SelectedFields []string
+	// RowRestriction is a SQL filter applied by the server, such as
+	// "state = 'WA' AND num > 10". If empty, all rows are read.
+	RowRestriction string
+	// MaxStreams is the maximum number of streams the table is split into,
+	// which bounds the parallelism of the read. If zero, the server picks a
+	// suitable number.
+	MaxStreams int
+}
+
+// ReadWithStorageAPI reads the rows of the given table using the BigQuery
+// Storage Read API. The table must have a schema compatible with the given
+// type, t, and ReadWithStorageAPI returns a PCollection<t>. For example:
+//
+//    type Sale struct {
+//        Item  string  `bigquery:"item"`
+//        Price float64 `bigquery:"price"`
+//    }
+//
+//    sales := bigqueryio.ReadWithStorageAPI(s, project, "p:shop.sales", reflect.TypeOf(Sale{}),
+//        bigqueryio.StorageReadOptions{RowRestriction: "price > 100"})
+//
+// Unlike Read, it does not run a query job. Only the selected columns are
+// read and rows are filtered by the server. The table is split into streams
+// that are read in parallel.
+func ReadWithStorageAPI(s beam.Scope, project, table string, t reflect.Type, opts StorageReadOptions) beam.PCollection {
+	qn := mustParseTable(table)
+	schema := mustInferSchema(t)
+
+	s = s.Scope("bigquery.ReadWithStorageAPI")
+
+	fields := opts.SelectedFields
+	if len(fields) == 0 {
+		for _, f := range schema {
+			fields = append(fields, f.Name)
+		}
+	}
+	if opts.MaxStreams < 0 {
+		panic(fmt.Sprintf("invalid number of streams: %v", opts.MaxStreams))
+	}
+
+	imp := beam.Impulse(s)
+	streams := beam.ParDo(s, &createReadSessionFn{
+		Project:        project,
+		Table:          qn,
+		SelectedFields: fields,
+		RowRestriction: opts.RowRestriction,
+		MaxStreams:     opts.MaxStreams,
+	}, imp)
+	// Prevent fusion, so that the streams are read in parallel.
+	streams = beam.Reshuffle(s, streams)
+	return beam.ParDo(s, &readStreamFn{Type: beam.EncodedType{T: t}}, streams, beam.TypeDefinition{Var: beam.XType, T: t})
+}
+
+// createReadSessionFn creates a read session for the table and emits each of
+// its streams together with the Avro schema of the rows.
+type createReadSessionFn struct {
+	// Project is the project billed for the read.
+	Project string `json:"project"`
+	// Table is the qualified table identifier.
+	Table QualifiedTableName `json:"table"`
+	// SelectedFields are the columns to read.
+	SelectedFields []string `json:"selected_fields"`
+	// RowRestriction is the server-side row filter.
+	RowRestriction string `json:"row_restriction,omitempty"`
+	// MaxStreams is the maximum number of streams. Zero lets the server decide.
+	MaxStreams int `json:"max_streams,omitempty"`
+}
+
+func (f *createReadSessionFn) ProcessElement(ctx context.Context, _ []byte, emit func(string, string)) error {
+	client, err := bqstorage.NewBigQueryStorageClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.CreateReadSession(ctx, &storagepb.CreateReadSessionRequest{
+		TableReference: &storagepb.TableReference{
+			ProjectId: f.Table.Project,
+			DatasetId: f.Table.Dataset,
+			TableId:   f.Table.Table,
+		},
+		Parent:           "projects/" + f.Project,
+		RequestedStreams: int32(f.MaxStreams),
+		ReadOptions: &storagepb.TableReadOptions{
+			SelectedFields: f.SelectedFields,
+			RowRestriction: f.RowRestriction,
+		},
+		Format: storagepb.DataFormat_AVRO,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create read session for %v", f.Table)
+	}
+
+	log.Infof(ctx, "Reading %v with %v streams", f.Table, len(session.GetStreams()))
+
+	schema := session.GetAvroSchema().GetSchema()
+	for _, stream := range session.GetStreams() {
+		emit(stream.GetName(), schema)
+	}
+	return nil
+}
+
+// readStreamFn reads all rows of a stream of a read session.
+type readStreamFn struct {
+	// Type is the encoded schema type.
+	Type beam.EncodedType `json:"type"`
+}
+
+func (f *readStreamFn) ProcessElement(ctx context.Context, stream, schema string, emit func(beam.X)) error {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Avro schema for stream %v", stream)
+	}
+
+	client, err := bqstorage.NewBigQueryStorageClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	rows, err := client.ReadRows(ctx, &storagepb.ReadRowsRequest{
+		ReadPosition: &storagepb.StreamPosition{Stream: &storagepb.Stream{Name: stream}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to read stream %v", stream)
+	}
+
+	for {
+		resp, err := rows.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read stream %v", stream)
+		}
+
+		buf := resp.GetAvroRows().GetSerializedBinaryRows()
+		for len(buf) > 0 {
+			var native interface{}
+			native, buf, err = codec.NativeFromBinary(buf)
+			if err != nil {
+				return errors.Wrapf(err, "failed to decode row of stream %v", stream)
+			}
+
+			val := reflect.New(f.Type.T) // val : *T
+			if err := decodeAvro(native, val.Elem()); err != nil {
+				return errors.Wrapf(err, "failed to decode row of stream %v into %v", stream, f.Type.T)
+			}
+			emit(val.Elem().Interface()) // emit(*val)
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// decodeAvro assigns the Avro native value, as decoded by goavro, to v. Unions
+// are unwrapped, so NULLABLE columns may be read into non-pointer fields. A
+// null value leaves v as the zero value. Struct fields are matched to record
+// fields by their bigquery tag or, failing that, their name.
+func decodeAvro(native interface{}, v reflect.Value) error {
+	native = unwrapUnion(native, v.Type())
+	if native == nil {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elm := reflect.New(v.Type().Elem())
+		if err := decodeAvro(native, elm.Elem()); err != nil {
+			return err
+		}
+		v.Set(elm)
+		return nil
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return decodeTime(native, v)
+		}
+		record, ok := native.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("cannot decode %T into %v", native, v.Type())
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, ok := fieldName(field)
+			if !ok {
+				continue
+			}
+			value, ok := lookupField(record, name)
+			if !ok {
+				continue // not selected
+			}
+			if err := decodeAvro(value, v.Field(i)); err != nil {
+				return errors.WithContextf(err, "decoding field %v", name)
+			}
+		}
+		return nil
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, ok := native.([]byte)
+			if !ok {
+				return errors.Errorf("cannot decode %T into %v", native, v.Type())
+			}
+			v.SetBytes(b)
+			return nil
+		}
+
+		list, ok := native.([]interface{})
+		if !ok {
+			return errors.Errorf("cannot decode %T into %v", native, v.Type())
+		}
+		ret := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, elm := range list {
+			if err := decodeAvro(elm, ret.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(ret)
+		return nil
+	}
+
+	value := reflect.ValueOf(native)
+	if (value.Kind() == reflect.String) != (v.Kind() == reflect.String) || !value.Type().ConvertibleTo(v.Type()) {
+		return errors.Errorf("cannot decode %T into %v", native, v.Type())
+	}
+	v.Set(value.Convert(v.Type()))
+	return nil
+}
+
+// unwrapUnion returns the value of a union branch, which goavro decodes as a
+// single-entry map keyed by the branch type name. A struct target is
+// ambiguous with a union, because records also decode as maps, so a map is
+// only unwrapped if its key is not a field of the struct.
+func unwrapUnion(native interface{}, t reflect.Type) interface{} {
+	m, ok := native.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return native
+	}
+	for key, value := range m {
+		if t.Kind() == reflect.Struct && t != timeType {
+			if _, ok := value.(map[string]interface{}); !ok {
+				return native
+			}
+			for i := 0; i < t.NumField(); i++ {
+				if name, ok := fieldName(t.Field(i)); ok && strings.EqualFold(name, key) {
+					return native
+				}
+			}
+		}
+		return value
+	}
+	return native
+}
+
+// decodeTime decodes a TIMESTAMP column, which BigQuery encodes as
+// microseconds since the epoch.
+func decodeTime(native interface{}, v reflect.Value) error {
+	switch t := native.(type) {
+	case time.Time:
+		v.Set(reflect.ValueOf(t))
+	case int64:
+		v.Set(reflect.ValueOf(time.Unix(0, t*int64(time.Microsecond)).UTC()))
+	default:
+		return errors.Errorf("cannot decode %T into %v", native, v.Type())
+	}
+	return nil
+}
+
+// fieldName returns the BigQuery column name of the struct field, if any.
+func fieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false // unexported
+	}
+	tag := strings.Split(field.Tag.Get("bigquery"), ",")[0]
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return tag, true
+	}
+}
+
+// lookupField returns the named field of the record. BigQuery column names
+// are case-insensitive.
+func lookupField(record map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := record[name]; ok {
+		return value, true
+	}
+	for key, value := range record {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
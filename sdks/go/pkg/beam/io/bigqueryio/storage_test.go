@@ -0,0 +1,119 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryio
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro"
+)
+
+type storageAddress struct {
+	City string `bigquery:"city"`
+}
+
+type storageRow struct {
+	Name    string         `bigquery:"name"`
+	Count   int            `bigquery:"count"`
+	Score   *float64       `bigquery:"score"`
+	Tags    []string       `bigquery:"tags"`
+	Created time.Time      `bigquery:"created"`
+	Address storageAddress `bigquery:"address"`
+	Missing string         `bigquery:"missing"`
+	Skipped string         `bigquery:"-"`
+}
+
+const storageSchema = `{
+  "type": "record",
+  "name": "__root__",
+  "fields": [
+    {"name": "name", "type": "string"},
+    {"name": "count", "type": ["null", "long"]},
+    {"name": "score", "type": ["null", "double"]},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "created", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}]},
+    {"name": "address", "type": ["null", {"type": "record", "name": "address", "fields": [
+      {"name": "city", "type": ["null", "string"]}
+    ]}]}
+  ]
+}`
+
+func TestDecodeAvro(t *testing.T) {
+	codec, err := goavro.NewCodec(storageSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	rows := []map[string]interface{}{
+		{
+			"name":    "a",
+			"count":   goavro.Union("long", int64(3)),
+			"score":   goavro.Union("double", 1.5),
+			"tags":    []interface{}{"x", "y"},
+			"created": goavro.Union("long", created.UnixNano()/int64(time.Microsecond)),
+			"address": goavro.Union("address", map[string]interface{}{"city": goavro.Union("string", "Seattle")}),
+		},
+		{
+			"name":    "b",
+			"count":   nil,
+			"score":   nil,
+			"tags":    []interface{}{},
+			"created": nil,
+			"address": nil,
+		},
+	}
+
+	score := 1.5
+	exp := []storageRow{
+		{Name: "a", Count: 3, Score: &score, Tags: []string{"x", "y"}, Created: created, Address: storageAddress{City: "Seattle"}},
+		{Name: "b", Tags: []string{}},
+	}
+
+	// Rows are streamed back to back in a single buffer.
+	var buf []byte
+	for _, row := range rows {
+		buf, err = codec.BinaryFromNative(buf, row)
+		if err != nil {
+			t.Fatalf("BinaryFromNative(%v) failed: %v", row, err)
+		}
+	}
+
+	for i := 0; len(buf) > 0; i++ {
+		var native interface{}
+		native, buf, err = codec.NativeFromBinary(buf)
+		if err != nil {
+			t.Fatalf("NativeFromBinary() failed: %v", err)
+		}
+
+		var row storageRow
+		if err := decodeAvro(native, reflect.ValueOf(&row).Elem()); err != nil {
+			t.Fatalf("decodeAvro(%v) failed: %v", native, err)
+		}
+		if !reflect.DeepEqual(row, exp[i]) {
+			t.Errorf("decodeAvro(%v) = %+v, want %+v", native, row, exp[i])
+		}
+	}
+}
+
+func TestDecodeAvroTypeMismatch(t *testing.T) {
+	var row storageRow
+	if err := decodeAvro(map[string]interface{}{"name": int64(1)}, reflect.ValueOf(&row).Elem()); err == nil {
+		t.Errorf("decodeAvro(name: 1) succeeded, want error")
+	}
+}
@@ -0,0 +1,422 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deltaio provides a transformation to read Delta Lake tables.
+// Experimental.
+//
+// Tables are read from a snapshot of their transaction log, such as the
+// latest version or an earlier version for time travel, without a Spark
+// cluster. Each data file of the snapshot is read as a Parquet file and its
+// rows are decoded into a struct type. Fields are matched to columns by
+// their delta tag or, failing that, their case-insensitive name, and
+// partition columns are filled in from the partition values of the file.
+// For example:
+//
+//    type Sale struct {
+//        Item  string    `delta:"item"`
+//        Price float64   `delta:"price"`
+//        Day   time.Time `delta:"day"`
+//    }
+//
+//    sales := deltaio.Read(s, "gs://lake/sales", reflect.TypeOf(Sale{}),
+//        &deltaio.ReadOptions{Partitions: map[string][]string{"day": {"2020-01-01"}}})
+//
+// Tables with deletion vectors or column mapping are not supported.
+package deltaio
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/internal/parquet"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*dataFile)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*snapshotFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*readFileFn)(nil)).Elem())
+}
+
+// ReadOptions configure a read of a table.
+type ReadOptions struct {
+	// Partitions restricts the read to the partitions with the given values
+	// of partition columns, by column name. Values are as in the
+	// transaction log, such as "2020-01-01" for a date. Files with a null
+	// value of a listed column are skipped.
+	Partitions map[string][]string
+}
+
+// Read reads the latest snapshot of the table at the given path and
+// returns a PCollection<t> of its rows. The type t must be a struct.
+func Read(s beam.Scope, path string, t reflect.Type, opts *ReadOptions) beam.PCollection {
+	s = s.Scope("deltaio.Read")
+	return read(s, path, -1, t, opts)
+}
+
+// ReadVersion reads the snapshot of the table at the given version, which
+// must still be in the transaction log, and returns a PCollection<t> of its
+// rows.
+func ReadVersion(s beam.Scope, path string, version int64, t reflect.Type, opts *ReadOptions) beam.PCollection {
+	s = s.Scope("deltaio.ReadVersion")
+	if version < 0 {
+		panic(fmt.Sprintf("invalid table version: %v", version))
+	}
+	return read(s, path, version, t, opts)
+}
+
+func read(s beam.Scope, path string, version int64, t reflect.Type, opts *ReadOptions) beam.PCollection {
+	filesystem.ValidateScheme(path)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("row type %v must be a struct", t))
+	}
+	if opts == nil {
+		opts = &ReadOptions{}
+	}
+
+	imp := beam.Impulse(s)
+	files := beam.ParDo(s, &snapshotFn{Path: path, Version: version, Partitions: opts.Partitions}, imp)
+	// Prevent fusion, so that the files are read in parallel.
+	files = beam.Reshuffle(s, files)
+	return beam.ParDo(s, &readFileFn{Type: beam.EncodedType{T: t}}, files, beam.TypeDefinition{Var: beam.XType, T: t})
+}
+
+// dataFile is a data file of a snapshot.
+type dataFile struct {
+	// Path is the full path of the file.
+	Path string `json:"path"`
+	// PartitionValues are the values of the partition columns of the rows
+	// of the file. Null values are nil.
+	PartitionValues map[string]*string `json:"partition_values,omitempty"`
+}
+
+// snapshotFn reads the snapshot of the table and emits its data files, after
+// partition pruning.
+type snapshotFn struct {
+	// Path is the path of the table.
+	Path string `json:"path"`
+	// Version is the version to read, or -1 for the latest version.
+	Version int64 `json:"version"`
+	// Partitions are the partition values to read, by column.
+	Partitions map[string][]string `json:"partitions,omitempty"`
+}
+
+func (f *snapshotFn) ProcessElement(ctx context.Context, _ []byte, emit func(dataFile)) error {
+	fs, err := filesystem.New(ctx, f.Path)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	snap, err := readSnapshot(ctx, fs, f.Path, f.Version)
+	if err != nil {
+		return err
+	}
+
+	partitioned := make(map[string]bool)
+	for _, c := range snap.metaData.PartitionColumns {
+		partitioned[c] = true
+	}
+	for c := range f.Partitions {
+		if !partitioned[c] {
+			return errors.Errorf("%v is not a partition column of %v", c, f.Path)
+		}
+	}
+
+	n := 0
+	for _, file := range snap.files {
+		if !f.matches(file.PartitionValues) {
+			continue
+		}
+		emit(dataFile{Path: joinPath(f.Path, file.Path), PartitionValues: file.PartitionValues})
+		n++
+	}
+	log.Infof(ctx, "Reading %v of %v files of %v at version %v", n, len(snap.files), f.Path, snap.version)
+	return nil
+}
+
+// matches returns whether the partition values are selected.
+func (f *snapshotFn) matches(values map[string]*string) bool {
+	for c, selected := range f.Partitions {
+		v := values[c]
+		if v == nil {
+			return false
+		}
+		ok := false
+		for _, s := range selected {
+			if s == *v {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// readFileFn reads the rows of a data file.
+type readFileFn struct {
+	// Type is the encoded row type.
+	Type beam.EncodedType `json:"type"`
+}
+
+func (f *readFileFn) ProcessElement(ctx context.Context, file dataFile, emit func(beam.X)) error {
+	fs, err := filesystem.New(ctx, file.Path)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	data, err := filesystem.Read(ctx, fs, file.Path)
+	if err != nil {
+		return err
+	}
+	pf, err := parquet.Open(data)
+	if err != nil {
+		return errors.WithContextf(err, "reading %v", file.Path)
+	}
+
+	t := f.Type.T
+	fields := fieldIndices(t)
+	var columns []string
+	for _, c := range pf.Schema.Children {
+		if _, ok := fields[strings.ToLower(c.Name)]; ok {
+			columns = append(columns, c.Name)
+		}
+	}
+	partitions := reflect.New(t).Elem()
+	for c, v := range file.PartitionValues {
+		i, ok := fields[strings.ToLower(c)]
+		if !ok || v == nil {
+			continue
+		}
+		if err := decodePartition(*v, partitions.Field(i)); err != nil {
+			return errors.WithContextf(err, "decoding partition column %v of %v", c, file.Path)
+		}
+	}
+
+	rows, err := pf.Rows(columns...)
+	if err != nil {
+		return errors.WithContextf(err, "reading %v", file.Path)
+	}
+	for _, row := range rows {
+		val := reflect.New(t).Elem()
+		val.Set(partitions)
+		if err := decodeRow(row, fields, val); err != nil {
+			return errors.WithContextf(err, "decoding row of %v into %v", file.Path, t)
+		}
+		emit(val.Interface())
+	}
+	return nil
+}
+
+// fieldIndices returns the indices of the fields of the struct type t, by
+// the lowercase column name.
+func fieldIndices(t reflect.Type) map[string]int {
+	ret := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		name := strings.Split(f.Tag.Get("delta"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		ret[strings.ToLower(name)] = i
+	}
+	return ret
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func decodeRow(row map[string]interface{}, fields map[string]int, v reflect.Value) error {
+	for c, value := range row {
+		i, ok := fields[strings.ToLower(c)]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(value, v.Field(i)); err != nil {
+			return errors.WithContextf(err, "decoding column %v", c)
+		}
+	}
+	return nil
+}
+
+// decodeValue assigns the value, as decoded from Parquet, to v. A null value
+// leaves v as the zero value.
+func decodeValue(value interface{}, v reflect.Value) error {
+	if value == nil {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elm := reflect.New(v.Type().Elem())
+		if err := decodeValue(value, elm.Elem()); err != nil {
+			return err
+		}
+		v.Set(elm)
+		return nil
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t, ok := value.(time.Time)
+			if !ok {
+				return errors.Errorf("cannot decode %T into %v", value, v.Type())
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+		group, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("cannot decode %T into %v", value, v.Type())
+		}
+		return decodeRow(group, fieldIndices(v.Type()), v)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			switch b := value.(type) {
+			case []byte:
+				v.SetBytes(b)
+			case string:
+				v.SetBytes([]byte(b))
+			default:
+				return errors.Errorf("cannot decode %T into %v", value, v.Type())
+			}
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return errors.Errorf("cannot decode %T into %v", value, v.Type())
+		}
+		ret := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, elm := range list {
+			if err := decodeValue(elm, ret.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(ret)
+		return nil
+
+	case reflect.Map:
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return errors.Errorf("cannot decode %T into %v", value, v.Type())
+		}
+		ret := reflect.MakeMapWithSize(v.Type(), len(m))
+		for key, elm := range m {
+			k := reflect.New(v.Type().Key()).Elem()
+			if err := decodeValue(key, k); err != nil {
+				return err
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(elm, e); err != nil {
+				return err
+			}
+			ret.SetMapIndex(k, e)
+		}
+		v.Set(ret)
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.String) != (v.Kind() == reflect.String) || !rv.Type().ConvertibleTo(v.Type()) {
+		return errors.Errorf("cannot decode %T into %v", value, v.Type())
+	}
+	v.Set(rv.Convert(v.Type()))
+	return nil
+}
+
+// partitionTimeLayouts are the layouts of date and timestamp partition
+// values. Timestamps are in UTC.
+var partitionTimeLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// decodePartition parses the partition value, as written in the transaction
+// log, into v by its kind.
+func decodePartition(s string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		elm := reflect.New(v.Type().Elem())
+		if err := decodePartition(s, elm.Elem()); err != nil {
+			return err
+		}
+		v.Set(elm)
+		return nil
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			for _, layout := range partitionTimeLayouts {
+				if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+					v.Set(reflect.ValueOf(t.UTC()))
+					return nil
+				}
+			}
+			return errors.Errorf("invalid date or timestamp %q", s)
+		}
+	}
+	return errors.Errorf("cannot decode partition value into %v", v.Type())
+}
@@ -0,0 +1,245 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltaio
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/memfs"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/internal/parquet"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type sale struct {
+	Item  string    `delta:"item"`
+	Price *float64  `delta:"price"`
+	Day   time.Time `delta:"day"`
+	Store int
+}
+
+func price(p float64) *float64 {
+	return &p
+}
+
+const commit0 = `{"commitInfo":{"timestamp":1577836800000,"operation":"WRITE"}}
+{"protocol":{"minReaderVersion":1,"minWriterVersion":2}}
+{"metaData":{"id":"t","format":{"provider":"parquet","options":{}},"schemaString":"{}","partitionColumns":["day"],"configuration":{}}}
+{"add":{"path":"day=2020-01-01/part-0.parquet","partitionValues":{"day":"2020-01-01"},"size":1,"dataChange":true}}
+{"add":{"path":"day=2020-01-02/part-0.parquet","partitionValues":{"day":"2020-01-02"},"size":1,"dataChange":true}}
+`
+
+const commit1 = `{"remove":{"path":"day=2020-01-01/part-0.parquet","dataChange":true}}
+{"add":{"path":"day=2020-01-01/part%201.parquet","partitionValues":{"day":"2020-01-01"},"size":1,"dataChange":true}}
+`
+
+// writeDataFile writes a data file of (item, price, store) rows.
+func writeDataFile(t *testing.T, filename string, rows ...[]interface{}) {
+	w := parquet.NewWriter([]parquet.Column{
+		{Name: "item", Type: parquet.ByteArray, ConvertedType: parquet.UTF8, Required: true},
+		{Name: "price", Type: parquet.Double, ConvertedType: parquet.None},
+		{Name: "store", Type: parquet.Int32, ConvertedType: parquet.None, Required: true},
+	})
+	for _, row := range rows {
+		if err := w.Add(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := filesystem.Write(ctx, memfs.New(ctx), filename, buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTable(t *testing.T) {
+	ctx := context.Background()
+	fs := memfs.New(ctx)
+	for name, data := range map[string]string{
+		"memfs://sales/_delta_log/00000000000000000000.json": commit0,
+		"memfs://sales/_delta_log/00000000000000000001.json": commit1,
+		"memfs://sales/_delta_log/_last_checkpoint":          "{}",
+	} {
+		if err := filesystem.Write(ctx, fs, name, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeDataFile(t, "memfs://sales/day=2020-01-01/part-0.parquet",
+		[]interface{}{"apple", 1.5, int32(1)})
+	writeDataFile(t, "memfs://sales/day=2020-01-02/part-0.parquet",
+		[]interface{}{"pear", nil, int32(2)})
+	writeDataFile(t, "memfs://sales/day=2020-01-01/part 1.parquet",
+		[]interface{}{"apple", 2.5, int32(1)}, []interface{}{"plum", 3.0, int32(3)})
+}
+
+var (
+	day1 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func TestRead(t *testing.T) {
+	writeTable(t)
+
+	tests := []struct {
+		name    string
+		version int64
+		opts    *ReadOptions
+		exp     []interface{}
+	}{
+		{"latest", -1, nil, []interface{}{
+			sale{Item: "apple", Price: price(2.5), Day: day1, Store: 1},
+			sale{Item: "plum", Price: price(3), Day: day1, Store: 3},
+			sale{Item: "pear", Day: day2, Store: 2},
+		}},
+		{"version", 0, nil, []interface{}{
+			sale{Item: "apple", Price: price(1.5), Day: day1, Store: 1},
+			sale{Item: "pear", Day: day2, Store: 2},
+		}},
+		{"partitions", -1, &ReadOptions{Partitions: map[string][]string{"day": {"2020-01-02"}}}, []interface{}{
+			sale{Item: "pear", Day: day2, Store: 2},
+		}},
+	}
+	for _, test := range tests {
+		p, s := beam.NewPipelineWithRoot()
+		var rows beam.PCollection
+		if test.version < 0 {
+			rows = Read(s, "memfs://sales", reflect.TypeOf(sale{}), test.opts)
+		} else {
+			rows = ReadVersion(s, "memfs://sales", test.version, reflect.TypeOf(sale{}), test.opts)
+		}
+		passert.Equals(s, rows, test.exp...)
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("Read(%v) failed: %v", test.name, err)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	var files []logFile
+	for _, name := range []string{
+		"00000000000000000000.json",
+		"00000000000000000001.json",
+		"00000000000000000002.json",
+		"00000000000000000002.checkpoint.parquet",
+		"00000000000000000003.json",
+		"00000000000000000004.checkpoint.0000000001.0000000002.parquet",
+		"00000000000000000004.json",
+		"_last_checkpoint",
+		"00000000000000000003.crc",
+	} {
+		if f, ok := parseLogFile("memfs://t/_delta_log/" + name); ok {
+			files = append(files, f)
+		}
+	}
+	if len(files) != 7 {
+		t.Fatalf("parsed %v log files, want 7", len(files))
+	}
+
+	tests := []struct {
+		version    int64
+		checkpoint int64
+		commits    []int64
+	}{
+		// The checkpoint at version 4 is incomplete.
+		{-1, 2, []int64{3, 4}},
+		{3, 2, []int64{3}},
+		{2, 2, nil},
+		{1, -1, []int64{0, 1}},
+	}
+	for _, test := range tests {
+		checkpoint, commits, err := plan(files, test.version)
+		if err != nil {
+			t.Errorf("plan(%v) failed: %v", test.version, err)
+			continue
+		}
+		cp := int64(-1)
+		if len(checkpoint) > 0 {
+			cp = checkpoint[0].version
+		}
+		var versions []int64
+		for _, f := range commits {
+			versions = append(versions, f.version)
+		}
+		if cp != test.checkpoint || !reflect.DeepEqual(versions, test.commits) {
+			t.Errorf("plan(%v) = %v, %v, want %v, %v", test.version, cp, versions, test.checkpoint, test.commits)
+		}
+	}
+	if _, _, err := plan(files, 5); err == nil {
+		t.Errorf("plan(5) succeeded for a missing version")
+	}
+	if _, _, err := plan(files[2:], 3); err != nil {
+		t.Errorf("plan(3) without early commits failed: %v", err)
+	}
+	if _, _, err := plan(files[2:], 1); err == nil {
+		t.Errorf("plan(1) without early commits succeeded")
+	}
+}
+
+func TestCheckpointAction(t *testing.T) {
+	day := "2020-01-01"
+	row := map[string]interface{}{
+		"add": map[string]interface{}{
+			"path":            "day=2020-01-01/a.parquet",
+			"partitionValues": map[interface{}]interface{}{"day": day, "hour": nil},
+			"size":            int64(10),
+			"deletionVector":  nil,
+		},
+		"metaData": nil,
+		"protocol": nil,
+	}
+	a, err := checkpointAction(row)
+	if err != nil {
+		t.Fatalf("checkpointAction failed: %v", err)
+	}
+	exp := &addAction{
+		Path:            "day=2020-01-01/a.parquet",
+		PartitionValues: map[string]*string{"day": &day, "hour": nil},
+		Size:            10,
+	}
+	if a.MetaData != nil || a.Protocol != nil || !reflect.DeepEqual(a.Add, exp) {
+		t.Errorf("checkpointAction(%v) = %+v, want add %+v", row, a, exp)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		protocol protocolAction
+		config   map[string]string
+		ok       bool
+	}{
+		{protocolAction{MinReaderVersion: 1}, nil, true},
+		{protocolAction{MinReaderVersion: 2}, nil, false},
+		{protocolAction{MinReaderVersion: 3, ReaderFeatures: []string{"timestampNtz"}}, nil, true},
+		{protocolAction{MinReaderVersion: 3, ReaderFeatures: []string{"deletionVectors"}}, nil, false},
+		{protocolAction{MinReaderVersion: 1}, map[string]string{"delta.columnMapping.mode": "name"}, false},
+	}
+	for _, test := range tests {
+		protocol := test.protocol
+		s := &snapshot{protocol: &protocol, metaData: &metaDataAction{Configuration: test.config}}
+		if err := s.check(); (err == nil) != test.ok {
+			t.Errorf("check(%+v, %v) = %v, want ok %v", test.protocol, test.config, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltaio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/internal/parquet"
+)
+
+// This file reconstructs a snapshot of a table from its transaction log.
+// The log holds a JSON file of actions for each commit, named by the
+// zero-padded version, and periodic Parquet checkpoints holding the state
+// of the table at a version. A snapshot is the latest checkpoint at or
+// before its version with the later commits applied in order.
+
+// readerVersion is the highest reader protocol version supported.
+const readerVersion = 1
+
+// readerFeatures are the table features of reader protocol version 3 that
+// do not affect how data files are read.
+var readerFeatures = map[string]bool{
+	"timestampNtz":        true,
+	"vacuumProtocolCheck": true,
+}
+
+// action is a line of a commit file, or a row of a checkpoint. Each holds
+// exactly one action.
+type action struct {
+	Add      *addAction      `json:"add,omitempty"`
+	Remove   *removeAction   `json:"remove,omitempty"`
+	MetaData *metaDataAction `json:"metaData,omitempty"`
+	Protocol *protocolAction `json:"protocol,omitempty"`
+}
+
+type addAction struct {
+	Path            string             `json:"path"`
+	PartitionValues map[string]*string `json:"partitionValues"`
+	Size            int64              `json:"size"`
+	DeletionVector  json.RawMessage    `json:"deletionVector,omitempty"`
+}
+
+type removeAction struct {
+	Path string `json:"path"`
+}
+
+type metaDataAction struct {
+	Format struct {
+		Provider string `json:"provider"`
+	} `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+}
+
+type protocolAction struct {
+	MinReaderVersion int      `json:"minReaderVersion"`
+	ReaderFeatures   []string `json:"readerFeatures"`
+}
+
+// snapshot is the state of a table at a version.
+type snapshot struct {
+	version  int64
+	metaData *metaDataAction
+	protocol *protocolAction
+	// files are the data files of the table, by path.
+	files map[string]*addAction
+}
+
+func (s *snapshot) apply(a *action) {
+	switch {
+	case a.Add != nil:
+		s.files[a.Add.Path] = a.Add
+	case a.Remove != nil:
+		delete(s.files, a.Remove.Path)
+	case a.MetaData != nil:
+		s.metaData = a.MetaData
+	case a.Protocol != nil:
+		s.protocol = a.Protocol
+	}
+}
+
+// check returns an error if the snapshot cannot be read.
+func (s *snapshot) check() error {
+	if s.protocol == nil || s.metaData == nil {
+		return errors.Errorf("version %v has no protocol or metadata", s.version)
+	}
+	switch v := s.protocol.MinReaderVersion; {
+	case v == 3:
+		for _, f := range s.protocol.ReaderFeatures {
+			if !readerFeatures[f] {
+				return errors.Errorf("table feature %v is not supported", f)
+			}
+		}
+	case v > readerVersion:
+		return errors.Errorf("reader version %v is not supported", v)
+	}
+	if p := s.metaData.Format.Provider; p != "" && p != "parquet" {
+		return errors.Errorf("data file format %v is not supported", p)
+	}
+	if mode := s.metaData.Configuration["delta.columnMapping.mode"]; mode != "" && mode != "none" {
+		return errors.Errorf("column mapping mode %v is not supported", mode)
+	}
+	for _, f := range s.files {
+		if len(f.DeletionVector) > 0 && string(f.DeletionVector) != "null" {
+			return errors.Errorf("data file %v has a deletion vector, which is not supported", f.Path)
+		}
+	}
+	return nil
+}
+
+// logFile is a commit or checkpoint file of the log.
+type logFile struct {
+	name    string
+	version int64
+	// parts is the number of parts of a checkpoint, or zero for a commit.
+	parts int
+}
+
+// parseLogFile parses the name of a log file. Other files, such as
+// _last_checkpoint and checksums, are ignored.
+func parseLogFile(name string) (logFile, bool) {
+	base := path.Base(name)
+	i := strings.Index(base, ".")
+	if i != 20 {
+		return logFile{}, false
+	}
+	version, err := strconv.ParseInt(base[:i], 10, 64)
+	if err != nil {
+		return logFile{}, false
+	}
+	switch rest := base[i:]; {
+	case rest == ".json":
+		return logFile{name: name, version: version}, true
+	case rest == ".checkpoint.parquet":
+		return logFile{name: name, version: version, parts: 1}, true
+	case strings.HasPrefix(rest, ".checkpoint.") && strings.HasSuffix(rest, ".parquet"):
+		var part, parts int
+		if _, err := fmt.Sscanf(rest, ".checkpoint.%010d.%010d.parquet", &part, &parts); err != nil || parts == 0 {
+			return logFile{}, false
+		}
+		return logFile{name: name, version: version, parts: parts}, true
+	}
+	return logFile{}, false
+}
+
+// plan returns the checkpoint files, if any, and the commit files to read
+// the snapshot at the version. If version is negative, the latest version
+// is read.
+func plan(files []logFile, version int64) ([]logFile, []logFile, error) {
+	commits := make(map[int64]logFile)
+	checkpoints := make(map[int64][]logFile)
+	latest := int64(-1)
+	for _, f := range files {
+		if f.parts == 0 {
+			commits[f.version] = f
+		} else {
+			checkpoints[f.version] = append(checkpoints[f.version], f)
+		}
+		if f.version > latest {
+			latest = f.version
+		}
+	}
+	if latest < 0 {
+		return nil, nil, errors.New("no transaction log")
+	}
+	if version < 0 {
+		version = latest
+	}
+	if _, ok := commits[version]; !ok {
+		if cp := checkpoints[version]; len(cp) == 0 || len(cp) != cp[0].parts {
+			return nil, nil, errors.Errorf("version %v does not exist", version)
+		}
+	}
+
+	// Use the latest complete checkpoint at or before the version.
+	var checkpoint []logFile
+	start := int64(0)
+	for v, cp := range checkpoints {
+		if v <= version && v >= start && len(cp) == cp[0].parts {
+			checkpoint, start = cp, v+1
+		}
+	}
+	sort.Slice(checkpoint, func(i, j int) bool { return checkpoint[i].name < checkpoint[j].name })
+
+	var ret []logFile
+	for v := start; v <= version; v++ {
+		f, ok := commits[v]
+		if !ok {
+			return nil, nil, errors.Errorf("commit %v is missing from the transaction log", v)
+		}
+		ret = append(ret, f)
+	}
+	return checkpoint, ret, nil
+}
+
+// readSnapshot reads the snapshot of the table at the version. If version is
+// negative, the latest version is read.
+func readSnapshot(ctx context.Context, fs filesystem.Interface, table string, version int64) (*snapshot, error) {
+	names, err := fs.List(ctx, joinPath(table, "_delta_log/*"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list transaction log of %v", table)
+	}
+	var files []logFile
+	for _, name := range names {
+		if f, ok := parseLogFile(name); ok {
+			files = append(files, f)
+		}
+	}
+	checkpoint, commits, err := plan(files, version)
+	if err != nil {
+		return nil, errors.WithContextf(err, "reading transaction log of %v", table)
+	}
+
+	ret := &snapshot{version: version, files: make(map[string]*addAction)}
+	for _, f := range checkpoint {
+		ret.version = f.version
+		if err := readCheckpoint(ctx, fs, f.name, ret); err != nil {
+			return nil, errors.WithContextf(err, "reading checkpoint %v", f.name)
+		}
+	}
+	for _, f := range commits {
+		ret.version = f.version
+		data, err := filesystem.Read(ctx, fs, f.name)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, len(data)+1)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var a action
+			if err := json.Unmarshal(line, &a); err != nil {
+				return nil, errors.Wrapf(err, "invalid action in commit %v", f.name)
+			}
+			ret.apply(&a)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if err := ret.check(); err != nil {
+		return nil, errors.WithContextf(err, "reading %v", table)
+	}
+	return ret, nil
+}
+
+// readCheckpoint applies the actions of a checkpoint file to the snapshot.
+// Removes in checkpoints are tombstones for vacuuming, and are skipped.
+func readCheckpoint(ctx context.Context, fs filesystem.Interface, name string, s *snapshot) error {
+	data, err := filesystem.Read(ctx, fs, name)
+	if err != nil {
+		return err
+	}
+	f, err := parquet.Open(data)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	for _, c := range []string{"add", "metaData", "protocol"} {
+		if _, ok := f.Column(c); ok {
+			columns = append(columns, c)
+		}
+	}
+	rows, err := f.Rows(columns...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		a, err := checkpointAction(row)
+		if err != nil {
+			return err
+		}
+		s.apply(a)
+	}
+	return nil
+}
+
+// checkpointAction converts a checkpoint row to an action. The row holds
+// the action in the column of its kind, with the same structure as in
+// commits, so it is converted through JSON.
+func checkpointAction(row map[string]interface{}) (*action, error) {
+	data, err := json.Marshal(jsonValue(row))
+	if err != nil {
+		return nil, err
+	}
+	var ret action
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, errors.Wrap(err, "invalid checkpoint action")
+	}
+	return &ret, nil
+}
+
+// jsonValue converts Parquet maps, which may have non-string keys, to
+// values that can be marshalled as JSON. Null fields are dropped.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if value != nil {
+				ret[key] = jsonValue(value)
+			}
+		}
+		return ret
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			ret[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, value := range v {
+			ret[i] = jsonValue(value)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+// joinPath returns the path of the file, relative to the table. Paths in
+// actions are relative URIs, or absolute URIs for shallow clones.
+func joinPath(table, file string) string {
+	if strings.Contains(file, "://") || strings.HasPrefix(file, "/") {
+		return file
+	}
+	if p, err := url.PathUnescape(file); err == nil {
+		file = p
+	}
+	return strings.TrimSuffix(table, "/") + "/" + file
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
 )
@@ -63,6 +64,46 @@ type Interface interface {
 	OpenWrite(ctx context.Context, filename string) (io.WriteCloser, error)
 }
 
+// Renamer is an optional interface for file systems that can move a file to
+// a new name. Sinks use it to stage output under a temporary name and commit
+// it once fully written, so readers never observe partial files.
+type Renamer interface {
+	// Rename moves the file oldpath to newpath, overwriting newpath if it
+	// already exists.
+	Rename(ctx context.Context, oldpath, newpath string) error
+}
+
+// Copier is an optional interface for file systems that can copy a file
+// without streaming it through the caller.
+type Copier interface {
+	// Copy copies the file oldpath to newpath, overwriting newpath if it
+	// already exists.
+	Copy(ctx context.Context, oldpath, newpath string) error
+}
+
+// Remover is an optional interface for file systems that can delete files.
+type Remover interface {
+	// Remove deletes the given file.
+	Remove(ctx context.Context, filename string) error
+}
+
+// Metadata describes a file matched by a Matcher.
+type Metadata struct {
+	// Filename is the full name of the file, including its scheme.
+	Filename string
+	// Size is the size of the file in bytes.
+	Size int64
+	// LastModified is the time the file was last modified.
+	LastModified time.Time
+}
+
+// Matcher is an optional interface for file systems that can return the
+// metadata of files as part of expanding a pattern.
+type Matcher interface {
+	// Match expands a pattern to the metadata of the matching files.
+	Match(ctx context.Context, glob string) ([]Metadata, error)
+}
+
 func getScheme(path string) string {
 	if index := strings.Index(path, "://"); index > 0 {
 		return path[:index]
@@ -19,12 +19,14 @@ package gcs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
 
 	"cloud.google.com/go/storage"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
 	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
 	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
 	"github.com/apache/beam/sdks/go/pkg/beam/log"
@@ -37,14 +39,71 @@ func init() {
 	filesystem.Register("gs", New)
 }
 
+// Options configure how the GCS file system accesses buckets and writes
+// objects. They apply to all GCS files of a pipeline.
+type Options struct {
+	// ChunkSize is the size in bytes of each request of a resumable upload.
+	// An object is retried chunk by chunk, so smaller chunks lose less
+	// progress on failure but require more requests. If zero, the client
+	// library default of 8MB is used. If negative, objects are uploaded in
+	// a single, non-resumable request.
+	ChunkSize int `json:"chunk_size,omitempty"`
+	// KMSKeyName is the Cloud KMS key used to encrypt written objects, of
+	// the form projects/P/locations/L/keyRings/R/cryptoKeys/K. If empty, the
+	// bucket's default encryption is used.
+	KMSKeyName string `json:"kms_key_name,omitempty"`
+	// UserProject is the project billed for access to requester pays
+	// buckets. Required to access such buckets.
+	UserProject string `json:"user_project,omitempty"`
+	// ContentType is the content type set on written objects. If empty, it
+	// is detected by GCS.
+	ContentType string `json:"content_type,omitempty"`
+	// Metadata is custom metadata set on written objects.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// optionsKey is the pipeline option under which the GCS options are stored.
+const optionsKey = "gcs_options"
+
+// SetOptions sets the options of the GCS file system. It must be called
+// during pipeline construction, because the options are passed to remote
+// workers as pipeline options.
+func SetOptions(opt Options) {
+	data, err := json.Marshal(opt)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to encode GCS options"))
+	}
+	runtime.GlobalOptions.Set(optionsKey, string(data))
+}
+
+// getOptions returns the options set by SetOptions, if any.
+func getOptions() (Options, error) {
+	var opt Options
+	data := runtime.GlobalOptions.Get(optionsKey)
+	if data == "" {
+		return opt, nil
+	}
+	if err := json.Unmarshal([]byte(data), &opt); err != nil {
+		return opt, errors.Wrapf(err, "failed to decode GCS options %v", data)
+	}
+	return opt, nil
+}
+
 type fs struct {
 	client *storage.Client
+	opt    Options
 }
 
 // New creates a new Google Cloud Storage filesystem using application
 // default credentials. If it fails, it falls back to unauthenticated
-// access.
+// access. The filesystem is configured with the options given to
+// SetOptions.
 func New(ctx context.Context) filesystem.Interface {
+	opt, err := getOptions()
+	if err != nil {
+		panic(err)
+	}
+
 	client, err := storage.NewClient(ctx, option.WithScopes(storage.ScopeReadWrite))
 	if err != nil {
 		log.Warnf(ctx, "Warning: falling back to unauthenticated GCS access: %v", err)
@@ -54,7 +113,17 @@ func New(ctx context.Context) filesystem.Interface {
 			panic(errors.Wrapf(err, "failed to create GCS client"))
 		}
 	}
-	return &fs{client: client}
+	return &fs{client: client, opt: opt}
+}
+
+// bucket returns a handle to the given bucket, billed to the user project
+// if one is set.
+func (f *fs) bucket(name string) *storage.BucketHandle {
+	b := f.client.Bucket(name)
+	if f.opt.UserProject != "" {
+		b = b.UserProject(f.opt.UserProject)
+	}
+	return b
 }
 
 func (f *fs) Close() error {
@@ -69,29 +138,12 @@ func (f *fs) List(ctx context.Context, glob string) ([]string, error) {
 
 	var candidates []string
 	if index := strings.Index(object, "*"); index > 0 {
-		// We handle globs by list all candidates and matching them here.
-		// For now, we assume * is the first matching character to make a
-		// prefix listing and not list the entire bucket.
-
-		it := f.client.Bucket(bucket).Objects(ctx, &storage.Query{
-			Prefix: object[:index],
-		})
-		for {
-			obj, err := it.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return nil, err
-			}
-
-			match, err := filepath.Match(object, obj.Name)
-			if err != nil {
-				return nil, err
-			}
-			if match {
-				candidates = append(candidates, obj.Name)
-			}
+		objs, err := f.listObjects(ctx, bucket, object, index)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			candidates = append(candidates, obj.Name)
 		}
 	} else {
 		// Single object.
@@ -105,13 +157,77 @@ func (f *fs) List(ctx context.Context, glob string) ([]string, error) {
 	return ret, nil
 }
 
+// Match is the same as List, except that it returns the metadata of each
+// object. A single object that does not exist is an error.
+func (f *fs) Match(ctx context.Context, glob string) ([]filesystem.Metadata, error) {
+	bucket, object, err := gcsx.ParseObject(glob)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []*storage.ObjectAttrs
+	if index := strings.Index(object, "*"); index > 0 {
+		objs, err = f.listObjects(ctx, bucket, object, index)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		obj, err := f.bucket(bucket).Object(object).Attrs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		objs = []*storage.ObjectAttrs{obj}
+	}
+
+	var ret []filesystem.Metadata
+	for _, obj := range objs {
+		ret = append(ret, filesystem.Metadata{
+			Filename:     fmt.Sprintf("gs://%v/%v", bucket, obj.Name),
+			Size:         obj.Size,
+			LastModified: obj.Updated,
+		})
+	}
+	return ret, nil
+}
+
+// listObjects returns the objects in the bucket matching the pattern, where
+// index is the position of the first * in the pattern.
+func (f *fs) listObjects(ctx context.Context, bucket, pattern string, index int) ([]*storage.ObjectAttrs, error) {
+	// We handle globs by list all candidates and matching them here.
+	// For now, we assume * is the first matching character to make a
+	// prefix listing and not list the entire bucket.
+
+	var ret []*storage.ObjectAttrs
+	it := f.bucket(bucket).Objects(ctx, &storage.Query{
+		Prefix: pattern[:index],
+	})
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		match, err := filepath.Match(pattern, obj.Name)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			ret = append(ret, obj)
+		}
+	}
+	return ret, nil
+}
+
 func (f *fs) OpenRead(ctx context.Context, filename string) (io.ReadCloser, error) {
 	bucket, object, err := gcsx.ParseObject(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	return f.client.Bucket(bucket).Object(object).NewReader(ctx)
+	return f.bucket(bucket).Object(object).NewReader(ctx)
 }
 
 // TODO(herohde) 7/12/2017: should we create the bucket in OpenWrite? For now, "no".
@@ -122,5 +238,52 @@ func (f *fs) OpenWrite(ctx context.Context, filename string) (io.WriteCloser, er
 		return nil, err
 	}
 
-	return f.client.Bucket(bucket).Object(object).NewWriter(ctx), nil
+	w := f.bucket(bucket).Object(object).NewWriter(ctx)
+	switch {
+	case f.opt.ChunkSize > 0:
+		w.ChunkSize = f.opt.ChunkSize
+	case f.opt.ChunkSize < 0:
+		w.ChunkSize = 0
+	}
+	w.KMSKeyName = f.opt.KMSKeyName
+	w.ContentType = f.opt.ContentType
+	w.Metadata = f.opt.Metadata
+	return w, nil
+}
+
+func (f *fs) Copy(ctx context.Context, oldpath, newpath string) error {
+	srcBucket, srcObject, err := gcsx.ParseObject(oldpath)
+	if err != nil {
+		return err
+	}
+	dstBucket, dstObject, err := gcsx.ParseObject(newpath)
+	if err != nil {
+		return err
+	}
+
+	src := f.bucket(srcBucket).Object(srcObject)
+	dst := f.bucket(dstBucket).Object(dstObject)
+	copier := dst.CopierFrom(src)
+	copier.DestinationKMSKeyName = f.opt.KMSKeyName
+	if _, err := copier.Run(ctx); err != nil {
+		return errors.Wrapf(err, "failed to copy %v to %v", oldpath, newpath)
+	}
+	return nil
+}
+
+// Rename copies the object to its new name and deletes the original. GCS has
+// no native rename, but the new object becomes visible atomically.
+func (f *fs) Rename(ctx context.Context, oldpath, newpath string) error {
+	if err := f.Copy(ctx, oldpath, newpath); err != nil {
+		return err
+	}
+	return f.Remove(ctx, oldpath)
+}
+
+func (f *fs) Remove(ctx context.Context, filename string) error {
+	bucket, object, err := gcsx.ParseObject(filename)
+	if err != nil {
+		return err
+	}
+	return f.bucket(bucket).Object(object).Delete(ctx)
 }
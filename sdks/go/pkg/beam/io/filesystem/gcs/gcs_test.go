@@ -0,0 +1,50 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcs
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestOptions tests that options set at construction time can be recovered,
+// as they would be on a remote worker.
+func TestOptions(t *testing.T) {
+	opt, err := getOptions()
+	if err != nil {
+		t.Fatalf("getOptions() failed: %v", err)
+	}
+	if !reflect.DeepEqual(opt, Options{}) {
+		t.Errorf("getOptions() = %v, want zero options", opt)
+	}
+
+	exp := Options{
+		ChunkSize:   1 << 20,
+		KMSKeyName:  "projects/p/locations/l/keyRings/r/cryptoKeys/k",
+		UserProject: "billing",
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"owner": "beam"},
+	}
+	SetOptions(exp)
+
+	opt, err = getOptions()
+	if err != nil {
+		t.Fatalf("getOptions() failed: %v", err)
+	}
+	if !reflect.DeepEqual(opt, exp) {
+		t.Errorf("getOptions() = %v, want %v", opt, exp)
+	}
+}
@@ -54,3 +54,53 @@ func (f *fs) OpenWrite(ctx context.Context, filename string) (io.WriteCloser, er
 	}
 	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 }
+
+func (f *fs) Rename(ctx context.Context, oldpath, newpath string) error {
+	if err := os.MkdirAll(filepath.Dir(newpath), 0755); err != nil {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (f *fs) Copy(ctx context.Context, oldpath, newpath string) error {
+	src, err := os.Open(oldpath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := f.OpenWrite(ctx, newpath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func (f *fs) Remove(ctx context.Context, filename string) error {
+	return os.Remove(filename)
+}
+
+func (f *fs) Match(ctx context.Context, glob string) ([]filesystem.Metadata, error) {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []filesystem.Metadata
+	for _, filename := range files {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, filesystem.Metadata{
+			Filename:     filename,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+	return ret, nil
+}
@@ -75,6 +75,56 @@ func (f *fs) OpenWrite(ctx context.Context, filename string) (io.WriteCloser, er
 	return &commitWriter{key: filename}, nil
 }
 
+func (f *fs) Rename(ctx context.Context, oldpath, newpath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, ok := f.m[normalize(oldpath)]
+	if !ok {
+		return os.ErrNotExist
+	}
+	delete(f.m, normalize(oldpath))
+	f.m[normalize(newpath)] = v
+	return nil
+}
+
+func (f *fs) Copy(ctx context.Context, oldpath, newpath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, ok := f.m[normalize(oldpath)]
+	if !ok {
+		return os.ErrNotExist
+	}
+	f.m[normalize(newpath)] = v
+	return nil
+}
+
+func (f *fs) Remove(ctx context.Context, filename string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.m[normalize(filename)]; !ok {
+		return os.ErrNotExist
+	}
+	delete(f.m, normalize(filename))
+	return nil
+}
+
+// Match returns the metadata of all files, like List. Modification times are
+// not tracked.
+func (f *fs) Match(ctx context.Context, glob string) ([]filesystem.Metadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ret []filesystem.Metadata
+	for k, v := range f.m {
+		ret = append(ret, filesystem.Metadata{Filename: k, Size: int64(len(v))})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Filename < ret[j].Filename })
+	return ret, nil
+}
+
 // Write stores the given key and value in the global store.
 func Write(key string, value []byte) {
 	instance.mu.Lock()
@@ -72,3 +72,83 @@ func TestDirectWrite(t *testing.T) {
 		t.Errorf("Read(foo2) = %v, want foo", string(foo))
 	}
 }
+
+// TestRename tests that renaming a file in the memory filesystem moves its
+// content to the new name.
+func TestRename(t *testing.T) {
+	ctx := context.Background()
+	fs := New(ctx).(filesystem.Renamer)
+
+	Write("old", []byte("old"))
+	if err := fs.Rename(ctx, "old", "new"); err != nil {
+		t.Fatalf("Rename(old, new) failed: %v", err)
+	}
+
+	v, err := filesystem.Read(ctx, New(ctx), "new")
+	if err != nil {
+		t.Errorf("Read(new) failed: %v", err)
+	}
+	if string(v) != "old" {
+		t.Errorf("Read(new) = %v, want old", string(v))
+	}
+	if _, err := filesystem.Read(ctx, New(ctx), "old"); err != os.ErrNotExist {
+		t.Errorf("Read(old) = %v, want os.ErrNotExist", err)
+	}
+	if err := fs.Rename(ctx, "old", "new"); err != os.ErrNotExist {
+		t.Errorf("Rename(old, new) = %v, want os.ErrNotExist", err)
+	}
+}
+
+// TestCopyRemove tests that files in the memory filesystem can be copied and
+// removed.
+func TestCopyRemove(t *testing.T) {
+	ctx := context.Background()
+	fs := New(ctx)
+
+	Write("src", []byte("src"))
+	if err := filesystem.Copy(ctx, fs, "src", "dst"); err != nil {
+		t.Fatalf("Copy(src, dst) failed: %v", err)
+	}
+	for _, name := range []string{"src", "dst"} {
+		v, err := filesystem.Read(ctx, fs, name)
+		if err != nil {
+			t.Errorf("Read(%v) failed: %v", name, err)
+		}
+		if string(v) != "src" {
+			t.Errorf("Read(%v) = %v, want src", name, string(v))
+		}
+	}
+
+	if err := filesystem.Remove(ctx, fs, "src"); err != nil {
+		t.Fatalf("Remove(src) failed: %v", err)
+	}
+	if _, err := filesystem.Read(ctx, fs, "src"); err != os.ErrNotExist {
+		t.Errorf("Read(src) = %v, want os.ErrNotExist", err)
+	}
+	if err := filesystem.Remove(ctx, fs, "src"); err != os.ErrNotExist {
+		t.Errorf("Remove(src) = %v, want os.ErrNotExist", err)
+	}
+}
+
+// TestMatch tests that matching files in the memory filesystem returns
+// their sizes.
+func TestMatch(t *testing.T) {
+	ctx := context.Background()
+	fs := New(ctx)
+
+	Write("match", []byte("12345"))
+
+	list, err := filesystem.Match(ctx, fs, "*")
+	if err != nil {
+		t.Fatalf("Match(*) failed: %v", err)
+	}
+	for _, md := range list {
+		if md.Filename == "memfs://match" {
+			if md.Size != 5 {
+				t.Errorf("Match(*) size of %v = %v, want 5", md.Filename, md.Size)
+			}
+			return
+		}
+	}
+	t.Errorf("Match(*) = %v, want memfs://match", list)
+}
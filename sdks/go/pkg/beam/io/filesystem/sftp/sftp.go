@@ -0,0 +1,407 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sftp contains an SFTP implementation of the Beam file system.
+// Paths are of the form sftp://user@host[:port]/path, where the path is
+// absolute on the server. For example:
+//
+//    sftp.SetOptions(sftp.Options{PrivateKeyFile: "/secrets/sftp_key", HostKey: hostKey})
+//    lines := textio.Read(s, "sftp://beam@drop.example.com/incoming/*.csv")
+package sftp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+func init() {
+	filesystem.Register("sftp", New)
+}
+
+// Options configure how the SFTP file system authenticates to servers. They
+// apply to all SFTP files of a pipeline. The credentials are read on the
+// workers from files or environment variables, so that they are not part of
+// the pipeline options.
+type Options struct {
+	// PasswordFile and PasswordEnv are the path of a file and the name of
+	// an environment variable of the workers that hold the password of the
+	// user, if password authentication is used. At most one may be set.
+	PasswordFile string `json:"password_file,omitempty"`
+	PasswordEnv  string `json:"password_env,omitempty"`
+	// PrivateKeyFile and PrivateKeyEnv are the path of a file and the name
+	// of an environment variable of the workers that hold the PEM encoded
+	// private key of the user, if public key authentication is used. At
+	// most one may be set.
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PrivateKeyEnv  string `json:"private_key_env,omitempty"`
+	// HostKey is the expected public key of the servers, in authorized_keys
+	// format. It is required, unless InsecureIgnoreHostKey is set.
+	HostKey string `json:"host_key,omitempty"`
+	// InsecureIgnoreHostKey disables host key verification. It should only
+	// be used for testing.
+	InsecureIgnoreHostKey bool `json:"insecure_ignore_host_key,omitempty"`
+}
+
+// sshFxOpUnsupported is the status code of servers that do not support an
+// operation.
+const sshFxOpUnsupported = 8
+
+// optionsKey is the pipeline option under which the SFTP options are stored.
+const optionsKey = "sftp_options"
+
+// SetOptions sets the options of the SFTP file system. It must be called
+// during pipeline construction, because the options are passed to remote
+// workers as pipeline options.
+func SetOptions(opt Options) {
+	data, err := json.Marshal(opt)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to encode SFTP options"))
+	}
+	runtime.GlobalOptions.Set(optionsKey, string(data))
+}
+
+// getOptions returns the options set by SetOptions, if any.
+func getOptions() (Options, error) {
+	var opt Options
+	data := runtime.GlobalOptions.Get(optionsKey)
+	if data == "" {
+		return opt, nil
+	}
+	if err := json.Unmarshal([]byte(data), &opt); err != nil {
+		return opt, errors.Wrap(err, "failed to decode SFTP options")
+	}
+	return opt, nil
+}
+
+type fs struct {
+	opt Options
+
+	// clients holds a connection to each server used, by user@host:port.
+	clients map[string]*client
+	mu      sync.Mutex
+}
+
+type client struct {
+	conn *ssh.Client
+	sftp *sftp.Client
+}
+
+// New creates a new SFTP filesystem configured with the options given to
+// SetOptions. Connections to servers are made on first use.
+func New(ctx context.Context) filesystem.Interface {
+	opt, err := getOptions()
+	if err != nil {
+		panic(err)
+	}
+	return &fs{opt: opt, clients: make(map[string]*client)}
+}
+
+func (f *fs) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var err error
+	for key, c := range f.clients {
+		if e := c.sftp.Close(); e != nil && err == nil {
+			err = e
+		}
+		if e := c.conn.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(f.clients, key)
+	}
+	return err
+}
+
+func (f *fs) List(ctx context.Context, glob string) ([]string, error) {
+	addr, p, err := parsePath(glob)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.client(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := c.Glob(p)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, file := range files {
+		ret = append(ret, makePath(addr, file))
+	}
+	return ret, nil
+}
+
+func (f *fs) Match(ctx context.Context, glob string) ([]filesystem.Metadata, error) {
+	addr, p, err := parsePath(glob)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.client(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := c.Glob(p)
+	if err != nil {
+		return nil, err
+	}
+	var ret []filesystem.Metadata
+	for _, file := range files {
+		info, err := c.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, filesystem.Metadata{
+			Filename:     makePath(addr, file),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+	return ret, nil
+}
+
+func (f *fs) OpenRead(ctx context.Context, filename string) (io.ReadCloser, error) {
+	addr, p, err := parsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.client(addr)
+	if err != nil {
+		return nil, err
+	}
+	return c.Open(p)
+}
+
+func (f *fs) OpenWrite(ctx context.Context, filename string) (io.WriteCloser, error) {
+	addr, p, err := parsePath(filename)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.client(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := mkdirAll(c, path.Dir(p)); err != nil {
+		return nil, err
+	}
+	return c.Create(p)
+}
+
+// Rename moves the file on the server. Renames across servers are not
+// supported. It uses the posix-rename extension, which replaces newpath
+// atomically. SFTP servers without the extension commonly refuse to rename
+// onto an existing file, so then newpath is removed first.
+func (f *fs) Rename(ctx context.Context, oldpath, newpath string) error {
+	oldAddr, oldp, err := parsePath(oldpath)
+	if err != nil {
+		return err
+	}
+	newAddr, newp, err := parsePath(newpath)
+	if err != nil {
+		return err
+	}
+	if oldAddr != newAddr {
+		return errors.Errorf("cannot rename %v to %v: different servers", oldpath, newpath)
+	}
+	c, err := f.client(oldAddr)
+	if err != nil {
+		return err
+	}
+
+	err = c.PosixRename(oldp, newp)
+	if status, ok := err.(*sftp.StatusError); !ok || status.Code != sshFxOpUnsupported {
+		return err
+	}
+	if err := c.Remove(newp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return c.Rename(oldp, newp)
+}
+
+func (f *fs) Remove(ctx context.Context, filename string) error {
+	addr, p, err := parsePath(filename)
+	if err != nil {
+		return err
+	}
+	c, err := f.client(addr)
+	if err != nil {
+		return err
+	}
+	return c.Remove(p)
+}
+
+// client returns an SFTP client for the given address, connecting to the
+// server if needed.
+func (f *fs) client(addr address) (*sftp.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := addr.String()
+	if c, ok := f.clients[key]; ok {
+		return c.sftp, nil
+	}
+
+	cfg, err := f.config(addr.user)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := ssh.Dial("tcp", addr.host, cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %v", key)
+	}
+	c, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "failed to start SFTP session with %v", key)
+	}
+	f.clients[key] = &client{conn: conn, sftp: c}
+	return c, nil
+}
+
+func (f *fs) config(user string) (*ssh.ClientConfig, error) {
+	cfg := &ssh.ClientConfig{User: user}
+
+	key, err := secret("private key", f.opt.PrivateKeyFile, f.opt.PrivateKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(key))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid SFTP private key")
+		}
+		cfg.Auth = append(cfg.Auth, ssh.PublicKeys(signer))
+	}
+	password, err := secret("password", f.opt.PasswordFile, f.opt.PasswordEnv)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		cfg.Auth = append(cfg.Auth, ssh.Password(password))
+	}
+
+	switch {
+	case f.opt.HostKey != "":
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(f.opt.HostKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid SFTP host key")
+		}
+		cfg.HostKeyCallback = ssh.FixedHostKey(key)
+	case f.opt.InsecureIgnoreHostKey:
+		cfg.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, errors.New("no SFTP host key specified")
+	}
+	return cfg, nil
+}
+
+// secret returns the credential held by the file or the environment
+// variable, if either is set.
+func secret(name, file, env string) (string, error) {
+	switch {
+	case file != "" && env != "":
+		return "", errors.Errorf("at most one file and variable of the SFTP %v may be set", name)
+	case file != "":
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read SFTP %v", name)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case env != "":
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return "", errors.Errorf("SFTP %v variable %v not set", name, env)
+		}
+		return v, nil
+	default:
+		return "", nil
+	}
+}
+
+// mkdirAll creates the directory and any missing parents on the server.
+func mkdirAll(c *sftp.Client, dir string) error {
+	if dir == "/" || dir == "." {
+		return nil
+	}
+	if info, err := c.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return errors.Errorf("%v is not a directory", dir)
+		}
+		return nil
+	}
+	if err := mkdirAll(c, path.Dir(dir)); err != nil {
+		return err
+	}
+	return c.Mkdir(dir)
+}
+
+// address identifies a user on an SFTP server.
+type address struct {
+	user, host string
+}
+
+func (a address) String() string {
+	return fmt.Sprintf("%v@%v", a.user, a.host)
+}
+
+// parsePath splits a path of the form sftp://user@host[:port]/path into the
+// server address and the absolute path on the server. The port defaults to
+// 22. It does not use net/url, because glob characters are not escaped.
+func parsePath(p string) (address, string, error) {
+	const prefix = "sftp://"
+	if !strings.HasPrefix(p, prefix) {
+		return address{}, "", errors.Errorf("invalid SFTP path: %v", p)
+	}
+	rest := p[len(prefix):]
+
+	i := strings.Index(rest, "/")
+	if i < 0 {
+		return address{}, "", errors.Errorf("invalid SFTP path %v: no file path", p)
+	}
+	authority, file := rest[:i], rest[i:]
+
+	j := strings.LastIndex(authority, "@")
+	if j <= 0 {
+		return address{}, "", errors.Errorf("invalid SFTP path %v: no user", p)
+	}
+	user, host := authority[:j], authority[j+1:]
+	if host == "" {
+		return address{}, "", errors.Errorf("invalid SFTP path %v: no host", p)
+	}
+	if !strings.Contains(host, ":") {
+		host += ":22"
+	}
+	return address{user: user, host: host}, file, nil
+}
+
+func makePath(addr address, file string) string {
+	return fmt.Sprintf("sftp://%v%v", addr, file)
+}
@@ -0,0 +1,88 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sftp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		addr address
+		file string
+		name string // the path as returned by List
+	}{
+		{"sftp://beam@host/a/b.txt", address{"beam", "host:22"}, "/a/b.txt", "sftp://beam@host:22/a/b.txt"},
+		{"sftp://beam@host:2222/a/*.csv", address{"beam", "host:2222"}, "/a/*.csv", "sftp://beam@host:2222/a/*.csv"},
+		{"sftp://me@corp@host/file?.txt", address{"me@corp", "host:22"}, "/file?.txt", "sftp://me@corp@host:22/file?.txt"},
+	}
+	for _, test := range tests {
+		addr, file, err := parsePath(test.path)
+		if err != nil {
+			t.Errorf("parsePath(%v) failed: %v", test.path, err)
+			continue
+		}
+		if addr != test.addr || file != test.file {
+			t.Errorf("parsePath(%v) = (%v, %v), want (%v, %v)", test.path, addr, file, test.addr, test.file)
+		}
+		if got := makePath(addr, file); got != test.name {
+			t.Errorf("makePath(%v, %v) = %v, want %v", addr, file, got, test.name)
+		}
+	}
+
+	for _, path := range []string{"gs://bucket/a", "sftp://host/a", "sftp://beam@host", "sftp://beam@/a"} {
+		if _, _, err := parsePath(path); err == nil {
+			t.Errorf("parsePath(%v) succeeded, want error", path)
+		}
+	}
+}
+
+func TestSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sftp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(file, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("SFTP_TEST_PASSWORD", "env-secret")
+	defer os.Unsetenv("SFTP_TEST_PASSWORD")
+
+	tests := []struct {
+		file, env string
+		want      string
+		fails     bool
+	}{
+		{"", "", "", false},
+		{file, "", "secret", false},
+		{"", "SFTP_TEST_PASSWORD", "env-secret", false},
+		{file, "SFTP_TEST_PASSWORD", "", true},
+		{filepath.Join(dir, "missing"), "", "", true},
+		{"", "SFTP_TEST_MISSING", "", true},
+	}
+	for _, test := range tests {
+		got, err := secret("password", test.file, test.env)
+		if (err != nil) != test.fails || got != test.want {
+			t.Errorf("secret(%q, %q) = (%q, %v), want %q, failure %v", test.file, test.env, got, err, test.want, test.fails)
+		}
+	}
+}
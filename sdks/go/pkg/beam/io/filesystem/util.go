@@ -17,7 +17,10 @@ package filesystem
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
 )
 
 // Read fully reads the given file from the file system.
@@ -43,3 +46,71 @@ func Write(ctx context.Context, fs Interface, filename string, data []byte) erro
 	}
 	return w.Close()
 }
+
+// Copy copies the file oldpath to newpath. If the file system is not a
+// Copier, the file is streamed from oldpath to newpath.
+func Copy(ctx context.Context, fs Interface, oldpath, newpath string) error {
+	if c, ok := fs.(Copier); ok {
+		return c.Copy(ctx, oldpath, newpath)
+	}
+
+	r, err := fs.OpenRead(ctx, oldpath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := fs.OpenWrite(ctx, newpath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Rename moves the file oldpath to newpath. If the file system is not a
+// Renamer, the file is copied and then removed, which requires it to be
+// a Remover.
+func Rename(ctx context.Context, fs Interface, oldpath, newpath string) error {
+	if r, ok := fs.(Renamer); ok {
+		return r.Rename(ctx, oldpath, newpath)
+	}
+	if _, ok := fs.(Remover); !ok {
+		return errors.Errorf("file system for %v does not support rename or remove", oldpath)
+	}
+
+	if err := Copy(ctx, fs, oldpath, newpath); err != nil {
+		return err
+	}
+	return Remove(ctx, fs, oldpath)
+}
+
+// Remove deletes the given file, if the file system is a Remover.
+func Remove(ctx context.Context, fs Interface, filename string) error {
+	r, ok := fs.(Remover)
+	if !ok {
+		return errors.Errorf("file system for %v does not support remove", filename)
+	}
+	return r.Remove(ctx, filename)
+}
+
+// Match expands a pattern to the metadata of the matching files. If the
+// file system is not a Matcher, only the filenames are populated.
+func Match(ctx context.Context, fs Interface, glob string) ([]Metadata, error) {
+	if m, ok := fs.(Matcher); ok {
+		return m.Match(ctx, glob)
+	}
+
+	files, err := fs.List(ctx, glob)
+	if err != nil {
+		return nil, err
+	}
+	var ret []Metadata
+	for _, filename := range files {
+		ret = append(ret, Metadata{Filename: filename})
+	}
+	return ret, nil
+}
@@ -0,0 +1,161 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gcsnotifyio provides a streaming source of files as they arrive in
+// Google Cloud Storage, based on Pub/Sub notifications of object changes.
+// Experimental.
+//
+// A notification configuration must first be set up for the bucket, such as
+// with:
+//
+//    gsutil notification create -t uploads -f json -e OBJECT_FINALIZE gs://bucket
+//
+// The files can then be processed as they arrive:
+//
+//    files := gcsnotifyio.Read(s, project, "uploads", &gcsnotifyio.ReadOptions{
+//        Glob: "gs://bucket/incoming/*.csv",
+//    })
+//    lines := textio.ReadMatches(s, files)
+package gcsnotifyio
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/pubsubio"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*filesystem.Metadata)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*parseNotificationFn)(nil)).Elem())
+}
+
+// Event types of Cloud Storage notifications.
+const (
+	ObjectFinalize       = "OBJECT_FINALIZE"
+	ObjectMetadataUpdate = "OBJECT_METADATA_UPDATE"
+	ObjectDelete         = "OBJECT_DELETE"
+	ObjectArchive        = "OBJECT_ARCHIVE"
+)
+
+// ReadOptions represents options for reading Cloud Storage notifications.
+type ReadOptions struct {
+	// Subscription is the Pub/Sub subscription to read from. If empty, a
+	// subscription is created for the topic.
+	Subscription string
+	// Glob restricts the files to those matching the pattern, such as
+	// "gs://bucket/incoming/*.csv". If empty, all files are emitted.
+	Glob string
+	// EventTypes are the notification event types to emit files for. If
+	// empty, only ObjectFinalize is used, i.e., newly created or
+	// overwritten files.
+	EventTypes []string
+}
+
+// Read reads Cloud Storage notifications from the given Pub/Sub topic and
+// returns an unbounded PCollection<filesystem.Metadata> of the changed files.
+// Notifications of other event types or for files not matching the glob are
+// dropped.
+func Read(s beam.Scope, project, topic string, opts *ReadOptions) beam.PCollection {
+	s = s.Scope("gcsnotifyio.Read")
+
+	fn := &parseNotificationFn{EventTypes: []string{ObjectFinalize}}
+	ps := &pubsubio.ReadOptions{WithAttributes: true}
+	if opts != nil {
+		if opts.Glob != "" {
+			if _, err := path.Match(opts.Glob, ""); err != nil {
+				panic(errors.Wrapf(err, "invalid glob %v", opts.Glob))
+			}
+			fn.Glob = opts.Glob
+		}
+		if len(opts.EventTypes) > 0 {
+			fn.EventTypes = opts.EventTypes
+		}
+		ps.Subscription = opts.Subscription
+	}
+
+	msgs := pubsubio.Read(s, project, topic, ps)
+	return beam.ParDo(s, fn, msgs)
+}
+
+// objectResource is the subset of the JSON object resource, which is the
+// payload of notifications in JSON_API_V1 format, used for the metadata.
+type objectResource struct {
+	Size    string    `json:"size"`
+	Updated time.Time `json:"updated"`
+}
+
+// parseNotificationFn converts notifications to file metadata.
+type parseNotificationFn struct {
+	// Glob is the pattern files must match, if any.
+	Glob string `json:"glob,omitempty"`
+	// EventTypes are the event types to emit files for.
+	EventTypes []string `json:"event_types"`
+}
+
+func (f *parseNotificationFn) ProcessElement(ctx context.Context, msg *pb.PubsubMessage, emit func(filesystem.Metadata)) error {
+	attr := msg.GetAttributes()
+	if !f.accepts(attr["eventType"]) {
+		return nil
+	}
+	bucket, object := attr["bucketId"], attr["objectId"]
+	if bucket == "" || object == "" {
+		log.Warnf(ctx, "Dropping notification without object: %v", attr)
+		return nil
+	}
+
+	filename := "gs://" + bucket + "/" + object
+	if f.Glob != "" {
+		if ok, _ := path.Match(f.Glob, filename); !ok {
+			return nil
+		}
+	}
+
+	m := filesystem.Metadata{Filename: filename}
+	if attr["payloadFormat"] == "JSON_API_V1" && len(msg.GetData()) > 0 {
+		var obj objectResource
+		if err := json.Unmarshal(msg.GetData(), &obj); err != nil {
+			return errors.Wrapf(err, "invalid notification payload for %v", filename)
+		}
+		if obj.Size != "" {
+			size, err := strconv.ParseInt(obj.Size, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid size of %v", filename)
+			}
+			m.Size = size
+		}
+		m.LastModified = obj.Updated
+	}
+	emit(m)
+	return nil
+}
+
+func (f *parseNotificationFn) accepts(eventType string) bool {
+	for _, t := range f.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcsnotifyio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+)
+
+func TestParseNotification(t *testing.T) {
+	notify := func(eventType, object, data string) *pb.PubsubMessage {
+		return &pb.PubsubMessage{
+			Attributes: map[string]string{
+				"eventType":     eventType,
+				"bucketId":      "bucket",
+				"objectId":      object,
+				"payloadFormat": "JSON_API_V1",
+			},
+			Data: []byte(data),
+		}
+	}
+	updated := time.Date(2018, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		msg *pb.PubsubMessage
+		exp []filesystem.Metadata
+	}{
+		{
+			notify(ObjectFinalize, "incoming/a.csv", `{"name":"incoming/a.csv","size":"42","updated":"2018-05-01T12:30:00Z"}`),
+			[]filesystem.Metadata{{Filename: "gs://bucket/incoming/a.csv", Size: 42, LastModified: updated}},
+		},
+		{
+			notify(ObjectFinalize, "incoming/b.csv", ""),
+			[]filesystem.Metadata{{Filename: "gs://bucket/incoming/b.csv"}},
+		},
+		{notify(ObjectDelete, "incoming/a.csv", `{}`), nil},
+		{notify(ObjectFinalize, "incoming/a.txt", `{}`), nil},
+		{notify(ObjectFinalize, "other/a.csv", `{}`), nil},
+	}
+
+	fn := &parseNotificationFn{Glob: "gs://bucket/incoming/*.csv", EventTypes: []string{ObjectFinalize}}
+	for _, test := range tests {
+		var got []filesystem.Metadata
+		if err := fn.ProcessElement(context.Background(), test.msg, func(m filesystem.Metadata) { got = append(got, m) }); err != nil {
+			t.Errorf("ProcessElement(%v) failed: %v", test.msg.Attributes, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("ProcessElement(%v) = %v, want %v", test.msg.Attributes, got, test.exp)
+		}
+	}
+}
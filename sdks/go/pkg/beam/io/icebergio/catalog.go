@@ -0,0 +1,204 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// RESTCatalog is an Iceberg REST catalog, which tables are loaded from and
+// snapshots committed to.
+type RESTCatalog struct {
+	// URI is the base URI of the catalog, such as
+	// "https://catalog.example.com/api/catalog".
+	URI string `json:"uri"`
+	// Prefix is the catalog prefix of the paths, if the catalog uses one,
+	// such as the warehouse name.
+	Prefix string `json:"prefix,omitempty"`
+	// TokenFile is the path of a file on the workers that holds the OAuth2
+	// bearer token used to authenticate, if any. It is read for each request,
+	// so the token can be refreshed in place.
+	TokenFile string `json:"token_file,omitempty"`
+	// TokenEnv is the name of an environment variable of the workers that
+	// holds the bearer token, if any. The token itself is not part of the
+	// pipeline.
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// token returns the bearer token, if any.
+func (c RESTCatalog) token() (string, error) {
+	switch {
+	case c.TokenFile != "" && c.TokenEnv != "":
+		return "", errors.New("at most one of TokenFile and TokenEnv may be set")
+	case c.TokenFile != "":
+		data, err := ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read catalog token")
+		}
+		return strings.TrimSpace(string(data)), nil
+	case c.TokenEnv != "":
+		v, ok := os.LookupEnv(c.TokenEnv)
+		if !ok {
+			return "", errors.Errorf("catalog token variable %v not set", c.TokenEnv)
+		}
+		return v, nil
+	default:
+		return "", nil
+	}
+}
+
+// errConflict is returned by commit if the table was changed concurrently.
+var errConflict = errors.New("commit conflict")
+
+// tableIdentifier is a table name and its namespace.
+type tableIdentifier struct {
+	Namespace []string `json:"namespace"`
+	Name      string   `json:"name"`
+}
+
+func (id tableIdentifier) String() string {
+	return strings.Join(append(append([]string{}, id.Namespace...), id.Name), ".")
+}
+
+// parseIdentifier parses a table identifier of the form "ns.table", where
+// the namespace may have multiple levels.
+func parseIdentifier(table string) (tableIdentifier, error) {
+	parts := strings.Split(table, ".")
+	if len(parts) < 2 {
+		return tableIdentifier{}, errors.Errorf("invalid table identifier %v: no namespace", table)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return tableIdentifier{}, errors.Errorf("invalid table identifier: %v", table)
+		}
+	}
+	return tableIdentifier{Namespace: parts[:len(parts)-1], Name: parts[len(parts)-1]}, nil
+}
+
+func (c RESTCatalog) tableURL(id tableIdentifier) string {
+	u := strings.TrimSuffix(c.URI, "/") + "/v1/"
+	if c.Prefix != "" {
+		u += url.PathEscape(c.Prefix) + "/"
+	}
+	// Namespace levels are separated by the unit separator.
+	return u + "namespaces/" + url.PathEscape(strings.Join(id.Namespace, "\x1f")) + "/tables/" + url.PathEscape(id.Name)
+}
+
+// loadTable returns the current metadata of the table.
+func (c RESTCatalog) loadTable(ctx context.Context, id tableIdentifier) (*TableMetadata, error) {
+	var resp struct {
+		Metadata *TableMetadata `json:"metadata"`
+	}
+	if err := c.do(ctx, "GET", c.tableURL(id), nil, &resp); err != nil {
+		return nil, errors.WithContextf(err, "loading table %v", id)
+	}
+	if resp.Metadata == nil {
+		return nil, errors.Errorf("no metadata for table %v", id)
+	}
+	return resp.Metadata, nil
+}
+
+// commitSnapshot adds the snapshot to the table and makes it the current
+// snapshot of the main branch, if the main branch is still at the parent
+// snapshot.
+func (c RESTCatalog) commitSnapshot(ctx context.Context, id tableIdentifier, snapshot Snapshot) error {
+	type requirement struct {
+		Type       string `json:"type"`
+		Ref        string `json:"ref"`
+		SnapshotID *int64 `json:"snapshot-id"`
+	}
+	type update struct {
+		Action     string    `json:"action"`
+		Snapshot   *Snapshot `json:"snapshot,omitempty"`
+		RefName    string    `json:"ref-name,omitempty"`
+		Type       string    `json:"type,omitempty"`
+		SnapshotID int64     `json:"snapshot-id,omitempty"`
+	}
+	req := struct {
+		Identifier   tableIdentifier `json:"identifier"`
+		Requirements []requirement   `json:"requirements"`
+		Updates      []update        `json:"updates"`
+	}{
+		Identifier: id,
+		Requirements: []requirement{
+			{Type: "assert-ref-snapshot-id", Ref: "main", SnapshotID: snapshot.ParentID},
+		},
+		Updates: []update{
+			{Action: "add-snapshot", Snapshot: &snapshot},
+			{Action: "set-snapshot-ref", RefName: "main", Type: "branch", SnapshotID: snapshot.ID},
+		},
+	}
+	if err := c.do(ctx, "POST", c.tableURL(id), req, nil); err != nil {
+		if err == errConflict {
+			return err
+		}
+		return errors.WithContextf(err, "committing snapshot %v to %v", snapshot.ID, id)
+	}
+	return nil
+}
+
+func (c RESTCatalog) do(ctx context.Context, method, u string, body, ret interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return errConflict
+	case resp.StatusCode/100 != 2:
+		return errors.Errorf("%v %v: %v: %s", method, u, resp.Status, data)
+	case ret != nil:
+		return json.Unmarshal(data, ret)
+	default:
+		return nil
+	}
+}
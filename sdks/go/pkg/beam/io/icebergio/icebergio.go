@@ -0,0 +1,410 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package icebergio provides a transformation to append to Apache Iceberg
+// tables. Experimental.
+//
+// Rows are written as Parquet data files in the table location and
+// committed as a single snapshot through an Iceberg REST catalog. The row
+// type must be a struct with a field for each required column. Fields are
+// matched to columns by their iceberg tag or, failing that, their
+// case-insensitive name. For example:
+//
+//    type Event struct {
+//        ID   int64     `iceberg:"id"`
+//        Kind *string   `iceberg:"kind"`
+//        At   time.Time `iceberg:"event_time"`
+//    }
+//
+//    catalog := icebergio.RESTCatalog{URI: "https://catalog.example.com"}
+//    icebergio.Write(s, catalog, "analytics.events", events)
+//
+// Only top-level columns of primitive types are written: boolean, int,
+// long, float, double, string, binary, date and timestamp(tz). Columns that
+// are not written are null.
+package icebergio
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/internal/parquet"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*dataFile)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*partitionFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*writeDataFileFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*commitFn)(nil)).Elem())
+}
+
+// commitAttempts is the number of times a commit is attempted, if the table
+// is changed concurrently.
+const commitAttempts = 5
+
+// commitIDProperty is the snapshot summary property that holds the id of the
+// Write that committed it, so that a retried commit is not applied twice.
+const commitIDProperty = "beam.commit-id"
+
+// targetFileSize is the estimated size at which a data file is completed and
+// the next rows of the partition are written to a new file.
+var targetFileSize int64 = 128 << 20
+
+// Write appends the elements of the given PCollection<T> to the table,
+// identified as "namespace.table". Rows are routed to the partitions of the
+// default partition spec of the table and each partition is written as
+// separate data files of about 128MB. All files are committed as one append
+// snapshot, once written. The snapshot records the id of the write, so that
+// a retried commit that already succeeded is skipped.
+func Write(s beam.Scope, catalog RESTCatalog, table string, col beam.PCollection) {
+	s = s.Scope("icebergio.Write")
+
+	if _, err := parseIdentifier(table); err != nil {
+		panic(err)
+	}
+	t := col.Type().Type()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("row type %v must be a struct", t))
+	}
+
+	keyed := beam.ParDo(s, &partitionFn{Catalog: catalog, Table: table, Type: beam.EncodedType{T: t}}, col)
+	files := beam.ParDo(s, &writeDataFileFn{Catalog: catalog, Table: table, Type: beam.EncodedType{T: t}}, beam.GroupByKey(s, keyed))
+	beam.ParDo0(s, &commitFn{Catalog: catalog, Table: table, CommitID: newUUID()}, beam.GroupByKey(s, beam.AddFixedKey(s, files)))
+}
+
+// table holds the parts of the table metadata needed to write rows.
+type table struct {
+	meta        *TableMetadata
+	spec        PartitionSpec
+	columns     []column
+	partitioner *partitioner
+}
+
+func loadTable(ctx context.Context, catalog RESTCatalog, name string, t reflect.Type) (*table, error) {
+	id, err := parseIdentifier(name)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := catalog.loadTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if meta.FormatVersion != 1 && meta.FormatVersion != 2 {
+		return nil, errors.Errorf("unsupported format version %v of table %v", meta.FormatVersion, name)
+	}
+	schema, err := meta.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := meta.defaultSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &table{meta: meta, spec: spec}
+	if t == nil {
+		return ret, nil
+	}
+	if ret.columns, err = mapColumns(schema, t); err != nil {
+		return nil, errors.WithContextf(err, "mapping %v to table %v", t, name)
+	}
+	if ret.partitioner, err = newPartitioner(spec, ret.columns); err != nil {
+		return nil, errors.WithContextf(err, "partitioning table %v", name)
+	}
+	return ret, nil
+}
+
+// values returns the column values of the row by field id.
+func (t *table) values(row interface{}) map[int]interface{} {
+	v := reflect.ValueOf(row)
+	ret := make(map[int]interface{}, len(t.columns))
+	for _, c := range t.columns {
+		ret[c.field.ID] = c.value(v)
+	}
+	return ret
+}
+
+// partitionFn keys rows by their encoded partition.
+type partitionFn struct {
+	// Catalog is the catalog of the table.
+	Catalog RESTCatalog `json:"catalog"`
+	// Table is the table identifier.
+	Table string `json:"table"`
+	// Type is the encoded row type.
+	Type beam.EncodedType `json:"type"`
+
+	table *table
+}
+
+func (f *partitionFn) Setup(ctx context.Context) error {
+	var err error
+	f.table, err = loadTable(ctx, f.Catalog, f.Table, f.Type.T)
+	return err
+}
+
+func (f *partitionFn) ProcessElement(row beam.X) (string, beam.X, error) {
+	key, err := json.Marshal(f.table.partitioner.partition(f.table.values(row)))
+	if err != nil {
+		return "", nil, err
+	}
+	return string(key), row, nil
+}
+
+// writeDataFileFn writes the rows of a partition as Parquet data files of
+// about targetFileSize.
+type writeDataFileFn struct {
+	// Catalog is the catalog of the table.
+	Catalog RESTCatalog `json:"catalog"`
+	// Table is the table identifier.
+	Table string `json:"table"`
+	// Type is the encoded row type.
+	Type beam.EncodedType `json:"type"`
+
+	table *table
+}
+
+func (f *writeDataFileFn) Setup(ctx context.Context) error {
+	var err error
+	f.table, err = loadTable(ctx, f.Catalog, f.Table, f.Type.T)
+	return err
+}
+
+func (f *writeDataFileFn) ProcessElement(ctx context.Context, _ string, rows func(*beam.X) bool, emit func(dataFile)) error {
+	w := newParquetWriter(f.table.columns)
+	var partition []interface{}
+	var row beam.X
+	for rows(&row) {
+		values := f.table.values(row)
+		if partition == nil {
+			partition = f.table.partitioner.partition(values)
+		}
+		if err := w.Add(parquetRow(f.table.columns, values)); err != nil {
+			return err
+		}
+		if w.Size() >= targetFileSize {
+			if err := f.write(ctx, w, partition, emit); err != nil {
+				return err
+			}
+			w = newParquetWriter(f.table.columns)
+		}
+	}
+	if w.Rows() == 0 {
+		return nil
+	}
+	return f.write(ctx, w, partition, emit)
+}
+
+// write writes the rows of the writer as a data file of the partition.
+func (f *writeDataFileFn) write(ctx context.Context, w *parquet.Writer, partition []interface{}, emit func(dataFile)) error {
+	dir := strings.TrimSuffix(f.table.meta.Location, "/") + "/data/"
+	if len(partition) > 0 {
+		dir += f.table.partitioner.path(partition) + "/"
+	}
+	filename := dir + newUUID() + ".parquet"
+
+	fs, err := newFileSystem(ctx, filename)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	fd, err := fs.OpenWrite(ctx, localPath(filename))
+	if err != nil {
+		return err
+	}
+	size, err := w.WriteTo(fd)
+	if err != nil {
+		fd.Close()
+		return errors.Wrapf(err, "failed to write %v", filename)
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+
+	file := dataFile{Path: filename, SpecID: f.table.spec.ID, RecordCount: w.Rows(), FileSize: size}
+	for _, v := range partition {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		file.Partition = append(file.Partition, raw)
+	}
+	log.Infof(ctx, "Wrote %v rows to %v", w.Rows(), filename)
+	emit(file)
+	return nil
+}
+
+// commitFn commits the data files as an append snapshot.
+type commitFn struct {
+	// Catalog is the catalog of the table.
+	Catalog RESTCatalog `json:"catalog"`
+	// Table is the table identifier.
+	Table string `json:"table"`
+	// CommitID identifies the commit in the snapshot summary.
+	CommitID string `json:"commit_id"`
+}
+
+func (f *commitFn) ProcessElement(ctx context.Context, _ int, iter func(*dataFile) bool) error {
+	var files []dataFile
+	var file dataFile
+	for iter(&file) {
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	id, err := parseIdentifier(f.Table)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err := f.commit(ctx, id, files)
+		if err != errConflict {
+			return err
+		}
+		if attempt == commitAttempts {
+			return errors.Errorf("failed to commit to %v after %v attempts: concurrent changes", f.Table, attempt)
+		}
+		log.Warnf(ctx, "Table %v changed concurrently; retrying commit", f.Table)
+	}
+}
+
+func (f *commitFn) commit(ctx context.Context, id tableIdentifier, files []dataFile) error {
+	t, err := loadTable(ctx, f.Catalog, f.Table, nil)
+	if err != nil {
+		return err
+	}
+	if s := t.meta.findCommit(f.CommitID); s != nil {
+		log.Infof(ctx, "Data files already committed to %v as snapshot %v", f.Table, s.ID)
+		return nil
+	}
+	schema, err := t.meta.currentSchema()
+	if err != nil {
+		return err
+	}
+	// The partition types only depend on the source column types.
+	p, err := newPartitioner(t.spec, primitiveColumns(schema))
+	if err != nil {
+		return err
+	}
+
+	snapshot := Snapshot{
+		ID:          newSnapshotID(),
+		TimestampMs: time.Now().UnixNano() / int64(time.Millisecond),
+		SchemaID:    &schema.ID,
+	}
+	if t.meta.FormatVersion > 1 {
+		snapshot.SequenceNumber = t.meta.LastSequenceNumber + 1
+	}
+	var manifests []map[string]interface{}
+	if parent := t.meta.currentSnapshot(); parent != nil {
+		snapshot.ParentID = &parent.ID
+		if manifests, err = readManifestList(ctx, parent.ManifestList); err != nil {
+			return err
+		}
+	}
+
+	base := strings.TrimSuffix(t.meta.Location, "/") + "/metadata/"
+	manifest, err := writeManifest(ctx, base+newUUID()+"-m0.avro", t.meta, schema, t.spec, p, snapshot.ID, files)
+	if err != nil {
+		return err
+	}
+	manifest["sequence_number"] = snapshot.SequenceNumber
+	manifest["min_sequence_number"] = snapshot.SequenceNumber
+	manifests = append([]map[string]interface{}{manifest}, manifests...)
+
+	snapshot.ManifestList = fmt.Sprintf("%vsnap-%v-1-%v.avro", base, snapshot.ID, newUUID())
+	if err := writeManifestList(ctx, snapshot.ManifestList, t.meta, snapshot, manifests); err != nil {
+		return err
+	}
+
+	var rows int64
+	for _, file := range files {
+		rows += file.RecordCount
+	}
+	snapshot.Summary = map[string]string{
+		"operation":        "append",
+		"added-data-files": fmt.Sprint(len(files)),
+		"added-records":    fmt.Sprint(rows),
+		commitIDProperty:   f.CommitID,
+	}
+	if err := f.Catalog.commitSnapshot(ctx, id, snapshot); err != nil {
+		return err
+	}
+	log.Infof(ctx, "Committed %v data files with %v rows to %v as snapshot %v", len(files), rows, f.Table, snapshot.ID)
+	return nil
+}
+
+// primitiveColumns returns the primitive columns of the schema, without
+// struct fields.
+func primitiveColumns(schema Schema) []column {
+	var ret []column
+	for _, f := range schema.Fields {
+		if typ := f.primitive(); typ != "" {
+			ret = append(ret, column{field: f, typ: typ})
+		}
+	}
+	return ret
+}
+
+// localPath returns the path for the Beam file systems, which treat paths
+// without a scheme as local.
+func localPath(path string) string {
+	return strings.TrimPrefix(path, "file://")
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	s := hex.EncodeToString(b[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+func newSnapshotID() int64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]) & math.MaxInt64)
+}
+
+// countingWriter counts the bytes written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
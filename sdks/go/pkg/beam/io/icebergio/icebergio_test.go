@@ -0,0 +1,309 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/memfs"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/linkedin/goavro"
+)
+
+type event struct {
+	ID   int64     `iceberg:"id"`
+	Kind *string   `iceberg:"kind"`
+	At   time.Time `iceberg:"event_time"`
+}
+
+const eventsMetadata = `{
+	"format-version": 2,
+	"location": "memfs://warehouse/analytics/events",
+	"last-sequence-number": 0,
+	"current-schema-id": 0,
+	"schemas": [{"schema-id": 0, "type": "struct", "fields": [
+		{"id": 1, "name": "id", "required": true, "type": "long"},
+		{"id": 2, "name": "kind", "required": false, "type": "string"},
+		{"id": 3, "name": "event_time", "required": true, "type": "timestamptz"},
+		{"id": 4, "name": "payload", "required": false, "type": {"type": "struct", "fields": []}}
+	]}],
+	"default-spec-id": 0,
+	"partition-specs": [{"spec-id": 0, "fields": [
+		{"name": "event_time_day", "transform": "day", "source-id": 3, "field-id": 1000}
+	]}],
+	"current-snapshot-id": -1,
+	"snapshots": []
+}`
+
+// fakeCatalog is a REST catalog of a single table that applies commits to
+// its metadata.
+type fakeCatalog struct {
+	meta    map[string]interface{}
+	token   string
+	commits int
+	mu      sync.Mutex
+}
+
+func newFakeCatalog(t *testing.T) *fakeCatalog {
+	fake := &fakeCatalog{}
+	dec := json.NewDecoder(strings.NewReader(eventsMetadata))
+	dec.UseNumber()
+	if err := dec.Decode(&fake.meta); err != nil {
+		t.Fatal(err)
+	}
+	return fake
+}
+
+func (c *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && r.Header.Get("Authorization") != "Bearer "+c.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.URL.EscapedPath() != "/v1/namespaces/analytics/tables/events" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{"metadata": c.meta})
+	case "POST":
+		var req struct {
+			Requirements []struct {
+				SnapshotID *int64 `json:"snapshot-id"`
+			} `json:"requirements"`
+			Updates []struct {
+				Action   string                 `json:"action"`
+				Snapshot map[string]interface{} `json:"snapshot"`
+			} `json:"updates"`
+		}
+		dec := json.NewDecoder(r.Body)
+		dec.UseNumber() // snapshot ids do not fit in a float64
+		if err := dec.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		current, _ := c.meta["current-snapshot-id"].(json.Number).Int64()
+		if want := req.Requirements[0].SnapshotID; (want == nil) != (current == -1) || (want != nil && *want != current) {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		snapshot := req.Updates[0].Snapshot
+		c.meta["snapshots"] = append(c.meta["snapshots"].([]interface{}), snapshot)
+		c.meta["current-snapshot-id"] = snapshot["snapshot-id"]
+		c.meta["last-sequence-number"] = snapshot["sequence-number"]
+		c.commits++
+		w.Write([]byte(`{}`))
+	}
+}
+
+func readOCF(t *testing.T, filename string) []map[string]interface{} {
+	ctx := context.Background()
+	data, err := filesystem.Read(ctx, memfs.New(ctx), filename)
+	if err != nil {
+		t.Fatalf("Read(%v) failed: %v", filename, err)
+	}
+	r, err := goavro.NewOCFReader(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid Avro file %v: %v", filename, err)
+	}
+	var ret []map[string]interface{}
+	for r.Scan() {
+		v, err := r.Read()
+		if err != nil {
+			t.Fatalf("invalid Avro file %v: %v", filename, err)
+		}
+		ret = append(ret, v.(map[string]interface{}))
+	}
+	return ret
+}
+
+func TestWrite(t *testing.T) {
+	fake := newFakeCatalog(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	catalog := RESTCatalog{URI: server.URL}
+
+	click := "click"
+	day1 := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2018, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	// Write twice, so that the second snapshot carries the first manifest.
+	batches := [][]event{
+		{{1, &click, day1}, {2, nil, day1}, {3, &click, day2}},
+		{{4, nil, day2}},
+	}
+	for _, batch := range batches {
+		p, s := beam.NewPipelineWithRoot()
+		Write(s, catalog, "analytics.events", beam.CreateList(s, batch))
+		if err := ptest.Run(p); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if fake.commits != 2 {
+		t.Fatalf("got %v commits, want 2", fake.commits)
+	}
+
+	snapshots := fake.meta["snapshots"].([]interface{})
+	current := snapshots[1].(map[string]interface{})
+	if seq := current["sequence-number"].(json.Number); seq != "2" {
+		t.Errorf("sequence number = %v, want 2", seq)
+	}
+	if op := current["summary"].(map[string]interface{})["operation"]; op != "append" {
+		t.Errorf("operation = %v, want append", op)
+	}
+
+	manifests := readOCF(t, current["manifest-list"].(string))
+	if len(manifests) != 2 {
+		t.Fatalf("got %v manifests, want 2", len(manifests))
+	}
+	var paths []string
+	var rows int64
+	for _, m := range manifests {
+		rows += m["added_rows_count"].(int64)
+		for _, entry := range readOCF(t, m["manifest_path"].(string)) {
+			file := entry["data_file"].(map[string]interface{})
+			paths = append(paths, file["file_path"].(string))
+
+			ctx := context.Background()
+			data, err := filesystem.Read(ctx, memfs.New(ctx), file["file_path"].(string))
+			if err != nil {
+				t.Fatalf("data file missing: %v", err)
+			}
+			if !strings.HasPrefix(string(data), "PAR1") || !strings.HasSuffix(string(data), "PAR1") {
+				t.Errorf("data file %v is not a Parquet file", file["file_path"])
+			}
+			if int64(len(data)) != file["file_size_in_bytes"].(int64) {
+				t.Errorf("file size of %v = %v, want %v", file["file_path"], file["file_size_in_bytes"], len(data))
+			}
+		}
+	}
+	if rows != 4 {
+		t.Errorf("got %v rows, want 4", rows)
+	}
+
+	sort.Strings(paths)
+	exp := []string{
+		"memfs://warehouse/analytics/events/data/event_time_day=2018-05-01/",
+		"memfs://warehouse/analytics/events/data/event_time_day=2018-05-02/",
+		"memfs://warehouse/analytics/events/data/event_time_day=2018-05-02/",
+	}
+	if len(paths) != len(exp) {
+		t.Fatalf("data files = %v, want 3 files in %v", paths, exp)
+	}
+	for i := range exp {
+		if !strings.HasPrefix(paths[i], exp[i]) || !strings.HasSuffix(paths[i], ".parquet") {
+			t.Errorf("data file %v, want file in %v", paths[i], exp[i])
+		}
+	}
+}
+
+// TestCommitRetry tests that a retried commit that already succeeded is
+// skipped.
+func TestCommitRetry(t *testing.T) {
+	fake := newFakeCatalog(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx := context.Background()
+	file := dataFile{Path: "memfs://warehouse/analytics/events/data/retry.parquet", RecordCount: 1, FileSize: 4, Partition: []json.RawMessage{json.RawMessage("17652")}}
+	fn := &commitFn{Catalog: RESTCatalog{URI: server.URL}, Table: "analytics.events", CommitID: newUUID()}
+	for attempt := 0; attempt < 2; attempt++ {
+		done := false
+		iter := func(f *dataFile) bool {
+			if done {
+				return false
+			}
+			*f, done = file, true
+			return true
+		}
+		if err := fn.ProcessElement(ctx, 0, iter); err != nil {
+			t.Fatalf("attempt %v failed: %v", attempt, err)
+		}
+	}
+	if fake.commits != 1 {
+		t.Errorf("got %v commits, want 1", fake.commits)
+	}
+}
+
+// TestWriteRollover tests that the rows of a partition are split into files
+// of the target size.
+func TestWriteRollover(t *testing.T) {
+	defer func(size int64) { targetFileSize = size }(targetFileSize)
+	targetFileSize = 1
+
+	fake := newFakeCatalog(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	day := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	p, s := beam.NewPipelineWithRoot()
+	Write(s, RESTCatalog{URI: server.URL}, "analytics.events", beam.Create(s, event{1, nil, day}, event{2, nil, day}, event{3, nil, day}))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	snapshot := fake.meta["snapshots"].([]interface{})[0].(map[string]interface{})
+	if n := snapshot["summary"].(map[string]interface{})["added-data-files"]; n != "3" {
+		t.Errorf("added %v data files, want 3", n)
+	}
+}
+
+// TestCatalogToken tests that the token is read on the workers.
+func TestCatalogToken(t *testing.T) {
+	fake := newFakeCatalog(t)
+	fake.token = "secret"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	os.Setenv("ICEBERGIO_TEST_TOKEN", "secret")
+	defer os.Unsetenv("ICEBERGIO_TEST_TOKEN")
+	file, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("secret\n")
+	file.Close()
+
+	ctx := context.Background()
+	id := tableIdentifier{Namespace: []string{"analytics"}, Name: "events"}
+	for _, catalog := range []RESTCatalog{
+		{URI: server.URL, TokenEnv: "ICEBERGIO_TEST_TOKEN"},
+		{URI: server.URL, TokenFile: file.Name()},
+	} {
+		if _, err := catalog.loadTable(ctx, id); err != nil {
+			t.Errorf("loadTable(%+v) failed: %v", catalog, err)
+		}
+	}
+	if _, err := (RESTCatalog{URI: server.URL}).loadTable(ctx, id); err == nil {
+		t.Error("loadTable without token succeeded, want error")
+	}
+}
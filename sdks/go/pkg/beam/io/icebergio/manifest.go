@@ -0,0 +1,386 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/linkedin/goavro"
+)
+
+// dataFile describes a data file written for a commit.
+type dataFile struct {
+	Path        string `json:"path"`
+	SpecID      int    `json:"spec_id"`
+	RecordCount int64  `json:"record_count"`
+	FileSize    int64  `json:"file_size"`
+	// Partition holds the JSON encoded partition values.
+	Partition []json.RawMessage `json:"partition"`
+}
+
+// avroType returns the Avro type of a primitive Iceberg type.
+func avroType(typ string) interface{} {
+	switch typ {
+	case "boolean", "int", "long", "float", "double", "string":
+		return typ
+	case "binary":
+		return "bytes"
+	case "date":
+		return map[string]interface{}{"type": "int", "logicalType": "date"}
+	default: // timestamp
+		return map[string]interface{}{"type": "long", "logicalType": "timestamp-micros"}
+	}
+}
+
+func avroField(name string, id int, typ interface{}, optional bool) map[string]interface{} {
+	f := map[string]interface{}{"name": name, "type": typ, "field-id": id}
+	if optional {
+		f["type"] = []interface{}{"null", typ}
+		f["default"] = nil
+	}
+	return f
+}
+
+// manifestSchema returns the Avro schema of manifest entries.
+func manifestSchema(version int, spec *partitioner) (string, error) {
+	var partition []interface{}
+	for _, f := range spec.fields {
+		partition = append(partition, avroField(f.Name, f.FieldID, avroType(f.transform.resultType(f.source.typ)), true))
+	}
+	if partition == nil {
+		partition = []interface{}{}
+	}
+
+	file := []interface{}{
+		avroField("file_path", 100, "string", false),
+		avroField("file_format", 101, "string", false),
+		avroField("partition", 102, map[string]interface{}{"type": "record", "name": "r102", "fields": partition}, false),
+		avroField("record_count", 103, "long", false),
+		avroField("file_size_in_bytes", 104, "long", false),
+	}
+	entry := []interface{}{
+		avroField("status", 0, "int", false),
+	}
+	if version == 1 {
+		file = append(file, avroField("block_size_in_bytes", 105, "long", false))
+		entry = append(entry, avroField("snapshot_id", 1, "long", false))
+	} else {
+		file = append([]interface{}{avroField("content", 134, "int", false)}, file...)
+		entry = append(entry,
+			avroField("snapshot_id", 1, "long", true),
+			avroField("sequence_number", 3, "long", true),
+			avroField("file_sequence_number", 4, "long", true))
+	}
+	entry = append(entry, avroField("data_file", 2, map[string]interface{}{"type": "record", "name": "r2", "fields": file}, false))
+
+	data, err := json.Marshal(map[string]interface{}{"type": "record", "name": "manifest_entry", "fields": entry})
+	return string(data), err
+}
+
+// manifestListSchema is the Avro schema of manifest files in a manifest
+// list. The sequence numbers and content are ignored by v1 readers.
+var manifestListSchema = func() string {
+	fields := []interface{}{
+		avroField("manifest_path", 500, "string", false),
+		avroField("manifest_length", 501, "long", false),
+		avroField("partition_spec_id", 502, "int", false),
+		avroField("content", 517, "int", false),
+		avroField("sequence_number", 515, "long", false),
+		avroField("min_sequence_number", 516, "long", false),
+		avroField("added_snapshot_id", 503, "long", false),
+		avroField("added_files_count", 504, "int", false),
+		avroField("existing_files_count", 505, "int", false),
+		avroField("deleted_files_count", 506, "int", false),
+		avroField("added_rows_count", 512, "long", false),
+		avroField("existing_rows_count", 513, "long", false),
+		avroField("deleted_rows_count", 514, "long", false),
+	}
+	data, err := json.Marshal(map[string]interface{}{"type": "record", "name": "manifest_file", "fields": fields})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}()
+
+// partitionValue decodes a JSON encoded partition value of the Iceberg type
+// to its Avro native value.
+func partitionValue(typ string, raw json.RawMessage) (interface{}, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	var v interface{}
+	var err error
+	switch typ {
+	case "boolean":
+		var b bool
+		err, v = json.Unmarshal(raw, &b), b
+	case "int", "date":
+		var i int32
+		err, v = json.Unmarshal(raw, &i), i
+	case "long", "timestamp", "timestamptz":
+		var i int64
+		err, v = json.Unmarshal(raw, &i), i
+	case "float":
+		var f float32
+		err, v = json.Unmarshal(raw, &f), f
+	case "double":
+		var f float64
+		err, v = json.Unmarshal(raw, &f), f
+	case "string":
+		var s string
+		err, v = json.Unmarshal(raw, &s), s
+	default: // binary
+		var b []byte
+		err, v = json.Unmarshal(raw, &b), b
+	}
+	if err != nil {
+		return nil, err
+	}
+	return goavro.Union(unionName(avroType(typ)), v), nil
+}
+
+// unionName returns the name goavro uses for a union branch of the type.
+func unionName(typ interface{}) string {
+	if m, ok := typ.(map[string]interface{}); ok {
+		return m["type"].(string)
+	}
+	return typ.(string)
+}
+
+// writeManifest writes a manifest of the added data files and returns the
+// manifest file list entry, without sequence numbers.
+func writeManifest(ctx context.Context, filename string, meta *TableMetadata, schema Schema, spec PartitionSpec, p *partitioner, snapshotID int64, files []dataFile) (map[string]interface{}, error) {
+	avroSchema, err := manifestSchema(meta.FormatVersion, p)
+	if err != nil {
+		return nil, err
+	}
+	schemaJSON, err := json.Marshal(struct {
+		Type string `json:"type"`
+		Schema
+	}{"struct", schema})
+	if err != nil {
+		return nil, err
+	}
+	specJSON, err := json.Marshal(spec.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []interface{}
+	var rows int64
+	for _, f := range files {
+		if f.SpecID != spec.ID {
+			return nil, errors.Errorf("data file %v was written for partition spec %v, but the table has spec %v", f.Path, f.SpecID, spec.ID)
+		}
+		partition := make(map[string]interface{})
+		for i, pf := range p.fields {
+			v, err := partitionValue(pf.transform.resultType(pf.source.typ), f.Partition[i])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid partition value of %v", f.Path)
+			}
+			partition[pf.Name] = v
+		}
+		file := map[string]interface{}{
+			"file_path":          f.Path,
+			"file_format":        "PARQUET",
+			"partition":          partition,
+			"record_count":       f.RecordCount,
+			"file_size_in_bytes": f.FileSize,
+		}
+		entry := map[string]interface{}{
+			"status":    1, // ADDED
+			"data_file": file,
+		}
+		if meta.FormatVersion == 1 {
+			file["block_size_in_bytes"] = int64(64 << 20)
+			entry["snapshot_id"] = snapshotID
+		} else {
+			file["content"] = 0 // data
+			entry["snapshot_id"] = goavro.Union("long", snapshotID)
+			// Sequence numbers are inherited from the manifest list.
+			entry["sequence_number"] = nil
+			entry["file_sequence_number"] = nil
+		}
+		records = append(records, entry)
+		rows += f.RecordCount
+	}
+
+	size, err := writeOCF(ctx, filename, avroSchema, map[string][]byte{
+		"schema":            schemaJSON,
+		"schema-id":         []byte(strconv.Itoa(schema.ID)),
+		"partition-spec":    specJSON,
+		"partition-spec-id": []byte(strconv.Itoa(spec.ID)),
+		"format-version":    []byte(strconv.Itoa(meta.FormatVersion)),
+		"content":           []byte("data"),
+	}, records)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"manifest_path":        filename,
+		"manifest_length":      size,
+		"partition_spec_id":    int32(spec.ID),
+		"content":              int32(0),
+		"added_snapshot_id":    snapshotID,
+		"added_files_count":    int32(len(files)),
+		"existing_files_count": int32(0),
+		"deleted_files_count":  int32(0),
+		"added_rows_count":     rows,
+		"existing_rows_count":  int64(0),
+		"deleted_rows_count":   int64(0),
+	}, nil
+}
+
+// readManifestList returns the manifest files of a manifest list, converted
+// to the manifest list schema.
+func readManifestList(ctx context.Context, filename string) ([]map[string]interface{}, error) {
+	fs, err := newFileSystem(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Close()
+
+	fd, err := fs.OpenRead(ctx, localPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	r, err := goavro.NewOCFReader(fd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid manifest list %v", filename)
+	}
+	var ret []map[string]interface{}
+	for r.Scan() {
+		native, err := r.Read()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid manifest list %v", filename)
+		}
+		m, ok := native.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid manifest list %v", filename)
+		}
+
+		// v1 lists use other names for the counts and may omit them.
+		get := func(names ...string) interface{} {
+			for _, name := range names {
+				if v, ok := m[name]; ok && v != nil {
+					if u, ok := v.(map[string]interface{}); ok {
+						for _, x := range u {
+							return x
+						}
+					}
+					return v
+				}
+			}
+			return nil
+		}
+		toInt32 := func(v interface{}) int32 {
+			switch x := v.(type) {
+			case int32:
+				return x
+			case int64:
+				return int32(x)
+			}
+			return 0
+		}
+		toInt64 := func(v interface{}) int64 {
+			switch x := v.(type) {
+			case int32:
+				return int64(x)
+			case int64:
+				return x
+			}
+			return 0
+		}
+
+		ret = append(ret, map[string]interface{}{
+			"manifest_path":        get("manifest_path"),
+			"manifest_length":      toInt64(get("manifest_length")),
+			"partition_spec_id":    toInt32(get("partition_spec_id")),
+			"content":              toInt32(get("content")),
+			"sequence_number":      toInt64(get("sequence_number")),
+			"min_sequence_number":  toInt64(get("min_sequence_number")),
+			"added_snapshot_id":    toInt64(get("added_snapshot_id")),
+			"added_files_count":    toInt32(get("added_files_count", "added_data_files_count")),
+			"existing_files_count": toInt32(get("existing_files_count", "existing_data_files_count")),
+			"deleted_files_count":  toInt32(get("deleted_files_count", "deleted_data_files_count")),
+			"added_rows_count":     toInt64(get("added_rows_count")),
+			"existing_rows_count":  toInt64(get("existing_rows_count")),
+			"deleted_rows_count":   toInt64(get("deleted_rows_count")),
+		})
+	}
+	return ret, r.Err()
+}
+
+// writeManifestList writes the manifest list of a snapshot.
+func writeManifestList(ctx context.Context, filename string, meta *TableMetadata, snapshot Snapshot, manifests []map[string]interface{}) error {
+	parent := "null"
+	if snapshot.ParentID != nil {
+		parent = strconv.FormatInt(*snapshot.ParentID, 10)
+	}
+	records := make([]interface{}, len(manifests))
+	for i, m := range manifests {
+		records[i] = m
+	}
+	_, err := writeOCF(ctx, filename, manifestListSchema, map[string][]byte{
+		"snapshot-id":        []byte(strconv.FormatInt(snapshot.ID, 10)),
+		"parent-snapshot-id": []byte(parent),
+		"sequence-number":    []byte(strconv.FormatInt(snapshot.SequenceNumber, 10)),
+		"format-version":     []byte(strconv.Itoa(meta.FormatVersion)),
+	}, records)
+	return err
+}
+
+// writeOCF writes the records as an Avro object container file and returns
+// its size.
+func writeOCF(ctx context.Context, filename, schema string, meta map[string][]byte, records []interface{}) (int64, error) {
+	fs, err := newFileSystem(ctx, filename)
+	if err != nil {
+		return 0, err
+	}
+	defer fs.Close()
+
+	fd, err := fs.OpenWrite(ctx, localPath(filename))
+	if err != nil {
+		return 0, err
+	}
+	w := &countingWriter{w: fd}
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{W: w, Schema: schema, MetaData: meta})
+	if err != nil {
+		fd.Close()
+		return 0, errors.Wrapf(err, "failed to write %v", filename)
+	}
+	if err := ocf.Append(records); err != nil {
+		fd.Close()
+		return 0, errors.Wrapf(err, "failed to write %v", filename)
+	}
+	if err := fd.Close(); err != nil {
+		return 0, err
+	}
+	return w.n, nil
+}
+
+// newFileSystem returns the file system of the path. Paths of the form
+// file:///path are local paths.
+func newFileSystem(ctx context.Context, path string) (filesystem.Interface, error) {
+	return filesystem.New(ctx, localPath(path))
+}
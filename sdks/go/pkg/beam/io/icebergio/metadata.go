@@ -0,0 +1,277 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// TableMetadata is the subset of the Iceberg table metadata used to append
+// to a table.
+type TableMetadata struct {
+	FormatVersion      int              `json:"format-version"`
+	Location           string           `json:"location"`
+	LastSequenceNumber int64            `json:"last-sequence-number"`
+	CurrentSchemaID    int              `json:"current-schema-id"`
+	Schemas            []Schema         `json:"schemas"`
+	Schema             *Schema          `json:"schema,omitempty"` // v1
+	DefaultSpecID      int              `json:"default-spec-id"`
+	PartitionSpecs     []PartitionSpec  `json:"partition-specs"`
+	PartitionSpec      []PartitionField `json:"partition-spec,omitempty"` // v1
+	CurrentSnapshotID  *int64           `json:"current-snapshot-id"`
+	Snapshots          []Snapshot       `json:"snapshots"`
+}
+
+// Schema is an Iceberg table schema.
+type Schema struct {
+	ID     int           `json:"schema-id"`
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField is a top-level column of a schema. Type is the type name for
+// primitive types, such as "long" or "timestamptz", and a JSON object for
+// nested types.
+type SchemaField struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+}
+
+// primitive returns the primitive type name of the field, or the empty
+// string for nested types.
+func (f SchemaField) primitive() string {
+	var name string
+	if err := json.Unmarshal(f.Type, &name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// PartitionSpec is an Iceberg partition spec.
+type PartitionSpec struct {
+	ID     int              `json:"spec-id"`
+	Fields []PartitionField `json:"fields"`
+}
+
+// PartitionField is a field of a partition spec, such as the "day"
+// transform of a timestamp column.
+type PartitionField struct {
+	Name      string `json:"name"`
+	Transform string `json:"transform"`
+	SourceID  int    `json:"source-id"`
+	FieldID   int    `json:"field-id"`
+}
+
+// Snapshot is an Iceberg snapshot.
+type Snapshot struct {
+	ID             int64             `json:"snapshot-id"`
+	ParentID       *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber int64             `json:"sequence-number,omitempty"`
+	TimestampMs    int64             `json:"timestamp-ms"`
+	ManifestList   string            `json:"manifest-list"`
+	Summary        map[string]string `json:"summary"`
+	SchemaID       *int              `json:"schema-id,omitempty"`
+}
+
+// currentSchema returns the current schema of the table.
+func (m *TableMetadata) currentSchema() (Schema, error) {
+	for _, s := range m.Schemas {
+		if s.ID == m.CurrentSchemaID {
+			return s, nil
+		}
+	}
+	if m.Schema != nil {
+		return *m.Schema, nil
+	}
+	return Schema{}, errors.Errorf("current schema %v not found", m.CurrentSchemaID)
+}
+
+// defaultSpec returns the default partition spec of the table.
+func (m *TableMetadata) defaultSpec() (PartitionSpec, error) {
+	for _, s := range m.PartitionSpecs {
+		if s.ID == m.DefaultSpecID {
+			return s, nil
+		}
+	}
+	if len(m.PartitionSpecs) == 0 {
+		return PartitionSpec{ID: 0, Fields: m.PartitionSpec}, nil
+	}
+	return PartitionSpec{}, errors.Errorf("default partition spec %v not found", m.DefaultSpecID)
+}
+
+// currentSnapshot returns the current snapshot of the table, if any.
+func (m *TableMetadata) currentSnapshot() *Snapshot {
+	if m.CurrentSnapshotID == nil || *m.CurrentSnapshotID == -1 {
+		return nil
+	}
+	for i := range m.Snapshots {
+		if m.Snapshots[i].ID == *m.CurrentSnapshotID {
+			return &m.Snapshots[i]
+		}
+	}
+	return nil
+}
+
+// findCommit returns the snapshot of the current snapshot and its ancestors
+// whose summary has the commit id, if any.
+func (m *TableMetadata) findCommit(id string) *Snapshot {
+	byID := make(map[int64]*Snapshot)
+	for i := range m.Snapshots {
+		byID[m.Snapshots[i].ID] = &m.Snapshots[i]
+	}
+	for s := m.currentSnapshot(); s != nil; {
+		if s.Summary[commitIDProperty] == id {
+			return s
+		}
+		if s.ParentID == nil {
+			break
+		}
+		s = byID[*s.ParentID]
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// column is a table column written from a struct field.
+type column struct {
+	field SchemaField
+	typ   string // primitive type name
+	index int    // struct field index
+}
+
+// mapColumns maps the columns of the schema to the fields of the struct
+// type t, by the iceberg tag or, failing that, the case-insensitive name.
+// Columns without a field are not written and must be optional.
+func mapColumns(schema Schema, t reflect.Type) ([]column, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("row type %v must be a struct", t)
+	}
+
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		name := strings.Split(f.Tag.Get("iceberg"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = i
+	}
+
+	var ret []column
+	for _, f := range schema.Fields {
+		index, ok := fields[strings.ToLower(f.Name)]
+		if !ok {
+			if f.Required {
+				return nil, errors.Errorf("required column %v has no field in %v", f.Name, t)
+			}
+			continue
+		}
+		typ := f.primitive()
+		if err := checkType(typ, t.Field(index).Type); err != nil {
+			return nil, errors.WithContextf(err, "mapping column %v", f.Name)
+		}
+		ret = append(ret, column{field: f, typ: typ, index: index})
+	}
+	return ret, nil
+}
+
+// checkType checks that values of the Go type can be written as the
+// primitive Iceberg type. Pointers are written as optional values.
+func checkType(typ string, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ok := false
+	switch typ {
+	case "boolean":
+		ok = t.Kind() == reflect.Bool
+	case "int":
+		ok = t.Kind() == reflect.Int32 || t.Kind() == reflect.Int16 || t.Kind() == reflect.Int8
+	case "long":
+		ok = t.Kind() == reflect.Int64 || t.Kind() == reflect.Int || t.Kind() == reflect.Int32
+	case "float":
+		ok = t.Kind() == reflect.Float32
+	case "double":
+		ok = t.Kind() == reflect.Float64 || t.Kind() == reflect.Float32
+	case "string":
+		ok = t.Kind() == reflect.String
+	case "binary":
+		ok = t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+	case "date", "timestamp", "timestamptz":
+		ok = t == timeType
+	case "":
+		return errors.New("nested types are not supported")
+	default:
+		return errors.Errorf("type %v is not supported", typ)
+	}
+	if !ok {
+		return errors.Errorf("cannot write %v as %v", t, typ)
+	}
+	return nil
+}
+
+// value returns the value of the column for the row, converted to its
+// representation for the Iceberg type: bool, int32, int64, float32, float64,
+// string, []byte, or for dates the days and for timestamps the
+// microseconds since the epoch. It returns nil for null values, which are
+// nil pointers and nil byte slices.
+func (c column) value(row reflect.Value) interface{} {
+	v := row.Field(c.index)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch c.typ {
+	case "boolean":
+		return v.Bool()
+	case "int":
+		return int32(v.Int())
+	case "long":
+		return v.Int()
+	case "float":
+		return float32(v.Float())
+	case "double":
+		return v.Float()
+	case "string":
+		return v.String()
+	case "binary":
+		if v.IsNil() {
+			return nil
+		}
+		return v.Bytes()
+	case "date":
+		return int32(floorDiv(v.Interface().(time.Time).Unix(), 24*3600))
+	default: // timestamp
+		t := v.Interface().(time.Time)
+		return t.Unix()*1000000 + int64(t.Nanosecond()/1000)
+	}
+}
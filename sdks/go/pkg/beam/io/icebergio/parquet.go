@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"github.com/apache/beam/sdks/go/pkg/beam/io/internal/parquet"
+)
+
+// Data files are written as Parquet files by the shared minimal writer.
+// Schema elements carry the Iceberg field ids, which is how Iceberg readers
+// resolve columns.
+
+// parquetType returns the physical and converted type of the Iceberg type.
+func parquetType(typ string) (int32, int32) {
+	switch typ {
+	case "boolean":
+		return parquet.Boolean, parquet.None
+	case "int":
+		return parquet.Int32, parquet.None
+	case "long":
+		return parquet.Int64, parquet.None
+	case "float":
+		return parquet.Float, parquet.None
+	case "double":
+		return parquet.Double, parquet.None
+	case "string":
+		return parquet.ByteArray, parquet.UTF8
+	case "binary":
+		return parquet.ByteArray, parquet.None
+	case "date":
+		return parquet.Int32, parquet.Date
+	default: // timestamp
+		return parquet.Int64, parquet.TimestampMicros
+	}
+}
+
+// newParquetWriter returns a Parquet writer of the columns.
+func newParquetWriter(columns []column) *parquet.Writer {
+	var ret []parquet.Column
+	for _, c := range columns {
+		physical, converted := parquetType(c.typ)
+		ret = append(ret, parquet.Column{
+			Name:          c.field.Name,
+			FieldID:       c.field.ID,
+			Type:          physical,
+			ConvertedType: converted,
+			Required:      c.field.Required,
+		})
+	}
+	return parquet.NewWriter(ret)
+}
+
+// parquetRow returns the row values, by field id, in column order.
+func parquetRow(columns []column, values map[int]interface{}) []interface{} {
+	ret := make([]interface{}, len(columns))
+	for i, c := range columns {
+		ret[i] = values[c.field.ID]
+	}
+	return ret
+}
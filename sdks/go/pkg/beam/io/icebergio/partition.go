@@ -0,0 +1,298 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// partitioner computes the partition of rows for a partition spec.
+type partitioner struct {
+	fields []partitionField
+}
+
+type partitionField struct {
+	PartitionField
+	source    column
+	transform transform
+}
+
+// transform is a partition transform, applied to the Iceberg representation
+// of a value as returned by column.value.
+type transform struct {
+	name  string // identity, bucket, truncate, year, month, day, hour, void
+	width int    // for bucket and truncate
+}
+
+func parseTransform(s string) (transform, error) {
+	switch s {
+	case "identity", "year", "month", "day", "hour", "void":
+		return transform{name: s}, nil
+	}
+	for _, name := range []string{"bucket", "truncate"} {
+		if strings.HasPrefix(s, name+"[") && strings.HasSuffix(s, "]") {
+			width, err := strconv.Atoi(s[len(name)+1 : len(s)-1])
+			if err != nil || width <= 0 {
+				return transform{}, errors.Errorf("invalid partition transform: %v", s)
+			}
+			return transform{name: name, width: width}, nil
+		}
+	}
+	return transform{}, errors.Errorf("unsupported partition transform: %v", s)
+}
+
+// newPartitioner returns a partitioner for the spec. All source columns of
+// the spec must be written.
+func newPartitioner(spec PartitionSpec, columns []column) (*partitioner, error) {
+	p := &partitioner{}
+	for _, f := range spec.Fields {
+		t, err := parseTransform(f.Transform)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, c := range columns {
+			if c.field.ID == f.SourceID {
+				p.fields = append(p.fields, partitionField{PartitionField: f, source: c, transform: t})
+				found = true
+				break
+			}
+		}
+		if !found && t.name != "void" {
+			return nil, errors.Errorf("source column %v of partition field %v is not written", f.SourceID, f.Name)
+		}
+		if !found {
+			p.fields = append(p.fields, partitionField{PartitionField: f, transform: t})
+		}
+	}
+	return p, nil
+}
+
+// partition returns the partition values of the row values.
+func (p *partitioner) partition(values map[int]interface{}) []interface{} {
+	ret := make([]interface{}, len(p.fields))
+	for i, f := range p.fields {
+		if f.transform.name == "void" {
+			continue
+		}
+		ret[i] = f.transform.apply(f.source.typ, values[f.source.field.ID])
+	}
+	return ret
+}
+
+// path returns the partition path of the values, such as
+// "day=2018-05-01/bucket=3".
+func (p *partitioner) path(partition []interface{}) string {
+	var parts []string
+	for i, f := range p.fields {
+		parts = append(parts, url.QueryEscape(f.Name)+"="+url.QueryEscape(f.transform.format(partition[i])))
+	}
+	return strings.Join(parts, "/")
+}
+
+// resultType returns the Iceberg type of the transform result.
+func (t transform) resultType(source string) string {
+	switch t.name {
+	case "bucket", "year", "month", "hour":
+		return "int"
+	case "day":
+		return "date"
+	default:
+		return source
+	}
+}
+
+func (t transform) apply(source string, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch t.name {
+	case "identity":
+		return v
+	case "bucket":
+		return int32(int64(hashValue(v)&math.MaxInt32) % int64(t.width))
+	case "truncate":
+		return truncate(v, t.width)
+	}
+
+	// Time transforms, relative to 1970-01-01.
+	var ts time.Time
+	switch x := v.(type) {
+	case int32: // date
+		switch t.name {
+		case "day":
+			return x
+		case "hour":
+			return nil // not defined for dates
+		}
+		ts = time.Unix(int64(x)*24*3600, 0).UTC()
+	case int64: // timestamp
+		switch t.name {
+		case "day":
+			return int32(floorDiv(x, 24*3600*1000000))
+		case "hour":
+			return int32(floorDiv(x, 3600*1000000))
+		}
+		ts = time.Unix(floorDiv(x, 1000000), 0).UTC()
+	default:
+		return nil
+	}
+	switch t.name {
+	case "year":
+		return int32(ts.Year() - 1970)
+	case "month":
+		return int32((ts.Year()-1970)*12 + int(ts.Month()) - 1)
+	default:
+		return nil
+	}
+}
+
+// format returns the human-readable representation of a partition value, as
+// used in partition paths.
+func (t transform) format(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	switch t.name {
+	case "year":
+		return strconv.Itoa(1970 + int(v.(int32)))
+	case "month":
+		m := int64(v.(int32))
+		y := floorDiv(m, 12)
+		return fmt.Sprintf("%04d-%02d", 1970+y, m-y*12+1)
+	case "day":
+		return time.Unix(int64(v.(int32))*24*3600, 0).UTC().Format("2006-01-02")
+	case "hour":
+		return time.Unix(int64(v.(int32))*3600, 0).UTC().Format("2006-01-02-15")
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func truncate(v interface{}, width int) interface{} {
+	switch x := v.(type) {
+	case int32:
+		w := int32(width)
+		return x - (((x % w) + w) % w)
+	case int64:
+		w := int64(width)
+		return x - (((x % w) + w) % w)
+	case string:
+		if utf8.RuneCountInString(x) <= width {
+			return x
+		}
+		n := 0
+		for i := range x {
+			if n == width {
+				return x[:i]
+			}
+			n++
+		}
+		return x
+	case []byte:
+		if len(x) <= width {
+			return x
+		}
+		return x[:width]
+	default:
+		return v
+	}
+}
+
+// hashValue returns the 32-bit Murmur3 hash of the value, as specified for
+// the bucket transform: ints, longs, dates and timestamps are hashed as
+// 8-byte little-endian longs, strings as UTF-8 bytes.
+func hashValue(v interface{}) uint32 {
+	var b []byte
+	switch x := v.(type) {
+	case int32:
+		b = make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(int64(x)))
+	case int64:
+		b = make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(x))
+	case string:
+		b = []byte(x)
+	case []byte:
+		b = x
+	default:
+		b = []byte(fmt.Sprint(v))
+	}
+	return murmur3(b)
+}
+
+// murmur3 returns the x86 32-bit Murmur3 hash of the data, with seed 0.
+func murmur3(data []byte) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+
+	var h uint32
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[n*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// floorDiv returns x/y rounded towards negative infinity.
+func floorDiv(x, y int64) int64 {
+	q := x / y
+	if (x%y != 0) && ((x < 0) != (y < 0)) {
+		q--
+	}
+	return q
+}
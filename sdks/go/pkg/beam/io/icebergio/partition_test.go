@@ -0,0 +1,84 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icebergio
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestBucket tests the bucket hash against the values given by the Iceberg
+// specification.
+func TestBucket(t *testing.T) {
+	micros := time.Date(2017, 11, 16, 22, 31, 8, 0, time.UTC).UnixNano() / 1000
+	tests := []struct {
+		v   interface{}
+		exp int32
+	}{
+		{int32(34), 2017239379},
+		{int64(34), 2017239379},
+		{int32(17486), -653330422}, // date 2017-11-16
+		{micros, -2047944441},
+		{"iceberg", 1210000089},
+		{[]byte{0, 1, 2, 3}, -188683207},
+	}
+	for _, test := range tests {
+		if got := int32(hashValue(test.v)); got != test.exp {
+			t.Errorf("hash(%v) = %v, want %v", test.v, got, test.exp)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	ts := time.Date(2018, 5, 1, 13, 30, 0, 0, time.UTC)
+	micros := ts.UnixNano() / 1000
+	date := int32(ts.Unix() / (24 * 3600))
+
+	tests := []struct {
+		transform string
+		v         interface{}
+		exp       interface{}
+		path      string
+	}{
+		{"identity", "a b", "a b", "a+b"},
+		{"year", micros, int32(48), "2018"},
+		{"month", date, int32(580), "2018-05"},
+		{"day", micros, date, "2018-05-01"},
+		{"hour", micros, int32(ts.Unix() / 3600), "2018-05-01-13"},
+		{"bucket[16]", int64(34), int32(2017239379 % 16), "3"},
+		{"truncate[10]", int32(-1), int32(-10), "-10"},
+		{"truncate[3]", "iceberg", "ice", "ice"},
+		{"void", int64(1), nil, "null"},
+	}
+	for _, test := range tests {
+		tr, err := parseTransform(test.transform)
+		if err != nil {
+			t.Fatalf("parseTransform(%v) failed: %v", test.transform, err)
+		}
+		var got interface{}
+		if tr.name != "void" {
+			got = tr.apply("", test.v)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%v(%v) = %v, want %v", test.transform, test.v, got, test.exp)
+		}
+		p := &partitioner{fields: []partitionField{{PartitionField: PartitionField{Name: "p"}, transform: tr}}}
+		if path := p.path([]interface{}{got}); path != "p="+test.path {
+			t.Errorf("path of %v(%v) = %v, want p=%v", test.transform, test.v, path, test.path)
+		}
+	}
+}
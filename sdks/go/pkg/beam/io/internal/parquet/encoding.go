@@ -0,0 +1,391 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"math/bits"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/golang/snappy"
+)
+
+// decompress decompresses page data with the codec.
+func decompress(codec int64, data []byte, size int) ([]byte, error) {
+	switch codec {
+	case codecUncompressed:
+		return data, nil
+	case codecSnappy:
+		return snappy.Decode(make([]byte, size), data)
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	default:
+		return nil, errors.Errorf("unsupported compression codec %v", codec)
+	}
+}
+
+// bitWidth returns the number of bits needed to encode levels up to max.
+func bitWidth(max int) int {
+	return bits.Len(uint(max))
+}
+
+// decodeHybrid decodes n values of the given bit width, encoded with the
+// RLE/bit-packing hybrid encoding. It returns the number of bytes read.
+func decodeHybrid(data []byte, width, n int) ([]int32, int, error) {
+	ret := make([]int32, 0, n)
+	pos := 0
+	for len(ret) < n {
+		header, k := binary.Uvarint(data[pos:])
+		if k <= 0 {
+			return nil, 0, errors.New("truncated RLE data")
+		}
+		pos += k
+
+		if header&1 == 0 {
+			// RLE run of a single value, in ceil(width/8) bytes.
+			count := int(header >> 1)
+			size := (width + 7) / 8
+			if pos+size > len(data) {
+				return nil, 0, errors.New("truncated RLE data")
+			}
+			var v int32
+			for i := 0; i < size; i++ {
+				v |= int32(data[pos+i]) << uint(8*i)
+			}
+			pos += size
+			for i := 0; i < count && len(ret) < n; i++ {
+				ret = append(ret, v)
+			}
+			continue
+		}
+
+		// Bit-packed groups of 8 values, least significant bit first.
+		count := int(header>>1) * 8
+		size := int(header>>1) * width
+		if pos+size > len(data) {
+			return nil, 0, errors.New("truncated bit-packed data")
+		}
+		packed := data[pos : pos+size]
+		pos += size
+		for i := 0; i < count && len(ret) < n; i++ {
+			var v int32
+			for j := 0; j < width; j++ {
+				bit := i*width + j
+				if packed[bit/8]&(1<<uint(bit%8)) != 0 {
+					v |= 1 << uint(j)
+				}
+			}
+			ret = append(ret, v)
+		}
+	}
+	return ret, pos, nil
+}
+
+// decodeLevels decodes n levels of a V1 data page, which are prefixed by
+// their length. It returns the number of bytes read.
+func decodeLevels(data []byte, max, n int) ([]int32, int, error) {
+	if max == 0 {
+		return nil, 0, nil
+	}
+	if len(data) < 4 {
+		return nil, 0, errors.New("truncated levels")
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if 4+size > len(data) {
+		return nil, 0, errors.New("truncated levels")
+	}
+	levels, _, err := decodeHybrid(data[4:4+size], bitWidth(max), n)
+	return levels, 4 + size, err
+}
+
+// decodePlain decodes n values of the physical type with the PLAIN
+// encoding. The length of fixed length byte arrays is given by size.
+func decodePlain(data []byte, physical int32, size, n int) ([]interface{}, error) {
+	ret := make([]interface{}, n)
+	pos := 0
+	need := func(k int) error {
+		if pos+k > len(data) {
+			return errors.New("truncated PLAIN data")
+		}
+		return nil
+	}
+	for i := range ret {
+		switch physical {
+		case Boolean:
+			if i/8 >= len(data) {
+				return nil, errors.New("truncated PLAIN data")
+			}
+			ret[i] = data[i/8]&(1<<uint(i%8)) != 0
+		case Int32:
+			if err := need(4); err != nil {
+				return nil, err
+			}
+			ret[i] = int32(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		case Int64:
+			if err := need(8); err != nil {
+				return nil, err
+			}
+			ret[i] = int64(binary.LittleEndian.Uint64(data[pos:]))
+			pos += 8
+		case Int96:
+			if err := need(12); err != nil {
+				return nil, err
+			}
+			ret[i] = append([]byte(nil), data[pos:pos+12]...)
+			pos += 12
+		case Float:
+			if err := need(4); err != nil {
+				return nil, err
+			}
+			ret[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		case Double:
+			if err := need(8); err != nil {
+				return nil, err
+			}
+			ret[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[pos:]))
+			pos += 8
+		case ByteArray:
+			if err := need(4); err != nil {
+				return nil, err
+			}
+			k := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if err := need(k); err != nil {
+				return nil, err
+			}
+			ret[i] = data[pos : pos+k]
+			pos += k
+		case FixedLenByteArray:
+			if err := need(size); err != nil {
+				return nil, err
+			}
+			ret[i] = data[pos : pos+size]
+			pos += size
+		default:
+			return nil, errors.Errorf("invalid physical type %v", physical)
+		}
+	}
+	return ret, nil
+}
+
+// decodeValues decodes n non-null values of a data page with the given
+// encoding. Dictionary encoded values are looked up in dict.
+func decodeValues(data []byte, encoding int64, physical int32, size, n int, dict []interface{}) ([]interface{}, error) {
+	switch encoding {
+	case encodingPlain:
+		return decodePlain(data, physical, size, n)
+	case encodingPlainDict, encodingRLEDict:
+		if n == 0 {
+			return nil, nil
+		}
+		if len(data) == 0 {
+			return nil, errors.New("truncated dictionary indices")
+		}
+		indices, _, err := decodeHybrid(data[1:], int(data[0]), n)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]interface{}, n)
+		for i, j := range indices {
+			if int(j) >= len(dict) {
+				return nil, errors.Errorf("dictionary index %v out of range", j)
+			}
+			ret[i] = dict[j]
+		}
+		return ret, nil
+	case encodingRLE:
+		if physical != Boolean {
+			return nil, errors.Errorf("RLE encoding of physical type %v not supported", physical)
+		}
+		if len(data) < 4 {
+			return nil, errors.New("truncated RLE data")
+		}
+		values, _, err := decodeHybrid(data[4:], 1, n)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]interface{}, n)
+		for i, v := range values {
+			ret[i] = v != 0
+		}
+		return ret, nil
+	case encodingDeltaBinary:
+		if physical != Int32 && physical != Int64 {
+			return nil, errors.Errorf("DELTA_BINARY_PACKED encoding of physical type %v not supported", physical)
+		}
+		values, _, err := decodeDeltaBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) < n {
+			return nil, errors.New("truncated DELTA_BINARY_PACKED data")
+		}
+		ret := make([]interface{}, n)
+		for i := range ret {
+			if physical == Int32 {
+				ret[i] = int32(values[i])
+			} else {
+				ret[i] = values[i]
+			}
+		}
+		return ret, nil
+	case encodingDeltaLength:
+		values, _, err := decodeDeltaLength(data)
+		if err != nil {
+			return nil, err
+		}
+		return toValues(values, n)
+	case encodingDeltaByteArray:
+		prefixes, k, err := decodeDeltaBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		suffixes, _, err := decodeDeltaLength(data[k:])
+		if err != nil {
+			return nil, err
+		}
+		if len(suffixes) != len(prefixes) {
+			return nil, errors.New("invalid DELTA_BYTE_ARRAY data")
+		}
+		var prev []byte
+		for i, suffix := range suffixes {
+			if prefixes[i] < 0 || int(prefixes[i]) > len(prev) {
+				return nil, errors.New("invalid DELTA_BYTE_ARRAY prefix length")
+			}
+			v := make([]byte, 0, int(prefixes[i])+len(suffix))
+			v = append(append(v, prev[:prefixes[i]]...), suffix...)
+			suffixes[i], prev = v, v
+		}
+		return toValues(suffixes, n)
+	default:
+		return nil, errors.Errorf("unsupported encoding %v", encoding)
+	}
+}
+
+func toValues(values [][]byte, n int) ([]interface{}, error) {
+	if len(values) < n {
+		return nil, errors.New("truncated byte array data")
+	}
+	ret := make([]interface{}, n)
+	for i := range ret {
+		ret[i] = values[i]
+	}
+	return ret, nil
+}
+
+// decodeDeltaBinary decodes integers with the DELTA_BINARY_PACKED
+// encoding. It returns the number of bytes read.
+func decodeDeltaBinary(data []byte) ([]int64, int, error) {
+	pos := 0
+	uvarint := func() (uint64, error) {
+		v, k := binary.Uvarint(data[pos:])
+		if k <= 0 {
+			return 0, errors.New("truncated DELTA_BINARY_PACKED data")
+		}
+		pos += k
+		return v, nil
+	}
+	blockSize, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	miniblocks, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	first, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	if miniblocks == 0 || blockSize%miniblocks != 0 || (blockSize/miniblocks)%8 != 0 {
+		return nil, 0, errors.New("invalid DELTA_BINARY_PACKED block size")
+	}
+	if total == 0 {
+		return nil, pos, nil
+	}
+	per := int(blockSize / miniblocks)
+
+	ret := make([]int64, 0, total)
+	ret = append(ret, unzigzag(first))
+	for uint64(len(ret)) < total {
+		v, err := uvarint()
+		if err != nil {
+			return nil, 0, err
+		}
+		min := unzigzag(v)
+		if pos+int(miniblocks) > len(data) {
+			return nil, 0, errors.New("truncated DELTA_BINARY_PACKED data")
+		}
+		widths := data[pos : pos+int(miniblocks)]
+		pos += int(miniblocks)
+
+		for _, width := range widths {
+			if uint64(len(ret)) >= total {
+				break // the remaining miniblocks are not stored
+			}
+			size := per * int(width) / 8
+			if pos+size > len(data) {
+				return nil, 0, errors.New("truncated DELTA_BINARY_PACKED data")
+			}
+			packed := data[pos : pos+size]
+			pos += size
+			for i := 0; i < per && uint64(len(ret)) < total; i++ {
+				var delta uint64
+				for j := 0; j < int(width); j++ {
+					bit := i*int(width) + j
+					if packed[bit/8]&(1<<uint(bit%8)) != 0 {
+						delta |= 1 << uint(j)
+					}
+				}
+				ret = append(ret, ret[len(ret)-1]+min+int64(delta))
+			}
+		}
+	}
+	return ret, pos, nil
+}
+
+// decodeDeltaLength decodes byte arrays with the DELTA_LENGTH_BYTE_ARRAY
+// encoding. It returns the number of bytes read.
+func decodeDeltaLength(data []byte) ([][]byte, int, error) {
+	lengths, pos, err := decodeDeltaBinary(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	ret := make([][]byte, len(lengths))
+	for i, k := range lengths {
+		if k < 0 || pos+int(k) > len(data) {
+			return nil, 0, errors.New("truncated DELTA_LENGTH_BYTE_ARRAY data")
+		}
+		ret[i] = data[pos : pos+int(k)]
+		pos += int(k)
+	}
+	return ret, pos, nil
+}
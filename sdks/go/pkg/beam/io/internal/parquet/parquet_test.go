@@ -0,0 +1,190 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoundtrip(t *testing.T) {
+	w := NewWriter([]Column{
+		{Name: "id", Type: Int64, ConvertedType: None, Required: true},
+		{Name: "name", Type: ByteArray, ConvertedType: UTF8},
+		{Name: "ok", Type: Boolean, ConvertedType: None},
+		{Name: "day", Type: Int32, ConvertedType: Date},
+		{Name: "ts", Type: Int64, ConvertedType: TimestampMicros},
+		{Name: "score", Type: Double, ConvertedType: None},
+	})
+	rows := [][]interface{}{
+		{int64(1), "a", true, int32(1), int64(1500000), 0.5},
+		{int64(2), nil, false, nil, nil, nil},
+		{int64(3), "c", nil, int32(-1), int64(-1), 2.0},
+	}
+	for _, row := range rows {
+		if err := w.Add(row); err != nil {
+			t.Fatalf("Add(%v) failed: %v", row, err)
+		}
+	}
+	if err := w.Add([]interface{}{nil, nil, nil, nil, nil, nil}); err == nil {
+		t.Errorf("Add with null required column succeeded")
+	}
+
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if f.NumRows != 3 {
+		t.Errorf("NumRows = %v, want 3", f.NumRows)
+	}
+
+	got, err := f.Rows()
+	if err != nil {
+		t.Fatalf("Rows failed: %v", err)
+	}
+	exp := []map[string]interface{}{
+		{"id": int64(1), "name": "a", "ok": true, "day": time.Unix(86400, 0).UTC(), "ts": time.Unix(1, 500000000).UTC(), "score": 0.5},
+		{"id": int64(2), "name": nil, "ok": false, "day": nil, "ts": nil, "score": nil},
+		{"id": int64(3), "name": "c", "ok": nil, "day": time.Unix(-86400, 0).UTC(), "ts": time.Unix(0, -1000).UTC(), "score": 2.0},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Rows() = %v, want %v", got, exp)
+	}
+
+	got, err = f.Rows("name")
+	if err != nil {
+		t.Fatalf("Rows(name) failed: %v", err)
+	}
+	if len(got) != 3 || len(got[0]) != 1 || got[2]["name"] != "c" {
+		t.Errorf("Rows(name) = %v, want only the name column", got)
+	}
+	if _, err := f.Rows("missing"); err == nil {
+		t.Errorf("Rows(missing) succeeded")
+	}
+}
+
+func TestDecodeHybrid(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		width int
+		n     int
+		exp   []int32
+	}{
+		// RLE run of 3 ones.
+		{[]byte{0x06, 0x01}, 1, 3, []int32{1, 1, 1}},
+		// Bit-packed group of 0..7 with width 3, from the Parquet spec.
+		{[]byte{0x03, 0x88, 0xc6, 0xfa}, 3, 8, []int32{0, 1, 2, 3, 4, 5, 6, 7}},
+		// RLE run followed by a bit-packed group, truncated to n.
+		{[]byte{0x04, 0x05, 0x03, 0x88, 0xc6, 0xfa}, 3, 4, []int32{5, 5, 0, 1}},
+	}
+	for _, test := range tests {
+		got, _, err := decodeHybrid(test.data, test.width, test.n)
+		if err != nil {
+			t.Errorf("decodeHybrid(%v) failed: %v", test.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("decodeHybrid(%v) = %v, want %v", test.data, got, test.exp)
+		}
+	}
+}
+
+// TestAssemble tests record assembly with the example of the Dremel paper.
+func TestAssemble(t *testing.T) {
+	leaf := func(name string, rep int32, typ int32, converted int32) *Node {
+		return &Node{Name: name, Repetition: rep, Type: typ, ConvertedType: converted}
+	}
+	group := func(name string, rep int32, children ...*Node) *Node {
+		return &Node{Name: name, Repetition: rep, ConvertedType: None, Children: children}
+	}
+	docID := leaf("DocId", repetitionRequired, Int64, None)
+	backward := leaf("Backward", repetitionRepeated, Int64, None)
+	forward := leaf("Forward", repetitionRepeated, Int64, None)
+	links := group("Links", repetitionOptional, backward, forward)
+	code := leaf("Code", repetitionRequired, ByteArray, UTF8)
+	country := leaf("Country", repetitionOptional, ByteArray, UTF8)
+	language := group("Language", repetitionRepeated, code, country)
+	url := leaf("Url", repetitionOptional, ByteArray, UTF8)
+	name := group("Name", repetitionRepeated, language, url)
+	root := group("Document", repetitionRequired, docID, links, name)
+	for _, c := range root.Children {
+		setLevels(c, 0, 0)
+	}
+
+	b := func(s string) interface{} { return []byte(s) }
+	columns := []struct {
+		path []*Node
+		data columnData
+	}{
+		{[]*Node{docID}, columnData{n: 2, values: []interface{}{int64(10), int64(20)}}},
+		{[]*Node{links, backward}, columnData{n: 3,
+			def: []int32{1, 2, 2}, rep: []int32{0, 0, 1},
+			values: []interface{}{int64(10), int64(30)}}},
+		{[]*Node{links, forward}, columnData{n: 4,
+			def: []int32{2, 2, 2, 2}, rep: []int32{0, 1, 1, 0},
+			values: []interface{}{int64(20), int64(40), int64(60), int64(80)}}},
+		{[]*Node{name, language, code}, columnData{n: 5,
+			def: []int32{2, 2, 1, 2, 1}, rep: []int32{0, 2, 1, 1, 0},
+			values: []interface{}{b("en-us"), b("en"), b("en-gb")}}},
+		{[]*Node{name, language, country}, columnData{n: 5,
+			def: []int32{3, 2, 1, 3, 1}, rep: []int32{0, 2, 1, 1, 0},
+			values: []interface{}{b("us"), b("gb")}}},
+		{[]*Node{name, url}, columnData{n: 4,
+			def: []int32{2, 2, 1, 1}, rep: []int32{0, 1, 1, 0},
+			values: []interface{}{b("http://A"), b("http://B")}}},
+	}
+
+	rows := []map[string]interface{}{{}, {}}
+	for _, c := range columns {
+		data := c.data
+		if err := assemble(rows, c.path, &data); err != nil {
+			t.Fatalf("assemble(%v) failed: %v", c.path[len(c.path)-1].Name, err)
+		}
+	}
+	for _, row := range rows {
+		for _, c := range root.Children {
+			row[c.Name] = convert(c, row[c.Name])
+		}
+	}
+
+	type m = map[string]interface{}
+	type l = []interface{}
+	exp := []map[string]interface{}{
+		{
+			"DocId": int64(10),
+			"Links": m{"Backward": l{}, "Forward": l{int64(20), int64(40), int64(60)}},
+			"Name": l{
+				m{"Language": l{m{"Code": "en-us", "Country": "us"}, m{"Code": "en", "Country": nil}}, "Url": "http://A"},
+				m{"Language": l{}, "Url": "http://B"},
+				m{"Language": l{m{"Code": "en-gb", "Country": "gb"}}, "Url": nil},
+			},
+		},
+		{
+			"DocId": int64(20),
+			"Links": m{"Backward": l{int64(10), int64(30)}, "Forward": l{int64(80)}},
+			"Name":  l{m{"Language": l{}, "Url": nil}},
+		},
+	}
+	if !reflect.DeepEqual(rows, exp) {
+		t.Errorf("assemble() = %v, want %v", rows, exp)
+	}
+}
@@ -0,0 +1,636 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// Node is a node of the schema of a Parquet file. Leaf nodes are columns
+// of a physical type, and other nodes are groups.
+type Node struct {
+	Name string
+	// Repetition is whether the node is required, optional or repeated.
+	Repetition int32
+	// Type is the physical type of a leaf node.
+	Type int32
+	// ConvertedType is the converted type of the node, or None. Logical
+	// types are mapped to their converted type, if any.
+	ConvertedType int32
+	// FieldID is the field id of the node, or zero if none.
+	FieldID  int
+	Children []*Node
+
+	// length is the length of a fixed length byte array.
+	length int
+	// unit is the unit of a timestamp, for logical TIMESTAMP types.
+	unit time.Duration
+	// maxDef and maxRep are the maximum definition and repetition levels
+	// of the node.
+	maxDef, maxRep int
+}
+
+// IsLeaf returns whether the node is a column.
+func (n *Node) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// IsRepeated returns whether the node is repeated.
+func (n *Node) IsRepeated() bool {
+	return n.Repetition == repetitionRepeated
+}
+
+// File is a Parquet file, read fully into memory.
+type File struct {
+	// Schema is the root node of the schema. Its children are the
+	// top-level columns.
+	Schema *Node
+	// NumRows is the number of rows of the file.
+	NumRows int64
+
+	data      []byte
+	rowGroups []thriftStructValue
+}
+
+// Open decodes the metadata of the Parquet file.
+func Open(data []byte) (*File, error) {
+	if len(data) < minimumFileSize || string(data[:len(magic)]) != magic || string(data[len(data)-len(magic):]) != magic {
+		return nil, errors.New("not a Parquet file")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-len(magic)-footerLengthSize:]))
+	end := len(data) - len(magic) - footerLengthSize
+	if size > end-len(magic) {
+		return nil, errors.New("invalid Parquet footer length")
+	}
+	r := &thriftReader{buf: data[end-size : end]}
+	meta, err := r.readStruct()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Parquet metadata")
+	}
+
+	elements := meta.list(2)
+	if len(elements) == 0 {
+		return nil, errors.New("Parquet file has no schema")
+	}
+	schema, rest, err := decodeSchema(elements)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("invalid Parquet schema")
+	}
+	// The root is not a column, whatever its repetition.
+	for _, c := range schema.Children {
+		setLevels(c, 0, 0)
+	}
+
+	ret := &File{Schema: schema, NumRows: meta.int(3), data: data}
+	for _, rg := range meta.list(4) {
+		ret.rowGroups = append(ret.rowGroups, rg.(thriftStructValue))
+	}
+	return ret, nil
+}
+
+// decodeSchema decodes the schema tree from its depth-first list of
+// elements. It returns the remaining elements.
+func decodeSchema(elements []interface{}) (*Node, []interface{}, error) {
+	e, ok := elements[0].(thriftStructValue)
+	if !ok {
+		return nil, nil, errors.New("invalid Parquet schema element")
+	}
+	n := &Node{
+		Name:          e.string(4),
+		Repetition:    int32(e.int(3)),
+		Type:          int32(e.int(1)),
+		ConvertedType: None,
+		FieldID:       int(e.int(9)),
+		length:        int(e.int(2)),
+	}
+	if e.has(6) {
+		n.ConvertedType = int32(e.int(6))
+	}
+	if logical := e.strct(10); logical != nil {
+		decodeLogicalType(n, logical)
+	}
+
+	rest := elements[1:]
+	for i := 0; i < int(e.int(5)); i++ {
+		if len(rest) == 0 {
+			return nil, nil, errors.Errorf("Parquet schema group %v has missing children", n.Name)
+		}
+		var child *Node
+		var err error
+		if child, rest, err = decodeSchema(rest); err != nil {
+			return nil, nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return n, rest, nil
+}
+
+// decodeLogicalType maps the logical type of the node to its converted
+// type. Timestamps in nanoseconds have no converted type, so their unit is
+// kept separately.
+func decodeLogicalType(n *Node, logical thriftStructValue) {
+	switch {
+	case logical.has(1):
+		n.ConvertedType = UTF8
+	case logical.has(2):
+		n.ConvertedType = Map
+	case logical.has(3):
+		n.ConvertedType = List
+	case logical.has(4):
+		n.ConvertedType = Enum
+	case logical.has(6):
+		n.ConvertedType = Date
+	case logical.has(8):
+		unit := logical.strct(8).strct(2)
+		switch {
+		case unit.has(1):
+			n.ConvertedType, n.unit = TimestampMillis, time.Millisecond
+		case unit.has(2):
+			n.ConvertedType, n.unit = TimestampMicros, time.Microsecond
+		case unit.has(3):
+			n.unit = time.Nanosecond
+		}
+	case logical.has(12):
+		n.ConvertedType = JSON
+	}
+}
+
+func setLevels(n *Node, def, rep int) {
+	switch n.Repetition {
+	case repetitionOptional:
+		def++
+	case repetitionRepeated:
+		def++
+		rep++
+	}
+	n.maxDef, n.maxRep = def, rep
+	for _, c := range n.Children {
+		setLevels(c, def, rep)
+	}
+}
+
+// Column returns the top-level column of the given name, if any.
+func (f *File) Column(name string) (*Node, bool) {
+	for _, c := range f.Schema.Children {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Rows decodes the given top-level columns of all rows, or all columns if
+// none are given. Rows are maps from column name to value, where null
+// values are nil. Values are decoded as follows:
+//
+//	BOOLEAN, INT32, INT64, FLOAT, DOUBLE  bool, int32, int64, float32, float64
+//	BYTE_ARRAY, FIXED_LEN_BYTE_ARRAY      []byte, or string if UTF8, ENUM or JSON
+//	INT96, TIMESTAMP, DATE                time.Time in UTC
+//	groups                                map[string]interface{}
+//	repeated nodes                        []interface{}
+//
+// Groups annotated as LIST are decoded as []interface{} of the elements,
+// and groups annotated as MAP as map[interface{}]interface{}.
+func (f *File) Rows(columns ...string) ([]map[string]interface{}, error) {
+	var projected []*Node
+	if len(columns) == 0 {
+		projected = f.Schema.Children
+	}
+	for _, name := range columns {
+		c, ok := f.Column(name)
+		if !ok {
+			return nil, errors.Errorf("no column %v in Parquet file", name)
+		}
+		projected = append(projected, c)
+	}
+
+	var ret []map[string]interface{}
+	for _, rg := range f.rowGroups {
+		rows := make([]map[string]interface{}, rg.int(3))
+		for i := range rows {
+			rows[i] = make(map[string]interface{})
+		}
+
+		chunks := rg.list(1)
+		leaf := 0
+		for _, c := range f.Schema.Children {
+			leaves := countLeaves(c)
+			if !contains(projected, c) {
+				leaf += leaves
+				continue
+			}
+			for _, path := range leafPaths(c, nil) {
+				if leaf >= len(chunks) {
+					return nil, errors.New("Parquet row group has missing column chunks")
+				}
+				chunk, err := f.readChunk(chunks[leaf].(thriftStructValue), path[len(path)-1])
+				if err != nil {
+					return nil, errors.WithContextf(err, "reading column %v", c.Name)
+				}
+				if err := assemble(rows, path, chunk); err != nil {
+					return nil, errors.WithContextf(err, "reading column %v", c.Name)
+				}
+				leaf++
+			}
+		}
+
+		for _, row := range rows {
+			for _, c := range projected {
+				row[c.Name] = convert(c, row[c.Name])
+			}
+		}
+		ret = append(ret, rows...)
+	}
+	return ret, nil
+}
+
+func contains(nodes []*Node, n *Node) bool {
+	for _, m := range nodes {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+func countLeaves(n *Node) int {
+	if n.IsLeaf() {
+		return 1
+	}
+	ret := 0
+	for _, c := range n.Children {
+		ret += countLeaves(c)
+	}
+	return ret
+}
+
+// leafPaths returns the paths from the node to each of its leaves, in
+// schema order.
+func leafPaths(n *Node, prefix []*Node) [][]*Node {
+	path := append(append([]*Node(nil), prefix...), n)
+	if n.IsLeaf() {
+		return [][]*Node{path}
+	}
+	var ret [][]*Node
+	for _, c := range n.Children {
+		ret = append(ret, leafPaths(c, path)...)
+	}
+	return ret
+}
+
+// columnData holds the decoded levels and non-null values of a column
+// chunk. Levels are nil if the maximum level is zero.
+type columnData struct {
+	n        int
+	def, rep []int32
+	values   []interface{}
+}
+
+// readChunk decodes the pages of a column chunk.
+func (f *File) readChunk(chunk thriftStructValue, leaf *Node) (*columnData, error) {
+	meta := chunk.strct(3)
+	if chunk.string(1) != "" {
+		return nil, errors.New("column chunks in external files are not supported")
+	}
+	if meta == nil {
+		return nil, errors.New("column chunk has no metadata")
+	}
+	codec := meta.int(4)
+	total := int(meta.int(5))
+	offset := meta.int(9)
+	if dict := meta.int(11); meta.has(11) && dict > 0 && dict < offset {
+		offset = dict
+	}
+
+	ret := &columnData{}
+	var dict []interface{}
+	for ret.n < total {
+		if offset < 0 || offset >= int64(len(f.data)) {
+			return nil, errors.New("invalid page offset")
+		}
+		r := &thriftReader{buf: f.data, pos: int(offset)}
+		header, err := r.readStruct()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid page header")
+		}
+		size := int(header.int(3))
+		if r.pos+size > len(f.data) {
+			return nil, errors.New("truncated page")
+		}
+		page := f.data[r.pos : r.pos+size]
+		offset = int64(r.pos + size)
+		uncompressed := int(header.int(2))
+
+		switch header.int(1) {
+		case pageDictionary:
+			h := header.strct(7)
+			data, err := decompress(codec, page, uncompressed)
+			if err != nil {
+				return nil, err
+			}
+			if dict, err = decodePlain(data, leaf.Type, leaf.length, int(h.int(1))); err != nil {
+				return nil, err
+			}
+
+		case pageData:
+			h := header.strct(5)
+			n := int(h.int(1))
+			data, err := decompress(codec, page, uncompressed)
+			if err != nil {
+				return nil, err
+			}
+			rep, k, err := decodeLevels(data, leaf.maxRep, n)
+			if err != nil {
+				return nil, err
+			}
+			data = data[k:]
+			def, k, err := decodeLevels(data, leaf.maxDef, n)
+			if err != nil {
+				return nil, err
+			}
+			data = data[k:]
+			if err := ret.add(n, def, rep, leaf, data, h.int(2), dict); err != nil {
+				return nil, err
+			}
+
+		case pageDataV2:
+			h := header.strct(8)
+			n := int(h.int(1))
+			repSize, defSize := int(h.int(6)), int(h.int(5))
+			if repSize+defSize > len(page) {
+				return nil, errors.New("truncated page levels")
+			}
+			var rep, def []int32
+			if leaf.maxRep > 0 {
+				if rep, _, err = decodeHybrid(page[:repSize], bitWidth(leaf.maxRep), n); err != nil {
+					return nil, err
+				}
+			}
+			if leaf.maxDef > 0 {
+				if def, _, err = decodeHybrid(page[repSize:repSize+defSize], bitWidth(leaf.maxDef), n); err != nil {
+					return nil, err
+				}
+			}
+			data := page[repSize+defSize:]
+			if h.bool(7, true) {
+				if data, err = decompress(codec, data, uncompressed-repSize-defSize); err != nil {
+					return nil, err
+				}
+			}
+			if err := ret.add(n, def, rep, leaf, data, h.int(4), dict); err != nil {
+				return nil, err
+			}
+
+		default:
+			// Index pages are skipped.
+		}
+	}
+	return ret, nil
+}
+
+// add adds the decoded levels and values of a data page of n values.
+func (c *columnData) add(n int, def, rep []int32, leaf *Node, data []byte, encoding int64, dict []interface{}) error {
+	defined := n
+	if def != nil {
+		defined = 0
+		for _, d := range def {
+			if int(d) == leaf.maxDef {
+				defined++
+			}
+		}
+	}
+	values, err := decodeValues(data, encoding, leaf.Type, leaf.length, defined, dict)
+	if err != nil {
+		return err
+	}
+	c.n += n
+	c.def = append(c.def, def...)
+	c.rep = append(c.rep, rep...)
+	c.values = append(c.values, values...)
+	return nil
+}
+
+// assemble adds the values of the leaf column at the end of the path to
+// the rows, following the record shredding of the Dremel paper. Groups are
+// assembled as maps and repeated nodes as slices.
+func assemble(rows []map[string]interface{}, path []*Node, data *columnData) error {
+	leaf := path[len(path)-1]
+	// indices holds the current element index of each repeated node on
+	// the path.
+	indices := make([]int, len(path))
+
+	row := -1
+	next := 0
+	for i := 0; i < data.n; i++ {
+		d, r := leaf.maxDef, 0
+		if data.def != nil {
+			d = int(data.def[i])
+		}
+		if data.rep != nil {
+			r = int(data.rep[i])
+		}
+
+		if r == 0 {
+			row++
+			if row >= len(rows) {
+				return errors.New("column has more rows than its row group")
+			}
+			for j := range indices {
+				indices[j] = 0
+			}
+		} else {
+			for j, n := range path {
+				if !n.IsRepeated() {
+					continue
+				}
+				switch {
+				case n.maxRep == r:
+					indices[j]++
+				case n.maxRep > r:
+					indices[j] = 0
+				}
+			}
+		}
+
+		var value interface{}
+		if d == leaf.maxDef {
+			if next >= len(data.values) {
+				return errors.New("column has fewer values than levels")
+			}
+			value = data.values[next]
+			next++
+		}
+
+		parent := rows[row]
+		for j, n := range path {
+			last := j == len(path)-1
+			if d < n.maxDef {
+				// The node is undefined, but its parent is defined.
+				if _, ok := parent[n.Name]; !ok {
+					if n.IsRepeated() {
+						parent[n.Name] = []interface{}{}
+					} else {
+						parent[n.Name] = nil
+					}
+				}
+				break
+			}
+
+			if n.IsRepeated() {
+				list, _ := parent[n.Name].([]interface{})
+				for len(list) <= indices[j] {
+					if last {
+						list = append(list, nil)
+					} else {
+						list = append(list, make(map[string]interface{}))
+					}
+				}
+				parent[n.Name] = list
+				if last {
+					list[indices[j]] = value
+				} else {
+					parent = list[indices[j]].(map[string]interface{})
+				}
+				continue
+			}
+
+			if last {
+				parent[n.Name] = value
+				break
+			}
+			child, ok := parent[n.Name].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[n.Name] = child
+			}
+			parent = child
+		}
+	}
+	return nil
+}
+
+// julianEpoch is the Julian day of the Unix epoch.
+const julianEpoch = 2440588
+
+// convert converts an assembled value of the node to its logical type.
+func convert(n *Node, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if n.IsRepeated() {
+		list := v.([]interface{})
+		elem := *n
+		elem.Repetition = repetitionRequired
+		for i, e := range list {
+			list[i] = convert(&elem, e)
+		}
+		return list
+	}
+	if n.IsLeaf() {
+		return convertLeaf(n, v)
+	}
+
+	group := v.(map[string]interface{})
+	switch n.ConvertedType {
+	case List:
+		if len(n.Children) == 1 && n.Children[0].IsRepeated() {
+			return convertList(n.Children[0], group[n.Children[0].Name])
+		}
+	case Map, MapKeyValue:
+		if len(n.Children) == 1 && n.Children[0].IsRepeated() && len(n.Children[0].Children) == 2 {
+			return convertMap(n.Children[0], group[n.Children[0].Name])
+		}
+	}
+	for _, c := range n.Children {
+		group[c.Name] = convert(c, group[c.Name])
+	}
+	return group
+}
+
+// convertList converts the repeated node of a LIST group. It either is the
+// element itself, or a group that wraps a single element node.
+func convertList(repeated *Node, v interface{}) interface{} {
+	list, _ := v.([]interface{})
+	if repeated.IsLeaf() || len(repeated.Children) != 1 {
+		return convert(repeated, list)
+	}
+	elem := repeated.Children[0]
+	ret := make([]interface{}, len(list))
+	for i, e := range list {
+		ret[i] = convert(elem, e.(map[string]interface{})[elem.Name])
+	}
+	return ret
+}
+
+// convertMap converts the repeated key-value group of a MAP group.
+func convertMap(repeated *Node, v interface{}) interface{} {
+	list, _ := v.([]interface{})
+	key, value := repeated.Children[0], repeated.Children[1]
+	ret := make(map[interface{}]interface{}, len(list))
+	for _, e := range list {
+		kv := e.(map[string]interface{})
+		k := convert(key, kv[key.Name])
+		if b, ok := k.([]byte); ok {
+			k = string(b) // []byte is not a valid map key
+		}
+		ret[k] = convert(value, kv[value.Name])
+	}
+	return ret
+}
+
+func convertLeaf(n *Node, v interface{}) interface{} {
+	switch n.Type {
+	case Int96:
+		b := v.([]byte)
+		nanos := int64(binary.LittleEndian.Uint64(b))
+		day := int64(binary.LittleEndian.Uint32(b[8:]))
+		return time.Unix((day-julianEpoch)*86400, nanos).UTC()
+	case Int32:
+		if n.ConvertedType == Date {
+			return time.Unix(int64(v.(int32))*86400, 0).UTC()
+		}
+	case Int64:
+		unit := n.unit
+		switch n.ConvertedType {
+		case TimestampMillis:
+			unit = time.Millisecond
+		case TimestampMicros:
+			unit = time.Microsecond
+		}
+		if unit != 0 {
+			t := v.(int64)
+			per := int64(time.Second / unit)
+			sec, rem := t/per, t%per
+			if rem < 0 {
+				sec, rem = sec-1, rem+per
+			}
+			return time.Unix(sec, rem*int64(unit)).UTC()
+		}
+	case ByteArray, FixedLenByteArray:
+		switch n.ConvertedType {
+		case UTF8, Enum, JSON:
+			return string(v.([]byte))
+		}
+		return append([]byte(nil), v.([]byte)...)
+	}
+	return v
+}
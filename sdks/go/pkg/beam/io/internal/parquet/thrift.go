@@ -0,0 +1,305 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// Thrift compact protocol types.
+const (
+	thriftStop      = 0
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+)
+
+// thriftWriter writes structs with the Thrift compact protocol, which
+// Parquet uses for its metadata.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// last holds the id of the last field written of each open struct.
+	last []int16
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buf.WriteByte(thriftStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		writeUvarint(&w.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) fieldI32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	writeUvarint(&w.buf, zigzag(int64(v)))
+}
+
+func (w *thriftWriter) fieldI64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	writeUvarint(&w.buf, zigzag(v))
+}
+
+func (w *thriftWriter) fieldString(id int16, s string) {
+	w.fieldHeader(id, thriftBinary)
+	w.listString(s)
+}
+
+func (w *thriftWriter) fieldStructBegin(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.beginStruct()
+}
+
+func (w *thriftWriter) fieldListBegin(id int16, elem byte, n int) {
+	w.fieldHeader(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		w.buf.WriteByte(0xf0 | elem)
+		writeUvarint(&w.buf, uint64(n))
+	}
+}
+
+func (w *thriftWriter) listI32(v int32) {
+	writeUvarint(&w.buf, zigzag(int64(v)))
+}
+
+func (w *thriftWriter) listString(s string) {
+	writeUvarint(&w.buf, uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+// raw appends an encoded struct, such as a list element.
+func (w *thriftWriter) raw(b []byte) {
+	w.buf.Write(b)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	buf.Write(b[:n])
+}
+
+// thriftStructValue is a decoded Thrift struct, by field id. Integers are
+// decoded as int64, binaries as []byte, lists as []interface{} and structs
+// as thriftStructValue.
+type thriftStructValue map[int16]interface{}
+
+func (s thriftStructValue) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s thriftStructValue) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s thriftStructValue) bool(id int16, def bool) bool {
+	if v, ok := s[id].(bool); ok {
+		return v
+	}
+	return def
+}
+
+func (s thriftStructValue) string(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+func (s thriftStructValue) strct(id int16) thriftStructValue {
+	v, _ := s[id].(thriftStructValue)
+	return v
+}
+
+func (s thriftStructValue) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+// thriftReader decodes Thrift compact protocol values generically.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+var errThriftEOF = errors.New("truncated Thrift data")
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftEOF
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftEOF
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) readStruct() (thriftStructValue, error) {
+	ret := make(thriftStructValue)
+	var last int16
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if b == thriftStop {
+			return ret, nil
+		}
+		typ := b & 0x0f
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(unzigzag(v))
+		}
+		last = id
+
+		var v interface{}
+		switch typ {
+		case thriftBoolTrue:
+			v = true
+		case thriftBoolFalse:
+			v = false
+		default:
+			if v, err = r.readValue(typ); err != nil {
+				return nil, err
+			}
+		}
+		ret[id] = v
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case thriftBoolTrue, thriftBoolFalse:
+		// Booleans in lists are encoded as a byte.
+		b, err := r.byte()
+		return b == thriftBoolTrue, err
+	case thriftByte:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		v, err := r.uvarint()
+		return unzigzag(v), err
+	case thriftDouble:
+		if r.pos+8 > len(r.buf) {
+			return nil, errThriftEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.buf[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if r.pos+int(n) > len(r.buf) {
+			return nil, errThriftEOF
+		}
+		v := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return v, nil
+	case thriftList, thriftSet:
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := int(b >> 4)
+		if n == 15 {
+			size, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			n = int(size)
+		}
+		ret := make([]interface{}, n)
+		for i := range ret {
+			if ret[i], err = r.readValue(b & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	case thriftMap:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		kv, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < int(n); i++ {
+			if _, err := r.readValue(kv >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(kv & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil // maps are not used by Parquet
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, errors.Errorf("invalid Thrift type %v", typ)
+	}
+}
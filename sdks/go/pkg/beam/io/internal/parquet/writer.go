@@ -0,0 +1,336 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet contains a minimal Parquet reader and writer, as used by
+// the table format connectors.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// Physical types.
+const (
+	Boolean           = 0
+	Int32             = 1
+	Int64             = 2
+	Int96             = 3
+	Float             = 4
+	Double            = 5
+	ByteArray         = 6
+	FixedLenByteArray = 7
+)
+
+// Converted types, which annotate physical types.
+const (
+	None            = -1
+	UTF8            = 0
+	Map             = 1
+	MapKeyValue     = 2
+	List            = 3
+	Enum            = 4
+	Decimal         = 5
+	Date            = 6
+	TimestampMillis = 9
+	TimestampMicros = 10
+	JSON            = 19
+)
+
+// Encodings.
+const (
+	encodingPlain          = 0
+	encodingPlainDict      = 2
+	encodingRLE            = 3
+	encodingBitPacked      = 4
+	encodingDeltaBinary    = 5
+	encodingDeltaLength    = 6
+	encodingDeltaByteArray = 7
+	encodingRLEDict        = 8
+	repetitionRequired     = 0
+	repetitionOptional     = 1
+	repetitionRepeated     = 2
+	pageData               = 0
+	pageDictionary         = 2
+	pageDataV2             = 3
+	codecUncompressed      = 0
+	codecSnappy            = 1
+	codecGzip              = 2
+	defaultCreatedBy       = "Apache Beam Go SDK"
+	maxPageHeaderSize      = 1 << 20
+	magic                  = "PAR1"
+	footerLengthSize       = 4
+	minimumFileSize        = 2*len(magic) + footerLengthSize
+)
+
+// Column is a top-level column written by a Writer.
+type Column struct {
+	Name string
+	// FieldID is the field id of the column, such as the Iceberg field id,
+	// or zero if none.
+	FieldID int
+	// Type is the physical type of the column.
+	Type int32
+	// ConvertedType is the converted type of the column, or None.
+	ConvertedType int32
+	// Required indicates that the column has no null values.
+	Required bool
+}
+
+// Writer buffers rows of a flat schema and writes them as a Parquet file.
+// Files have a single row group with one uncompressed, PLAIN encoded data
+// page per column.
+type Writer struct {
+	columns []Column
+	// values holds the non-null values of each column.
+	values [][]interface{}
+	// defined holds whether each value of each column is non-null.
+	defined [][]bool
+	rows    int64
+	size    int64
+}
+
+// NewWriter returns a writer of the columns.
+func NewWriter(columns []Column) *Writer {
+	return &Writer{
+		columns: columns,
+		values:  make([][]interface{}, len(columns)),
+		defined: make([][]bool, len(columns)),
+	}
+}
+
+// Rows returns the number of rows added.
+func (w *Writer) Rows() int64 {
+	return w.rows
+}
+
+// Size returns an estimate of the size of the file of the rows added so
+// far, without the metadata.
+func (w *Writer) Size() int64 {
+	return w.size
+}
+
+// Add adds a row, given as the values of the columns, where nil is null.
+// Values are bool, int32, int64, float32, float64, string or []byte, as
+// given by the physical type.
+func (w *Writer) Add(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return errors.Errorf("row has %v values, want %v", len(row), len(w.columns))
+	}
+	for i, c := range w.columns {
+		if c.Required && row[i] == nil {
+			return errors.Errorf("required column %v is null", c.Name)
+		}
+	}
+	for i, v := range row {
+		w.defined[i] = append(w.defined[i], v != nil)
+		if v != nil {
+			w.values[i] = append(w.values[i], v)
+			w.size += plainSize(v)
+		}
+	}
+	w.rows++
+	return nil
+}
+
+// WriteTo writes the Parquet file. It returns the size of the file.
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+
+	meta := &thriftWriter{}
+	meta.beginStruct()
+	meta.fieldI32(1, 1) // version
+	meta.fieldListBegin(2, thriftStruct, len(w.columns)+1)
+	meta.beginStruct() // root schema element
+	meta.fieldString(4, "schema")
+	meta.fieldI32(5, int32(len(w.columns)))
+	meta.endStruct()
+	for _, c := range w.columns {
+		meta.beginStruct()
+		meta.fieldI32(1, c.Type)
+		if c.Required {
+			meta.fieldI32(3, repetitionRequired)
+		} else {
+			meta.fieldI32(3, repetitionOptional)
+		}
+		meta.fieldString(4, c.Name)
+		if c.ConvertedType != None {
+			meta.fieldI32(6, c.ConvertedType)
+		}
+		if c.FieldID != 0 {
+			meta.fieldI32(9, int32(c.FieldID))
+		}
+		meta.endStruct()
+	}
+	meta.fieldI64(3, w.rows)
+
+	var chunks []*thriftWriter
+	var total int64
+	for i, c := range w.columns {
+		offset := int64(buf.Len())
+
+		var page bytes.Buffer
+		if !c.Required {
+			levels := encodeLevels(w.defined[i])
+			binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		encodePlain(&page, c.Type, w.values[i])
+
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.fieldI32(1, pageData)
+		header.fieldI32(2, int32(page.Len()))
+		header.fieldI32(3, int32(page.Len()))
+		header.fieldStructBegin(5) // DataPageHeader
+		header.fieldI32(1, int32(w.rows))
+		header.fieldI32(2, encodingPlain)
+		header.fieldI32(3, encodingRLE)
+		header.fieldI32(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		buf.Write(header.buf.Bytes())
+		buf.Write(page.Bytes())
+		size := int64(header.buf.Len() + page.Len())
+		total += size
+
+		chunk := &thriftWriter{}
+		chunk.beginStruct()
+		chunk.fieldI64(2, offset)
+		chunk.fieldStructBegin(3) // ColumnMetaData
+		chunk.fieldI32(1, c.Type)
+		chunk.fieldListBegin(2, thriftI32, 2)
+		chunk.listI32(encodingPlain)
+		chunk.listI32(encodingRLE)
+		chunk.fieldListBegin(3, thriftBinary, 1)
+		chunk.listString(c.Name)
+		chunk.fieldI32(4, codecUncompressed)
+		chunk.fieldI64(5, w.rows)
+		chunk.fieldI64(6, size)
+		chunk.fieldI64(7, size)
+		chunk.fieldI64(9, offset)
+		chunk.endStruct()
+		chunk.endStruct()
+		chunks = append(chunks, chunk)
+	}
+
+	meta.fieldListBegin(4, thriftStruct, 1)
+	meta.beginStruct() // RowGroup
+	meta.fieldListBegin(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		meta.raw(chunk.buf.Bytes())
+	}
+	meta.fieldI64(2, total)
+	meta.fieldI64(3, w.rows)
+	meta.endStruct()
+	meta.fieldString(6, defaultCreatedBy)
+	meta.endStruct()
+
+	buf.Write(meta.buf.Bytes())
+	binary.Write(&buf, binary.LittleEndian, uint32(meta.buf.Len()))
+	buf.WriteString(magic)
+
+	n, err := out.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// encodeLevels encodes definition levels of bit width 1 with the RLE
+// hybrid encoding, using only RLE runs.
+func encodeLevels(defined []bool) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		writeUvarint(&buf, uint64(j-i)<<1)
+		if defined[i] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		i = j
+	}
+	return buf.Bytes()
+}
+
+// encodePlain encodes the values with the PLAIN encoding.
+func encodePlain(buf *bytes.Buffer, physical int32, values []interface{}) {
+	var b [8]byte
+	switch physical {
+	case Boolean:
+		packed := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		buf.Write(packed)
+	case Int32:
+		for _, v := range values {
+			binary.LittleEndian.PutUint32(b[:], uint32(v.(int32)))
+			buf.Write(b[:4])
+		}
+	case Int64:
+		for _, v := range values {
+			binary.LittleEndian.PutUint64(b[:], uint64(v.(int64)))
+			buf.Write(b[:])
+		}
+	case Float:
+		for _, v := range values {
+			binary.LittleEndian.PutUint32(b[:], math.Float32bits(v.(float32)))
+			buf.Write(b[:4])
+		}
+	case Double:
+		for _, v := range values {
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v.(float64)))
+			buf.Write(b[:])
+		}
+	case ByteArray:
+		for _, v := range values {
+			var data []byte
+			if s, ok := v.(string); ok {
+				data = []byte(s)
+			} else {
+				data = v.([]byte)
+			}
+			binary.LittleEndian.PutUint32(b[:], uint32(len(data)))
+			buf.Write(b[:4])
+			buf.Write(data)
+		}
+	}
+}
+
+// plainSize returns the size of the value in the PLAIN encoding.
+func plainSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case bool:
+		return 1 // bit-packed, but a byte bounds it
+	case int32, float32:
+		return 4
+	case string:
+		return 4 + int64(len(v))
+	case []byte:
+		return 4 + int64(len(v))
+	default: // int64, float64
+		return 8
+	}
+}
@@ -0,0 +1,110 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookupio
+
+import (
+	"context"
+	"regexp"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// Bigtable is a Bigtable table. The key is the row key and the value is
+// stored in the cell of Family and Column.
+type Bigtable struct {
+	Project  string `json:"project"`
+	Instance string `json:"instance"`
+	Table    string `json:"table"`
+	Family   string `json:"family"`
+	Column   string `json:"column"`
+	// Endpoint is the address of a Bigtable emulator, if any. The
+	// connection is then unauthenticated and insecure.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	client *bigtable.Client
+	table  *bigtable.Table
+}
+
+func (b *Bigtable) Open(ctx context.Context) error {
+	if b.Family == "" || b.Column == "" {
+		return errors.New("no column family or column")
+	}
+	var opts []option.ClientOption
+	if b.Endpoint != "" {
+		opts = append(opts,
+			option.WithEndpoint(b.Endpoint),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()))
+	}
+	var err error
+	if b.client, err = bigtable.NewClient(ctx, b.Project, b.Instance, opts...); err != nil {
+		return errors.Wrapf(err, "failed to create Bigtable client for %v", b.Instance)
+	}
+	b.table = b.client.Open(b.Table)
+	return nil
+}
+
+func (b *Bigtable) Put(ctx context.Context, entries []Entry) error {
+	var keys []string
+	var muts []*bigtable.Mutation
+	for _, e := range entries {
+		mut := bigtable.NewMutation()
+		mut.Set(b.Family, b.Column, bigtable.ServerTime, e.Value)
+		keys = append(keys, e.Key)
+		muts = append(muts, mut)
+	}
+	errs, err := b.table.ApplyBulk(ctx, keys, muts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to %v", b.Table)
+	}
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrapf(err, "failed to write row %v to %v", keys[i], b.Table)
+		}
+	}
+	return nil
+}
+
+func (b *Bigtable) Get(ctx context.Context, key string) ([]byte, error) {
+	filter := bigtable.ChainFilters(
+		bigtable.FamilyFilter(exact(b.Family)),
+		bigtable.ColumnFilter(exact(b.Column)),
+		bigtable.LatestNFilter(1))
+	row, err := b.table.ReadRow(ctx, key, bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read row %v from %v", key, b.Table)
+	}
+	for _, item := range row[b.Family] {
+		return item.Value, nil
+	}
+	return nil, nil
+}
+
+// exact returns the regular expression of the filters that matches the name
+// only, since the filters match by RE2 expressions.
+func exact(name string) string {
+	return "^" + regexp.QuoteMeta(name) + "$"
+}
+
+func (b *Bigtable) Close() error {
+	if b.client == nil {
+		return nil
+	}
+	return b.client.Close()
+}
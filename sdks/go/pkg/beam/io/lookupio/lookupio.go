@@ -0,0 +1,281 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lookupio contains a side-input alternative backed by an external
+// key-value store, such as Bigtable, for lookup tables too large to be
+// broadcast to all workers. One branch of the pipeline writes the table and
+// another looks up its keys. For example:
+//
+//	store := lookupio.Bigtable{Project: project, Instance: "lookup",
+//	    Table: "products", Family: "p", Column: "v"}
+//	written := lookupio.Write(s, store, products, lookupio.Options{})
+//	enriched := lookupio.Lookup(s, store, reflect.TypeOf(Product{}), written,
+//	    ordersByProduct, lookupio.Options{TTL: time.Hour})
+//
+// pairs each order with its product, if any.
+package lookupio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/cache"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/stats"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*writeFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*lookupFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*Bigtable)(nil)).Elem())
+}
+
+// Entry is the encoded value of a key.
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Store is an external key-value store. A Store is encoded as JSON and sent
+// to the workers, so its configuration must be in exported fields and its
+// type must be registered with beam.RegisterType.
+type Store interface {
+	// Open prepares the store for reads and writes.
+	Open(ctx context.Context) error
+	// Put writes the entries, replacing the values of existing keys.
+	Put(ctx context.Context, entries []Entry) error
+	// Get returns the value of the key, or nil if the key is not present.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Options configure Write and Lookup.
+type Options struct {
+	// BatchSize is the maximum number of entries Write puts at once. If
+	// zero, 500 is used.
+	BatchSize int
+	// TTL is the time Lookup caches the value of a key in the worker cache,
+	// including the absence of a value. If zero, values are cached until
+	// evicted, which suits tables that are written once.
+	TTL time.Duration
+}
+
+// Write writes a PCollection<KV<K,V>> to the store. The key is formatted
+// with fmt.Sprint, or used as is if it is a string or []byte, and the value
+// is encoded with the coder of V. It returns a singleton PCollection<int> of
+// the number of entries written, which Lookup waits on.
+func Write(s beam.Scope, store Store, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope("lookupio.Write")
+
+	_, v := beam.ValidateKVType(col)
+	if opts.BatchSize < 0 {
+		panic(fmt.Sprintf("invalid batch size: %v", opts.BatchSize))
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = 500
+	}
+	data := encodeStore(store)
+
+	written := beam.ParDo(s, &writeFn{
+		Store:     beam.EncodedType{T: reflect.TypeOf(store)},
+		Config:    data,
+		Value:     beam.EncodedType{T: v.Type()},
+		BatchSize: opts.BatchSize,
+	}, col)
+	return stats.Sum(s, written)
+}
+
+// Lookup looks up the keys of a PCollection<KV<K,A>> in the store, once the
+// PCollection<int> returned by Write for the store is computed. It returns a
+// PCollection<KV<A,V>> of each element paired with the value of its key,
+// where V is the type t the values were written with. Elements whose key is
+// not present are dropped and counted by the counter "missing" in the
+// "lookupio" namespace. Values are cached in the worker cache, so repeated
+// keys are read from the store once per worker.
+func Lookup(s beam.Scope, store Store, t reflect.Type, written, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope("lookupio.Lookup")
+
+	beam.ValidateKVType(col)
+	if opts.TTL < 0 {
+		panic(fmt.Sprintf("invalid TTL: %v", opts.TTL))
+	}
+	data := encodeStore(store)
+
+	return beam.ParDo(s, &lookupFn{
+		Store:  beam.EncodedType{T: reflect.TypeOf(store)},
+		Config: data,
+		Value:  beam.EncodedType{T: t},
+		TTL:    opts.TTL,
+	}, col, beam.SideInput{Input: written}, beam.TypeDefinition{Var: beam.ZType, T: t})
+}
+
+func encodeStore(store Store) string {
+	data, err := json.Marshal(store)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to encode store %T", store))
+	}
+	return string(data)
+}
+
+// openStore decodes and opens the store of the given type and JSON encoding.
+func openStore(ctx context.Context, t reflect.Type, config string) (Store, error) {
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Ptr {
+		ptr = reflect.New(t.Elem())
+	}
+	if err := json.Unmarshal([]byte(config), ptr.Interface()); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode store %v", t)
+	}
+	var store Store
+	if t.Kind() == reflect.Ptr {
+		store = ptr.Interface().(Store)
+	} else {
+		store = ptr.Elem().Interface().(Store)
+	}
+	if err := store.Open(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// writeFn puts the entries to the store in batches and emits a 1 for each.
+type writeFn struct {
+	// Store is the type of the store and Config its JSON encoding.
+	Store     beam.EncodedType `json:"store"`
+	Config    string           `json:"config"`
+	Value     beam.EncodedType `json:"value"`
+	BatchSize int              `json:"batch_size"`
+
+	store Store
+	enc   beam.ElementEncoder
+	batch []Entry
+}
+
+func (f *writeFn) Setup(ctx context.Context) error {
+	f.enc = beam.NewElementEncoder(f.Value.T)
+	var err error
+	f.store, err = openStore(ctx, f.Store.T, f.Config)
+	return err
+}
+
+func (f *writeFn) StartBundle(_ context.Context, _ func(int)) {
+	f.batch = nil
+}
+
+func (f *writeFn) ProcessElement(ctx context.Context, key beam.X, value beam.Y, emit func(int)) error {
+	var buf bytes.Buffer
+	if err := f.enc.Encode(value, &buf); err != nil {
+		return errors.Wrapf(err, "failed to encode value of key %v", key)
+	}
+	f.batch = append(f.batch, Entry{Key: storeKey(key), Value: buf.Bytes()})
+	emit(1)
+	if len(f.batch) >= f.BatchSize {
+		return f.flush(ctx)
+	}
+	return nil
+}
+
+func (f *writeFn) FinishBundle(ctx context.Context, _ func(int)) error {
+	return f.flush(ctx)
+}
+
+func (f *writeFn) Teardown() error {
+	if f.store == nil {
+		return nil
+	}
+	return f.store.Close()
+}
+
+func (f *writeFn) flush(ctx context.Context) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	if err := f.store.Put(ctx, f.batch); err != nil {
+		return errors.Wrapf(err, "failed to put %v entries", len(f.batch))
+	}
+	f.batch = nil
+	return nil
+}
+
+// lookupFn pairs elements with the cached value of their key.
+type lookupFn struct {
+	Store  beam.EncodedType `json:"store"`
+	Config string           `json:"config"`
+	Value  beam.EncodedType `json:"value"`
+	TTL    time.Duration    `json:"ttl,omitempty"`
+
+	store   Store
+	dec     beam.ElementDecoder
+	prefix  string
+	missing beam.Counter
+}
+
+func (f *lookupFn) Setup(ctx context.Context) error {
+	f.dec = beam.NewElementDecoder(f.Value.T)
+	h := fnv.New64a()
+	h.Write([]byte(f.Store.T.String() + f.Config))
+	f.prefix = "lookupio/" + strconv.FormatUint(h.Sum64(), 16) + "/"
+	f.missing = beam.NewCounter("lookupio", "missing")
+	var err error
+	f.store, err = openStore(ctx, f.Store.T, f.Config)
+	return err
+}
+
+func (f *lookupFn) ProcessElement(ctx context.Context, key beam.X, elm beam.Y, _ func(*int) bool, emit func(beam.Y, beam.Z)) error {
+	k := storeKey(key)
+	v, err := cache.Load(f.prefix+k, f.TTL, func() (interface{}, error) {
+		return f.store.Get(ctx, k)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get key %v", k)
+	}
+	data := v.([]byte)
+	if data == nil {
+		f.missing.Inc(ctx, 1)
+		return nil
+	}
+	value, err := f.dec.Decode(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode value of key %v", k)
+	}
+	emit(elm, value)
+	return nil
+}
+
+func (f *lookupFn) Teardown() error {
+	if f.store == nil {
+		return nil
+	}
+	return f.store.Close()
+}
+
+func storeKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	default:
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,118 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookupio
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*memoryStore)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*product)(nil)).Elem())
+	beam.RegisterFunction(productKey)
+	beam.RegisterFunction(formatFn)
+}
+
+type product struct {
+	ID   int
+	Name string
+}
+
+func productKey(p product) (int, product) {
+	return p.ID, p
+}
+
+func formatFn(order string, p product) string {
+	return fmt.Sprintf("%v:%v", order, p.Name)
+}
+
+var (
+	mu     sync.Mutex
+	stores = make(map[string]map[string][]byte)
+)
+
+// memoryStore is a Store of entries in stores.
+type memoryStore struct {
+	Name string
+}
+
+func (m memoryStore) Open(ctx context.Context) error {
+	return nil
+}
+
+func (m memoryStore) Put(ctx context.Context, entries []Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if stores[m.Name] == nil {
+		stores[m.Name] = make(map[string][]byte)
+	}
+	for _, e := range entries {
+		stores[m.Name][e.Key] = e.Value
+	}
+	return nil
+}
+
+func (m memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return stores[m.Name][key], nil
+}
+
+func (m memoryStore) Close() error {
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	store := memoryStore{Name: "products"}
+	products := beam.Create(s, product{1, "apple"}, product{2, "pear"})
+	written := Write(s, store, beam.ParDo(s, productKey, products), Options{BatchSize: 1})
+
+	orders := beam.ParDo(s, func(order string, emit func(int, string)) {
+		switch order {
+		case "o1", "o3":
+			emit(1, order)
+		case "o2":
+			emit(2, order)
+		default:
+			emit(3, order)
+		}
+	}, beam.Create(s, "o1", "o2", "o3", "o4"))
+	enriched := Lookup(s, store, reflect.TypeOf(product{}), written, orders, Options{})
+	passert.Equals(s, beam.ParDo(s, formatFn, enriched), "o1:apple", "o2:pear", "o3:apple")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestExact(t *testing.T) {
+	re := regexp.MustCompile(exact("cf.v+"))
+	for name, want := range map[string]bool{"cf.v+": true, "cfxv": false, "cf.vv": false, "cf.v+2": false} {
+		if got := re.MatchString(name); got != want {
+			t.Errorf("exact(cf.v+) matches %q = %v, want %v", name, got, want)
+		}
+	}
+}
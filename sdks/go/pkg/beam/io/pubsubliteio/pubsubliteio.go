@@ -0,0 +1,363 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pubsubliteio provides access to Pub/Sub Lite. The transforms run
+// as DoFns over a Client, which a client package registers with
+// RegisterClient on the launcher and the workers. Experimental.
+package pubsubliteio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/pubsubliteio/v1"
+	"github.com/golang/protobuf/proto"
+)
+
+var messageType = reflect.TypeOf((*v1.Message)(nil))
+
+func init() {
+	beam.RegisterType(messageType.Elem())
+	beam.RegisterType(reflect.TypeOf((*partitionsFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*readFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*commitFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*writeFn)(nil)).Elem())
+	beam.RegisterFunction(marshalMessageFn)
+	beam.RegisterFunction(unmarshalMessageFn)
+}
+
+// Client is a Pub/Sub Lite client.
+type Client interface {
+	// Partitions returns the number of partitions of the topic of the
+	// subscription.
+	Partitions(ctx context.Context, subscription string) (int, error)
+	// Receive returns the next message of the partition after the
+	// committed cursor of the subscription and the messages already
+	// received by this client. It returns io.EOF if the partition has no
+	// more messages at the moment. Each partition is read by a new client,
+	// so that a retried read starts at the committed cursor.
+	Receive(ctx context.Context, subscription string, partition int) (*v1.Message, error)
+	// Commit commits the cursor of the partition to the offset, so that
+	// the messages before it are not received again.
+	Commit(ctx context.Context, subscription string, partition int, offset int64) error
+	// Publish publishes the messages to the topic and returns once they
+	// are stored.
+	Publish(ctx context.Context, topic string, msgs []*v1.Message) error
+	// Close releases the resources of the client.
+	Close() error
+}
+
+var (
+	clientMu  sync.Mutex
+	newClient func(context.Context) (Client, error)
+)
+
+// RegisterClient registers the function that creates the clients of the
+// transforms of this package.
+func RegisterClient(fn func(context.Context) (Client, error)) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	if newClient != nil {
+		panic("Pub/Sub Lite client already registered")
+	}
+	newClient = fn
+}
+
+func openClient(ctx context.Context) (Client, error) {
+	clientMu.Lock()
+	fn := newClient
+	clientMu.Unlock()
+
+	if fn == nil {
+		return nil, errors.New("no Pub/Sub Lite client registered")
+	}
+	return fn(ctx)
+}
+
+// TopicPath returns the path of a Pub/Sub Lite topic. The location is a
+// zone, such as "us-central1-a".
+func TopicPath(project, location, topic string) string {
+	return fmt.Sprintf("projects/%v/locations/%v/topics/%v", project, location, topic)
+}
+
+// SubscriptionPath returns the path of a Pub/Sub Lite subscription.
+func SubscriptionPath(project, location, subscription string) string {
+	return fmt.Sprintf("projects/%v/locations/%v/subscriptions/%v", project, location, subscription)
+}
+
+// ReadOptions represents options for reading from Pub/Sub Lite.
+type ReadOptions struct {
+	// Partitions are the partitions of the topic to read. If empty, all
+	// partitions are read.
+	Partitions []int
+	// WithEventTime uses the event time of messages as their timestamps,
+	// rather than the publish time. Messages without an event time are
+	// timestamped with their publish time.
+	WithEventTime bool
+	// WithMetadata produces *v1.Message elements, rather than the data.
+	WithMetadata bool
+}
+
+// Read reads the messages of the given subscription path. It produces a
+// PCollection<*v1.Message>, if WithMetadata is set, or a
+// PCollection<[]byte>.
+//
+// Each partition is read in offset order by one DoFn call, which reads the
+// messages available when it runs. The output is thus bounded: this SDK has
+// no splittable DoFns to read a partition indefinitely, so a pipeline reads
+// the backlog of the subscription each time it runs. Nor are there
+// per-partition watermarks: elements are timestamped, but the watermark of
+// the output advances only once all partitions are read.
+//
+// The cursor of a partition is committed after a GroupByKey, that is, once
+// the runner has committed the bundle that read the messages and its output.
+// If the bundle is retried or the pipeline fails before the cursor is
+// committed, the messages are read again, so delivery is at-least-once.
+func Read(s beam.Scope, subscription string, opts *ReadOptions) beam.PCollection {
+	s = s.Scope("pubsubliteio.Read")
+
+	if opts == nil {
+		opts = &ReadOptions{}
+	}
+	for _, p := range opts.Partitions {
+		if p < 0 {
+			panic(fmt.Sprintf("invalid partition: %v", p))
+		}
+	}
+
+	partitions := beam.ParDo(s, &partitionsFn{Subscription: subscription, Partitions: opts.Partitions}, beam.Impulse(s))
+	out, cursors := beam.ParDo2(s, &readFn{
+		Subscription:  subscription,
+		WithEventTime: opts.WithEventTime,
+		WithMetadata:  opts.WithMetadata,
+	}, beam.Reshuffle(s, partitions))
+	beam.ParDo0(s, &commitFn{Subscription: subscription}, beam.GroupByKey(s, cursors))
+	if opts.WithMetadata {
+		return beam.ParDo(s, unmarshalMessageFn, out)
+	}
+	return out
+}
+
+// partitionsFn emits the partitions to read.
+type partitionsFn struct {
+	Subscription string `json:"subscription"`
+	Partitions   []int  `json:"partitions,omitempty"`
+}
+
+func (f *partitionsFn) ProcessElement(ctx context.Context, _ []byte, emit func(int)) error {
+	if len(f.Partitions) > 0 {
+		for _, p := range f.Partitions {
+			emit(p)
+		}
+		return nil
+	}
+
+	client, err := openClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	n, err := client.Partitions(ctx, f.Subscription)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get partitions of %v", f.Subscription)
+	}
+	for p := 0; p < n; p++ {
+		emit(p)
+	}
+	return nil
+}
+
+// readFn reads the available messages of a partition and emits them as
+// data or serialized messages, and the cursor after the last message.
+type readFn struct {
+	Subscription  string `json:"subscription"`
+	WithEventTime bool   `json:"with_event_time,omitempty"`
+	WithMetadata  bool   `json:"with_metadata,omitempty"`
+}
+
+func (f *readFn) ProcessElement(ctx context.Context, partition int, emit func(beam.EventTime, []byte), cursor func(int, int64)) error {
+	// The client is not reused, since it does not receive messages that it
+	// received before, which a retried read must emit again.
+	client, err := openClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	next := int64(-1)
+	for {
+		msg, err := client.Receive(ctx, f.Subscription, partition)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to receive from partition %v of %v", partition, f.Subscription)
+		}
+
+		t := msg.PublishTime
+		if f.WithEventTime && msg.EventTime != 0 {
+			t = msg.EventTime
+		}
+		if f.WithMetadata {
+			data, err := proto.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			emit(mtime.FromMilliseconds(t), data)
+		} else {
+			emit(mtime.FromMilliseconds(t), msg.Data)
+		}
+		next = msg.Offset + 1
+	}
+	if next >= 0 {
+		cursor(partition, next)
+	}
+	return nil
+}
+
+// commitFn commits the cursor of a partition to the largest offset read.
+type commitFn struct {
+	Subscription string `json:"subscription"`
+
+	client Client
+}
+
+func (f *commitFn) Setup(ctx context.Context) error {
+	var err error
+	f.client, err = openClient(ctx)
+	return err
+}
+
+func (f *commitFn) ProcessElement(ctx context.Context, partition int, offsets func(*int64) bool) error {
+	next := int64(-1)
+	var offset int64
+	for offsets(&offset) {
+		if offset > next {
+			next = offset
+		}
+	}
+	if err := f.client.Commit(ctx, f.Subscription, partition, next); err != nil {
+		return errors.Wrapf(err, "failed to commit partition %v of %v", partition, f.Subscription)
+	}
+	return nil
+}
+
+func (f *commitFn) Teardown() error {
+	if f.client == nil {
+		return nil
+	}
+	return f.client.Close()
+}
+
+func marshalMessageFn(msg *v1.Message) ([]byte, error) {
+	return proto.Marshal(msg)
+}
+
+func unmarshalMessageFn(raw []byte) (*v1.Message, error) {
+	var msg v1.Message
+	if err := proto.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Write writes *v1.Messages or bytes to the given topic path. Messages are
+// assigned to partitions by key: messages with the same key are published
+// to the same partition and in the order they are written by a bundle.
+// Messages without a key, including bytes, are spread over the partitions.
+// Messages are published in batches, and all messages of a bundle are
+// stored before the bundle completes, so a retried bundle may publish its
+// messages again.
+func Write(s beam.Scope, topic string, col beam.PCollection) {
+	s = s.Scope("pubsubliteio.Write")
+
+	out := col
+	fn := &writeFn{Topic: topic, BatchSize: 1000}
+	switch t := col.Type().Type(); t {
+	case reflectx.ByteSlice:
+	case messageType:
+		out = beam.ParDo(s, marshalMessageFn, col)
+		fn.WithMetadata = true
+	default:
+		panic(fmt.Sprintf("invalid element type: %v, want []byte or %v", t, messageType))
+	}
+	beam.ParDo0(s, fn, out)
+}
+
+// writeFn publishes the messages of a bundle in batches.
+type writeFn struct {
+	Topic        string `json:"topic"`
+	WithMetadata bool   `json:"with_metadata,omitempty"`
+	BatchSize    int    `json:"batch_size"`
+
+	client Client
+	batch  []*v1.Message
+}
+
+func (f *writeFn) Setup(ctx context.Context) error {
+	var err error
+	f.client, err = openClient(ctx)
+	return err
+}
+
+func (f *writeFn) StartBundle() {
+	f.batch = nil
+}
+
+func (f *writeFn) ProcessElement(ctx context.Context, data []byte) error {
+	msg := &v1.Message{Data: data}
+	if f.WithMetadata {
+		msg = &v1.Message{}
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+	}
+	f.batch = append(f.batch, msg)
+	if len(f.batch) >= f.BatchSize {
+		return f.flush(ctx)
+	}
+	return nil
+}
+
+func (f *writeFn) FinishBundle(ctx context.Context) error {
+	return f.flush(ctx)
+}
+
+func (f *writeFn) Teardown() error {
+	if f.client == nil {
+		return nil
+	}
+	return f.client.Close()
+}
+
+func (f *writeFn) flush(ctx context.Context) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	if err := f.client.Publish(ctx, f.Topic, f.batch); err != nil {
+		return errors.Wrapf(err, "failed to publish %v messages to %v", len(f.batch), f.Topic)
+	}
+	f.batch = nil
+	return nil
+}
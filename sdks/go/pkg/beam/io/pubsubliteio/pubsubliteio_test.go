@@ -0,0 +1,209 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsubliteio
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/pubsubliteio/v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+const numPartitions = 3
+
+func init() {
+	beam.RegisterFunction(bytesFn)
+	beam.RegisterFunction(stringFn)
+	beam.RegisterFunction(keyedMessageFn)
+	beam.RegisterFunction(messageKeyFn)
+	RegisterClient(func(context.Context) (Client, error) {
+		return &fakeClient{received: make(map[int]int64)}, nil
+	})
+}
+
+// fakeServer stores the messages of fake topics, where the subscription of
+// a topic has the same name.
+var fakeServer = struct {
+	mu      sync.Mutex
+	topics  map[string][][]*v1.Message
+	cursors map[string][]int64
+}{
+	topics:  make(map[string][][]*v1.Message),
+	cursors: make(map[string][]int64),
+}
+
+// fakeClient is a Client of fakeServer.
+type fakeClient struct {
+	received map[int]int64
+}
+
+func (c *fakeClient) Partitions(ctx context.Context, subscription string) (int, error) {
+	return numPartitions, nil
+}
+
+func (c *fakeClient) Receive(ctx context.Context, subscription string, partition int) (*v1.Message, error) {
+	fakeServer.mu.Lock()
+	defer fakeServer.mu.Unlock()
+
+	msgs := fakeServer.topics[subscription]
+	if msgs == nil {
+		return nil, io.EOF
+	}
+	next, ok := c.received[partition]
+	if !ok {
+		next = fakeServer.cursors[subscription][partition]
+	}
+	if next >= int64(len(msgs[partition])) {
+		return nil, io.EOF
+	}
+	c.received[partition] = next + 1
+	return msgs[partition][next], nil
+}
+
+func (c *fakeClient) Commit(ctx context.Context, subscription string, partition int, offset int64) error {
+	fakeServer.mu.Lock()
+	defer fakeServer.mu.Unlock()
+
+	fakeServer.cursors[subscription][partition] = offset
+	return nil
+}
+
+func (c *fakeClient) Publish(ctx context.Context, topic string, msgs []*v1.Message) error {
+	fakeServer.mu.Lock()
+	defer fakeServer.mu.Unlock()
+
+	if fakeServer.topics[topic] == nil {
+		fakeServer.topics[topic] = make([][]*v1.Message, numPartitions)
+		fakeServer.cursors[topic] = make([]int64, numPartitions)
+	}
+	partitions := fakeServer.topics[topic]
+	for i, msg := range msgs {
+		p := i % numPartitions
+		if len(msg.Key) > 0 {
+			h := fnv.New32a()
+			h.Write(msg.Key)
+			p = int(h.Sum32() % numPartitions)
+		}
+		stored := *msg
+		stored.Partition = int64(p)
+		stored.Offset = int64(len(partitions[p]))
+		stored.PublishTime = 1000
+		partitions[p] = append(partitions[p], &stored)
+	}
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	return nil
+}
+
+func bytesFn(s string) []byte {
+	return []byte(s)
+}
+
+func stringFn(b []byte) string {
+	return string(b)
+}
+
+func keyedMessageFn(s string) *v1.Message {
+	return &v1.Message{Key: []byte(s[:1]), Data: []byte(s), EventTime: 5000}
+}
+
+func messageKeyFn(t beam.EventTime, msg *v1.Message) string {
+	return string(msg.Key) + ":" + string(msg.Data) + "@" + strconv.FormatInt(t.Milliseconds(), 10)
+}
+
+func TestWriteRead(t *testing.T) {
+	topic := TopicPath("project", "zone", "bytes")
+
+	p, s := beam.NewPipelineWithRoot()
+	Write(s, topic, beam.ParDo(s, bytesFn, beam.Create(s, "a", "b", "c", "d")))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	passert.Equals(s, beam.ParDo(s, stringFn, Read(s, topic, nil)), "a", "b", "c", "d")
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	// The messages are committed, so a second read has no output.
+	p, s = beam.NewPipelineWithRoot()
+	passert.Empty(s, Read(s, topic, nil))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+}
+
+func TestWriteReadMetadata(t *testing.T) {
+	topic := TopicPath("project", "zone", "messages")
+
+	p, s := beam.NewPipelineWithRoot()
+	Write(s, topic, beam.ParDo(s, keyedMessageFn, beam.Create(s, "a1", "a2", "b1")))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	msgs := Read(s, topic, &ReadOptions{WithEventTime: true, WithMetadata: true})
+	passert.Equals(s, beam.ParDo(s, messageKeyFn, msgs), "a:a1@5000", "a:a2@5000", "b:b1@5000")
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+}
+
+// TestReadRetry tests that the cursor is not committed by the read itself,
+// so that a retried read emits the messages again.
+func TestReadRetry(t *testing.T) {
+	topic := TopicPath("project", "zone", "retry")
+
+	p, s := beam.NewPipelineWithRoot()
+	Write(s, topic, beam.ParDo(s, bytesFn, beam.Create(s, "a", "b", "c")))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	ctx := context.Background()
+	fn := &readFn{Subscription: topic}
+	for attempt := 0; attempt < 2; attempt++ {
+		var got []string
+		var cursors []int64
+		emit := func(_ beam.EventTime, data []byte) { got = append(got, string(data)) }
+		cursor := func(_ int, offset int64) { cursors = append(cursors, offset) }
+		for partition := 0; partition < numPartitions; partition++ {
+			if err := fn.ProcessElement(ctx, partition, emit, cursor); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(got) != 3 || len(cursors) != 3 {
+			t.Errorf("attempt %v read %v with cursors %v, want 3 messages and cursors", attempt, got, cursors)
+		}
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	passert.Equals(s, beam.ParDo(s, stringFn, Read(s, topic, nil)), "a", "b", "c")
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+}
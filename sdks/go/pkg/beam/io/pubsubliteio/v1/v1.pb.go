@@ -0,0 +1,134 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: v1.proto
+
+package v1
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Message struct {
+	Key                  []byte            `protobuf:"bytes,1,opt,name=Key,json=key,proto3" json:"Key,omitempty"`
+	Data                 []byte            `protobuf:"bytes,2,opt,name=Data,json=data,proto3" json:"Data,omitempty"`
+	Attributes           map[string]string `protobuf:"bytes,3,rep,name=Attributes,json=attributes,proto3" json:"Attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	EventTime            int64             `protobuf:"varint,4,opt,name=EventTime,json=eventTime,proto3" json:"EventTime,omitempty"`
+	PublishTime          int64             `protobuf:"varint,5,opt,name=PublishTime,json=publishTime,proto3" json:"PublishTime,omitempty"`
+	Partition            int64             `protobuf:"varint,6,opt,name=Partition,json=partition,proto3" json:"Partition,omitempty"`
+	Offset               int64             `protobuf:"varint,7,opt,name=Offset,json=offset,proto3" json:"Offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Message) Reset()         { *m = Message{} }
+func (m *Message) String() string { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()    {}
+func (*Message) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2e4aa7d76fd7ee8a, []int{0}
+}
+
+func (m *Message) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Message.Unmarshal(m, b)
+}
+func (m *Message) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Message.Marshal(b, m, deterministic)
+}
+func (m *Message) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Message.Merge(m, src)
+}
+func (m *Message) XXX_Size() int {
+	return xxx_messageInfo_Message.Size(m)
+}
+func (m *Message) XXX_DiscardUnknown() {
+	xxx_messageInfo_Message.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Message proto.InternalMessageInfo
+
+func (m *Message) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *Message) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Message) GetAttributes() map[string]string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *Message) GetEventTime() int64 {
+	if m != nil {
+		return m.EventTime
+	}
+	return 0
+}
+
+func (m *Message) GetPublishTime() int64 {
+	if m != nil {
+		return m.PublishTime
+	}
+	return 0
+}
+
+func (m *Message) GetPartition() int64 {
+	if m != nil {
+		return m.Partition
+	}
+	return 0
+}
+
+func (m *Message) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Message)(nil), "v1.Message")
+	proto.RegisterMapType((map[string]string)(nil), "v1.Message.AttributesEntry")
+}
+
+func init() { proto.RegisterFile("v1.proto", fileDescriptor_2e4aa7d76fd7ee8a) }
+
+var fileDescriptor_2e4aa7d76fd7ee8a = []byte{
+	// 235 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x40, 0x49, 0x36, 0x4d, 0xcd, 0x44, 0x50, 0x06, 0x91, 0x45, 0x3d, 0x04, 0x4f, 0x39, 0x05,
+	0xa2, 0x17, 0x51, 0x3c, 0x08, 0xf6, 0x24, 0x62, 0x09, 0xfe, 0xc0, 0x04, 0xa7, 0xba, 0xb4, 0x26,
+	0x21, 0x3b, 0x59, 0xc8, 0xe7, 0xf8, 0xa7, 0xd2, 0x4d, 0x6b, 0xc1, 0xe3, 0xbc, 0xc7, 0x2c, 0xfb,
+	0x06, 0x8e, 0x5c, 0x59, 0x74, 0x7d, 0x2b, 0x2d, 0x86, 0xae, 0xbc, 0xfe, 0x09, 0x61, 0xfe, 0xca,
+	0xd6, 0xd2, 0x27, 0xe3, 0x29, 0xa8, 0x17, 0x1e, 0x75, 0x90, 0x05, 0xf9, 0x71, 0xa5, 0xd6, 0x3c,
+	0x22, 0x42, 0xf4, 0x4c, 0x42, 0x3a, 0xf4, 0x28, 0xfa, 0x20, 0x21, 0x7c, 0x00, 0x78, 0x12, 0xe9,
+	0x4d, 0x3d, 0x08, 0x5b, 0xad, 0x32, 0x95, 0xa7, 0x37, 0x97, 0x85, 0x2b, 0x8b, 0xdd, 0x33, 0xc5,
+	0xc1, 0x2e, 0x1a, 0xe9, 0xc7, 0x0a, 0xe8, 0x0f, 0xe0, 0x15, 0x24, 0x0b, 0xc7, 0x8d, 0xbc, 0x9b,
+	0x6f, 0xd6, 0x51, 0x16, 0xe4, 0xaa, 0x4a, 0x78, 0x0f, 0x30, 0x83, 0x74, 0x39, 0xd4, 0x1b, 0x63,
+	0xbf, 0xbc, 0x9f, 0x79, 0x9f, 0x76, 0x07, 0xb4, 0xdd, 0x5f, 0x52, 0x2f, 0x46, 0x4c, 0xdb, 0xe8,
+	0x78, 0xda, 0xef, 0xf6, 0x00, 0xcf, 0x21, 0x7e, 0x5b, 0xad, 0x2c, 0x8b, 0x9e, 0x7b, 0x15, 0xb7,
+	0x7e, 0xba, 0x78, 0x84, 0x93, 0x7f, 0x9f, 0xda, 0xb6, 0xae, 0x77, 0xad, 0xc9, 0xd4, 0x7a, 0x06,
+	0x33, 0x47, 0x9b, 0x81, 0x7d, 0x6c, 0x52, 0x4d, 0xc3, 0x7d, 0x78, 0x17, 0xd4, 0xb1, 0x3f, 0xd7,
+	0xed, 0xef, 0x00, 0xb4, 0xca, 0xfc, 0xf9, 0x3a, 0x01, 0x00, 0x00,
+}
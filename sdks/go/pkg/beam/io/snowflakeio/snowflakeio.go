@@ -0,0 +1,483 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snowflakeio provides transformations to bulk load data into and
+// unload data from Snowflake, using COPY INTO through a stage. It uses the
+// database/sql API with the "snowflake" driver, which must be imported by
+// the pipeline:
+//
+//    import _ "github.com/snowflakedb/gosnowflake"
+//
+// Rows are staged as newline-delimited JSON. Struct fields are matched to
+// columns case-insensitively by their json name, so fields should be tagged
+// with the column names:
+//
+//    type Sale struct {
+//        ItemID string  `json:"item_id"`
+//        Price  float64 `json:"price"`
+//    }
+package snowflakeio
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*shardFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*stageFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*stageBundleFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*copyFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*unloadFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*readFileFn)(nil)).Elem())
+}
+
+// driver is the database/sql driver name of Snowflake.
+const driver = "snowflake"
+
+// defaultShards is the number of files written by a write, if not set.
+const defaultShards = 16
+
+// RunnerDeterminedShards, as the number of shards of a write, lets the
+// runner determine the number of staged files: each bundle is staged as its
+// own file, so the files follow the parallelism picked by the runner.
+const RunnerDeterminedShards = -1
+
+// Stage identifies the Snowflake stage that data is copied through.
+type Stage struct {
+	// Name is the name of the stage, such as "my_stage" or "~" for the
+	// user stage.
+	Name string
+	// Location is the storage location of an external stage, such as
+	// "gs://bucket/path", which must be accessible with a Beam file system.
+	// If empty, the stage is internal and files are uploaded with PUT.
+	// Reads require an external stage.
+	Location string
+}
+
+func (st Stage) ref() string {
+	return "@" + strings.TrimPrefix(st.Name, "@")
+}
+
+func (st Stage) validate() {
+	if strings.TrimPrefix(st.Name, "@") == "" {
+		panic("stage name is required")
+	}
+	if st.Location != "" {
+		filesystem.ValidateScheme(st.Location)
+	}
+}
+
+// WriteOptions represents options for writing to Snowflake.
+type WriteOptions struct {
+	// Shards is the number of files the rows are staged in, which bounds
+	// the parallelism of the write and the load. Defaults to 16. If
+	// RunnerDeterminedShards, the runner determines the number of files.
+	Shards int
+}
+
+// Write loads the elements of the given PCollection<T> into the table. The
+// rows are staged as files in the stage in parallel and then loaded with a
+// single COPY INTO statement, so the load is atomic. Staged files are
+// purged once loaded.
+func Write(s beam.Scope, dsn, table string, stage Stage, col beam.PCollection, opts *WriteOptions) {
+	s = s.Scope("snowflakeio.Write")
+
+	stage.validate()
+	shards := defaultShards
+	if opts != nil && opts.Shards != 0 {
+		shards = opts.Shards
+	}
+	prefix := uniquePrefix()
+	stager := stageFn{Dsn: dsn, Stage: stage.ref(), Location: stage.Location, Prefix: prefix}
+
+	var files beam.PCollection
+	switch {
+	case shards == RunnerDeterminedShards:
+		files = beam.ParDo(s, &stageBundleFn{stageFn: stager}, col)
+	case shards >= 1:
+		keyed := beam.ParDo(s, &shardFn{Shards: shards}, col)
+		files = beam.ParDo(s, &stager, beam.GroupByKey(s, keyed))
+	default:
+		panic(fmt.Sprintf("invalid number of shards: %v", shards))
+	}
+	beam.ParDo0(s, &copyFn{Dsn: dsn, Table: table, Stage: stage.ref(), Prefix: prefix}, beam.GroupByKey(s, beam.AddFixedKey(s, files)))
+}
+
+// uniquePrefix returns a new prefix for the staged files of a transform.
+func uniquePrefix() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(errors.Wrap(err, "failed to generate stage prefix"))
+	}
+	return "beam-" + hex.EncodeToString(b[:])
+}
+
+// shardFn assigns elements to a random shard.
+type shardFn struct {
+	// Shards is the number of shards.
+	Shards int `json:"shards"`
+}
+
+func (f *shardFn) ProcessElement(elm beam.X) (int, beam.X, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(f.Shards)))
+	if err != nil {
+		return 0, nil, err
+	}
+	return int(n.Int64()), elm, nil
+}
+
+// stageFn writes a shard of rows as a file to the stage and emits its name,
+// relative to the stage.
+type stageFn struct {
+	// Dsn is the data source name, used to PUT files to an internal stage.
+	Dsn string `json:"dsn"`
+	// Stage is the stage reference, "@name".
+	Stage string `json:"stage"`
+	// Location is the storage location of an external stage, if any.
+	Location string `json:"location,omitempty"`
+	// Prefix is the path of the files within the stage.
+	Prefix string `json:"prefix"`
+}
+
+func (f *stageFn) ProcessElement(ctx context.Context, shard int, rows func(*beam.X) bool, emit func(string)) error {
+	sf, err := f.create(ctx, fmt.Sprintf("part-%05d", shard))
+	if err != nil {
+		return err
+	}
+	if err := writeRows(sf.fd, rows); err != nil {
+		sf.abort()
+		return errors.Wrapf(err, "failed to stage %v", sf.name)
+	}
+	if err := f.commit(ctx, sf); err != nil {
+		return err
+	}
+	emit(sf.name)
+	return nil
+}
+
+// stagedFile is a file being written to the stage.
+type stagedFile struct {
+	// name is the name of the file, relative to the stage.
+	name string
+	fd   io.WriteCloser
+	// fs is the file system of an external stage.
+	fs filesystem.Interface
+	// local is the local file uploaded to an internal stage, in the
+	// temporary directory dir.
+	local, dir string
+}
+
+// create opens a new uniquely named file in the stage.
+func (f *stageFn) create(ctx context.Context, part string) (*stagedFile, error) {
+	name := fmt.Sprintf("%v/%v-%v.json", f.Prefix, part, uniquePrefix())
+
+	if f.Location != "" {
+		filename := joinPath(f.Location, name)
+		fs, err := filesystem.New(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+		fd, err := fs.OpenWrite(ctx, filename)
+		if err != nil {
+			fs.Close()
+			return nil, err
+		}
+		return &stagedFile{name: name, fd: fd, fs: fs}, nil
+	}
+
+	// Internal stage: write the file locally and upload it with PUT.
+	dir, err := ioutil.TempDir("", "beam-snowflake")
+	if err != nil {
+		return nil, err
+	}
+	local := filepath.Join(dir, filepath.Base(name))
+	fd, err := os.Create(local)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &stagedFile{name: name, fd: fd, local: local, dir: dir}, nil
+}
+
+// commit closes the file and, for an internal stage, uploads it.
+func (f *stageFn) commit(ctx context.Context, sf *stagedFile) error {
+	if sf.fs != nil {
+		defer sf.fs.Close()
+		return sf.fd.Close()
+	}
+
+	defer os.RemoveAll(sf.dir)
+	if err := sf.fd.Close(); err != nil {
+		return err
+	}
+
+	db, err := sql.Open(driver, f.Dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to open database")
+	}
+	defer db.Close()
+
+	put := fmt.Sprintf("PUT 'file://%v' '%v/%v' AUTO_COMPRESS=FALSE", filepath.ToSlash(sf.local), f.Stage, f.Prefix)
+	if _, err := db.ExecContext(ctx, put); err != nil {
+		return errors.Wrapf(err, "failed to upload %v to %v", sf.name, f.Stage)
+	}
+	return nil
+}
+
+// abort closes the file after a failed write. A partial file in an external
+// stage is left behind but never loaded, since only emitted names are.
+func (sf *stagedFile) abort() {
+	sf.fd.Close()
+	if sf.fs != nil {
+		sf.fs.Close()
+	} else {
+		os.RemoveAll(sf.dir)
+	}
+}
+
+// stageBundleFn writes the rows of each bundle as a file to the stage and,
+// when the bundle finishes, emits its name.
+type stageBundleFn struct {
+	stageFn
+
+	file *stagedFile
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// StartBundle aborts and drops the file of a failed bundle, if any, since the
+// instance may be reused after a failure. The file is never loaded, because
+// its name is not emitted.
+func (f *stageBundleFn) StartBundle() {
+	if f.file == nil {
+		return
+	}
+	f.file.abort()
+	f.file, f.buf, f.enc = nil, nil, nil
+}
+
+func (f *stageBundleFn) ProcessElement(ctx context.Context, row beam.X, _ func(string)) error {
+	if f.file == nil {
+		sf, err := f.create(ctx, "bundle")
+		if err != nil {
+			return err
+		}
+		f.file = sf
+		f.buf = bufio.NewWriterSize(sf.fd, 1<<20)
+		f.enc = json.NewEncoder(f.buf)
+	}
+	return f.enc.Encode(row)
+}
+
+func (f *stageBundleFn) FinishBundle(ctx context.Context, emit func(string)) error {
+	sf := f.file
+	if sf == nil {
+		return nil // empty bundle
+	}
+	f.file = nil
+
+	if err := f.buf.Flush(); err != nil {
+		sf.abort()
+		return errors.Wrapf(err, "failed to stage %v", sf.name)
+	}
+	if err := f.commit(ctx, sf); err != nil {
+		return err
+	}
+	emit(sf.name)
+	return nil
+}
+
+// writeRows writes the rows as newline-delimited JSON.
+func writeRows(w io.Writer, rows func(*beam.X) bool) error {
+	buf := bufio.NewWriterSize(w, 1<<20)
+	enc := json.NewEncoder(buf)
+	var row beam.X
+	for rows(&row) {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+func joinPath(location, name string) string {
+	return strings.TrimSuffix(location, "/") + "/" + name
+}
+
+// copyFn loads all staged files into the table.
+type copyFn struct {
+	// Dsn is the data source name.
+	Dsn string `json:"dsn"`
+	// Table is the table to load into.
+	Table string `json:"table"`
+	// Stage is the stage reference, "@name".
+	Stage string `json:"stage"`
+	// Prefix is the path of the files within the stage.
+	Prefix string `json:"prefix"`
+}
+
+func (f *copyFn) ProcessElement(ctx context.Context, _ int, names func(*string) bool) error {
+	var files []string
+	var name string
+	for names(&name) {
+		files = append(files, name)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	db, err := sql.Open(driver, f.Dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to open database")
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, copyStatement(f.Table, f.Stage, f.Prefix, files)); err != nil {
+		return errors.Wrapf(err, "failed to copy into %v", f.Table)
+	}
+	log.Infof(ctx, "Loaded %v file(s) into %v", len(files), f.Table)
+	return nil
+}
+
+// copyStatement returns the COPY INTO statement that loads the staged files,
+// given relative to the stage, into the table.
+func copyStatement(table, stage, prefix string, files []string) string {
+	quoted := make([]string, len(files))
+	for i, file := range files {
+		quoted[i] = "'" + strings.TrimPrefix(file, prefix+"/") + "'"
+	}
+	return fmt.Sprintf("COPY INTO %v FROM '%v/%v/' FILES = (%v) FILE_FORMAT = (TYPE = JSON) MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE PURGE = TRUE",
+		table, stage, prefix, strings.Join(quoted, ", "))
+}
+
+// Read returns the rows of the given query as a PCollection<T>, where T is
+// the given type. The result is unloaded to the external stage with COPY
+// INTO and the unloaded files are read in parallel. The files are left in
+// the stage.
+func Read(s beam.Scope, dsn, query string, stage Stage, t reflect.Type) beam.PCollection {
+	s = s.Scope("snowflakeio.Read")
+
+	stage.validate()
+	if stage.Location == "" {
+		panic("snowflakeio.Read requires an external stage location")
+	}
+
+	imp := beam.Impulse(s)
+	files := beam.ParDo(s, &unloadFn{Dsn: dsn, Query: query, Stage: stage.ref(), Location: stage.Location, Prefix: uniquePrefix()}, imp)
+	// Prevent fusion, so that the files are read in parallel.
+	files = beam.Reshuffle(s, files)
+	return beam.ParDo(s, &readFileFn{Type: beam.EncodedType{T: t}}, files, beam.TypeDefinition{Var: beam.XType, T: t})
+}
+
+// unloadFn unloads the result of a query to the stage and emits the names
+// of the unloaded files.
+type unloadFn struct {
+	// Dsn is the data source name.
+	Dsn string `json:"dsn"`
+	// Query is the query to unload.
+	Query string `json:"query"`
+	// Stage is the stage reference, "@name".
+	Stage string `json:"stage"`
+	// Location is the storage location of the stage.
+	Location string `json:"location"`
+	// Prefix is the path of the files within the stage.
+	Prefix string `json:"prefix"`
+}
+
+func (f *unloadFn) ProcessElement(ctx context.Context, _ []byte, emit func(string)) error {
+	db, err := sql.Open(driver, f.Dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to open database")
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, unloadStatement(f.Query, f.Stage, f.Prefix)); err != nil {
+		return errors.Wrapf(err, "failed to unload query: %v", f.Query)
+	}
+
+	glob := joinPath(f.Location, f.Prefix+"/*")
+	fs, err := filesystem.New(ctx, glob)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	files, err := fs.List(ctx, glob)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "Unloaded query into %v file(s)", len(files))
+	for _, filename := range files {
+		emit(filename)
+	}
+	return nil
+}
+
+// unloadStatement returns the COPY INTO statement that unloads the query
+// result as newline-delimited JSON objects to the stage.
+func unloadStatement(query, stage, prefix string) string {
+	return fmt.Sprintf("COPY INTO '%v/%v/' FROM (SELECT OBJECT_CONSTRUCT(*) FROM (%v)) FILE_FORMAT = (TYPE = JSON COMPRESSION = NONE)",
+		stage, prefix, query)
+}
+
+// readFileFn decodes the rows of an unloaded file.
+type readFileFn struct {
+	// Type is the encoded row type.
+	Type beam.EncodedType `json:"type"`
+}
+
+func (f *readFileFn) ProcessElement(ctx context.Context, filename string, emit func(beam.X)) error {
+	log.Infof(ctx, "Reading from %v", filename)
+
+	fs, err := filesystem.New(ctx, filename)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	fd, err := fs.OpenRead(ctx, filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(fd))
+	for dec.More() {
+		val := reflect.New(f.Type.T) // val : *T
+		if err := dec.Decode(val.Interface()); err != nil {
+			return errors.Wrapf(err, "failed to decode row of %v into %v", filename, f.Type.T)
+		}
+		emit(val.Elem().Interface()) // emit(*val)
+	}
+	return nil
+}
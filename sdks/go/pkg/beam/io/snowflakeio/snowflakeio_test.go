@@ -0,0 +1,161 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snowflakeio
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/memfs"
+)
+
+type sale struct {
+	ItemID string  `json:"item_id"`
+	Price  float64 `json:"price"`
+}
+
+func TestCopyStatement(t *testing.T) {
+	got := copyStatement("shop.sales", "@load", "beam-1", []string{"beam-1/part-00000-a.json", "beam-1/part-00001-b.json"})
+	exp := "COPY INTO shop.sales FROM '@load/beam-1/' FILES = ('part-00000-a.json', 'part-00001-b.json') " +
+		"FILE_FORMAT = (TYPE = JSON) MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE PURGE = TRUE"
+	if got != exp {
+		t.Errorf("copyStatement() = %v, want %v", got, exp)
+	}
+}
+
+func TestUnloadStatement(t *testing.T) {
+	got := unloadStatement("SELECT * FROM sales", "@unload", "beam-2")
+	exp := "COPY INTO '@unload/beam-2/' FROM (SELECT OBJECT_CONSTRUCT(*) FROM (SELECT * FROM sales)) " +
+		"FILE_FORMAT = (TYPE = JSON COMPRESSION = NONE)"
+	if got != exp {
+		t.Errorf("unloadStatement() = %v, want %v", got, exp)
+	}
+}
+
+// TestStageAndRead tests that rows staged to an external stage are read back
+// by the unloaded file reader, which sees the uppercase column names of
+// Snowflake.
+func TestStageAndRead(t *testing.T) {
+	ctx := context.Background()
+	rows := []sale{{"a", 1.5}, {"b", 2}}
+
+	stage := &stageFn{Stage: "@load", Location: "memfs://stage/", Prefix: "beam-3"}
+	var names []string
+	i := 0
+	iter := func(x *beam.X) bool {
+		if i == len(rows) {
+			return false
+		}
+		*x = rows[i]
+		i++
+		return true
+	}
+	if err := stage.ProcessElement(ctx, 0, iter, func(name string) { names = append(names, name) }); err != nil {
+		t.Fatalf("stage failed: %v", err)
+	}
+	if len(names) != 1 || !strings.HasPrefix(names[0], "beam-3/part-00000-") {
+		t.Fatalf("stage emitted %v, want one file in beam-3", names)
+	}
+
+	fs := memfs.New(ctx)
+	memfs.Write("memfs://stage/beam-3/unload_0.json", []byte(`{"ITEM_ID":"c","PRICE":3}`+"\n"))
+	files, err := fs.List(ctx, "memfs://stage/beam-3/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := &readFileFn{Type: beam.EncodedType{T: reflect.TypeOf(sale{})}}
+	var got []sale
+	for _, file := range files {
+		if err := read.ProcessElement(ctx, file, func(x beam.X) { got = append(got, x.(sale)) }); err != nil {
+			t.Fatalf("read of %v failed: %v", file, err)
+		}
+	}
+	exp := []sale{{"a", 1.5}, {"b", 2}, {"c", 3}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("read %v, want %v", got, exp)
+	}
+}
+
+// TestStageBundle tests that each bundle is staged as a single file, which
+// is emitted when the bundle finishes.
+func TestStageBundle(t *testing.T) {
+	ctx := context.Background()
+	stage := &stageBundleFn{stageFn: stageFn{Stage: "@load", Location: "memfs://bundles/", Prefix: "beam-4"}}
+
+	var names []string
+	emit := func(name string) { names = append(names, name) }
+	for _, rows := range [][]sale{{{"a", 1}, {"b", 2}}, nil, {{"c", 3}}} {
+		for _, row := range rows {
+			if err := stage.ProcessElement(ctx, row, emit); err != nil {
+				t.Fatalf("stage failed: %v", err)
+			}
+		}
+		if err := stage.FinishBundle(ctx, emit); err != nil {
+			t.Fatalf("stage failed: %v", err)
+		}
+	}
+	if len(names) != 2 {
+		t.Fatalf("stage emitted %v, want a file for each non-empty bundle", names)
+	}
+
+	fs := memfs.New(ctx)
+	data, err := filesystem.Read(ctx, fs, "memfs://bundles/"+names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "{\"item_id\":\"a\",\"price\":1}\n{\"item_id\":\"b\",\"price\":2}\n"; string(data) != exp {
+		t.Errorf("staged %q, want %q", data, exp)
+	}
+}
+
+// TestStageBundleAfterFailure tests that a bundle that follows a failed one
+// on the same instance does not stage the rows of the failed bundle.
+func TestStageBundleAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	stage := &stageBundleFn{stageFn: stageFn{Stage: "@load", Location: "memfs://failed-bundles/", Prefix: "beam-5"}}
+
+	var names []string
+	emit := func(name string) { names = append(names, name) }
+	stage.StartBundle()
+	if err := stage.ProcessElement(ctx, sale{"a", 1}, emit); err != nil {
+		t.Fatalf("stage failed: %v", err)
+	}
+	// The bundle fails before it finishes.
+
+	stage.StartBundle()
+	if err := stage.ProcessElement(ctx, sale{"b", 2}, emit); err != nil {
+		t.Fatalf("stage failed: %v", err)
+	}
+	if err := stage.FinishBundle(ctx, emit); err != nil {
+		t.Fatalf("stage failed: %v", err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("stage emitted %v, want one file", names)
+	}
+
+	data, err := filesystem.Read(ctx, memfs.New(ctx), "memfs://failed-bundles/"+names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "{\"item_id\":\"b\",\"price\":2}\n"; string(data) != exp {
+		t.Errorf("staged %q, want %q", data, exp)
+	}
+}
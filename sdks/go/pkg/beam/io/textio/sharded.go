@@ -0,0 +1,326 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textio
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+// WriteSharded writes a PCollection<string> to a set of files as separate
+// lines. The files are named by the prefix, the shard number, the number of
+// shards and the suffix, such as out-00001-of-00004.txt for the prefix "out"
+// and suffix ".txt".
+//
+// If numShards is positive, lines are spread over exactly that many files.
+// If it is zero, the number of files is determined by the runner: each
+// bundle is written to its own file, so the output follows the parallelism
+// the runner picked rather than a fixed number that either bottlenecks a
+// large write or fragments a small one. For example:
+//
+//    textio.WriteSharded(s, "gs://bucket/out/lines", ".txt", 0, lines)
+//
+// Shards are written to a temporary directory next to the output and renamed
+// once all are written, so the files appear together. A retried rename skips
+// the shards that were already renamed. Only the global window is supported,
+// and other windows fail the pipeline; use WriteWindowed for windowed
+// collections.
+func WriteSharded(s beam.Scope, prefix, suffix string, numShards int, col beam.PCollection) {
+	s = s.Scope("textio.WriteSharded")
+
+	filesystem.ValidateScheme(prefix)
+	if numShards < 0 {
+		panic(fmt.Sprintf("invalid number of shards: %v", numShards))
+	}
+	id, err := uniqueID()
+	if err != nil {
+		panic(errors.Wrap(err, "failed to generate temporary directory"))
+	}
+	tempDir := prefix[:strings.LastIndex(prefix, "/")+1] + ".temp-beam-" + id + "/"
+
+	var temps beam.PCollection
+	if numShards == 0 {
+		temps = beam.ParDo(s, &writeBundleFn{TempDir: tempDir}, col)
+	} else {
+		keyed := beam.ParDo(s, &shardFn{Shards: numShards}, col)
+		temps = beam.ParDo(s, &writeShardFn{TempDir: tempDir}, beam.GroupByKey(s, keyed))
+	}
+	fn := &finalizeShardsFn{Prefix: prefix, Suffix: suffix, Shards: numShards, TempDir: tempDir}
+	beam.ParDo0(s, fn, beam.GroupByKey(s, beam.AddFixedKey(s, temps)))
+}
+
+// shardFn assigns lines to shards round-robin, starting at a random shard
+// in each bundle so that small bundles do not all fill the first shards.
+type shardFn struct {
+	// Shards is the number of shards.
+	Shards int `json:"shards"`
+
+	next int
+}
+
+func (f *shardFn) StartBundle() {
+	f.next = rand.Intn(f.Shards)
+}
+
+func (f *shardFn) ProcessElement(w beam.Window, line string) (int, string, error) {
+	if err := checkGlobal(w); err != nil {
+		return 0, "", err
+	}
+	shard := f.next
+	f.next = (f.next + 1) % f.Shards
+	return shard, line, nil
+}
+
+// checkGlobal returns an error if the window is not the global window, since
+// shards are named by their number alone.
+func checkGlobal(w beam.Window) error {
+	if _, ok := w.(window.GlobalWindow); !ok {
+		return errors.Errorf("WriteSharded supports only the global window, not %v; use WriteWindowed", w)
+	}
+	return nil
+}
+
+// writeShardFn writes the lines of a shard to a temporary file and emits
+// its name, which records the shard number.
+type writeShardFn struct {
+	// TempDir is the temporary directory, ending in a slash.
+	TempDir string `json:"temp_dir"`
+}
+
+func (f *writeShardFn) ProcessElement(ctx context.Context, shard int, lines func(*string) bool, emit func(string)) error {
+	id, err := uniqueID()
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("%vshard-%05d-%v", f.TempDir, shard, id)
+
+	fs, err := filesystem.New(ctx, filename)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	if err := writeLines(ctx, fs, filename, lines); err != nil {
+		return err
+	}
+	emit(filename)
+	return nil
+}
+
+// writeBundleFn writes the lines of each bundle to a temporary file and,
+// when the bundle finishes, emits its name.
+type writeBundleFn struct {
+	// TempDir is the temporary directory, ending in a slash.
+	TempDir string `json:"temp_dir"`
+
+	filename string
+	fs       filesystem.Interface
+	fd       io.WriteCloser
+	buf      *bufio.Writer
+}
+
+// StartBundle closes and drops the file of a failed bundle, if any, since the
+// instance may be reused after a failure. The file is never committed, because
+// its name is not emitted, and is removed with the temporary directory.
+func (f *writeBundleFn) StartBundle() {
+	if f.buf == nil {
+		return
+	}
+	f.fd.Close()
+	f.fs.Close()
+	f.filename, f.fs, f.fd, f.buf = "", nil, nil, nil
+}
+
+func (f *writeBundleFn) ProcessElement(ctx context.Context, w beam.Window, line string, _ func(string)) error {
+	if err := checkGlobal(w); err != nil {
+		return err
+	}
+	if f.buf == nil {
+		id, err := uniqueID()
+		if err != nil {
+			return err
+		}
+		f.filename = f.TempDir + "bundle-" + id
+		if f.fs, err = filesystem.New(ctx, f.filename); err != nil {
+			return err
+		}
+		if f.fd, err = f.fs.OpenWrite(ctx, f.filename); err != nil {
+			f.fs.Close()
+			return err
+		}
+		f.buf = bufio.NewWriterSize(f.fd, 1<<20) // use 1MB buffer
+	}
+
+	if _, err := f.buf.WriteString(line); err != nil {
+		return err
+	}
+	return f.buf.WriteByte('\n')
+}
+
+func (f *writeBundleFn) FinishBundle(ctx context.Context, emit func(string)) error {
+	if f.buf == nil {
+		return nil // empty bundle
+	}
+	defer f.fs.Close()
+	buf, fd := f.buf, f.fd
+	f.buf, f.fd = nil, nil
+
+	if err := buf.Flush(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	emit(f.filename)
+	return nil
+}
+
+// finalizeShardsFn renames the temporary files to their final names, once
+// all are written, and removes the temporary directory. If it is retried, the
+// files that were already renamed are skipped.
+type finalizeShardsFn struct {
+	// Prefix and Suffix are the parts of the final names.
+	Prefix string `json:"prefix"`
+	Suffix string `json:"suffix"`
+	// Shards is the number of shards, or zero if runner-determined.
+	Shards int `json:"shards"`
+	// TempDir is the temporary directory, ending in a slash.
+	TempDir string `json:"temp_dir"`
+}
+
+func (f *finalizeShardsFn) ProcessElement(ctx context.Context, _ int, temps func(*string) bool) error {
+	var files []string
+	var temp string
+	for temps(&temp) {
+		files = append(files, temp)
+	}
+	sort.Strings(files)
+
+	fs, err := filesystem.New(ctx, f.Prefix)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	n := f.Shards
+	if n == 0 {
+		n = len(files)
+	}
+	written := make(map[int]bool)
+	for i, file := range files {
+		shard := i
+		if f.Shards != 0 {
+			if shard, err = parseShard(f.TempDir, file); err != nil {
+				return err
+			}
+		}
+		filename := f.filename(shard, n)
+		done, err := renamed(ctx, fs, file, filename)
+		if err != nil {
+			return err
+		}
+		if done {
+			log.Infof(ctx, "Skipping %v, which was already renamed to %v", file, filename)
+			written[shard] = true
+			continue
+		}
+		if err := filesystem.Rename(ctx, fs, file, filename); err != nil {
+			return errors.Wrapf(err, "failed to commit %v", file)
+		}
+		written[shard] = true
+	}
+	// Shards that received no lines are written as empty files, so that
+	// the set of files is complete.
+	for shard := 0; shard < f.Shards; shard++ {
+		if !written[shard] {
+			if err := filesystem.Write(ctx, fs, f.filename(shard, n), nil); err != nil {
+				return err
+			}
+		}
+	}
+	log.Infof(ctx, "Wrote %v files to %v", n, f.Prefix)
+
+	// Remove files left behind by retried bundles.
+	if _, ok := fs.(filesystem.Remover); ok {
+		leftover, err := fs.List(ctx, f.TempDir+"*")
+		if err != nil {
+			log.Warnf(ctx, "Failed to list temporary files in %v: %v", f.TempDir, err)
+			return nil
+		}
+		for _, file := range leftover {
+			if !strings.HasPrefix(file, f.TempDir) {
+				continue
+			}
+			if err := filesystem.Remove(ctx, fs, file); err != nil {
+				log.Warnf(ctx, "Failed to remove temporary file %v: %v", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renamed returns whether the temporary file was already renamed to the final
+// name by an earlier attempt, that is, whether the final file exists and the
+// temporary one does not.
+func renamed(ctx context.Context, fs filesystem.Interface, temp, filename string) (bool, error) {
+	if exists(ctx, fs, temp) {
+		return false, nil
+	}
+	if !exists(ctx, fs, filename) {
+		return false, errors.Errorf("temporary file %v is missing", temp)
+	}
+	return true, nil
+}
+
+// exists returns whether the file exists, that is, whether it can be opened.
+// List is not used, because some file systems, such as GCS, return a name
+// that is not a pattern without checking that the file exists.
+func exists(ctx context.Context, fs filesystem.Interface, filename string) bool {
+	fd, err := fs.OpenRead(ctx, filename)
+	if err != nil {
+		return false
+	}
+	fd.Close()
+	return true
+}
+
+func (f *finalizeShardsFn) filename(shard, n int) string {
+	return fmt.Sprintf("%v-%05d-of-%05d%v", f.Prefix, shard, n, f.Suffix)
+}
+
+// parseShard returns the shard number of a temporary file written by
+// writeShardFn.
+func parseShard(tempDir, filename string) (int, error) {
+	name := strings.TrimPrefix(filename, tempDir)
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) != 3 || parts[0] != "shard" {
+		return 0, errors.Errorf("invalid temporary file %v", filename)
+	}
+	return strconv.Atoi(parts[1])
+}
@@ -19,19 +19,35 @@ package textio
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
 	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
 	"github.com/apache/beam/sdks/go/pkg/beam/log"
+	"github.com/apache/beam/sdks/go/pkg/beam/options/template"
 )
 
 func init() {
+	beam.RegisterType(reflect.TypeOf((*filesystem.Metadata)(nil)).Elem())
 	beam.RegisterType(reflect.TypeOf((*writeFileFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*writeWindowedFileFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*shardFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*writeShardFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*writeBundleFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*finalizeShardsFn)(nil)).Elem())
 	beam.RegisterFunction(readFn)
 	beam.RegisterFunction(expandFn)
+	beam.RegisterFunction(matchFilenameFn)
+	beam.RegisterFunction(template.Expand)
 }
 
 // Read reads a set of file and returns the lines as a PCollection<string>. The
@@ -43,6 +59,16 @@ func Read(s beam.Scope, glob string) beam.PCollection {
 	return read(s, beam.Create(s, glob))
 }
 
+// ReadTemplate is the same as Read, except that the glob refers to template
+// parameters, such as "gs://bucket/${date}/*.txt", which are substituted
+// with template.Expand when the pipeline runs.
+func ReadTemplate(s beam.Scope, glob string) beam.PCollection {
+	s = s.Scope("textio.ReadTemplate")
+
+	filesystem.ValidateScheme(glob)
+	return read(s, beam.ParDo(s, template.Expand, beam.Create(s, glob)))
+}
+
 // ReadAll expands and reads the filename given as globs by the incoming
 // PCollection<string>. It returns the lines of all files as a single
 // PCollection<string>. The newlines are not part of the lines.
@@ -52,6 +78,21 @@ func ReadAll(s beam.Scope, col beam.PCollection) beam.PCollection {
 	return read(s, col)
 }
 
+// ReadMatches reads the files described by the incoming
+// PCollection<filesystem.Metadata>, such as produced by a file notification
+// source. It returns the lines of all files as a single PCollection<string>.
+// The newlines are not part of the lines.
+func ReadMatches(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("textio.ReadMatches")
+
+	files := beam.ParDo(s, matchFilenameFn, col)
+	return beam.ParDo(s, readFn, files)
+}
+
+func matchFilenameFn(m filesystem.Metadata) string {
+	return m.Filename
+}
+
 func read(s beam.Scope, col beam.PCollection) beam.PCollection {
 	files := beam.ParDo(s, expandFn, col)
 	return beam.ParDo(s, readFn, files)
@@ -61,7 +102,6 @@ func expandFn(ctx context.Context, glob string, emit func(string)) error {
 	if strings.TrimSpace(glob) == "" {
 		return nil // ignore empty string elements here
 	}
-
 	fs, err := filesystem.New(ctx, glob)
 	if err != nil {
 		return err
@@ -100,11 +140,9 @@ func readFn(ctx context.Context, filename string, emit func(string)) error {
 	return scanner.Err()
 }
 
-// TODO(herohde) 7/12/2017: extend Write to write to a series of files
-// as well as allow sharding.
-
 // Write writes a PCollection<string> to a file as separate lines. The
-// writer add a newline after each element.
+// writer add a newline after each element. Use WriteSharded to write to a
+// set of files in parallel.
 func Write(s beam.Scope, filename string, col beam.PCollection) {
 	s = s.Scope("textio.Write")
 
@@ -132,13 +170,150 @@ func (w *writeFileFn) ProcessElement(ctx context.Context, _ int, lines func(*str
 	}
 	defer fs.Close()
 
-	fd, err := fs.OpenWrite(ctx, w.Filename)
+	log.Infof(ctx, "Writing to %v", w.Filename)
+
+	return writeLines(ctx, fs, w.Filename, lines)
+}
+
+// WindowPlaceholder is replaced by the window bounds in the filename pattern
+// given to WriteWindowed.
+const WindowPlaceholder = "{window}"
+
+// WindowTimeLayout is the layout of window bounds in filenames written by
+// WriteWindowed.
+const WindowTimeLayout = "20060102T150405.000Z"
+
+// WriteWindowed writes a windowed PCollection<string> to one file per window
+// as separate lines. The filename of each window is given by the pattern,
+// in which WindowPlaceholder is replaced by the window bounds. For example:
+//
+//    windowed := beam.WindowInto(s, window.NewFixedWindows(time.Hour), lines)
+//    textio.WriteWindowed(s, "gs://bucket/out/lines-{window}.txt", windowed)
+//
+// writes files such as lines-20180301T100000.000Z-20180301T110000.000Z.txt.
+// The placeholder is replaced by "global" for the global window.
+//
+// Each file is first written under a temporary name in the same directory
+// and then renamed to its final name, if the file system is a
+// filesystem.Renamer. A window is thus either fully written or absent, and
+// a retried write replaces the file rather than corrupting it. Otherwise,
+// the file is written in place, as with Write.
+func WriteWindowed(s beam.Scope, pattern string, col beam.PCollection) {
+	s = s.Scope("textio.WriteWindowed")
+
+	filesystem.ValidateScheme(pattern)
+	if !strings.Contains(pattern, WindowPlaceholder) {
+		panic(fmt.Sprintf("filename pattern %v must contain %v", pattern, WindowPlaceholder))
+	}
+
+	// The GBK groups elements per window as well as by the fixed key, so
+	// each invocation sees all the lines of a single window.
+
+	pre := beam.AddFixedKey(s, col)
+	post := beam.GroupByKey(s, pre)
+	beam.ParDo0(s, &writeWindowedFileFn{Pattern: pattern}, post)
+}
+
+type writeWindowedFileFn struct {
+	Pattern string `json:"pattern"`
+}
+
+func (w *writeWindowedFileFn) ProcessElement(ctx context.Context, win beam.Window, _ int, lines func(*string) bool) error {
+	bounds, err := formatWindow(win)
 	if err != nil {
 		return err
 	}
-	buf := bufio.NewWriterSize(fd, 1<<20) // use 1MB buffer
+	filename := strings.Replace(w.Pattern, WindowPlaceholder, bounds, -1)
 
-	log.Infof(ctx, "Writing to %v", w.Filename)
+	fs, err := filesystem.New(ctx, filename)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+	return writeWindow(ctx, fs, filename, lines)
+}
+
+// writeWindow writes the lines of a window to the file, via a temporary file
+// if the file system is a filesystem.Renamer. The temporary file is removed
+// if the write fails.
+func writeWindow(ctx context.Context, fs filesystem.Interface, filename string, lines func(*string) bool) error {
+	renamer, ok := fs.(filesystem.Renamer)
+	if !ok {
+		log.Infof(ctx, "Writing to %v", filename)
+		return writeLines(ctx, fs, filename, lines)
+	}
+
+	tmp, err := tempFilename(filename)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "Writing to %v via %v", filename, tmp)
+
+	if err := writeLines(ctx, fs, tmp, lines); err != nil {
+		removeTemp(ctx, fs, tmp)
+		return err
+	}
+	if err := renamer.Rename(ctx, tmp, filename); err != nil {
+		removeTemp(ctx, fs, tmp)
+		return err
+	}
+	return nil
+}
+
+// removeTemp removes the temporary file of a failed write, if the file
+// system supports it.
+func removeTemp(ctx context.Context, fs filesystem.Interface, tmp string) {
+	if _, ok := fs.(filesystem.Remover); !ok {
+		return
+	}
+	if err := filesystem.Remove(ctx, fs, tmp); err != nil {
+		log.Warnf(ctx, "Failed to remove temporary file %v: %v", tmp, err)
+	}
+}
+
+// formatWindow returns the bounds of the given window for use in a filename.
+func formatWindow(win beam.Window) (string, error) {
+	switch w := win.(type) {
+	case window.IntervalWindow:
+		return formatTime(w.Start) + "-" + formatTime(w.End), nil
+	case window.GlobalWindow:
+		return "global", nil
+	default:
+		return "", errors.Errorf("unexpected window type %T for %v", win, win)
+	}
+}
+
+func formatTime(t mtime.Time) string {
+	return time.Unix(0, t.Milliseconds()*int64(time.Millisecond)).UTC().Format(WindowTimeLayout)
+}
+
+// tempFilename returns a unique hidden name in the same directory as the
+// given filename, so that the committing rename stays within a directory
+// or bucket.
+func tempFilename(filename string) (string, error) {
+	id, err := uniqueID()
+	if err != nil {
+		return "", err
+	}
+	i := strings.LastIndex(filename, "/") + 1
+	return filename[:i] + ".temp-beam-" + id + "-" + filename[i:], nil
+}
+
+func uniqueID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// writeLines writes the lines to the given file, each followed by a newline.
+func writeLines(ctx context.Context, fs filesystem.Interface, filename string, lines func(*string) bool) error {
+	fd, err := fs.OpenWrite(ctx, filename)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriterSize(fd, 1<<20) // use 1MB buffer
 
 	var line string
 	for lines(&line) {
@@ -0,0 +1,378 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textio
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	_ "github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/local"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/memfs"
+	"github.com/apache/beam/sdks/go/pkg/beam/options/template"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+// The year parameter is referenced by TestReadTemplate.
+var _ = template.NewString("textio_test_year", "2019", "Test year.")
+
+func init() {
+	beam.RegisterFunction(lineTime)
+}
+
+// lineTime returns the timestamp of a "<seconds>:<text>" line.
+func lineTime(line string) beam.EventTime {
+	sec, _ := strconv.Atoi(strings.SplitN(line, ":", 2)[0])
+	return mtime.FromMilliseconds(int64(sec) * 1000)
+}
+
+// TestWriteWindowed tests that each window is committed to its own file.
+func TestWriteWindowed(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	lines := beam.Create(s, "1:a", "2:b", "61:c")
+	stamped := beam.WithTimestamps(s, lineTime, lines)
+	windowed := beam.WindowInto(s, window.NewFixedWindows(time.Minute), stamped)
+	WriteWindowed(s, "memfs://windowed/out-{window}.txt", windowed)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("WriteWindowed failed: %v", err)
+	}
+
+	ctx := context.Background()
+	fs := memfs.New(ctx)
+
+	tests := []struct {
+		filename string
+		lines    []string
+	}{
+		{"memfs://windowed/out-19700101T000000.000Z-19700101T000100.000Z.txt", []string{"1:a", "2:b"}},
+		{"memfs://windowed/out-19700101T000100.000Z-19700101T000200.000Z.txt", []string{"61:c"}},
+	}
+	for _, test := range tests {
+		data, err := filesystem.Read(ctx, fs, test.filename)
+		if err != nil {
+			t.Errorf("Read(%v) failed: %v", test.filename, err)
+			continue
+		}
+		got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(test.lines, ",") {
+			t.Errorf("Read(%v) = %v, want %v", test.filename, got, test.lines)
+		}
+	}
+
+	files, err := fs.List(ctx, "memfs://windowed/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.Contains(f, ".temp-beam-") {
+			t.Errorf("temporary file %v was not committed", f)
+		}
+	}
+}
+
+func TestFormatWindow(t *testing.T) {
+	tests := []struct {
+		w   beam.Window
+		exp string
+	}{
+		{window.GlobalWindow{}, "global"},
+		{window.IntervalWindow{Start: 0, End: 3600000}, "19700101T000000.000Z-19700101T010000.000Z"},
+	}
+	for _, test := range tests {
+		got, err := formatWindow(test.w)
+		if err != nil {
+			t.Errorf("formatWindow(%v) failed: %v", test.w, err)
+		}
+		if got != test.exp {
+			t.Errorf("formatWindow(%v) = %v, want %v", test.w, got, test.exp)
+		}
+	}
+}
+
+func TestReadMatches(t *testing.T) {
+	ctx := context.Background()
+	fs := memfs.New(ctx)
+	if err := filesystem.Write(ctx, fs, "memfs://matches/a.txt", []byte("a1\na2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := filesystem.Write(ctx, fs, "memfs://matches/b.txt", []byte("b1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	matches := beam.Create(s,
+		filesystem.Metadata{Filename: "memfs://matches/a.txt", Size: 6},
+		filesystem.Metadata{Filename: "memfs://matches/b.txt", Size: 3})
+	lines := ReadMatches(s, matches)
+	passert.Equals(s, lines, "a1", "a2", "b1")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("ReadMatches failed: %v", err)
+	}
+}
+
+func TestWriteSharded(t *testing.T) {
+	tests := []struct {
+		name   string
+		shards int
+	}{
+		{"fixed", 3},
+		{"runner", 0},
+	}
+	for _, test := range tests {
+		prefix := "memfs://sharded/" + test.name + "/out"
+		p, s := beam.NewPipelineWithRoot()
+		lines := beam.Create(s, "a", "b", "c", "d", "e")
+		WriteSharded(s, prefix, ".txt", test.shards, lines)
+		if err := ptest.Run(p); err != nil {
+			t.Fatalf("WriteSharded(%v) failed: %v", test.name, err)
+		}
+
+		ctx := context.Background()
+		fs := memfs.New(ctx)
+		all, err := fs.List(ctx, "memfs://sharded/"+test.name+"/*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for _, f := range all {
+			if strings.HasPrefix(f, "memfs://sharded/"+test.name+"/") {
+				files = append(files, f) // memfs lists all files
+			}
+		}
+		if len(files) == 0 || test.shards != 0 && len(files) != test.shards {
+			t.Errorf("WriteSharded(%v) wrote %v, want %v files", test.name, files, test.shards)
+		}
+		var got []string
+		for _, f := range files {
+			if !strings.HasPrefix(f, prefix+"-") || !strings.HasSuffix(f, "-of-0000"+strconv.Itoa(len(files))+".txt") {
+				t.Errorf("WriteSharded(%v) wrote unexpected file %v", test.name, f)
+			}
+			data, err := filesystem.Read(ctx, fs, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) > 0 {
+				got = append(got, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != "a,b,c,d,e" {
+			t.Errorf("WriteSharded(%v) wrote lines %v, want a,b,c,d,e", test.name, got)
+		}
+	}
+}
+
+// TestFinalizeShardsRetry tests that a retried rename skips the shards that
+// were already renamed.
+func TestFinalizeShardsRetry(t *testing.T) {
+	ctx := context.Background()
+	tempDir := "memfs://retry/.temp-beam-test/"
+	memfs.Write(tempDir+"shard-00000-a", []byte("a\n"))
+	memfs.Write(tempDir+"shard-00001-b", []byte("b\n"))
+	temps := []string{tempDir + "shard-00000-a", tempDir + "shard-00001-b"}
+
+	fn := &finalizeShardsFn{Prefix: "memfs://retry/out", Suffix: ".txt", Shards: 2, TempDir: tempDir}
+	for attempt := 0; attempt < 2; attempt++ {
+		i := 0
+		iter := func(temp *string) bool {
+			if i == len(temps) {
+				return false
+			}
+			*temp = temps[i]
+			i++
+			return true
+		}
+		if err := fn.ProcessElement(ctx, 0, iter); err != nil {
+			t.Fatalf("attempt %v failed: %v", attempt, err)
+		}
+	}
+
+	fs := memfs.New(ctx)
+	for shard, want := range []string{"a\n", "b\n"} {
+		data, err := filesystem.Read(ctx, fs, fn.filename(shard, 2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("shard %v = %q, want %q", shard, data, want)
+		}
+	}
+}
+
+// TestWriteShardedWindowed tests that windows other than the global window
+// are rejected.
+func TestWriteShardedWindowed(t *testing.T) {
+	for _, shards := range []int{0, 2} {
+		p, s := beam.NewPipelineWithRoot()
+		lines := beam.WindowInto(s, window.NewFixedWindows(time.Minute), beam.Create(s, "a", "b"))
+		WriteSharded(s, "memfs://windowed-sharded/out", ".txt", shards, lines)
+		if err := ptest.Run(p); err == nil || !strings.Contains(err.Error(), "global window") {
+			t.Errorf("WriteSharded(%v shards) with fixed windows = %v, want global window error", shards, err)
+		}
+	}
+}
+
+// TestReadTemplate tests that template parameters are only substituted by
+// ReadTemplate.
+func TestReadTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "textio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"2019", "${textio_test_year}"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name, "lines.txt"), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	glob := filepath.Join(dir, "${textio_test_year}", "*.txt")
+	passert.Equals(s, ReadTemplate(s, glob), "2019")
+	passert.Equals(s, Read(s, glob), "${textio_test_year}")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("ReadTemplate failed: %v", err)
+	}
+}
+
+// listAllFS is a file system that, like GCS, lists a name that is not a
+// pattern without checking that the file exists.
+type listAllFS struct {
+	filesystem.Interface
+}
+
+func (listAllFS) List(ctx context.Context, glob string) ([]string, error) {
+	return []string{glob}, nil
+}
+
+// TestRenamed tests that renamed checks whether the files exist, rather than
+// whether they are listed.
+func TestRenamed(t *testing.T) {
+	ctx := context.Background()
+	fs := listAllFS{memfs.New(ctx)}
+	memfs.Write("memfs://renamed/temp", []byte("a\n"))
+	memfs.Write("memfs://renamed/out", []byte("b\n"))
+
+	tests := []struct {
+		temp, filename string
+		want           bool
+		err            bool
+	}{
+		{"memfs://renamed/temp", "memfs://renamed/out", false, false},
+		{"memfs://renamed/temp", "memfs://renamed/missing", false, false},
+		{"memfs://renamed/missing", "memfs://renamed/out", true, false},
+		{"memfs://renamed/missing", "memfs://renamed/missing2", false, true},
+	}
+	for _, test := range tests {
+		got, err := renamed(ctx, fs, test.temp, test.filename)
+		if got != test.want || (err != nil) != test.err {
+			t.Errorf("renamed(%v, %v) = %v, %v, want %v, error %v", test.temp, test.filename, got, err, test.want, test.err)
+		}
+	}
+}
+
+// TestWriteBundleAfterFailure tests that a bundle that follows a failed one
+// on the same instance does not write the lines of the failed bundle.
+func TestWriteBundleAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	fn := &writeBundleFn{TempDir: "memfs://failed-bundle/.temp-beam-test/"}
+
+	var names []string
+	emit := func(name string) { names = append(names, name) }
+	fn.StartBundle()
+	if err := fn.ProcessElement(ctx, window.GlobalWindow{}, "a", emit); err != nil {
+		t.Fatal(err)
+	}
+	// The bundle fails before it finishes.
+
+	fn.StartBundle()
+	if err := fn.ProcessElement(ctx, window.GlobalWindow{}, "b", emit); err != nil {
+		t.Fatal(err)
+	}
+	if err := fn.FinishBundle(ctx, emit); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("writeBundleFn emitted %v, want one file", names)
+	}
+	data, err := filesystem.Read(ctx, memfs.New(ctx), names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "b\n" {
+		t.Errorf("writeBundleFn wrote %q, want %q", data, "b\n")
+	}
+}
+
+// failRenameFS is a memory file system whose renames fail.
+type failRenameFS struct {
+	filesystem.Interface
+}
+
+func (failRenameFS) Rename(ctx context.Context, oldpath, newpath string) error {
+	return errors.New("rename failed")
+}
+
+func (f failRenameFS) Remove(ctx context.Context, filename string) error {
+	return filesystem.Remove(ctx, f.Interface, filename)
+}
+
+// TestWriteWindowCleanup tests that the temporary file of a failed window
+// write is removed.
+func TestWriteWindowCleanup(t *testing.T) {
+	ctx := context.Background()
+	fs := failRenameFS{memfs.New(ctx)}
+
+	lines := []string{"a"}
+	err := writeWindow(ctx, fs, "memfs://cleanup/out.txt", func(line *string) bool {
+		if len(lines) == 0 {
+			return false
+		}
+		*line, lines = lines[0], lines[1:]
+		return true
+	})
+	if err == nil {
+		t.Fatal("writeWindow succeeded, want the rename error")
+	}
+	files, err := fs.List(ctx, "memfs://cleanup/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file, "memfs://cleanup/") {
+			t.Errorf("writeWindow left %v behind", file)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upsertio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// Bigtable is a Bigtable table. The row key is the key formatted with
+// fmt.Sprint, or the key itself if it is a string or []byte. Each field of
+// the row is a column of Family. Strings and []byte values are stored as is
+// and other values as JSON.
+//
+// The cells are timestamped with the version of the change, in
+// milliseconds, so that a mutation only replaces or deletes the cells of
+// earlier versions. If the versions are log sequence numbers rather than
+// source timestamps, the garbage collection policy of Family must not
+// expire cells by age. A row is read with a filter of the latest cell of
+// each column.
+type Bigtable struct {
+	Project  string `json:"project"`
+	Instance string `json:"instance"`
+	Table    string `json:"table"`
+	Family   string `json:"family"`
+	// Endpoint is the address of a Bigtable emulator, if any. The
+	// connection is then unauthenticated and insecure.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	client  *bigtable.Client
+	table   *bigtable.Table
+	columns []string
+	index   []int
+}
+
+func (b *Bigtable) Open(ctx context.Context, key, row reflect.Type) error {
+	if b.Family == "" {
+		return errors.New("no column family")
+	}
+	var err error
+	if b.columns, b.index, err = columns(row); err != nil {
+		return err
+	}
+
+	var opts []option.ClientOption
+	if b.Endpoint != "" {
+		opts = append(opts,
+			option.WithEndpoint(b.Endpoint),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()))
+	}
+	if b.client, err = bigtable.NewClient(ctx, b.Project, b.Instance, opts...); err != nil {
+		return errors.Wrapf(err, "failed to create Bigtable client for %v", b.Instance)
+	}
+	b.table = b.client.Open(b.Table)
+	return nil
+}
+
+func (b *Bigtable) Apply(ctx context.Context, mutations []Mutation) error {
+	keys, muts, err := b.mutations(mutations)
+	if err != nil {
+		return err
+	}
+	errs, err := b.table.ApplyBulk(ctx, keys, muts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to %v", b.Table)
+	}
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrapf(err, "failed to write row %v to %v", keys[i], b.Table)
+		}
+	}
+	return nil
+}
+
+func (b *Bigtable) Close() error {
+	if b.client == nil {
+		return nil
+	}
+	return b.client.Close()
+}
+
+// mutations returns the row keys and Bigtable mutations of the mutations.
+func (b *Bigtable) mutations(mutations []Mutation) ([]string, []*bigtable.Mutation, error) {
+	var keys []string
+	var muts []*bigtable.Mutation
+	for _, m := range mutations {
+		keys = append(keys, rowKey(m.Key))
+
+		// Cells of earlier versions are deleted, and cells of the same
+		// version replaced, so that a replayed mutation has no effect.
+		ts := bigtable.Timestamp(m.Version * 1000)
+		mut := bigtable.NewMutation()
+		if m.Row == nil {
+			for _, c := range b.columns {
+				mut.DeleteTimestampRange(b.Family, c, 0, ts+1000)
+			}
+			muts = append(muts, mut)
+			continue
+		}
+		v := reflect.ValueOf(m.Row)
+		for i, c := range b.columns {
+			data, err := cellValue(v.Field(b.index[i]).Interface())
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to encode column %v", c)
+			}
+			mut.DeleteTimestampRange(b.Family, c, 0, ts)
+			mut.Set(b.Family, c, ts, data)
+		}
+		muts = append(muts, mut)
+	}
+	return keys, muts, nil
+}
+
+func rowKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	default:
+		return fmt.Sprint(k)
+	}
+}
+
+func cellValue(v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case string:
+		return []byte(x), nil
+	case []byte:
+		return x, nil
+	default:
+		return json.Marshal(x)
+	}
+}
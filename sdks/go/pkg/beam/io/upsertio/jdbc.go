@@ -0,0 +1,247 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upsertio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// JDBC is a SQL database table, accessed with database/sql. The driver must
+// be imported by the pipeline binary.
+type JDBC struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+	// Table is the name of the table, which may be qualified by a schema,
+	// such as "shop.customers". The names of the table and columns are
+	// quoted in statements, so they are case-sensitive.
+	Table string `json:"table"`
+	// KeyColumns are the columns of the primary key. If the key type is a
+	// struct, its exported fields are the values of the columns, in order.
+	// Otherwise, the key is the value of the only column.
+	KeyColumns []string `json:"key_columns"`
+	// Dialect is the SQL dialect of the upsert statement: "postgres", also
+	// understood by SQLite and CockroachDB, or "mysql". If empty, "postgres"
+	// is used.
+	Dialect string `json:"dialect,omitempty"`
+	// VersionColumn, if set, is the non-null integer column that holds the
+	// version of each row. It is not a field of the row type: it is written along
+	// with the row, and rows are only replaced or deleted by mutations of
+	// later versions. Without it, a replayed change may overwrite a later
+	// one.
+	VersionColumn string `json:"version_column,omitempty"`
+
+	db      *sql.DB
+	columns []string
+	index   []int
+}
+
+func (j *JDBC) Open(ctx context.Context, key, row reflect.Type) error {
+	if len(j.KeyColumns) == 0 {
+		return errors.New("no key columns")
+	}
+	if key.Kind() == reflect.Struct && key.NumField() != len(j.KeyColumns) {
+		return errors.Errorf("key type %v does not match key columns %v", key, j.KeyColumns)
+	}
+	if key.Kind() != reflect.Struct && len(j.KeyColumns) != 1 {
+		return errors.Errorf("key type %v does not match key columns %v", key, j.KeyColumns)
+	}
+	switch j.Dialect {
+	case "", "postgres", "mysql":
+	default:
+		return errors.Errorf("unknown SQL dialect %v", j.Dialect)
+	}
+
+	var err error
+	if j.columns, j.index, err = columns(row); err != nil {
+		return err
+	}
+	for _, c := range j.columns {
+		if c == j.VersionColumn {
+			return errors.Errorf("version column %v is a column of row type %v", c, row)
+		}
+	}
+	if j.db, err = sql.Open(j.Driver, j.DSN); err != nil {
+		return errors.Wrapf(err, "failed to open database: %v", j.Driver)
+	}
+	return nil
+}
+
+func (j *JDBC) Apply(ctx context.Context, mutations []Mutation) error {
+	var upserts, deletes []Mutation
+	for _, m := range mutations {
+		if m.Row == nil {
+			deletes = append(deletes, m)
+		} else {
+			upserts = append(upserts, m)
+		}
+	}
+
+	tx, err := j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if len(upserts) > 0 {
+		q, args := j.upsert(upserts)
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "failed to upsert into %v", j.Table)
+		}
+	}
+	if len(deletes) > 0 {
+		q, args := j.delete(deletes)
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "failed to delete from %v", j.Table)
+		}
+	}
+	return tx.Commit()
+}
+
+func (j *JDBC) Close() error {
+	if j.db == nil {
+		return nil
+	}
+	return j.db.Close()
+}
+
+func (j *JDBC) placeholder(n int) string {
+	if j.Dialect == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%v", n)
+}
+
+// quote quotes the identifier for the dialect, so that names that are
+// keywords or contain special characters are taken as is.
+func (j *JDBC) quote(name string) string {
+	if j.Dialect == "mysql" {
+		return "`" + strings.Replace(name, "`", "``", -1) + "`"
+	}
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// quoteAll quotes the identifiers for the dialect.
+func (j *JDBC) quoteAll(names []string) []string {
+	ret := make([]string, len(names))
+	for i, name := range names {
+		ret[i] = j.quote(name)
+	}
+	return ret
+}
+
+// table returns the quoted table name, whose parts such as the schema are
+// separated by dots.
+func (j *JDBC) table() string {
+	return strings.Join(j.quoteAll(strings.Split(j.Table, ".")), ".")
+}
+
+// upsert returns the statement that inserts or replaces the rows, of earlier
+// versions if the table has a version column.
+func (j *JDBC) upsert(mutations []Mutation) (string, []interface{}) {
+	var args []interface{}
+	var values []string
+	for _, m := range mutations {
+		v := reflect.ValueOf(m.Row)
+		var ps []string
+		for _, i := range j.index {
+			args = append(args, v.Field(i).Interface())
+			ps = append(ps, j.placeholder(len(args)))
+		}
+		if j.VersionColumn != "" {
+			args = append(args, m.Version)
+			ps = append(ps, j.placeholder(len(args)))
+		}
+		values = append(values, "("+strings.Join(ps, ", ")+")")
+	}
+
+	isKey := make(map[string]bool)
+	for _, c := range j.KeyColumns {
+		isKey[c] = true
+	}
+	version := j.quote(j.VersionColumn)
+	var set []string
+	for _, name := range j.columns {
+		if isKey[name] {
+			continue
+		}
+		c := j.quote(name)
+		switch {
+		case j.Dialect == "mysql" && j.VersionColumn != "":
+			set = append(set, fmt.Sprintf("%v = IF(VALUES(%v) > %v, VALUES(%v), %v)", c, version, version, c, c))
+		case j.Dialect == "mysql":
+			set = append(set, fmt.Sprintf("%v = VALUES(%v)", c, c))
+		default:
+			set = append(set, fmt.Sprintf("%v = EXCLUDED.%v", c, c))
+		}
+	}
+
+	cols := j.quoteAll(j.columns)
+	if j.VersionColumn != "" {
+		cols = append(cols, version)
+	}
+	keys := strings.Join(j.quoteAll(j.KeyColumns), ", ")
+	q := fmt.Sprintf("INSERT INTO %v (%v) VALUES %v", j.table(), strings.Join(cols, ", "), strings.Join(values, ", "))
+	switch {
+	case j.Dialect == "mysql" && j.VersionColumn != "":
+		// MySQL assigns the columns in order, so the version is assigned
+		// last for the others to compare with the old version.
+		set = append(set, fmt.Sprintf("%v = GREATEST(%v, VALUES(%v))", version, version, version))
+		return q + " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", "), args
+	case j.VersionColumn != "":
+		set = append(set, fmt.Sprintf("%v = EXCLUDED.%v", version, version))
+		return q + fmt.Sprintf(" ON CONFLICT (%v) DO UPDATE SET %v WHERE %v.%v < EXCLUDED.%v",
+			keys, strings.Join(set, ", "), j.table(), version, version), args
+	case j.Dialect == "mysql" && len(set) == 0:
+		return "INSERT IGNORE" + strings.TrimPrefix(q, "INSERT"), args
+	case j.Dialect == "mysql":
+		return q + " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", "), args
+	case len(set) == 0:
+		return q + fmt.Sprintf(" ON CONFLICT (%v) DO NOTHING", keys), args
+	default:
+		return q + fmt.Sprintf(" ON CONFLICT (%v) DO UPDATE SET %v", keys, strings.Join(set, ", ")), args
+	}
+}
+
+// delete returns the statement that deletes the rows of the keys, of
+// earlier versions if the table has a version column.
+func (j *JDBC) delete(mutations []Mutation) (string, []interface{}) {
+	var args []interface{}
+	var conds []string
+	for _, m := range mutations {
+		v := reflect.ValueOf(m.Key)
+		var eqs []string
+		for i, c := range j.KeyColumns {
+			if v.Kind() == reflect.Struct {
+				args = append(args, v.Field(i).Interface())
+			} else {
+				args = append(args, m.Key)
+			}
+			eqs = append(eqs, fmt.Sprintf("%v = %v", j.quote(c), j.placeholder(len(args))))
+		}
+		if j.VersionColumn != "" {
+			args = append(args, m.Version)
+			eqs = append(eqs, fmt.Sprintf("%v < %v", j.quote(j.VersionColumn), j.placeholder(len(args))))
+		}
+		conds = append(conds, "("+strings.Join(eqs, " AND ")+")")
+	}
+	return fmt.Sprintf("DELETE FROM %v WHERE %v", j.table(), strings.Join(conds, " OR ")), args
+}
@@ -0,0 +1,229 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upsertio contains a sink that replicates change data capture (CDC)
+// streams to keyed stores, such as SQL databases and Bigtable. For example:
+//
+//    changes := cdc.ParseJSON(s, reflect.TypeOf(CustomerChange{}), messages)
+//    keyed := beam.ParDo(s, func(c CustomerChange) (int64, CustomerChange) {
+//        if c.After != nil {
+//            return c.After.ID, c
+//        }
+//        return c.Before.ID, c
+//    }, changes)
+//    upsertio.Write(s, &upsertio.JDBC{Driver: "postgres", DSN: dsn, Table: "customers",
+//        KeyColumns: []string{"id"}}, keyed, upsertio.Options{})
+//
+// keeps the customers table in sync with the source database.
+package upsertio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/cdc"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*applyFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*JDBC)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*Bigtable)(nil)).Elem())
+}
+
+// Mutation is an upsert or a delete of the row of a key.
+type Mutation struct {
+	Key interface{}
+	// Row is the new row of the key, or nil if the row is deleted.
+	Row interface{}
+	// Version is the version of the change: its log sequence number, if
+	// the source database has one, or else its source timestamp. Stores
+	// use it to skip mutations older than the row they hold, such as
+	// changes replayed by a retried bundle.
+	Version int64
+}
+
+// KeyedUpsert is a keyed store that changes are applied to. A KeyedUpsert
+// is encoded as JSON and sent to the workers, so its configuration must be
+// in exported fields and its type must be registered with beam.RegisterType.
+type KeyedUpsert interface {
+	// Open prepares the store for mutations of rows of the given key and
+	// row types.
+	Open(ctx context.Context, key, row reflect.Type) error
+	// Apply applies the mutations. There is at most one mutation per key,
+	// so they may be applied in any order. A mutation must not replace a
+	// row of a later version.
+	Apply(ctx context.Context, mutations []Mutation) error
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Options configure Write.
+type Options struct {
+	// BatchSize is the maximum number of mutations applied at once. If zero,
+	// 500 is used.
+	BatchSize int
+}
+
+// Write applies a PCollection<KV<K,T>> of changes to the store, where T is
+// a cdc change type with an After field of type *R, the row type, and K is
+// the key of the row. The changes of each key are ordered by cdc.LatestPerKey
+// and only the last change of each key in a pane is applied: as each change
+// holds the whole row, the store ends up in the same state as if the changes
+// were applied one by one. Creates and updates upsert the After row and
+// deletes delete the row of the key, unless the store holds a later version
+// of the row. Truncates cannot be applied per key and fail the pipeline.
+func Write(s beam.Scope, sink KeyedUpsert, col beam.PCollection, opts Options) {
+	s = s.Scope("upsertio.Write")
+
+	k, v := beam.ValidateKVType(col)
+	after, ok := v.Type().FieldByName("After")
+	if !ok || after.Type.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("change type %v has no After pointer field", v))
+	}
+	if opts.BatchSize < 0 {
+		panic(fmt.Sprintf("invalid batch size: %v", opts.BatchSize))
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = 500
+	}
+	data, err := json.Marshal(sink)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to encode sink %T", sink))
+	}
+
+	latest := cdc.LatestPerKey(s, col)
+	beam.ParDo0(s, &applyFn{
+		Sink:      beam.EncodedType{T: reflect.TypeOf(sink)},
+		Config:    string(data),
+		Key:       beam.EncodedType{T: k.Type()},
+		Row:       beam.EncodedType{T: after.Type.Elem()},
+		BatchSize: opts.BatchSize,
+	}, latest)
+}
+
+// applyFn applies the latest change of each key to the sink in batches.
+type applyFn struct {
+	// Sink is the type of the sink and Config its JSON encoding.
+	Sink   beam.EncodedType `json:"sink"`
+	Config string           `json:"config"`
+	// Key and Row are the key and row types.
+	Key       beam.EncodedType `json:"key"`
+	Row       beam.EncodedType `json:"row"`
+	BatchSize int              `json:"batch_size"`
+
+	sink  KeyedUpsert
+	batch []Mutation
+}
+
+func (f *applyFn) Setup(ctx context.Context) error {
+	t := f.Sink.T
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Ptr {
+		ptr = reflect.New(t.Elem())
+	}
+	if err := json.Unmarshal([]byte(f.Config), ptr.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode sink %v", t)
+	}
+	if t.Kind() == reflect.Ptr {
+		f.sink = ptr.Interface().(KeyedUpsert)
+	} else {
+		f.sink = ptr.Elem().Interface().(KeyedUpsert)
+	}
+	return f.sink.Open(ctx, f.Key.T, f.Row.T)
+}
+
+func (f *applyFn) StartBundle() {
+	f.batch = nil
+}
+
+func (f *applyFn) ProcessElement(ctx context.Context, key beam.X, change beam.Y) error {
+	md := change.(cdc.Change).ChangeMetadata()
+	version := md.Source.LSN
+	if version == 0 {
+		version = md.Source.TsMs
+	}
+	switch md.Op {
+	case cdc.Truncate:
+		return errors.Errorf("truncate of %v.%v cannot be applied: truncates are not supported", md.Source.Schema, md.Source.Table)
+	case cdc.Delete:
+		f.batch = append(f.batch, Mutation{Key: key, Version: version})
+	default:
+		after := reflect.ValueOf(change).FieldByName("After")
+		if after.IsNil() {
+			return errors.Errorf("change of key %v has no After row", key)
+		}
+		f.batch = append(f.batch, Mutation{Key: key, Row: after.Elem().Interface(), Version: version})
+	}
+	if len(f.batch) >= f.BatchSize {
+		return f.flush(ctx)
+	}
+	return nil
+}
+
+func (f *applyFn) FinishBundle(ctx context.Context) error {
+	return f.flush(ctx)
+}
+
+func (f *applyFn) Teardown() error {
+	if f.sink == nil {
+		return nil
+	}
+	return f.sink.Close()
+}
+
+func (f *applyFn) flush(ctx context.Context) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	if err := f.sink.Apply(ctx, f.batch); err != nil {
+		return errors.Wrapf(err, "failed to apply %v mutations", len(f.batch))
+	}
+	f.batch = nil
+	return nil
+}
+
+// columns returns the column names and field indices of the exported fields
+// of the struct type t. The name of a column is given by the column tag of
+// the field or, failing that, the field name.
+func columns(t reflect.Type) ([]string, []int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, errors.Errorf("row type %v is not a struct", t)
+	}
+	var names []string
+	var index []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("column")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+		index = append(index, i)
+	}
+	if len(names) == 0 {
+		return nil, nil, errors.Errorf("row type %v has no exported fields", t)
+	}
+	return names, index, nil
+}
@@ -0,0 +1,275 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upsertio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/cdc"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*memorySink)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*customerChange)(nil)).Elem())
+	beam.RegisterFunction(customerKey)
+}
+
+type customer struct {
+	ID    int64  `column:"id"`
+	Email string `column:"email"`
+}
+
+type customerChange struct {
+	cdc.Metadata
+	Before *customer
+	After  *customer
+}
+
+var (
+	mu       sync.Mutex
+	tables   = make(map[string]map[int64]customer)
+	versions = make(map[int64]int64)
+)
+
+// memorySink is a KeyedUpsert of rows in tables. It keeps the version of
+// each key, across tables.
+type memorySink struct {
+	Table string
+}
+
+func (m memorySink) Open(ctx context.Context, key, row reflect.Type) error {
+	return nil
+}
+
+func (m memorySink) Apply(ctx context.Context, mutations []Mutation) error {
+	mu.Lock()
+	defer mu.Unlock()
+	seen := make(map[int64]bool)
+	for _, mut := range mutations {
+		key := mut.Key.(int64)
+		if seen[key] {
+			return fmt.Errorf("duplicate key %v", key)
+		}
+		seen[key] = true
+		if mut.Version <= versions[key] {
+			continue
+		}
+		versions[key] = mut.Version
+		if mut.Row == nil {
+			delete(tables[m.Table], key)
+		} else {
+			tables[m.Table][key] = mut.Row.(customer)
+		}
+	}
+	return nil
+}
+
+func (m memorySink) Close() error {
+	return nil
+}
+
+func customerKey(c customerChange) (int64, customerChange) {
+	if c.After != nil {
+		return c.After.ID, c
+	}
+	return c.Before.ID, c
+}
+
+func change(op cdc.Op, ts int64, before, after *customer) customerChange {
+	return customerChange{Metadata: cdc.Metadata{Op: op, Source: cdc.Source{TsMs: ts}}, Before: before, After: after}
+}
+
+func TestWrite(t *testing.T) {
+	tables["customers"] = map[int64]customer{3: {3, "old@example.com"}}
+
+	p, s := beam.NewPipelineWithRoot()
+	changes := beam.ParDo(s, customerKey, beam.Create(s,
+		change(cdc.Update, 2, &customer{1, "a@example.com"}, &customer{1, "b@example.com"}),
+		change(cdc.Create, 1, nil, &customer{1, "a@example.com"}),
+		change(cdc.Create, 1, nil, &customer{2, "c@example.com"}),
+		change(cdc.Delete, 3, &customer{2, "c@example.com"}, nil),
+		change(cdc.Delete, 1, &customer{3, "old@example.com"}, nil)))
+	Write(s, memorySink{Table: "customers"}, changes, Options{BatchSize: 2})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	exp := map[int64]customer{1: {1, "b@example.com"}}
+	if !reflect.DeepEqual(tables["customers"], exp) {
+		t.Errorf("Write = %v, want %v", tables["customers"], exp)
+	}
+
+	// Replayed changes do not replace later versions.
+	p, s = beam.NewPipelineWithRoot()
+	changes = beam.ParDo(s, customerKey, beam.Create(s,
+		change(cdc.Create, 1, nil, &customer{1, "a@example.com"})))
+	Write(s, memorySink{Table: "customers"}, changes, Options{})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !reflect.DeepEqual(tables["customers"], exp) {
+		t.Errorf("Write of replayed changes = %v, want %v", tables["customers"], exp)
+	}
+}
+
+func TestWriteTruncate(t *testing.T) {
+	tables["truncated"] = map[int64]customer{}
+
+	p, s := beam.NewPipelineWithRoot()
+	changes := beam.ParDo(s, customerKey, beam.Create(s,
+		change(cdc.Truncate, 10, &customer{ID: 10}, nil)))
+	Write(s, memorySink{Table: "truncated"}, changes, Options{})
+
+	if err := ptest.Run(p); err == nil {
+		t.Errorf("Write of truncate succeeded, want error")
+	}
+}
+
+func TestJDBCStatements(t *testing.T) {
+	type key struct {
+		Region string
+		ID     int64
+	}
+	type row struct {
+		Region string `column:"region"`
+		ID     int64  `column:"id"`
+		Email  string `column:"email"`
+	}
+
+	tests := []struct {
+		dialect        string
+		upsert, delete string
+	}{
+		{
+			"",
+			`INSERT INTO "t" ("region", "id", "email") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("region", "id") DO UPDATE SET "email" = EXCLUDED."email"`,
+			`DELETE FROM "t" WHERE ("region" = $1 AND "id" = $2) OR ("region" = $3 AND "id" = $4)`,
+		},
+		{
+			"mysql",
+			"INSERT INTO `t` (`region`, `id`, `email`) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`)",
+			"DELETE FROM `t` WHERE (`region` = ? AND `id` = ?) OR (`region` = ? AND `id` = ?)",
+		},
+	}
+	for _, test := range tests {
+		j := &JDBC{Table: "t", KeyColumns: []string{"region", "id"}, Dialect: test.dialect}
+		var err error
+		if j.columns, j.index, err = columns(reflect.TypeOf(row{})); err != nil {
+			t.Fatal(err)
+		}
+
+		q, args := j.upsert([]Mutation{
+			{Key: key{"eu", 1}, Row: row{"eu", 1, "a"}},
+			{Key: key{"us", 2}, Row: row{"us", 2, "b"}},
+		})
+		if q != test.upsert {
+			t.Errorf("upsert(%v) = %v, want %v", test.dialect, q, test.upsert)
+		}
+		if got, _ := json.Marshal(args); string(got) != `["eu",1,"a","us",2,"b"]` {
+			t.Errorf("upsert(%v) arguments = %s", test.dialect, got)
+		}
+
+		q, args = j.delete([]Mutation{{Key: key{"eu", 1}}, {Key: key{"us", 2}}})
+		if q != test.delete {
+			t.Errorf("delete(%v) = %v, want %v", test.dialect, q, test.delete)
+		}
+		if got, _ := json.Marshal(args); string(got) != `["eu",1,"us",2]` {
+			t.Errorf("delete(%v) arguments = %s", test.dialect, got)
+		}
+	}
+}
+
+func TestJDBCVersionStatements(t *testing.T) {
+	tests := []struct {
+		dialect        string
+		upsert, delete string
+	}{
+		{
+			"",
+			`INSERT INTO "t" ("id", "email", "version") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "version" = EXCLUDED."version" WHERE "t"."version" < EXCLUDED."version"`,
+			`DELETE FROM "t" WHERE ("id" = $1 AND "version" < $2)`,
+		},
+		{
+			"mysql",
+			"INSERT INTO `t` (`id`, `email`, `version`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `email` = IF(VALUES(`version`) > `version`, VALUES(`email`), `email`), `version` = GREATEST(`version`, VALUES(`version`))",
+			"DELETE FROM `t` WHERE (`id` = ? AND `version` < ?)",
+		},
+	}
+	for _, test := range tests {
+		j := &JDBC{Table: "t", KeyColumns: []string{"id"}, Dialect: test.dialect, VersionColumn: "version"}
+		var err error
+		if j.columns, j.index, err = columns(reflect.TypeOf(customer{})); err != nil {
+			t.Fatal(err)
+		}
+
+		q, args := j.upsert([]Mutation{{Key: int64(1), Row: customer{1, "a"}, Version: 7}})
+		if q != test.upsert {
+			t.Errorf("upsert(%v) = %v, want %v", test.dialect, q, test.upsert)
+		}
+		if got, _ := json.Marshal(args); string(got) != `[1,"a",7]` {
+			t.Errorf("upsert(%v) arguments = %s", test.dialect, got)
+		}
+
+		q, args = j.delete([]Mutation{{Key: int64(1), Version: 8}})
+		if q != test.delete {
+			t.Errorf("delete(%v) = %v, want %v", test.dialect, q, test.delete)
+		}
+		if got, _ := json.Marshal(args); string(got) != `[1,8]` {
+			t.Errorf("delete(%v) arguments = %s", test.dialect, got)
+		}
+	}
+}
+
+func TestBigtableMutations(t *testing.T) {
+	b := &Bigtable{Family: "f"}
+	var err error
+	if b.columns, b.index, err = columns(reflect.TypeOf(customer{})); err != nil {
+		t.Fatal(err)
+	}
+	keys, muts, err := b.mutations([]Mutation{{Key: int64(1), Row: customer{1, "a"}}, {Key: int64(2)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"1", "2"}) || len(muts) != 2 {
+		t.Errorf("mutations = %v, %v, want row keys [1 2]", keys, muts)
+	}
+	if data, _ := cellValue(int64(1)); string(data) != "1" {
+		t.Errorf("cellValue(1) = %s, want 1", data)
+	}
+}
+
+func TestJDBCQuote(t *testing.T) {
+	tests := []struct {
+		dialect, table, exp string
+	}{
+		{"", `public.order "items"`, `"public"."order ""items"""`},
+		{"mysql", "shop.order`s", "`shop`.`order``s`"},
+	}
+	for _, test := range tests {
+		j := &JDBC{Table: test.table, Dialect: test.dialect}
+		if got := j.table(); got != test.exp {
+			t.Errorf("table(%v, %v) = %v, want %v", test.dialect, test.table, got, test.exp)
+		}
+	}
+}
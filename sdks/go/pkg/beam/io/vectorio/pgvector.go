@@ -0,0 +1,197 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// PgvectorOptions configure WritePgvector.
+type PgvectorOptions struct {
+	// IDColumn is the primary key column of the IDs. If empty, it defaults
+	// to "id".
+	IDColumn string
+	// VectorColumn is the vector column of the values. If empty, it
+	// defaults to "embedding".
+	VectorColumn string
+	// MetadataColumn is the jsonb column of the metadata. If empty, the
+	// metadata is not written.
+	MetadataColumn string
+	// BatchSize is the maximum number of vectors of an INSERT statement. If
+	// zero, it defaults to 500.
+	BatchSize int
+}
+
+const defaultPgvectorBatchSize = 500
+
+// WritePgvector upserts the vectors of col, a PCollection<Vector>, into a
+// PostgreSQL table with the pgvector extension. The driver is the name of
+// a registered database/sql driver for PostgreSQL, such as "postgres" or
+// "pgx", and dsn is its data source name. The table, which may be qualified
+// by its schema as in "public.docs", and the columns are quoted, so their
+// names are case sensitive.
+func WritePgvector(s beam.Scope, driver, dsn, table string, col beam.PCollection, opts PgvectorOptions) {
+	s = s.Scope("vectorio.WritePgvector")
+
+	mustVectors(col)
+	if opts.BatchSize < 0 {
+		panic(fmt.Sprintf("invalid batch size: %v", opts.BatchSize))
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultPgvectorBatchSize
+	}
+	if opts.IDColumn == "" {
+		opts.IDColumn = "id"
+	}
+	if opts.VectorColumn == "" {
+		opts.VectorColumn = "embedding"
+	}
+	beam.ParDo0(s, &pgvectorFn{
+		Driver:         driver,
+		Dsn:            dsn,
+		Table:          table,
+		IDColumn:       opts.IDColumn,
+		VectorColumn:   opts.VectorColumn,
+		MetadataColumn: opts.MetadataColumn,
+		BatchSize:      opts.BatchSize,
+	}, col)
+}
+
+// pgvectorFn upserts batches of vectors with INSERT ... ON CONFLICT.
+type pgvectorFn struct {
+	Driver         string `json:"driver"`
+	Dsn            string `json:"dsn"`
+	Table          string `json:"table"`
+	IDColumn       string `json:"id_column"`
+	VectorColumn   string `json:"vector_column"`
+	MetadataColumn string `json:"metadata_column,omitempty"`
+	BatchSize      int    `json:"batch_size"`
+
+	db    *sql.DB
+	batch []Vector
+}
+
+func (f *pgvectorFn) Setup() error {
+	db, err := sql.Open(f.Driver, f.Dsn)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open database: %v", f.Driver)
+	}
+	f.db = db
+	return nil
+}
+
+func (f *pgvectorFn) StartBundle() {
+	f.batch = nil
+}
+
+func (f *pgvectorFn) ProcessElement(ctx context.Context, v Vector) error {
+	f.batch = append(f.batch, v)
+	if len(f.batch) < f.BatchSize {
+		return nil
+	}
+	return f.flush(ctx)
+}
+
+func (f *pgvectorFn) FinishBundle(ctx context.Context) error {
+	return f.flush(ctx)
+}
+
+func (f *pgvectorFn) Teardown() error {
+	if f.db == nil {
+		return nil
+	}
+	return f.db.Close()
+}
+
+func (f *pgvectorFn) flush(ctx context.Context) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	q, args, err := f.statement(dedup(f.batch))
+	if err != nil {
+		return err
+	}
+	if _, err := f.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrapf(err, "failed to upsert %v vectors into %v", len(f.batch), f.Table)
+	}
+	f.batch = nil
+	return nil
+}
+
+// statement returns the upsert statement of the vectors and its arguments.
+func (f *pgvectorFn) statement(vectors []Vector) (string, []interface{}, error) {
+	columns := []string{f.IDColumn, f.VectorColumn}
+	casts := []string{"", "::vector"}
+	if f.MetadataColumn != "" {
+		columns = append(columns, f.MetadataColumn)
+		casts = append(casts, "::jsonb")
+	}
+
+	var rows []string
+	var args []interface{}
+	for _, v := range vectors {
+		args = append(args, v.ID, formatVector(v.Values))
+		if f.MetadataColumn != "" {
+			data, err := json.Marshal(v.Metadata)
+			if err != nil {
+				return "", nil, errors.Wrapf(err, "failed to encode metadata of vector %v", v.ID)
+			}
+			args = append(args, string(data))
+		}
+		var values []string
+		for _, c := range casts {
+			values = append(values, "$"+strconv.Itoa(len(args)-len(casts)+len(values)+1)+c)
+		}
+		rows = append(rows, "("+strings.Join(values, ", ")+")")
+	}
+
+	for i, c := range columns {
+		columns[i] = quoteIdentifier(c)
+	}
+	var updates []string
+	for _, c := range columns[1:] {
+		updates = append(updates, fmt.Sprintf("%v = EXCLUDED.%v", c, c))
+	}
+	var table []string
+	for _, part := range strings.Split(f.Table, ".") {
+		table = append(table, quoteIdentifier(part))
+	}
+	q := fmt.Sprintf("INSERT INTO %v (%v) VALUES %v ON CONFLICT (%v) DO UPDATE SET %v",
+		strings.Join(table, "."), strings.Join(columns, ", "), strings.Join(rows, ", "), columns[0], strings.Join(updates, ", "))
+	return q, args, nil
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier, so that it is used as is.
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// formatVector formats the values as a pgvector literal, such as "[1,2.5]".
+func formatVector(values []float32) string {
+	parts := make([]string, len(values))
+	for i, x := range values {
+		parts[i] = strconv.FormatFloat(float64(x), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,186 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// PineconeOptions configure WritePinecone.
+type PineconeOptions struct {
+	// APIKeyFile and APIKeyEnv are the path of a file and the name of an
+	// environment variable of the workers that hold the key used to
+	// authenticate. Exactly one must be set, so the key itself is not part
+	// of the pipeline.
+	APIKeyFile string
+	APIKeyEnv  string
+	// Namespace is the namespace of the index written to. If empty, the
+	// default namespace is used.
+	Namespace string
+	// BatchSize is the maximum number of vectors of an upsert request. If
+	// zero, it defaults to 100.
+	BatchSize int
+}
+
+const (
+	defaultPineconeBatchSize = 100
+	// maxAttempts is the number of attempts of a request that is throttled
+	// or fails with a server error.
+	maxAttempts = 5
+)
+
+// WritePinecone upserts the vectors of col, a PCollection<Vector>, into the
+// index served at host with the Pinecone vector API. Other stores that
+// implement the same API may be written as well.
+func WritePinecone(s beam.Scope, host string, col beam.PCollection, opts PineconeOptions) {
+	s = s.Scope("vectorio.WritePinecone")
+
+	mustVectors(col)
+	if opts.BatchSize < 0 {
+		panic(fmt.Sprintf("invalid batch size: %v", opts.BatchSize))
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultPineconeBatchSize
+	}
+	if (opts.APIKeyFile == "") == (opts.APIKeyEnv == "") {
+		panic("exactly one of APIKeyFile and APIKeyEnv must be set")
+	}
+	beam.ParDo0(s, &pineconeFn{
+		Host:       strings.TrimSuffix(host, "/"),
+		APIKeyFile: opts.APIKeyFile,
+		APIKeyEnv:  opts.APIKeyEnv,
+		Namespace:  opts.Namespace,
+		BatchSize:  opts.BatchSize,
+	}, col)
+}
+
+// pineconeFn upserts batches of vectors with the Pinecone API.
+type pineconeFn struct {
+	Host       string `json:"host"`
+	APIKeyFile string `json:"api_key_file,omitempty"`
+	APIKeyEnv  string `json:"api_key_env,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	BatchSize  int    `json:"batch_size"`
+
+	key   string
+	batch []Vector
+}
+
+func (f *pineconeFn) Setup() error {
+	switch {
+	case f.APIKeyFile != "":
+		data, err := ioutil.ReadFile(f.APIKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read Pinecone API key")
+		}
+		f.key = strings.TrimSpace(string(data))
+	default:
+		key, ok := os.LookupEnv(f.APIKeyEnv)
+		if !ok {
+			return errors.Errorf("Pinecone API key variable %v not set", f.APIKeyEnv)
+		}
+		f.key = key
+	}
+	return nil
+}
+
+func (f *pineconeFn) StartBundle() {
+	f.batch = nil
+}
+
+func (f *pineconeFn) ProcessElement(ctx context.Context, v Vector) error {
+	f.batch = append(f.batch, v)
+	if len(f.batch) < f.BatchSize {
+		return nil
+	}
+	return f.flush(ctx)
+}
+
+func (f *pineconeFn) FinishBundle(ctx context.Context) error {
+	return f.flush(ctx)
+}
+
+type upsertRequest struct {
+	Vectors   []Vector `json:"vectors"`
+	Namespace string   `json:"namespace,omitempty"`
+}
+
+func (f *pineconeFn) flush(ctx context.Context) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(upsertRequest{Vectors: dedup(f.batch), Namespace: f.Namespace})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode vectors")
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		retry, err := f.upsert(ctx, data)
+		if err == nil {
+			f.batch = nil
+			return nil
+		}
+		if !retry || attempt == maxAttempts {
+			return errors.Wrapf(err, "failed to upsert %v vectors", len(f.batch))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// upsert sends an upsert request. It returns whether a failed request may
+// be retried.
+func (f *pineconeFn) upsert(ctx context.Context, data []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, f.Host+"/vectors/upsert", bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Api-Key", f.key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5:
+		return true, errors.Errorf("%v: %s", resp.Status, body)
+	default:
+		return false, errors.Errorf("%v: %s", resp.Status, body)
+	}
+}
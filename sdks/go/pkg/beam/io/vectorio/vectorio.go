@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vectorio contains transforms that write embeddings to vector
+// databases. Elements are batched within bundles and upserted, so writing
+// the same vector again replaces it. For example:
+//
+//    vectors := beam.ParDo(s, func(doc Document) vectorio.Vector {
+//        return vectorio.Vector{ID: doc.ID, Values: doc.Embedding, Metadata: map[string]interface{}{"title": doc.Title}}
+//    }, docs)
+//    vectorio.WritePinecone(s, "https://docs-abc123.svc.pinecone.io", vectors, vectorio.PineconeOptions{APIKeyEnv: "PINECONE_API_KEY"})
+package vectorio
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Vector)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*pineconeFn)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*pgvectorFn)(nil)).Elem())
+}
+
+// Vector is an embedding with its identifier and metadata.
+type Vector struct {
+	// ID identifies the vector in the store.
+	ID string `json:"id"`
+	// Values are the components of the embedding.
+	Values []float32 `json:"values"`
+	// Metadata are the attributes stored with the vector, if any. The values
+	// must be encodable as JSON.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// dedup returns the vectors of a batch with only the last vector of each ID,
+// since stores commonly reject upserting an ID twice in one request.
+func dedup(batch []Vector) []Vector {
+	last := make(map[string]int)
+	for i, v := range batch {
+		last[v.ID] = i
+	}
+	if len(last) == len(batch) {
+		return batch
+	}
+	var ret []Vector
+	for i, v := range batch {
+		if last[v.ID] == i {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func mustVectors(col beam.PCollection) {
+	if t := col.Type().Type(); t != reflect.TypeOf(Vector{}) {
+		panic(fmt.Sprintf("element type %v must be vectorio.Vector", t))
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func TestWritePinecone(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Path != "/vectors/upsert" || r.Header.Get("Api-Key") != "key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var req upsertRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Namespace != "docs" || len(req.Vectors) > 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		for _, v := range req.Vectors {
+			ids = append(ids, v.ID)
+		}
+	}))
+	defer srv.Close()
+
+	os.Setenv("VECTORIO_TEST_KEY", "key")
+	defer os.Unsetenv("VECTORIO_TEST_KEY")
+
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s,
+		Vector{ID: "a", Values: []float32{1, 2}},
+		Vector{ID: "b", Values: []float32{3, 4}, Metadata: map[string]interface{}{"title": "b"}},
+		Vector{ID: "c", Values: []float32{5, 6}})
+	WritePinecone(s, srv.URL, col, PineconeOptions{APIKeyEnv: "VECTORIO_TEST_KEY", Namespace: "docs", BatchSize: 2})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("WritePinecone failed: %v", err)
+	}
+	sort.Strings(ids)
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Errorf("WritePinecone upserted %v, want a,b,c", ids)
+	}
+}
+
+func TestPgvectorStatement(t *testing.T) {
+	fn := &pgvectorFn{Table: "public.docs", IDColumn: "id", VectorColumn: "embedding", MetadataColumn: `Meta"data`}
+	q, args, err := fn.statement(dedup([]Vector{
+		{ID: "a", Values: []float32{1, 2.5}},
+		{ID: "b", Values: []float32{3}, Metadata: map[string]interface{}{"n": 1}},
+		{ID: "a", Values: []float32{0}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `INSERT INTO "public"."docs" ("id", "embedding", "Meta""data") VALUES ($1, $2::vector, $3::jsonb), ($4, $5::vector, $6::jsonb) ` +
+		`ON CONFLICT ("id") DO UPDATE SET "embedding" = EXCLUDED."embedding", "Meta""data" = EXCLUDED."Meta""data"`
+	if q != exp {
+		t.Errorf("statement = %v, want %v", q, exp)
+	}
+	got, _ := json.Marshal(args)
+	if string(got) != `["b","[3]","{\"n\":1}","a","[0]","null"]` {
+		t.Errorf("arguments = %s", got)
+	}
+}
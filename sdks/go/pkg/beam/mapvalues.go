@@ -0,0 +1,133 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/funcx"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/coder"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+var (
+	mapValuesSig = &funcx.Signature{Args: []reflect.Type{TType}, Return: []reflect.Type{UType}} // T -> U
+)
+
+// MapValues transforms the values of a PCollection<KV<K,V>> using the given
+// function, fn : V -> W, and returns a PCollection<KV<K,W>>. The function
+// never observes the key, so the key and its coder are carried over from the
+// input unchanged. For example:
+//
+//    lengths := beam.MapValues(s, func(v string) int {
+//          return len(v)
+//    }, kvs)  // PCollection<KV<K,int>>
+//
+// Since the key cannot change, a key-partitioned input remains partitioned
+// the same way and runners need not reshuffle the output before a
+// subsequent GroupByKey.
+func MapValues(s Scope, fn interface{}, col PCollection) PCollection {
+	s = s.Scope("beam.MapValues")
+
+	w := validateMapValues(fn, col, false)
+	dofn := &mapValuesFn{Fn: EncodedFunc{Fn: reflectx.MakeFunc(fn)}}
+	return keyPreserving(col, ParDo(s, dofn, col, TypeDefinition{Var: ZType, T: w}))
+}
+
+// FlatMapValues transforms each value of a PCollection<KV<K,V>> into zero or
+// more values using the given function, fn : V -> []W, and returns a
+// PCollection<KV<K,W>> with one element per returned value, each paired with
+// the original key. As with MapValues, the key and its coder are preserved.
+func FlatMapValues(s Scope, fn interface{}, col PCollection) PCollection {
+	s = s.Scope("beam.FlatMapValues")
+
+	w := validateMapValues(fn, col, true)
+	dofn := &flatMapValuesFn{Fn: EncodedFunc{Fn: reflectx.MakeFunc(fn)}}
+	return keyPreserving(col, ParDo(s, dofn, col, TypeDefinition{Var: ZType, T: w}))
+}
+
+// validateMapValues panics if fn is not of the form V -> W (or V -> []W, if
+// flat) for the value type V of the given PCollection<KV<K,V>>. It returns W.
+func validateMapValues(fn interface{}, col PCollection, flat bool) reflect.Type {
+	_, v := ValidateKVType(col)
+
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() != 1 {
+		panic(fmt.Sprintf("value function must be of the form V -> W: %v", t))
+	}
+	w := t.Out(0)
+	funcx.MustSatisfy(fn, funcx.Replace(funcx.Replace(mapValuesSig, TType, v.Type()), UType, w))
+
+	if flat {
+		if w.Kind() != reflect.Slice {
+			panic(fmt.Sprintf("value function must be of the form V -> []W: %v", t))
+		}
+		w = w.Elem()
+	}
+	return w
+}
+
+// keyPreserving re-attaches the key coder of the input PCollection<KV<K,V>> to
+// the output PCollection<KV<K,W>>, which would otherwise be inferred from K
+// alone.
+func keyPreserving(in, out PCollection) PCollection {
+	kc := in.Coder().coder.Components[0]
+	vc := out.Coder().coder.Components[1]
+
+	c := coder.NewKV([]*coder.Coder{kc, vc})
+	if err := out.SetCoder(Coder{c}); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// mapValuesFn applies a function to the value of each KV and passes the key
+// through as-is.
+type mapValuesFn struct {
+	// Fn is the encoded value function.
+	Fn EncodedFunc `json:"fn"`
+
+	fn reflectx.Func1x1
+}
+
+func (f *mapValuesFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+}
+
+func (f *mapValuesFn) ProcessElement(key X, value Y) (X, Z) {
+	return key, f.fn.Call1x1(value)
+}
+
+// flatMapValuesFn applies a slice-valued function to the value of each KV and
+// emits every resulting value with the original key.
+type flatMapValuesFn struct {
+	// Fn is the encoded value function.
+	Fn EncodedFunc `json:"fn"`
+
+	fn reflectx.Func1x1
+}
+
+func (f *flatMapValuesFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+}
+
+func (f *flatMapValuesFn) ProcessElement(key X, value Y, emit func(X, Z)) {
+	list := reflect.ValueOf(f.fn.Call1x1(value))
+	for i := 0; i < list.Len(); i++ {
+		emit(key, list.Index(i).Interface())
+	}
+}
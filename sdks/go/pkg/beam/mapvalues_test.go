@@ -0,0 +1,81 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(splitKV)
+	beam.RegisterFunction(formatKV)
+	beam.RegisterFunction(strlen)
+	beam.RegisterFunction(chars)
+}
+
+// splitKV turns "k=v" into KV<k,v>.
+func splitKV(s string) (string, string) {
+	parts := strings.SplitN(s, "=", 2)
+	return parts[0], parts[1]
+}
+
+func formatKV(k string, v beam.V) string {
+	return fmt.Sprintf("%v:%v", k, v)
+}
+
+func strlen(s string) int { return len(s) }
+
+func chars(s string) []string { return strings.Split(s, "") }
+
+func TestMapValues(t *testing.T) {
+	p, s, in, exp := ptest.CreateList2([]string{"a=foo", "b=ba", "a="}, []string{"a:3", "b:2", "a:0"})
+	kvs := beam.ParDo(s, splitKV, in)
+	out := beam.MapValues(s, strlen, kvs)
+	passert.Equals(s, beam.ParDo(s, formatKV, out), exp)
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("MapValues(strlen) failed: %v", err)
+	}
+}
+
+func TestFlatMapValues(t *testing.T) {
+	p, s, in, exp := ptest.CreateList2([]string{"a=xy", "b=z", "c="}, []string{"a:x", "a:y", "b:z"})
+	kvs := beam.ParDo(s, splitKV, in)
+	out := beam.FlatMapValues(s, chars, kvs)
+	passert.Equals(s, beam.ParDo(s, formatKV, out), exp)
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("FlatMapValues(chars) failed: %v", err)
+	}
+}
+
+func TestMapValuesKeyCoder(t *testing.T) {
+	_, s, in := ptest.CreateList([]string{"a=foo"})
+	kvs := beam.ParDo(s, splitKV, in)
+
+	out := beam.MapValues(s, strlen, kvs)
+	want, got := kvs.Coder().Type().Components()[0], out.Coder().Type().Components()[0]
+	if !typex.IsEqual(want, got) {
+		t.Errorf("MapValues key coder type = %v, want %v", got, want)
+	}
+}
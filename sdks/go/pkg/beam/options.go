@@ -0,0 +1,84 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// optionChecks are the option checks registered by the transforms of a
+// pipeline.
+type optionChecks struct {
+	checks []optionCheck
+}
+
+type optionCheck struct {
+	scope string
+	fn    func() error
+}
+
+// RequireFlag registers that the transform in the scope requires the named
+// flag to be set to a non-empty value when the pipeline is run. For example,
+// a sink that stages files may require --temp_location.
+func RequireFlag(s Scope, name string) {
+	ValidateOptions(s, func() error {
+		f := flag.Lookup(name)
+		if f == nil {
+			return errors.Errorf("unknown flag --%v", name)
+		}
+		if f.Value.String() == "" {
+			return errors.Errorf("--%v is required", name)
+		}
+		return nil
+	})
+}
+
+// ValidateOptions registers a function that validates the options of the
+// transform in the scope when the pipeline is run, before it is submitted
+// to the runner. Options are usually flags.
+func ValidateOptions(s Scope, fn func() error) {
+	if !s.IsValid() {
+		panic("Invalid Scope")
+	}
+	s.checks.checks = append(s.checks.checks, optionCheck{scope: s.String(), fn: fn})
+}
+
+// CheckOptions runs the option validations registered by the transforms of
+// the pipeline and returns an error that lists all problems found, if any.
+// It is called by Run.
+func CheckOptions(p *Pipeline) error {
+	var problems []string
+	seen := make(map[string]bool)
+	for _, c := range p.checks.checks {
+		err := c.fn()
+		if err == nil {
+			continue
+		}
+		// Many transforms may require the same option.
+		if msg := err.Error(); !seen[msg] {
+			seen[msg] = true
+			problems = append(problems, fmt.Sprintf("%v (required by %v)", msg, c.scope))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.Errorf("invalid pipeline options:\n\t%v", strings.Join(problems, "\n\t"))
+}
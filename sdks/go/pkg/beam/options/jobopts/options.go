@@ -52,6 +52,12 @@ var (
 			"\"env\":{\"<Environment variables 1>\": \"<ENV_VAL>\"} }. "+
 			"All fields in the json are optional except command.")
 
+	// Environments are the configurations of named environments, which run
+	// the user code of transforms in scopes with an environment.
+	Environments = flag.String("environments", "",
+		"Comma-separated list of <name>=<config> of named environments, of the type given by "+
+			"--environment_type, for transforms in scopes with an environment (optional).")
+
 	// WorkerBinary is the location of the compiled worker binary. If not
 	// specified, the binary is produced via go build.
 	WorkerBinary = flag.String("worker_binary", "", "Worker binary (optional)")
@@ -110,6 +116,26 @@ func GetEnvironmentConfig(ctx context.Context) string {
 	return *EnvironmentConfig
 }
 
+// GetEnvironments returns the configurations of the named environments, by
+// name. Convenience function.
+func GetEnvironments() (map[string]string, error) {
+	ret := make(map[string]string)
+	if *Environments == "" {
+		return ret, nil
+	}
+	for _, env := range strings.Split(*Environments, ",") {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid environment %q: want <name>=<config>", env)
+		}
+		if _, ok := ret[parts[0]]; ok {
+			return nil, errors.Errorf("duplicate environment %v", parts[0])
+		}
+		ret[parts[0]] = parts[1]
+	}
+	return ret, nil
+}
+
 // GetExperiments returns the experiments.
 func GetExperiments() []string {
 	if *Experiments == "" {
@@ -0,0 +1,237 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package template contains template parameters, which are pipeline options
+// that are resolved on the workers when the pipeline runs rather than when
+// it is constructed. A single pipeline binary can thus serve many runs that
+// differ only in parameters, such as the date or table to process.
+//
+// Parameters are defined as flags during init and held by DoFns, which read
+// their values at runtime:
+//
+//    var table = template.NewString("table", "", "Output table.")
+//
+//    type writeFn struct {
+//        Table template.String `json:"table"`
+//    }
+//
+//    func (f *writeFn) ProcessElement(ctx context.Context, row Row) error {
+//        name, err := f.Table.Get()
+//        if err != nil {
+//            return err
+//        }
+//        ...
+//    }
+//
+// The flag values at construction are exported with the pipeline options.
+// A launcher may override them without constructing the pipeline again by
+// setting the "beam:option:go_parameters:v1" pipeline option to a map of
+// parameter names to values. Parameters may also be substituted into
+// strings, such as file patterns, with Expand, as textio.ReadTemplate does.
+package template
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+var (
+	// params are the names of the defined parameters.
+	params   = make(map[string]bool)
+	paramsMu sync.Mutex
+)
+
+func init() {
+	runtime.RegisterInit(exportParameters)
+}
+
+// exportParameters records the flag values of the parameters in the global
+// options, which are exported to the workers.
+func exportParameters() {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+
+	for name := range params {
+		runtime.GlobalOptions.Set(runtime.ParameterKey(name), flag.Lookup(name).Value.String())
+	}
+}
+
+func define(name string) {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+
+	params[name] = true
+}
+
+func defined(name string) bool {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+
+	return params[name]
+}
+
+// value returns the runtime value of the parameter. It falls back to the
+// value of the flag, if the global options do not hold the parameter, such
+// as during construction.
+func value(name string) (string, error) {
+	if v, ok := runtime.GlobalOptions.Lookup(runtime.ParameterKey(name)); ok {
+		return v, nil
+	}
+	if !defined(name) {
+		return "", errors.Errorf("template parameter %v not defined", name)
+	}
+	return flag.Lookup(name).Value.String(), nil
+}
+
+// String is a string parameter.
+type String struct {
+	// Name is the name of the parameter and its flag.
+	Name string `json:"name"`
+}
+
+// NewString defines a string parameter and its flag with the given name,
+// default value and usage. It must be called before flags are parsed.
+func NewString(name, value, usage string) String {
+	flag.String(name, value, usage)
+	define(name)
+	return String{Name: name}
+}
+
+// Get returns the value of the parameter. It fails if the parameter is not
+// defined.
+func (p String) Get() (string, error) {
+	return value(p.Name)
+}
+
+// Int is an integer parameter.
+type Int struct {
+	// Name is the name of the parameter and its flag.
+	Name string `json:"name"`
+}
+
+// NewInt defines an integer parameter and its flag with the given name,
+// default value and usage. It must be called before flags are parsed.
+func NewInt(name string, value int64, usage string) Int {
+	flag.Int64(name, value, usage)
+	define(name)
+	return Int{Name: name}
+}
+
+// Get returns the value of the parameter. It fails if the parameter is not
+// defined or if its value, which may have been set by a launcher, is not an
+// integer.
+func (p Int) Get() (int64, error) {
+	v, err := value(p.Name)
+	if err != nil {
+		return 0, err
+	}
+	ret, err := strconv.ParseInt(v, 0, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value of template parameter %v", p.Name)
+	}
+	return ret, nil
+}
+
+// Bool is a boolean parameter.
+type Bool struct {
+	// Name is the name of the parameter and its flag.
+	Name string `json:"name"`
+}
+
+// NewBool defines a boolean parameter and its flag with the given name,
+// default value and usage. It must be called before flags are parsed.
+func NewBool(name string, value bool, usage string) Bool {
+	flag.Bool(name, value, usage)
+	define(name)
+	return Bool{Name: name}
+}
+
+// Get returns the value of the parameter. It fails if the parameter is not
+// defined or if its value is not a boolean.
+func (p Bool) Get() (bool, error) {
+	v, err := value(p.Name)
+	if err != nil {
+		return false, err
+	}
+	ret, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid value of template parameter %v", p.Name)
+	}
+	return ret, nil
+}
+
+// Duration is a duration parameter, such as "90s".
+type Duration struct {
+	// Name is the name of the parameter and its flag.
+	Name string `json:"name"`
+}
+
+// NewDuration defines a duration parameter and its flag with the given
+// name, default value and usage. It must be called before flags are parsed.
+func NewDuration(name string, value time.Duration, usage string) Duration {
+	flag.Duration(name, value, usage)
+	define(name)
+	return Duration{Name: name}
+}
+
+// Get returns the value of the parameter. It fails if the parameter is not
+// defined or if its value is not a duration.
+func (p Duration) Get() (time.Duration, error) {
+	v, err := value(p.Name)
+	if err != nil {
+		return 0, err
+	}
+	ret, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value of template parameter %v", p.Name)
+	}
+	return ret, nil
+}
+
+// Expand replaces the "${name}" references in text with the runtime values
+// of the named parameters, such as "gs://bucket/${date}/*.csv". It fails if
+// a referenced parameter is not defined.
+func Expand(text string) (string, error) {
+	if !strings.Contains(text, "${") {
+		return text, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "${")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(text[i:], "}")
+		if j < 0 {
+			return "", errors.Errorf("unterminated template parameter in %q", text)
+		}
+		v, err := value(text[i+2 : i+j])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(text[:i])
+		b.WriteString(v)
+		text = text[i+j+1:]
+	}
+	b.WriteString(text)
+	return b.String(), nil
+}
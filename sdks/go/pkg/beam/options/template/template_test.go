@@ -0,0 +1,137 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+var (
+	table   = NewString("template_test_table", "events", "Test table.")
+	limit   = NewInt("template_test_limit", 10, "Test limit.")
+	dryRun  = NewBool("template_test_dry_run", false, "Test dry run.")
+	timeout = NewDuration("template_test_timeout", time.Minute, "Test timeout.")
+	date    = NewString("template_test_date", "", "Test date.")
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*prefixFn)(nil)).Elem())
+}
+
+type prefixFn struct {
+	Table String `json:"table"`
+}
+
+func (f *prefixFn) ProcessElement(_ context.Context, elm string) (string, error) {
+	table, err := f.Table.Get()
+	if err != nil {
+		return "", err
+	}
+	return table + "." + elm, nil
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+func TestGet(t *testing.T) {
+	if got, err := table.Get(); err != nil || got != "events" {
+		t.Errorf("String.Get() = %v, %v, want events", got, err)
+	}
+	if got, err := limit.Get(); err != nil || got != 10 {
+		t.Errorf("Int.Get() = %v, %v, want 10", got, err)
+	}
+	if got, err := dryRun.Get(); err != nil || got {
+		t.Errorf("Bool.Get() = %v, %v, want false", got, err)
+	}
+	if got, err := timeout.Get(); err != nil || got != time.Minute {
+		t.Errorf("Duration.Get() = %v, %v, want 1m", got, err)
+	}
+
+	// Values set by a launcher take precedence over the flags.
+	runtime.GlobalOptions.Set(runtime.ParameterKey(limit.Name), "25")
+	if got, err := limit.Get(); err != nil || got != 25 {
+		t.Errorf("Int.Get() = %v, %v, want 25", got, err)
+	}
+	runtime.GlobalOptions.Set(runtime.ParameterKey(limit.Name), "many")
+	if _, err := limit.Get(); err == nil {
+		t.Errorf("Int.Get() succeeded for invalid value, want error")
+	}
+	if _, err := (Int{Name: "template_test_undefined"}).Get(); err == nil {
+		t.Errorf("Int.Get() succeeded for undefined parameter, want error")
+	}
+	if _, err := (String{Name: "template_test_undefined"}).Get(); err == nil {
+		t.Errorf("String.Get() succeeded for undefined parameter, want error")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	runtime.GlobalOptions.Set(runtime.ParameterKey(date.Name), "2019-05-01")
+
+	tests := []struct {
+		text string
+		exp  string
+		err  bool
+	}{
+		{text: "gs://bucket/*.txt", exp: "gs://bucket/*.txt"},
+		{text: "gs://bucket/${template_test_date}/*.txt", exp: "gs://bucket/2019-05-01/*.txt"},
+		{text: "${template_test_table}_${template_test_date}", exp: "events_2019-05-01"},
+		{text: "gs://bucket/${template_test_undefined}", err: true},
+		{text: "gs://bucket/${template_test_date", err: true},
+	}
+	for _, test := range tests {
+		got, err := Expand(test.text)
+		if test.err {
+			if err == nil {
+				t.Errorf("Expand(%v) = %v, want error", test.text, got)
+			}
+			continue
+		}
+		if err != nil || got != test.exp {
+			t.Errorf("Expand(%v) = %v, %v, want %v", test.text, got, err, test.exp)
+		}
+	}
+}
+
+func TestParDo(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "a", "b")
+	out := beam.ParDo(s, &prefixFn{Table: table}, col)
+	passert.Equals(s, out, "events.a", "events.b")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestExportParameters(t *testing.T) {
+	exportParameters()
+	raw := runtime.GlobalOptions.Export()
+	if got := raw.Options[runtime.ParameterKey(table.Name)]; got != "events" {
+		t.Errorf("exported %v = %q, want events", table.Name, got)
+	}
+	if _, ok := raw.Options[runtime.ParameterKey(timeout.Name)]; !ok {
+		t.Errorf("parameter %v not exported", timeout.Name)
+	}
+}
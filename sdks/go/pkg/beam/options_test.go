@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+var testFlag = flag.String("test_required_option", "", "Option required by TestCheckOptions.")
+
+func TestCheckOptions(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	beam.RequireFlag(s.Scope("a"), "test_required_option")
+	beam.RequireFlag(s.Scope("b"), "test_required_option")
+	beam.RequireFlag(s.Scope("c"), "no_such_option")
+	beam.ValidateOptions(s.Scope("d"), func() error { return errors.New("bad option") })
+
+	err := beam.CheckOptions(p)
+	if err == nil {
+		t.Fatal("CheckOptions succeeded, want error")
+	}
+	for _, want := range []string{"--test_required_option is required (required by root/a)", "unknown flag --no_such_option", "bad option"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckOptions = %v, want %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "root/b") {
+		t.Errorf("CheckOptions = %v, want duplicate problems reported once", err)
+	}
+
+	*testFlag = "x"
+	defer func() { *testFlag = "" }()
+	p, s = beam.NewPipelineWithRoot()
+	beam.RequireFlag(s, "test_required_option")
+	if err := beam.CheckOptions(p); err != nil {
+		t.Errorf("CheckOptions = %v, want nil", err)
+	}
+}
+
+// TestRunChecksOptions verifies that Run checks the options before the
+// pipeline is executed.
+func TestRunChecksOptions(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	beam.Impulse(s)
+	beam.RequireFlag(s.Scope("a"), "test_required_option")
+
+	err := ptest.Run(p)
+	if err == nil || !strings.Contains(err.Error(), "--test_required_option is required") {
+		t.Errorf("Run = %v, want missing option error", err)
+	}
+}
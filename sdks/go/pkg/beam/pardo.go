@@ -71,6 +71,10 @@ func ParDo0(s Scope, dofn interface{}, col PCollection, opts ...Option) {
 	}
 }
 
+// InfiniteTimestampSkew is the allowed timestamp skew of DoFns that may
+// output elements with any timestamps. See ParDo.
+const InfiniteTimestampSkew = graph.InfiniteTimestampSkew
+
 // ParDo is the core element-wise PTransform in Apache Beam, invoking a
 // user-specified function on each of the elements of the input PCollection
 // to produce zero or more output elements, all of which are collected into
@@ -132,6 +136,16 @@ func ParDo0(s Scope, dofn interface{}, col PCollection, opts ...Option) {
 // used as the DoFn name. Function literals do not have stable names and should
 // thus not be used in production code.
 //
+// Outputs may have any timestamps. A struct DoFn may opt in to checking
+// that its outputs are not earlier than their input by more than a given
+// skew, as they could be late for windowing downstream, with an
+// AllowedTimestampSkew method. Outputs earlier than that fail the bundle.
+// For example:
+//
+//    func (f *parseFn) AllowedTimestampSkew() time.Duration {
+//          return time.Hour
+//    }
+//
 // Side Inputs
 //
 // While a ParDo processes elements from a single "main input" PCollection, it
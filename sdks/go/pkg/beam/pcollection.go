@@ -17,6 +17,7 @@ package beam
 
 import (
 	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
 	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
 	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
 )
@@ -49,8 +50,6 @@ func (p PCollection) IsValid() bool {
 }
 
 // TODO(herohde) 5/30/2017: add name for PCollections? Java supports it.
-// TODO(herohde) 5/30/2017: add windowing strategy and documentation.
-
 // Type returns the full type 'A' of the elements. 'A' must be a concrete
 // type, such as int or KV<int,string>.
 func (p PCollection) Type() FullType {
@@ -60,6 +59,15 @@ func (p PCollection) Type() FullType {
 	return p.n.Type()
 }
 
+// WindowingStrategy returns the windowing strategy of the collection, which
+// determines the windows that its elements are assigned to.
+func (p PCollection) WindowingStrategy() *window.WindowingStrategy {
+	if !p.IsValid() {
+		panic("Invalid PCollection")
+	}
+	return p.n.WindowingStrategy()
+}
+
 // Coder returns the coder for the collection. The Coder is of type 'A'.
 func (p PCollection) Coder() Coder {
 	if !p.IsValid() {
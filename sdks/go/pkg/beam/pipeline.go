@@ -28,6 +28,8 @@ type Scope struct {
 	scope *graph.Scope
 	// real is the enclosing graph.
 	real *graph.Graph
+	// checks are the option checks of the pipeline.
+	checks *optionChecks
 }
 
 // IsValid returns true iff the Scope is valid. Any use of an invalid Scope
@@ -43,7 +45,26 @@ func (s Scope) Scope(name string) Scope {
 		panic("Invalid Scope")
 	}
 	scope := s.real.NewScope(s.scope, name)
-	return Scope{scope: scope, real: s.real}
+	return Scope{scope: scope, real: s.real, checks: s.checks}
+}
+
+// WithEnvironment returns a sub-scope, named after the environment, whose
+// transforms run in the named environment instead of the default one. The
+// environments are configured by the runner, such as by the --environments
+// flag of the universal runner. For example:
+//
+//    gpu := s.WithEnvironment("gpu")
+//    predictions := inference.RunInference(gpu, handler, examples, t, opts)
+//
+// runs only the inference step in the "gpu" environment. The Dataflow runner
+// supports the default environment only and rejects such pipelines.
+func (s Scope) WithEnvironment(env string) Scope {
+	if env == "" {
+		panic("empty environment name")
+	}
+	ret := s.Scope(env)
+	ret.scope.Environment = env
+	return ret
 }
 
 func (s Scope) String() string {
@@ -61,16 +82,18 @@ func (s Scope) String() string {
 type Pipeline struct {
 	// real is the deferred execution Graph as it is being constructed.
 	real *graph.Graph
+	// checks are the option checks registered by the transforms.
+	checks *optionChecks
 }
 
 // NewPipeline creates a new empty pipeline.
 func NewPipeline() *Pipeline {
-	return &Pipeline{real: graph.New()}
+	return &Pipeline{real: graph.New(), checks: &optionChecks{}}
 }
 
 // Root returns the root scope of the pipeline.
 func (p *Pipeline) Root() Scope {
-	return Scope{scope: p.real.Root(), real: p.real}
+	return Scope{scope: p.real.Root(), real: p.real, checks: p.checks}
 }
 
 // TODO(herohde) 11/13/2017: consider making Build return the model Pipeline proto
@@ -0,0 +1,305 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+)
+
+func init() {
+	RegisterType(reflect.TypeOf((*materialized)(nil)).Elem())
+}
+
+// Reshuffle is a PTransform that returns a PCollection with the same elements
+// as its input, but materialized and redistributed across workers. It is
+// typically used to checkpoint the output of a non-deterministic or expensive
+// step, or to prevent a runner from fusing a slow source with the DoFns that
+// consume it, which would otherwise limit their parallelism to that of
+// the source. For example:
+//
+//    lines := textio.Read(s, "gs://bucket/many/*.txt")
+//    spread := beam.Reshuffle(s, lines)
+//    results := beam.ParDo(s, &expensiveFn{}, spread)
+//
+// A PCollection<KV<K,V>> is redistributed by key, as with RedistributeByKey.
+// Any other PCollection is redistributed arbitrarily. In both cases, the
+// output PCollection has the same coder as the input.
+//
+// Reshuffle is translated to the portable reshuffle URN, with a GroupByKey
+// based expansion for runners that don't support it natively. Element
+// timestamps are preserved by that expansion, as by RedistributeByKey and
+// RedistributeArbitrarily. The restored timestamps can be behind the output
+// watermark of the GroupByKey, so elements may be late for windowing after
+// the expansion, as with Java's ReifyTimestamps.
+func Reshuffle(s Scope, col PCollection) PCollection {
+	s = s.Scope(graph.ReshuffleScope)
+
+	if typex.IsKV(col.Type()) {
+		return redistributeByKey(s, col)
+	}
+	return redistributeArbitrarily(s, col)
+}
+
+// RedistributeByKey is a PTransform that redistributes the elements of a
+// PCollection<KV<K,V>> such that all elements with the same key are processed
+// by the same worker. The output PCollection<KV<K,V>> has the same elements and
+// coder as the input. For example:
+//
+//    counts := beam.ParDo(s, toKV, words)      // PCollection<KV<string,int>>
+//    spread := beam.RedistributeByKey(s, counts)
+//
+// Unlike GroupByKey, the values are not grouped in the output.
+func RedistributeByKey(s Scope, col PCollection) PCollection {
+	s = s.Scope(graph.RedistributeByKeyScope)
+
+	ValidateKVType(col)
+	return redistributeByKey(s, col)
+}
+
+// RedistributeArbitrarily is a PTransform that redistributes the elements of
+// a PCollection<T> evenly across workers. The assignment of elements to
+// workers is arbitrary. The output PCollection<T> has the same elements and
+// coder as the input.
+func RedistributeArbitrarily(s Scope, col PCollection) PCollection {
+	s = s.Scope(graph.RedistributeArbitrarilyScope)
+
+	ValidateNonCompositeType(col)
+	return redistributeArbitrarily(s, col)
+}
+
+func redistributeByKey(s Scope, col PCollection) PCollection {
+	v := col.Type().Components()[1].Type()
+	value := EncodedType{T: v}
+	keyed := ParDo(s, &materializeByKeyFn{Value: value}, col)
+	out := ParDo(s, &restoreByKeyFn{Value: value}, GroupByKey(s, keyed), TypeDefinition{Var: YType, T: v})
+	return withCoderOf(col, out)
+}
+
+func redistributeArbitrarily(s Scope, col PCollection) PCollection {
+	return materialize(s, col, 0)
+}
+
+// materialize groups the elements of the PCollection by a random shard of
+// the given number, or arbitrarily if zero, and restores them with their
+// timestamps, which the GroupByKey would otherwise reset.
+func materialize(s Scope, col PCollection, shards int) PCollection {
+	t := col.Type()
+	if typex.IsKV(t) {
+		k, v := t.Components()[0], t.Components()[1]
+		key, value := EncodedType{T: k.Type()}, EncodedType{T: v.Type()}
+		keyed := ParDo(s, &materializeKVFn{Key: key, Value: value, Shards: shards}, col)
+		out := ParDo(s, &restoreKVFn{Key: key, Value: value}, GroupByKey(s, keyed),
+			TypeDefinition{Var: XType, T: k.Type()}, TypeDefinition{Var: YType, T: v.Type()})
+		return withCoderOf(col, out)
+	}
+
+	value := EncodedType{T: t.Type()}
+	keyed := ParDo(s, &materializeFn{Value: value, Shards: shards}, col)
+	out := ParDo(s, &restoreFn{Value: value}, GroupByKey(s, keyed), TypeDefinition{Var: TType, T: t.Type()})
+	return withCoderOf(col, out)
+}
+
+// withCoderOf sets the coder of the input PCollection on the output, which
+// would otherwise be inferred from the element type alone.
+func withCoderOf(in, out PCollection) PCollection {
+	if err := out.SetCoder(in.Coder()); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// materialized is an encoded element and its timestamp in milliseconds.
+// The key is only set for KV elements grouped by shard.
+type materialized struct {
+	Timestamp int64
+	Key       []byte `json:",omitempty"`
+	Value     []byte
+}
+
+func randomShard(shards int) int {
+	if shards == 0 {
+		return rand.Int()
+	}
+	return rand.Intn(shards)
+}
+
+// materializeFn keys encoded elements by their shard.
+type materializeFn struct {
+	Value  EncodedType `json:"value"`
+	Shards int         `json:"shards,omitempty"`
+
+	enc ElementEncoder
+}
+
+func (f *materializeFn) Setup() {
+	f.enc = NewElementEncoder(f.Value.T)
+}
+
+func (f *materializeFn) ProcessElement(t EventTime, elm T) (int, materialized, error) {
+	var buf bytes.Buffer
+	if err := f.enc.Encode(elm, &buf); err != nil {
+		return 0, materialized{}, err
+	}
+	return randomShard(f.Shards), materialized{Timestamp: t.Milliseconds(), Value: buf.Bytes()}, nil
+}
+
+// materializeKVFn keys encoded KV elements by their shard.
+type materializeKVFn struct {
+	Key    EncodedType `json:"key"`
+	Value  EncodedType `json:"value"`
+	Shards int         `json:"shards,omitempty"`
+
+	kenc, venc ElementEncoder
+}
+
+func (f *materializeKVFn) Setup() {
+	f.kenc = NewElementEncoder(f.Key.T)
+	f.venc = NewElementEncoder(f.Value.T)
+}
+
+func (f *materializeKVFn) ProcessElement(t EventTime, key X, value Y) (int, materialized, error) {
+	var k, v bytes.Buffer
+	if err := f.kenc.Encode(key, &k); err != nil {
+		return 0, materialized{}, err
+	}
+	if err := f.venc.Encode(value, &v); err != nil {
+		return 0, materialized{}, err
+	}
+	return randomShard(f.Shards), materialized{Timestamp: t.Milliseconds(), Key: k.Bytes(), Value: v.Bytes()}, nil
+}
+
+// materializeByKeyFn encodes the values of KV elements, which are grouped
+// by their keys.
+type materializeByKeyFn struct {
+	Value EncodedType `json:"value"`
+
+	enc ElementEncoder
+}
+
+func (f *materializeByKeyFn) Setup() {
+	f.enc = NewElementEncoder(f.Value.T)
+}
+
+func (f *materializeByKeyFn) ProcessElement(t EventTime, key X, value Y) (X, materialized, error) {
+	var buf bytes.Buffer
+	if err := f.enc.Encode(value, &buf); err != nil {
+		return nil, materialized{}, err
+	}
+	return key, materialized{Timestamp: t.Milliseconds(), Value: buf.Bytes()}, nil
+}
+
+// restoreFn decodes the materialized elements and restores their
+// timestamps.
+type restoreFn struct {
+	Value EncodedType `json:"value"`
+
+	dec ElementDecoder
+}
+
+func (f *restoreFn) Setup() {
+	f.dec = NewElementDecoder(f.Value.T)
+}
+
+// AllowedTimestampSkew allows the restored timestamps, which are earlier
+// than the timestamps of the grouped elements.
+func (f *restoreFn) AllowedTimestampSkew() time.Duration {
+	return InfiniteTimestampSkew
+}
+
+func (f *restoreFn) ProcessElement(_ int, values func(*materialized) bool, emit func(EventTime, T)) error {
+	var m materialized
+	for values(&m) {
+		v, err := f.dec.Decode(bytes.NewReader(m.Value))
+		if err != nil {
+			return err
+		}
+		emit(mtime.FromMilliseconds(m.Timestamp), v)
+	}
+	return nil
+}
+
+// restoreKVFn decodes the materialized KV elements and restores their
+// timestamps.
+type restoreKVFn struct {
+	Key   EncodedType `json:"key"`
+	Value EncodedType `json:"value"`
+
+	kdec, vdec ElementDecoder
+}
+
+func (f *restoreKVFn) Setup() {
+	f.kdec = NewElementDecoder(f.Key.T)
+	f.vdec = NewElementDecoder(f.Value.T)
+}
+
+// AllowedTimestampSkew allows the restored timestamps, which are earlier
+// than the timestamps of the grouped elements.
+func (f *restoreKVFn) AllowedTimestampSkew() time.Duration {
+	return InfiniteTimestampSkew
+}
+
+func (f *restoreKVFn) ProcessElement(_ int, values func(*materialized) bool, emit func(EventTime, X, Y)) error {
+	var m materialized
+	for values(&m) {
+		k, err := f.kdec.Decode(bytes.NewReader(m.Key))
+		if err != nil {
+			return err
+		}
+		v, err := f.vdec.Decode(bytes.NewReader(m.Value))
+		if err != nil {
+			return err
+		}
+		emit(mtime.FromMilliseconds(m.Timestamp), k, v)
+	}
+	return nil
+}
+
+// restoreByKeyFn decodes the materialized values of a key and restores
+// their timestamps.
+type restoreByKeyFn struct {
+	Value EncodedType `json:"value"`
+
+	dec ElementDecoder
+}
+
+func (f *restoreByKeyFn) Setup() {
+	f.dec = NewElementDecoder(f.Value.T)
+}
+
+// AllowedTimestampSkew allows the restored timestamps, which are earlier
+// than the timestamps of the grouped elements.
+func (f *restoreByKeyFn) AllowedTimestampSkew() time.Duration {
+	return InfiniteTimestampSkew
+}
+
+func (f *restoreByKeyFn) ProcessElement(key X, values func(*materialized) bool, emit func(EventTime, X, Y)) error {
+	var m materialized
+	for values(&m) {
+		v, err := f.dec.Decode(bytes.NewReader(m.Value))
+		if err != nil {
+			return err
+		}
+		emit(mtime.FromMilliseconds(m.Timestamp), key, v)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/graphx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func TestReshuffle(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(beam.Scope, beam.PCollection) beam.PCollection
+		kv   bool
+	}{
+		{"Reshuffle", beam.Reshuffle, false},
+		{"ReshuffleKV", beam.Reshuffle, true},
+		{"RedistributeByKey", beam.RedistributeByKey, true},
+		{"RedistributeArbitrarily", beam.RedistributeArbitrarily, false},
+	}
+
+	for _, test := range tests {
+		in := []string{"a=1", "b=2", "a=3", "c=4"}
+		p, s, col, exp := ptest.CreateList2(in, in)
+
+		if test.kv {
+			kvs := test.fn(s, beam.ParDo(s, splitKV, col))
+			exp = beam.ParDo(s, formatKV, beam.ParDo(s, splitKV, exp))
+			passert.Equals(s, beam.ParDo(s, formatKV, kvs), exp)
+		} else {
+			passert.Equals(s, test.fn(s, col), exp)
+		}
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("%v failed: %v", test.name, err)
+		}
+	}
+}
+
+// TestReshuffleTimestamps verifies that the expansion of Reshuffle keeps
+// the timestamps of the elements.
+func TestReshuffleTimestamps(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(beam.Scope, beam.PCollection) beam.PCollection
+	}{
+		{"Reshuffle", beam.Reshuffle},
+		{"RedistributeByKey", beam.RedistributeByKey},
+		{"RedistributeArbitrarily", beam.RedistributeArbitrarily},
+	}
+
+	for _, test := range tests {
+		p, s := beam.NewPipelineWithRoot()
+		in := beam.WithTimestamps(s, checkpointTime, beam.Create(s, "a=1", "b=22", "a=333"))
+		if test.name == "RedistributeArbitrarily" {
+			out := test.fn(s, in)
+			passert.Equals(s, beam.ParDo(s, formatTimestamped, out), "a=1@3000", "b=22@4000", "a=333@5000")
+		} else {
+			out := test.fn(s, beam.ParDo(s, splitKV, in))
+			passert.Equals(s, beam.ParDo(s, formatTimestampedKV, out), "a=1@3000", "b=22@4000", "a=333@5000")
+		}
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("%v failed: %v", test.name, err)
+		}
+	}
+}
+
+func TestReshuffleURN(t *testing.T) {
+	tests := []struct {
+		fn  func(beam.Scope, beam.PCollection) beam.PCollection
+		urn string
+		kv  bool
+	}{
+		{beam.Reshuffle, graphx.URNReshuffle, false},
+		{beam.RedistributeArbitrarily, graphx.URNRedistributeArbitrarily, false},
+		{beam.RedistributeByKey, graphx.URNRedistributeByKey, true},
+	}
+
+	for _, test := range tests {
+		p, s, col := ptest.CreateList([]string{"a=1", "b=22"})
+		if test.kv {
+			col = beam.ParDo(s, splitKV, col)
+		}
+		test.fn(s, col)
+
+		edges, _, err := p.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pipe, err := graphx.Marshal(edges, &graphx.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := false
+		for _, transform := range pipe.GetComponents().GetTransforms() {
+			if transform.GetSpec().GetUrn() == test.urn {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no transform with URN %v in pipeline", test.urn)
+		}
+	}
+}
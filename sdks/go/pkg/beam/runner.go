@@ -41,11 +41,19 @@ func RegisterRunner(name string, fn func(ctx context.Context, p *Pipeline) error
 
 // Run executes the pipeline using the selected registred runner. It is customary
 // to define a "runner" with no default as a flag to let users control runner
-// selection.
+// selection. The options required by the transforms of the pipeline and the
+// capabilities of the runner are checked first, see CheckOptions and
+// CheckCapabilities.
 func Run(ctx context.Context, runner string, p *Pipeline) error {
 	fn, ok := runners[runner]
 	if !ok {
 		log.Exitf(ctx, "Runner %v not registered. Forgot to _ import it?", runner)
 	}
+	if err := CheckOptions(p); err != nil {
+		return err
+	}
+	if err := CheckCapabilities(p, runner); err != nil {
+		return err
+	}
 	return fn(ctx, p)
 }
@@ -71,6 +71,7 @@ var (
 func init() {
 	// Note that we also _ import harness/init to setup the remote execution hook.
 	beam.RegisterRunner("dataflow", Execute)
+	beam.RegisterCapabilities("dataflow", beam.MergingWindowsCapability, beam.UnboundedCapability)
 
 	perf.RegisterProfCaptureHook("gcs_profile_writer", gcsRecorderHook)
 }
@@ -80,6 +81,10 @@ var unique int32
 // Execute runs the given pipeline on Google Cloud Dataflow. It uses the
 // default application credentials to submit the job.
 func Execute(ctx context.Context, p *beam.Pipeline) error {
+	if *jobopts.Environments != "" {
+		return errors.New("Dataflow supports the default environment only: --environments is not supported")
+	}
+
 	// (1) Gather job options
 
 	project := *gcpopts.Project
@@ -153,6 +158,11 @@ func Execute(ctx context.Context, p *beam.Pipeline) error {
 	if err != nil {
 		return err
 	}
+	for _, edge := range edges {
+		if name := edge.Scope().EnvironmentName(); name != "" {
+			return errors.Errorf("Dataflow supports the default environment only: %v uses environment %v", edge, name)
+		}
+	}
 	model, err := graphx.Marshal(edges, &graphx.Options{Environment: createEnvironment(ctx)})
 	if err != nil {
 		return errors.WithContext(err, "generating model pipeline")
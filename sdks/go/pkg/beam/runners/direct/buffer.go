@@ -86,11 +86,18 @@ type wait struct {
 	instID string
 	mgr    exec.DataContext
 
-	buf   []exec.FullValue
+	buf   []waiting
 	ready int  // guards ready
 	done  bool // FinishBundle called for main input?
 }
 
+// waiting is an element buffered by wait, with the values of its key if it
+// is the result of a GroupByKey.
+type waiting struct {
+	value  exec.FullValue
+	values []exec.ReStream
+}
+
 func (w *wait) ID() exec.UnitID {
 	return w.UID
 }
@@ -113,7 +120,7 @@ func (w *wait) notify(ctx context.Context) error {
 		return err
 	}
 	for _, elm := range w.buf {
-		if err := w.next.ProcessElement(ctx, &elm); err != nil {
+		if err := w.next.ProcessElement(ctx, &elm.value, elm.values...); err != nil {
 			return err
 		}
 	}
@@ -139,12 +146,12 @@ func (w *wait) StartBundle(ctx context.Context, id string, data exec.DataContext
 func (w *wait) ProcessElement(ctx context.Context, elm *exec.FullValue, values ...exec.ReStream) error {
 	if w.ready < w.need {
 		// log.Printf("buffer[%v]: %v", w.UID, elm)
-		w.buf = append(w.buf, *elm)
+		w.buf = append(w.buf, waiting{value: *elm, values: values})
 		return nil
 	}
 
 	// log.Printf("NOT buffer[%v]: %v", w.UID, elm)
-	return w.next.ProcessElement(ctx, elm)
+	return w.next.ProcessElement(ctx, elm, values...)
 }
 
 func (w *wait) FinishBundle(ctx context.Context) error {
@@ -32,6 +32,9 @@ import (
 
 func init() {
 	beam.RegisterRunner("direct", Execute)
+	// The direct runner executes bounded pipelines with the default trigger
+	// and non-merging windows only.
+	beam.RegisterCapabilities("direct")
 }
 
 // Execute runs the pipeline in-process.
@@ -0,0 +1,103 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flink
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/graphx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/golang/protobuf/proto"
+)
+
+// statefulURNs are the transforms that keep state in Flink operators, which
+// are identified by the unique names of the transforms.
+var statefulURNs = map[string]bool{
+	graphx.URNGBK:           true,
+	graphx.URNCombinePerKey: true,
+}
+
+// CheckCompatible checks that the state that a job of the restored pipeline
+// keeps in a savepoint can be restored by a job of the pipeline p. The state
+// of a grouping transform is compatible if p has a transform of the same
+// unique name and with the same input coder. If allowNonRestored is set, the
+// state of transforms that p lacks may be dropped.
+func CheckCompatible(restored, p *pb.Pipeline, allowNonRestored bool) error {
+	before := statefulTransforms(restored)
+	after := statefulTransforms(p)
+
+	var problems []string
+	for name, t := range before {
+		u, ok := after[name]
+		if !ok {
+			if !allowNonRestored {
+				problems = append(problems, fmt.Sprintf("%v was removed; its state cannot be restored", name))
+			}
+			continue
+		}
+		if t.urn != u.urn {
+			problems = append(problems, fmt.Sprintf("%v changed from %v to %v", name, t.urn, u.urn))
+			continue
+		}
+		if !proto.Equal(t.coder, u.coder) {
+			problems = append(problems, fmt.Sprintf("the input coder of %v changed", name))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.Errorf("incompatible state:\n\t%v", strings.Join(problems, "\n\t"))
+}
+
+type statefulTransform struct {
+	urn   string
+	coder *pb.Coder // with the coder IDs of components resolved
+}
+
+func statefulTransforms(p *pb.Pipeline) map[string]statefulTransform {
+	comps := p.GetComponents()
+	ret := make(map[string]statefulTransform)
+	for _, t := range comps.GetTransforms() {
+		urn := t.GetSpec().GetUrn()
+		if !statefulURNs[urn] {
+			continue
+		}
+		for _, in := range t.GetInputs() {
+			c := comps.GetPcollections()[in].GetCoderId()
+			ret[t.GetUniqueName()] = statefulTransform{urn: urn, coder: resolve(comps, c)}
+		}
+	}
+	return ret
+}
+
+// resolve returns the coder with its component coder IDs replaced by the
+// resolved coders, so that coders can be compared across pipelines.
+func resolve(comps *pb.Components, id string) *pb.Coder {
+	c, ok := comps.GetCoders()[id]
+	if !ok {
+		return nil
+	}
+	ret := &pb.Coder{Spec: c.GetSpec()}
+	for _, sub := range c.GetComponentCoderIds() {
+		data, _ := proto.Marshal(resolve(comps, sub))
+		ret.ComponentCoderIds = append(ret.ComponentCoderIds, string(data))
+	}
+	return ret
+}
@@ -18,17 +18,69 @@ package flink
 
 import (
 	"context"
+	"flag"
+	"io/ioutil"
 
 	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
 	"github.com/apache/beam/sdks/go/pkg/beam/runners/universal"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	savepointPath         = flag.String("savepoint_path", "", "Savepoint to restore the job from (optional).")
+	allowNonRestoredState = flag.Bool("allow_non_restored_state", false, "Allow state of the savepoint that cannot be mapped to the pipeline to be dropped (optional).")
+	pipelineFile          = flag.String("pipeline_file", "", "File to save the model pipeline to, for checking restarts from savepoints of the job (optional).")
+	restoredPipelineFile  = flag.String("restored_pipeline_file", "", "Model pipeline of the job of --savepoint_path, saved with --pipeline_file. "+
+		"The state of the savepoint is checked to be compatible with the pipeline before submission (optional).")
 )
 
 func init() {
 	beam.RegisterRunner("flink", Execute)
+	// Splitting of portable DoFns is not supported by Flink.
+	beam.RegisterCapabilities("flink", beam.MergingWindowsCapability, beam.UnboundedCapability)
 }
 
 // Execute runs the given pipeline on Flink. Convenience wrapper over the
 // universal runner.
 func Execute(ctx context.Context, p *beam.Pipeline) error {
-	return universal.Execute(ctx, p)
+	opts := universal.Options{
+		RunnerOptions: make(map[string]interface{}),
+		Validate:      validate,
+	}
+	if *savepointPath != "" {
+		opts.RunnerOptions["savepoint_path"] = *savepointPath
+		opts.RunnerOptions["allow_non_restored_state"] = *allowNonRestoredState
+	}
+	return universal.ExecuteWithOptions(ctx, p, opts)
+}
+
+// validate checks that the pipeline can be restored from the savepoint, if
+// any, and saves it to --pipeline_file.
+func validate(p *pb.Pipeline) error {
+	if *restoredPipelineFile != "" {
+		if *savepointPath == "" {
+			return errors.New("--restored_pipeline_file requires --savepoint_path")
+		}
+		data, err := ioutil.ReadFile(*restoredPipelineFile)
+		if err != nil {
+			return err
+		}
+		var restored pb.Pipeline
+		if err := proto.Unmarshal(data, &restored); err != nil {
+			return errors.Wrapf(err, "invalid pipeline in %v", *restoredPipelineFile)
+		}
+		if err := CheckCompatible(&restored, p, *allowNonRestoredState); err != nil {
+			return errors.WithContextf(err, "restoring %v", *savepointPath)
+		}
+	}
+	if *pipelineFile != "" {
+		data, err := proto.Marshal(p)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(*pipelineFile, data, 0644)
+	}
+	return nil
 }
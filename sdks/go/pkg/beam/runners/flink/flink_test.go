@@ -0,0 +1,119 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/graphx"
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+)
+
+// pipeline returns a pipeline with a transform of the urn named "group",
+// with input of a KV coder of the given value coder.
+func pipeline(urn, value string) *pb.Pipeline {
+	return &pb.Pipeline{
+		Components: &pb.Components{
+			Transforms: map[string]*pb.PTransform{
+				"e1": {UniqueName: "group", Spec: &pb.FunctionSpec{Urn: urn}, Inputs: map[string]string{"i0": "n1"}},
+			},
+			Pcollections: map[string]*pb.PCollection{
+				"n1": {UniqueName: "n1", CoderId: "c0"},
+			},
+			Coders: map[string]*pb.Coder{
+				"c0": {Spec: &pb.SdkFunctionSpec{Spec: &pb.FunctionSpec{Urn: "beam:coder:kv:v1"}}, ComponentCoderIds: []string{"c1", "c2"}},
+				"c1": {Spec: &pb.SdkFunctionSpec{Spec: &pb.FunctionSpec{Urn: "beam:coder:string_utf8:v1"}}},
+				"c2": {Spec: &pb.SdkFunctionSpec{Spec: &pb.FunctionSpec{Urn: value}}},
+			},
+		},
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	restored := pipeline(graphx.URNGBK, "beam:coder:varint:v1")
+	empty := &pb.Pipeline{Components: &pb.Components{}}
+
+	tests := []struct {
+		name             string
+		p                *pb.Pipeline
+		allowNonRestored bool
+		err              string
+	}{
+		{"same", pipeline(graphx.URNGBK, "beam:coder:varint:v1"), false, ""},
+		{"coder", pipeline(graphx.URNGBK, "beam:coder:bytes:v1"), false, "input coder of group changed"},
+		{"urn", pipeline(graphx.URNCombinePerKey, "beam:coder:varint:v1"), false, "group changed from"},
+		{"removed", empty, false, "group was removed"},
+		{"removed allowed", empty, true, ""},
+	}
+	for _, test := range tests {
+		err := CheckCompatible(restored, test.p, test.allowNonRestored)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("CheckCompatible(%v) failed: %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("CheckCompatible(%v) = %v, want error containing %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestTriggerSavepoint(t *testing.T) {
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/jobs/overview":
+			w.Write([]byte(`{"jobs":[{"jid":"old","name":"wordcount","state":"FINISHED"},{"jid":"j1","name":"wordcount","state":"RUNNING"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/jobs/j1/savepoints":
+			var req map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["target-directory"] != "/savepoints" || req["cancel-job"] != true {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"request-id":"r1"}`))
+		case r.Method == "GET" && r.URL.Path == "/jobs/j1/savepoints/r1":
+			polls++
+			if polls < 2 {
+				w.Write([]byte(`{"status":{"id":"IN_PROGRESS"}}`))
+				return
+			}
+			w.Write([]byte(`{"status":{"id":"COMPLETED"},"operation":{"location":"/savepoints/savepoint-1"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := &Client{URL: srv.URL, PollInterval: 1}
+	id, err := c.FindJob(ctx, "wordcount")
+	if err != nil || id != "j1" {
+		t.Fatalf("FindJob(wordcount) = %v, %v, want j1", id, err)
+	}
+	location, err := c.TriggerSavepoint(ctx, id, "/savepoints", true)
+	if err != nil {
+		t.Fatalf("TriggerSavepoint failed: %v", err)
+	}
+	if location != "/savepoints/savepoint-1" {
+		t.Errorf("TriggerSavepoint = %v, want /savepoints/savepoint-1", location)
+	}
+	if _, err := c.FindJob(ctx, "missing"); err == nil {
+		t.Errorf("FindJob(missing) succeeded, want error")
+	}
+}
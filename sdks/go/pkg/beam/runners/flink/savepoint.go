@@ -0,0 +1,138 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// Client is a client of the REST API of a Flink cluster, used to manage the
+// savepoints of running jobs.
+type Client struct {
+	// URL is the base URL of the REST API of the job manager, such as
+	// "http://localhost:8081".
+	URL string
+	// PollInterval is the interval at which the status of savepoints is
+	// checked. If zero, one second is used.
+	PollInterval time.Duration
+}
+
+// FindJob returns the Flink ID of the running job with the given name, which
+// is the job name of the pipeline given by --job_name.
+func (c *Client) FindJob(ctx context.Context, name string) (string, error) {
+	var resp struct {
+		Jobs []struct {
+			ID    string `json:"jid"`
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"jobs"`
+	}
+	if err := c.do(ctx, "GET", "/jobs/overview", nil, &resp); err != nil {
+		return "", err
+	}
+	for _, job := range resp.Jobs {
+		if job.Name == name && job.State == "RUNNING" {
+			return job.ID, nil
+		}
+	}
+	return "", errors.Errorf("no running job %v", name)
+}
+
+// TriggerSavepoint takes a savepoint of the job in the target directory and
+// waits for it to complete. If cancel is set, the job is cancelled once the
+// savepoint is taken. It returns the path of the savepoint, which can be
+// given as --savepoint_path to restart the job.
+func (c *Client) TriggerSavepoint(ctx context.Context, jobID, targetDir string, cancel bool) (string, error) {
+	req := map[string]interface{}{"target-directory": targetDir, "cancel-job": cancel}
+	var trigger struct {
+		RequestID string `json:"request-id"`
+	}
+	if err := c.do(ctx, "POST", "/jobs/"+jobID+"/savepoints", req, &trigger); err != nil {
+		return "", errors.WithContextf(err, "triggering savepoint of job %v", jobID)
+	}
+
+	interval := c.PollInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+	for {
+		var status struct {
+			Status struct {
+				ID string `json:"id"`
+			} `json:"status"`
+			Operation struct {
+				Location     string `json:"location"`
+				FailureCause struct {
+					Class      string `json:"class"`
+					StackTrace string `json:"stack-trace"`
+				} `json:"failure-cause"`
+			} `json:"operation"`
+		}
+		if err := c.do(ctx, "GET", "/jobs/"+jobID+"/savepoints/"+trigger.RequestID, nil, &status); err != nil {
+			return "", errors.WithContextf(err, "checking savepoint of job %v", jobID)
+		}
+		if status.Status.ID == "COMPLETED" {
+			if cause := status.Operation.FailureCause; cause.Class != "" {
+				return "", errors.Errorf("savepoint of job %v failed: %v\n%v", jobID, cause.Class, cause.StackTrace)
+			}
+			return status.Operation.Location, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, ret interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.URL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("%v %v: %v: %s", method, path, resp.Status, data)
+	}
+	return json.Unmarshal(data, ret)
+}
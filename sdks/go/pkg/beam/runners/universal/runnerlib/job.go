@@ -17,6 +17,7 @@ package runnerlib
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -29,6 +30,7 @@ import (
 	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
 	"github.com/apache/beam/sdks/go/pkg/beam/provision"
 	"github.com/golang/protobuf/proto"
+	google_protobuf "github.com/golang/protobuf/ptypes/struct"
 )
 
 // JobOptions capture the various options for submitting jobs
@@ -43,6 +45,11 @@ type JobOptions struct {
 
 	// Worker is the worker binary override.
 	Worker string
+
+	// RunnerOptions are runner-specific pipeline options, such as the
+	// savepoint to restore on Flink. They are passed to the runner as
+	// "beam:option:<name>:v1" and must be JSON-serializable.
+	RunnerOptions map[string]interface{}
 }
 
 // Prepare prepares a job to the given job service. It returns the preparation id
@@ -55,7 +62,7 @@ func Prepare(ctx context.Context, client jobpb.JobServiceClient, p *pb.Pipeline,
 		Experiments: append(opt.Experiments, "beam_fn_api"),
 	}
 
-	options, err := provision.OptionsToProto(raw)
+	options, err := optionsToProto(raw, opt.RunnerOptions)
 	if err != nil {
 		return "", "", "", errors.WithContext(err, "producing pipeline options")
 	}
@@ -71,6 +78,26 @@ func Prepare(ctx context.Context, client jobpb.JobServiceClient, p *pb.Pipeline,
 	return resp.GetPreparationId(), resp.GetArtifactStagingEndpoint().GetUrl(), resp.GetStagingSessionToken(), nil
 }
 
+// optionsToProto returns the pipeline options with the runner options
+// added.
+func optionsToProto(raw runtime.RawOptionsWrapper, extra map[string]interface{}) (*google_protobuf.Struct, error) {
+	if len(extra) == 0 {
+		return provision.OptionsToProto(raw)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		m["beam:option:"+k+":v1"] = v
+	}
+	return provision.OptionsToProto(m)
+}
+
 // Submit submits a job to the given job service. It returns a jobID, if successful.
 func Submit(ctx context.Context, client jobpb.JobServiceClient, id, token string) (string, error) {
 	req := &jobpb.RunJobRequest{
@@ -38,8 +38,23 @@ func init() {
 	beam.RegisterRunner("universal", Execute)
 }
 
+// Options configure ExecuteWithOptions.
+type Options struct {
+	// RunnerOptions are runner-specific pipeline options.
+	RunnerOptions map[string]interface{}
+	// Validate, if set, is called with the model pipeline before it is
+	// submitted. The pipeline is not submitted if it returns an error.
+	Validate func(*pb.Pipeline) error
+}
+
 // Execute executes the pipeline on a universal beam runner.
 func Execute(ctx context.Context, p *beam.Pipeline) error {
+	return ExecuteWithOptions(ctx, p, Options{})
+}
+
+// ExecuteWithOptions executes the pipeline on a universal beam runner with
+// the given options. It is meant for runner-specific wrappers.
+func ExecuteWithOptions(ctx context.Context, p *beam.Pipeline, options Options) error {
 	endpoint, err := jobopts.GetEndpoint()
 	if err != nil {
 		return err
@@ -49,23 +64,41 @@ func Execute(ctx context.Context, p *beam.Pipeline) error {
 	if err != nil {
 		return err
 	}
-	pipeline, err := graphx.Marshal(edges, &graphx.Options{Environment: createEnvironment(ctx)})
+	envs, err := jobopts.GetEnvironments()
+	if err != nil {
+		return err
+	}
+	environments := make(map[string]pb.Environment)
+	for name, config := range envs {
+		environments[name] = createEnvironment(ctx, config)
+	}
+	pipeline, err := graphx.Marshal(edges, &graphx.Options{
+		Environment:  createEnvironment(ctx, jobopts.GetEnvironmentConfig(ctx)),
+		Environments: environments,
+	})
 	if err != nil {
 		return errors.WithContextf(err, "generating model pipeline")
 	}
 
 	log.Info(ctx, proto.MarshalTextString(pipeline))
 
+	if options.Validate != nil {
+		if err := options.Validate(pipeline); err != nil {
+			return err
+		}
+	}
+
 	opt := &runnerlib.JobOptions{
-		Name:        jobopts.GetJobName(),
-		Experiments: jobopts.GetExperiments(),
-		Worker:      *jobopts.WorkerBinary,
+		Name:          jobopts.GetJobName(),
+		Experiments:   jobopts.GetExperiments(),
+		Worker:        *jobopts.WorkerBinary,
+		RunnerOptions: options.RunnerOptions,
 	}
 	_, err = runnerlib.Execute(ctx, pipeline, endpoint, opt, *jobopts.Async)
 	return err
 }
 
-func createEnvironment(ctx context.Context) pb.Environment {
+func createEnvironment(ctx context.Context, config string) pb.Environment {
 	var environment pb.Environment
 	switch urn := jobopts.GetEnvironmentUrn(ctx); urn {
 	case "beam:env:process:v1":
@@ -74,7 +107,6 @@ func createEnvironment(ctx context.Context) pb.Environment {
 	case "beam:env:docker:v1":
 		fallthrough
 	default:
-		config := jobopts.GetEnvironmentConfig(ctx)
 		payload := &pb.DockerPayload{ContainerImage: config}
 		serializedPayload, err := proto.Marshal(payload)
 		if err != nil {
@@ -0,0 +1,152 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/core/funcx"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+var (
+	timeType     = reflect.TypeOf((*time.Time)(nil)).Elem()
+	timestampSig = &funcx.Signature{Args: []reflect.Type{TType}, Return: []reflect.Type{UType}} // T -> U
+)
+
+// WithTimestamps assigns an event timestamp to each element of a
+// PCollection<T> using the given extractor function, fn : T -> time.Time or
+// fn : T -> EventTime, and returns a PCollection<T> with the same elements.
+// For example:
+//
+//    func eventTime(e LogEntry) time.Time {
+//          return e.Time
+//    }
+//
+//    entries = beam.WithTimestamps(s, eventTime, entries)
+//    windowed := beam.WindowInto(s, window.NewFixedWindows(time.Minute), entries)
+//
+// The extracted timestamps may arrive in any order. Use WithBoundedTimestamps
+// to guard against elements that are unexpectedly out of order.
+func WithTimestamps(s Scope, fn interface{}, col PCollection) PCollection {
+	s = s.Scope("beam.WithTimestamps")
+
+	validateTimestampFn(fn, col)
+	return ParDo(s, &withTimestampsFn{Fn: EncodedFunc{Fn: reflectx.MakeFunc(fn)}}, col)
+}
+
+// WithBoundedTimestamps is the same as WithTimestamps, except that the extracted
+// timestamps are expected to be out of order by at most the given bound. It
+// returns the PCollection<T> of the elements within the bound and the
+// PCollection<T> of the late elements, whose timestamp is more than bound
+// earlier than the latest timestamp seen so far in the same bundle. Both keep
+// the extracted timestamps. The late elements are counted as "late" in the
+// "beam.WithBoundedTimestamps" namespace, so that they can be monitored
+// rather than silently becoming late data downstream. For example:
+//
+//    entries, late := beam.WithBoundedTimestamps(s, eventTime, entries, time.Minute)
+//    textio.Write(s, "gs://bucket/late.txt", beam.ParDo(s, formatEntry, late))
+//
+// The latest timestamp is tracked per bundle, so elements are only compared
+// with the earlier elements of their bundle.
+func WithBoundedTimestamps(s Scope, fn interface{}, col PCollection, bound time.Duration) (PCollection, PCollection) {
+	s = s.Scope("beam.WithBoundedTimestamps")
+
+	validateTimestampFn(fn, col)
+	if bound < 0 {
+		panic(fmt.Sprintf("out-of-orderness bound must be non-negative: %v", bound))
+	}
+	return ParDo2(s, &boundedTimestampsFn{Fn: EncodedFunc{Fn: reflectx.MakeFunc(fn)}, Bound: bound}, col)
+}
+
+// validateTimestampFn panics if fn is not of the form T -> time.Time or
+// T -> EventTime for the element type T of the given PCollection.
+func validateTimestampFn(fn interface{}, col PCollection) {
+	ValidateNonCompositeType(col)
+
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() != 1 {
+		panic(fmt.Sprintf("timestamp function must be of the form T -> time.Time: %v", t))
+	}
+	if out := t.Out(0); out != timeType && out != EventTimeType {
+		panic(fmt.Sprintf("timestamp function must return time.Time or beam.EventTime: %v", t))
+	}
+	funcx.MustSatisfy(fn, funcx.Replace(funcx.Replace(timestampSig, TType, col.Type().Type()), UType, t.Out(0)))
+}
+
+// withTimestampsFn replaces the timestamp of each element with the one
+// extracted by the timestamp function.
+type withTimestampsFn struct {
+	// Fn is the encoded timestamp function.
+	Fn EncodedFunc `json:"fn"`
+
+	fn reflectx.Func1x1
+}
+
+func (f *withTimestampsFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+}
+
+func (f *withTimestampsFn) ProcessElement(elm T) (EventTime, T) {
+	return extractTimestamp(f.fn, elm), elm
+}
+
+// extractTimestamp returns the timestamp that fn extracts from the element.
+func extractTimestamp(fn reflectx.Func1x1, elm interface{}) EventTime {
+	switch v := fn.Call1x1(elm).(type) {
+	case time.Time:
+		return mtime.FromTime(v)
+	default:
+		return v.(EventTime)
+	}
+}
+
+// boundedTimestampsFn is withTimestampsFn that outputs the elements more than
+// Bound behind the latest timestamp of the bundle as late.
+type boundedTimestampsFn struct {
+	// Fn is the encoded timestamp function.
+	Fn EncodedFunc `json:"fn"`
+	// Bound is the allowed out-of-orderness.
+	Bound time.Duration `json:"bound"`
+
+	fn   reflectx.Func1x1
+	max  EventTime
+	late Counter
+}
+
+func (f *boundedTimestampsFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+	f.late = NewCounter("beam.WithBoundedTimestamps", "late")
+}
+
+func (f *boundedTimestampsFn) StartBundle(_ func(EventTime, T), _ func(EventTime, T)) {
+	f.max = mtime.MinTimestamp
+}
+
+func (f *boundedTimestampsFn) ProcessElement(ctx context.Context, elm T, emit, late func(EventTime, T)) {
+	ts := extractTimestamp(f.fn, elm)
+	if ts < f.max.Subtract(f.Bound) {
+		f.late.Inc(ctx, 1)
+		late(ts, elm)
+		return
+	}
+	f.max = mtime.Max(f.max, ts)
+	emit(ts, elm)
+}
@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beam_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/metrics"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(secondsTime)
+	beam.RegisterFunction(secondsEventTime)
+	beam.RegisterFunction(formatTimestamp)
+}
+
+func secondsTime(n int) time.Time {
+	return time.Unix(int64(n), 0)
+}
+
+func secondsEventTime(n int) beam.EventTime {
+	return mtime.FromMilliseconds(int64(n) * 1000)
+}
+
+func formatTimestamp(ts beam.EventTime, n int) string {
+	return fmt.Sprintf("%v@%v", n, ts.Milliseconds())
+}
+
+func TestWithTimestamps(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+	}{
+		{"time.Time", secondsTime},
+		{"EventTime", secondsEventTime},
+	}
+
+	for _, test := range tests {
+		p, s, in, exp := ptest.CreateList2([]int{1, 2, 30}, []interface{}{"1@1000", "2@2000", "30@30000"})
+		out := beam.WithTimestamps(s, test.fn, in)
+		passert.Equals(s, beam.ParDo(s, formatTimestamp, out), exp)
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("WithTimestamps(%v) failed: %v", test.name, err)
+		}
+	}
+}
+
+func TestWithBoundedTimestamps(t *testing.T) {
+	// Drop the late counters, which the metrics examples would dump.
+	defer metrics.Clear()
+
+	tests := []struct {
+		bound  time.Duration
+		onTime []interface{}
+		late   []interface{}
+	}{
+		{0, []interface{}{"1@1000", "20@20000", "30@30000"}, []interface{}{"10@10000"}},
+		{5 * time.Second, []interface{}{"1@1000", "20@20000", "30@30000"}, []interface{}{"10@10000"}},
+		{10 * time.Second, []interface{}{"1@1000", "20@20000", "10@10000", "30@30000"}, nil},
+		{time.Minute, []interface{}{"1@1000", "20@20000", "10@10000", "30@30000"}, nil},
+	}
+
+	for _, test := range tests {
+		p, s := beam.NewPipelineWithRoot()
+		in := beam.Create(s, 1, 20, 10, 30)
+		onTime, late := beam.WithBoundedTimestamps(s, secondsEventTime, in, test.bound)
+		passert.Equals(s, beam.ParDo(s, formatTimestamp, onTime), test.onTime...)
+		passert.Equals(s, beam.ParDo(s, formatTimestamp, late), test.late...)
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("WithBoundedTimestamps(%v) failed: %v", test.bound, err)
+		}
+	}
+}
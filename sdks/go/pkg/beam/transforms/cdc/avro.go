@@ -0,0 +1,296 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/linkedin/goavro"
+)
+
+// AvroOptions configure the decoding of Avro envelopes.
+type AvroOptions struct {
+	// Framed indicates that records are in the Confluent wire format, as
+	// written by the Confluent Avro converter. The header, which holds the
+	// schema registry id, is skipped. The given schema must be the schema
+	// of the records.
+	Framed bool
+}
+
+// ParseAvro decodes Debezium Avro envelopes in a PCollection<[]byte> into a
+// PCollection<T>, where T is the given change type. The envelopes must be
+// binary encoded with the given Avro schema. Record fields are matched to
+// struct fields as for ParseJSON, using the json tags. Empty elements, such
+// as Kafka tombstones, are dropped.
+func ParseAvro(s beam.Scope, t reflect.Type, schema string, col beam.PCollection, opts *AvroOptions) beam.PCollection {
+	s = s.Scope("cdc.ParseAvro")
+
+	validateChangeType(t)
+	if _, err := goavro.NewCodec(schema); err != nil {
+		panic(errors.Wrap(err, "invalid Avro schema"))
+	}
+
+	fn := &parseAvroFn{Type: beam.EncodedType{T: t}, Schema: schema}
+	if opts != nil {
+		fn.Framed = opts.Framed
+	}
+	return beam.ParDo(s, fn, col, beam.TypeDefinition{Var: beam.XType, T: t})
+}
+
+// confluentHeaderSize is the size of the Confluent wire format header: a
+// zero magic byte followed by a 4-byte schema id.
+const confluentHeaderSize = 5
+
+type parseAvroFn struct {
+	// Type is the encoded change type.
+	Type beam.EncodedType `json:"type"`
+	// Schema is the Avro schema of the envelopes.
+	Schema string `json:"schema"`
+	// Framed indicates that records have a Confluent wire format header.
+	Framed bool `json:"framed,omitempty"`
+
+	codec  *goavro.Codec
+	schema *avroSchema
+}
+
+func (f *parseAvroFn) Setup() error {
+	codec, err := goavro.NewCodec(f.Schema)
+	if err != nil {
+		return errors.Wrap(err, "invalid Avro schema")
+	}
+	schema, err := parseAvroSchema(f.Schema)
+	if err != nil {
+		return err
+	}
+	f.codec, f.schema = codec, schema
+	return nil
+}
+
+func (f *parseAvroFn) ProcessElement(data []byte, emit func(beam.X)) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if f.Framed {
+		if len(data) < confluentHeaderSize || data[0] != 0 {
+			return errors.New("invalid Confluent wire format header")
+		}
+		data = data[confluentHeaderSize:]
+	}
+
+	native, _, err := f.codec.NativeFromBinary(data)
+	if err != nil {
+		return errors.Wrap(err, "invalid Avro change envelope")
+	}
+
+	// Reuse the JSON decoding of envelopes, once unions are unwrapped.
+	raw, err := json.Marshal(f.schema.normalize(f.schema.root, "", native))
+	if err != nil {
+		return errors.Wrap(err, "failed to convert Avro change envelope")
+	}
+	val := reflect.New(f.Type.T) // val : *T
+	if err := json.Unmarshal(raw, val.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode change envelope into %v", f.Type.T)
+	}
+	emit(val.Elem().Interface()) // emit(*val)
+	return nil
+}
+
+// avroSchema is a parsed Avro schema, used to unwrap the union values that
+// goavro decodes as single-entry maps keyed by the branch type.
+type avroSchema struct {
+	root interface{}
+	// named holds the named types of the schema, by full name.
+	named map[string]interface{}
+}
+
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		// A primitive type may be given as a bare name.
+		root = schema
+	}
+	ret := &avroSchema{root: root, named: make(map[string]interface{})}
+	ret.collect(root, "")
+	return ret, nil
+}
+
+// collect registers the named types of the schema.
+func (a *avroSchema) collect(schema interface{}, namespace string) {
+	switch t := schema.(type) {
+	case []interface{}:
+		for _, branch := range t {
+			a.collect(branch, namespace)
+		}
+	case map[string]interface{}:
+		name, ns := fullName(t, namespace)
+		if name != "" {
+			a.named[name] = t
+		}
+		switch t["type"] {
+		case "record", "error":
+			fields, _ := t["fields"].([]interface{})
+			for _, field := range fields {
+				if f, ok := field.(map[string]interface{}); ok {
+					a.collect(f["type"], ns)
+				}
+			}
+		case "array":
+			a.collect(t["items"], ns)
+		case "map":
+			a.collect(t["values"], ns)
+		default:
+			if _, ok := t["type"].(string); !ok {
+				a.collect(t["type"], ns)
+			}
+		}
+	}
+}
+
+// fullName returns the full name of a named type and the namespace of its
+// nested types. It returns an empty name for unnamed types.
+func fullName(t map[string]interface{}, namespace string) (string, string) {
+	name, _ := t["name"].(string)
+	if name == "" {
+		return "", namespace
+	}
+	if ns, ok := t["namespace"].(string); ok {
+		namespace = ns
+	}
+	if !strings.Contains(name, ".") && namespace != "" {
+		name = namespace + "." + name
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name, name[:i]
+	}
+	return name, ""
+}
+
+// normalize converts a native goavro value of the given schema to a value
+// that marshals to the equivalent JSON, by unwrapping unions.
+func (a *avroSchema) normalize(schema interface{}, namespace string, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	switch t := schema.(type) {
+	case string:
+		if named, ok := a.lookup(t, namespace); ok {
+			return a.normalize(named, namespace, v)
+		}
+		return v // primitive
+
+	case []interface{}:
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return v
+		}
+		for key, value := range m {
+			for _, branch := range t {
+				if a.typeName(branch, namespace) == key {
+					return a.normalize(branch, namespace, value)
+				}
+			}
+			return value
+		}
+
+	case map[string]interface{}:
+		_, ns := fullName(t, namespace)
+		switch t["type"] {
+		case "record", "error":
+			rec, ok := v.(map[string]interface{})
+			if !ok {
+				return v
+			}
+			ret := make(map[string]interface{}, len(rec))
+			fields, _ := t["fields"].([]interface{})
+			for _, field := range fields {
+				f, ok := field.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _ := f["name"].(string)
+				if value, ok := rec[name]; ok {
+					ret[name] = a.normalize(f["type"], ns, value)
+				}
+			}
+			return ret
+
+		case "array":
+			list, ok := v.([]interface{})
+			if !ok {
+				return v
+			}
+			ret := make([]interface{}, len(list))
+			for i, elm := range list {
+				ret[i] = a.normalize(t["items"], ns, elm)
+			}
+			return ret
+
+		case "map":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return v
+			}
+			ret := make(map[string]interface{}, len(m))
+			for key, elm := range m {
+				ret[key] = a.normalize(t["values"], ns, elm)
+			}
+			return ret
+
+		case "enum", "fixed":
+			return v
+
+		default:
+			return a.normalize(t["type"], ns, v)
+		}
+	}
+	return v
+}
+
+// lookup returns the named type, resolving the name in the namespace first.
+func (a *avroSchema) lookup(name, namespace string) (interface{}, bool) {
+	if namespace != "" && !strings.Contains(name, ".") {
+		if t, ok := a.named[namespace+"."+name]; ok {
+			return t, true
+		}
+	}
+	t, ok := a.named[name]
+	return t, ok
+}
+
+// typeName returns the name of the type, as used by goavro to key union
+// values.
+func (a *avroSchema) typeName(schema interface{}, namespace string) string {
+	switch t := schema.(type) {
+	case string:
+		if named, ok := a.lookup(t, namespace); ok {
+			return a.typeName(named, namespace)
+		}
+		return t
+	case map[string]interface{}:
+		if name, _ := fullName(t, namespace); name != "" {
+			return name
+		}
+		if typ, ok := t["type"].(string); ok {
+			return typ
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdc contains transformations for processing change data capture
+// (CDC) streams in the Debezium envelope format.
+//
+// A change is decoded into a user-defined struct that embeds Metadata and
+// has Before and After fields of the row type. For example:
+//
+//    type Customer struct {
+//        ID    int64  `json:"id"`
+//        Email string `json:"email"`
+//    }
+//
+//    type CustomerChange struct {
+//        cdc.Metadata
+//        Before *Customer `json:"before"`
+//        After  *Customer `json:"after"`
+//    }
+//
+//    changes := cdc.ParseJSON(s, reflect.TypeOf(CustomerChange{}), messages)
+//
+// Before is nil for inserts and After is nil for deletes.
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=cdc --identifiers=parseJSONFn,parseAvroFn,latestFn
+//go:generate go fmt
+
+// Op is the operation of a change.
+type Op string
+
+// Operations of changes, as encoded by Debezium.
+const (
+	Create   Op = "c"
+	Update   Op = "u"
+	Delete   Op = "d"
+	Read     Op = "r" // read as part of a snapshot
+	Truncate Op = "t"
+)
+
+// Source describes the origin of a change in the source database. Which
+// fields are set depends on the connector.
+type Source struct {
+	Version   string `json:"version,omitempty"`
+	Connector string `json:"connector,omitempty"`
+	// Name is the logical name of the server, as configured for the
+	// connector.
+	Name string `json:"name,omitempty"`
+	// TsMs is the time the change was made in the database, in
+	// milliseconds since the epoch.
+	TsMs   int64  `json:"ts_ms,omitempty"`
+	DB     string `json:"db,omitempty"`
+	Schema string `json:"schema,omitempty"`
+	Table  string `json:"table,omitempty"`
+	// LSN is the log sequence number of the change, if the database has
+	// one, such as PostgreSQL.
+	LSN int64 `json:"lsn,omitempty"`
+}
+
+// Metadata holds the fields of a change envelope besides the row images.
+// It is meant to be embedded in the change type.
+type Metadata struct {
+	Op     Op     `json:"op"`
+	Source Source `json:"source"`
+	// TsMs is the time the change was processed by the connector, in
+	// milliseconds since the epoch.
+	TsMs int64 `json:"ts_ms,omitempty"`
+}
+
+// ChangeMetadata returns the metadata of the change. It makes any type that
+// embeds Metadata a Change.
+func (m Metadata) ChangeMetadata() Metadata {
+	return m
+}
+
+// Change is implemented by change types, usually by embedding Metadata.
+type Change interface {
+	ChangeMetadata() Metadata
+}
+
+var changeType = reflect.TypeOf((*Change)(nil)).Elem()
+
+// before reports whether the change a happened before the change b, ordered
+// by the source time and then by log sequence number.
+func before(a, b Metadata) bool {
+	if a.Source.TsMs != b.Source.TsMs {
+		return a.Source.TsMs < b.Source.TsMs
+	}
+	if a.Source.LSN != b.Source.LSN {
+		return a.Source.LSN < b.Source.LSN
+	}
+	return a.TsMs < b.TsMs
+}
+
+func validateChangeType(t reflect.Type) {
+	if t.Kind() != reflect.Struct || !t.Implements(changeType) {
+		panic(fmt.Sprintf("change type %v must be a struct that embeds cdc.Metadata", t))
+	}
+}
+
+// ParseJSON decodes Debezium JSON envelopes in a PCollection<[]byte> into a
+// PCollection<T>, where T is the given change type. Envelopes may include
+// the schema, i.e., be of the form {"schema": ..., "payload": ...}. Empty
+// elements, such as Kafka tombstones, are dropped.
+func ParseJSON(s beam.Scope, t reflect.Type, col beam.PCollection) beam.PCollection {
+	s = s.Scope("cdc.ParseJSON")
+
+	validateChangeType(t)
+	return beam.ParDo(s, &parseJSONFn{Type: beam.EncodedType{T: t}}, col, beam.TypeDefinition{Var: beam.XType, T: t})
+}
+
+type parseJSONFn struct {
+	// Type is the encoded change type.
+	Type beam.EncodedType `json:"type"`
+}
+
+func (f *parseJSONFn) ProcessElement(data []byte, emit func(beam.X)) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var wrapper struct {
+		Schema  json.RawMessage `json:"schema"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return errors.Wrap(err, "invalid change envelope")
+	}
+	if wrapper.Schema != nil && wrapper.Payload != nil {
+		data = wrapper.Payload
+		if string(data) == "null" {
+			return nil
+		}
+	}
+
+	val := reflect.New(f.Type.T) // val : *T
+	if err := json.Unmarshal(data, val.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode change envelope into %v", f.Type.T)
+	}
+	emit(val.Elem().Interface()) // emit(*val)
+	return nil
+}
+
+// LatestPerKey compacts a PCollection<KV<K,T>> of changes, where T is a change
+// type and K is usually the primary key of the row, to the latest change of
+// each key. Changes are ordered by the source timestamp and then by log
+// sequence number. Deletes are kept, so that downstream sinks can remove the
+// row; they can be dropped with a filter if not needed.
+func LatestPerKey(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("cdc.LatestPerKey")
+
+	_, t := beam.ValidateKVType(col)
+	validateChangeType(t.Type())
+	return beam.ParDo(s, latestFn, beam.GroupByKey(s, col))
+}
+
+func latestFn(key beam.X, changes func(*beam.Y) bool, emit func(beam.X, beam.Y)) {
+	var latest, change beam.Y
+	found := false
+	for changes(&change) {
+		if !found || !before(change.(Change).ChangeMetadata(), latest.(Change).ChangeMetadata()) {
+			latest = change
+			found = true
+		}
+	}
+	if found {
+		emit(key, latest)
+	}
+}
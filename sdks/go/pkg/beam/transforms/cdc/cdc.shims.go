@@ -0,0 +1,235 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: cdc.shims.go
+
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(latestFn)
+	runtime.RegisterType(reflect.TypeOf((*parseAvroFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*parseJSONFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parseAvroFn)(nil)).Elem(), wrapMakerParseAvroFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parseJSONFn)(nil)).Elem(), wrapMakerParseJSONFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(typex.X)) error)(nil)).Elem(), funcMakerSliceOfByteEmitTypex۰XГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, func(*typex.Y) bool, func(typex.X, typex.Y)))(nil)).Elem(), funcMakerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X))(nil)).Elem(), emitMakerTypex۰X)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X, typex.Y))(nil)).Elem(), emitMakerTypex۰XTypex۰Y)
+	exec.RegisterInput(reflect.TypeOf((*func(*typex.Y) bool)(nil)).Elem(), iterMakerTypex۰Y)
+}
+
+func wrapMakerParseAvroFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parseAvroFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []byte, a1 func(typex.X)) error { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+func wrapMakerParseJSONFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parseJSONFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []byte, a1 func(typex.X)) error { return dfn.ProcessElement(a0, a1) }),
+	}
+}
+
+type callerSliceOfByteEmitTypex۰XГError struct {
+	fn func([]byte, func(typex.X)) error
+}
+
+func funcMakerSliceOfByteEmitTypex۰XГError(fn interface{}) reflectx.Func {
+	f := fn.(func([]byte, func(typex.X)) error)
+	return &callerSliceOfByteEmitTypex۰XГError{fn: f}
+}
+
+func (c *callerSliceOfByteEmitTypex۰XГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfByteEmitTypex۰XГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfByteEmitTypex۰XГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].([]byte), args[1].(func(typex.X)))
+	return []interface{}{out0}
+}
+
+func (c *callerSliceOfByteEmitTypex۰XГError) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.([]byte), arg1.(func(typex.X)))
+}
+
+type callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ struct {
+	fn func(typex.X, func(*typex.Y) bool, func(typex.X, typex.Y))
+}
+
+func funcMakerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, func(*typex.Y) bool, func(typex.X, typex.Y)))
+	return &callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ{fn: f}
+}
+
+func (c *callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(typex.X), args[1].(func(*typex.Y) bool), args[2].(func(typex.X, typex.Y)))
+	return []interface{}{}
+}
+
+func (c *callerTypex۰XIterTypex۰YEmitTypex۰XTypex۰YГ) Call3x0(arg0, arg1, arg2 interface{}) {
+	c.fn(arg0.(typex.X), arg1.(func(*typex.Y) bool), arg2.(func(typex.X, typex.Y)))
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerTypex۰X(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰X
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰X(val typex.X) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerTypex۰XTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰XTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰XTypex۰Y(key typex.X, val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerTypex۰Y(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readTypex۰Y
+	return ret
+}
+
+func (v *iterNative) readTypex۰Y(value *typex.Y) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(typex.Y)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,162 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/linkedin/goavro"
+)
+
+type customer struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+type customerChange struct {
+	Metadata
+	Before *customer `json:"before"`
+	After  *customer `json:"after"`
+}
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*customerChange)(nil)).Elem())
+	beam.RegisterFunction(formatChange)
+	beam.RegisterFunction(keyByID)
+	beam.RegisterFunction(dropKey)
+}
+
+func formatChange(c customerChange) string {
+	ret := fmt.Sprintf("%v@%v", c.Op, c.Source.TsMs)
+	if c.Before != nil {
+		ret += fmt.Sprintf(" %v:%v", c.Before.ID, c.Before.Email)
+	}
+	if c.After != nil {
+		ret += fmt.Sprintf(" -> %v:%v", c.After.ID, c.After.Email)
+	}
+	return ret
+}
+
+func keyByID(c customerChange) (int64, customerChange) {
+	if c.After != nil {
+		return c.After.ID, c
+	}
+	return c.Before.ID, c
+}
+
+func dropKey(_ int64, c customerChange) customerChange {
+	return c
+}
+
+func TestParseJSON(t *testing.T) {
+	in := [][]byte{
+		[]byte(`{"op":"c","source":{"ts_ms":1,"table":"customers"},"before":null,"after":{"id":1,"email":"a@x"}}`),
+		[]byte(`{"schema":{"type":"struct"},"payload":{"op":"u","source":{"ts_ms":2},"before":{"id":1,"email":"a@x"},"after":{"id":1,"email":"b@x"}}}`),
+		[]byte(`{"op":"d","source":{"ts_ms":3},"before":{"id":1,"email":"b@x"},"after":null}`),
+		{}, // tombstone
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	changes := ParseJSON(s, reflect.TypeOf(customerChange{}), beam.CreateList(s, in))
+	passert.Equals(s, beam.ParDo(s, formatChange, changes),
+		"c@1 -> 1:a@x", "u@2 1:a@x -> 1:b@x", "d@3 1:b@x")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+}
+
+const envelopeSchema = `{
+	"type": "record", "name": "Envelope", "namespace": "db.shop.customers",
+	"fields": [
+		{"name": "before", "type": ["null", {"type": "record", "name": "Value", "fields": [
+			{"name": "id", "type": "long"},
+			{"name": "email", "type": ["null", "string"]}
+		]}]},
+		{"name": "after", "type": ["null", "Value"]},
+		{"name": "source", "type": {"type": "record", "name": "Source", "namespace": "io.debezium", "fields": [
+			{"name": "ts_ms", "type": "long"},
+			{"name": "table", "type": ["null", "string"]}
+		]}},
+		{"name": "op", "type": "string"},
+		{"name": "ts_ms", "type": ["null", "long"]}
+	]
+}`
+
+func TestParseAvro(t *testing.T) {
+	codec, err := goavro.NewCodec(envelopeSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := map[string]interface{}{
+		"before": nil,
+		"after": map[string]interface{}{"db.shop.customers.Value": map[string]interface{}{
+			"id":    int64(7),
+			"email": map[string]interface{}{"string": "a@x"},
+		}},
+		"source": map[string]interface{}{"ts_ms": int64(5), "table": map[string]interface{}{"string": "customers"}},
+		"op":     "c",
+		"ts_ms":  map[string]interface{}{"long": int64(6)},
+	}
+	data, err := codec.BinaryFromNative(nil, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	framed := append([]byte{0, 0, 0, 0, 42}, data...)
+
+	tests := []struct {
+		data []byte
+		opts *AvroOptions
+	}{
+		{data, nil},
+		{framed, &AvroOptions{Framed: true}},
+	}
+	for _, test := range tests {
+		p, s := beam.NewPipelineWithRoot()
+		changes := ParseAvro(s, reflect.TypeOf(customerChange{}), envelopeSchema, beam.Create(s, test.data), test.opts)
+		passert.Equals(s, beam.ParDo(s, formatChange, changes), "c@5 -> 7:a@x")
+
+		if err := ptest.Run(p); err != nil {
+			t.Errorf("ParseAvro(%v) failed: %v", test.opts, err)
+		}
+	}
+}
+
+func TestLatestPerKey(t *testing.T) {
+	in := [][]byte{
+		[]byte(`{"op":"u","source":{"ts_ms":2},"before":{"id":1,"email":"a@x"},"after":{"id":1,"email":"b@x"}}`),
+		[]byte(`{"op":"c","source":{"ts_ms":1},"after":{"id":1,"email":"a@x"}}`),
+		[]byte(`{"op":"c","source":{"ts_ms":1},"after":{"id":2,"email":"c@x"}}`),
+		[]byte(`{"op":"d","source":{"ts_ms":3},"before":{"id":2,"email":"c@x"}}`),
+		[]byte(`{"op":"c","source":{"ts_ms":1,"lsn":10},"after":{"id":3,"email":"d@x"}}`),
+		[]byte(`{"op":"u","source":{"ts_ms":1,"lsn":11},"before":{"id":3,"email":"d@x"},"after":{"id":3,"email":"e@x"}}`),
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	changes := ParseJSON(s, reflect.TypeOf(customerChange{}), beam.CreateList(s, in))
+	latest := LatestPerKey(s, beam.ParDo(s, keyByID, changes))
+	passert.Equals(s, beam.ParDo(s, formatChange, beam.ParDo(s, dropKey, latest)),
+		"u@2 1:a@x -> 1:b@x", "d@3 2:c@x", "u@1 3:d@x -> 3:e@x")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("LatestPerKey failed: %v", err)
+	}
+}
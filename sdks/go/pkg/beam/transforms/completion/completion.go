@@ -0,0 +1,109 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package completion notifies downstream systems that the data of a window
+// is complete. For example:
+//
+//	hourly := beam.WindowInto(s, window.NewFixedWindows(time.Hour), events)
+//	done := completion.Notify(s, beam.ParDo(s, regionOf, hourly))
+//	pubsubio.Write(s, project, "hours-complete", beam.ParDo(s, encode, done))
+//
+// publishes a notification per hour and region once the events of the hour
+// are final.
+package completion
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/stats"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=completion --identifiers=keySpaceFn,globalKeySpaceFn,notifyFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Notification)(nil)).Elem())
+}
+
+// Notification reports that the data of a window and key space is
+// complete.
+type Notification struct {
+	// KeySpace is the key formatted with fmt.Sprint, or empty for a
+	// PCollection that is not keyed.
+	KeySpace string `json:"key_space,omitempty"`
+	// Start and End are the bounds of the window. They are zero for the
+	// global window.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Elements is the number of elements of the window and key space.
+	Elements int `json:"elements"`
+}
+
+// Notify returns a PCollection<Notification> with a single notification
+// per window and key space of the PCollection, which is the key of a
+// PCollection<KV<K,V>> and a single key space otherwise. A notification is
+// emitted once the watermark passes the end of the window, when the
+// elements of the window are final as this SDK does not support late data.
+// Windows without elements are not notified, as there is nothing to
+// trigger on; downstream systems that need every window should treat a
+// missing notification after the window end plus the pipeline latency as
+// an empty window.
+func Notify(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("completion.Notify")
+
+	var keys beam.PCollection
+	switch {
+	case typex.IsKV(col.Type()):
+		keys = beam.ParDo(s, keySpaceFn, col)
+	case typex.IsCoGBK(col.Type()):
+		panic(fmt.Sprintf("cannot notify on grouped PCollection %v", col))
+	default:
+		keys = beam.ParDo(s, globalKeySpaceFn, col)
+	}
+	return beam.ParDo(s, notifyFn, stats.Count(s, keys))
+}
+
+func keySpaceFn(key beam.X, _ beam.Y) string {
+	return fmt.Sprint(key)
+}
+
+func globalKeySpaceFn(_ beam.T) string {
+	return ""
+}
+
+func notifyFn(w beam.Window, key string, n int) (Notification, error) {
+	ret := Notification{KeySpace: key, Elements: n}
+	switch win := w.(type) {
+	case window.IntervalWindow:
+		ret.Start = toTime(win.Start)
+		ret.End = toTime(win.End)
+	case window.GlobalWindow:
+	default:
+		return Notification{}, errors.Errorf("unexpected window type %T for %v", w, w)
+	}
+	return ret, nil
+}
+
+func toTime(t mtime.Time) time.Time {
+	return time.Unix(0, t.Milliseconds()*int64(time.Millisecond)).UTC()
+}
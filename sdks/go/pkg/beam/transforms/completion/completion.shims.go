@@ -0,0 +1,118 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: completion.shims.go
+
+package completion
+
+import (
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(globalKeySpaceFn)
+	runtime.RegisterFunction(keySpaceFn)
+	runtime.RegisterFunction(notifyFn)
+	runtime.RegisterType(reflect.TypeOf((*Notification)(nil)).Elem())
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) string)(nil)).Elem(), funcMakerTypex۰TГString)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.Window, string, int) (Notification, error))(nil)).Elem(), funcMakerTypex۰WindowStringIntГNotificationError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) string)(nil)).Elem(), funcMakerTypex۰XTypex۰YГString)
+}
+
+type callerTypex۰TГString struct {
+	fn func(typex.T) string
+}
+
+func funcMakerTypex۰TГString(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.T) string)
+	return &callerTypex۰TГString{fn: f}
+}
+
+func (c *callerTypex۰TГString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰TГString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰TГString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.T))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰TГString) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(typex.T))
+}
+
+type callerTypex۰WindowStringIntГNotificationError struct {
+	fn func(typex.Window, string, int) (Notification, error)
+}
+
+func funcMakerTypex۰WindowStringIntГNotificationError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.Window, string, int) (Notification, error))
+	return &callerTypex۰WindowStringIntГNotificationError{fn: f}
+}
+
+func (c *callerTypex۰WindowStringIntГNotificationError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰WindowStringIntГNotificationError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰WindowStringIntГNotificationError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.Window), args[1].(string), args[2].(int))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰WindowStringIntГNotificationError) Call3x2(arg0, arg1, arg2 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.Window), arg1.(string), arg2.(int))
+}
+
+type callerTypex۰XTypex۰YГString struct {
+	fn func(typex.X, typex.Y) string
+}
+
+func funcMakerTypex۰XTypex۰YГString(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, typex.Y) string)
+	return &callerTypex۰XTypex۰YГString{fn: f}
+}
+
+func (c *callerTypex۰XTypex۰YГString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X), args[1].(typex.Y))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XTypex۰YГString) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(typex.X), arg1.(typex.Y))
+}
+
+// DO NOT MODIFY: GENERATED CODE
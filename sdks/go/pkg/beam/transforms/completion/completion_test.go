@@ -0,0 +1,69 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(eventTime)
+	beam.RegisterFunction(regionOf)
+	beam.RegisterFunction(formatFn)
+}
+
+// eventTime returns the timestamp of a "<region>:<minutes>" event.
+func eventTime(e string) beam.EventTime {
+	m, _ := strconv.Atoi(strings.Split(e, ":")[1])
+	return mtime.FromMilliseconds(int64(m) * 60000)
+}
+
+func regionOf(e string) (string, string) {
+	return strings.Split(e, ":")[0], e
+}
+
+func formatFn(n Notification) string {
+	return fmt.Sprintf("%v@%v-%v:%v", n.KeySpace, n.Start.Hour(), n.End.Hour(), n.Elements)
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+func TestNotify(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "eu:1", "eu:2", "us:3", "eu:70")
+	stamped := beam.WithTimestamps(s, eventTime, col)
+	hourly := beam.WindowInto(s, window.NewFixedWindows(time.Hour), stamped)
+
+	passert.Equals(s, beam.ParDo(s, formatFn, Notify(s, beam.ParDo(s, regionOf, hourly))),
+		"eu@0-1:2", "us@0-1:1", "eu@1-2:1")
+	passert.Equals(s, beam.ParDo(s, formatFn, Notify(s, hourly)), "@0-1:3", "@1-2:1")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
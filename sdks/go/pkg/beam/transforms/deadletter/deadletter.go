@@ -0,0 +1,215 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deadletter collects the failed elements of the steps of a pipeline
+// into one dead-letter collection. For example:
+//
+//    dl := deadletter.NewCollector(deadletter.Options{MaxFailureRate: 0.1})
+//    orders := dl.WithFailures(s, "ParseOrder", parseOrder, lines)
+//    totals := dl.WithFailures(s, "Price", price, orders)
+//    ...
+//    dl.Write(s, func(s beam.Scope, col beam.PCollection) {
+//        textio.Write(s, "gs://bucket/deadletter.json", col)
+//    })
+//
+// writes the elements that parseOrder or price failed on, with the step and
+// error, as JSON lines. The pipeline fails if more than 10% of the elements
+// of a step fail.
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stepfn"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=deadletter --identifiers=withFailuresFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Failure)(nil)).Elem())
+}
+
+// Failure is an element that a step failed on.
+type Failure struct {
+	// Step is the name of the step.
+	Step string `json:"step"`
+	// Element is the element, encoded as JSON if possible and otherwise
+	// formatted with fmt.Sprint.
+	Element string `json:"element"`
+	// Error is the error returned by the step, or the value it panicked
+	// with.
+	Error string `json:"error"`
+}
+
+// Options configure a Collector.
+type Options struct {
+	// MaxFailureRate, if positive, is the maximum fraction of the elements
+	// of a step that may fail. If a bundle of a step has a higher failure
+	// rate, the bundle and thus the pipeline fails. This stops the pipeline
+	// early if, for example, the input is in an unexpected format. The rate
+	// across the pipeline is given by the counters of the step.
+	MaxFailureRate float64
+	// MinElements is the number of elements of a step a bundle processes
+	// before its failure rate is checked. If zero, 100 is used.
+	MinElements int
+}
+
+// Collector collects the failures of the steps wrapped by WithFailures.
+type Collector struct {
+	opts     Options
+	steps    map[string]bool
+	failures []beam.PCollection
+}
+
+// NewCollector returns a new collector.
+func NewCollector(opts Options) *Collector {
+	if opts.MaxFailureRate < 0 || opts.MaxFailureRate > 1 {
+		panic(fmt.Sprintf("invalid failure rate: %v", opts.MaxFailureRate))
+	}
+	if opts.MinElements == 0 {
+		opts.MinElements = 100
+	}
+	return &Collector{opts: opts, steps: make(map[string]bool)}
+}
+
+// WithFailures applies fn to each element of the PCollection<A> as the named
+// step, where fn is of the form A -> B or A -> (B, error). It returns the
+// PCollection<B> of the outputs of fn for the elements it succeeds on. The
+// elements that fn returns an error for or panics on are added to the
+// failures of the collector. The number of elements and failures of the step
+// are reported as the counters "<step>.elements" and "<step>.failures" in the
+// "deadletter" namespace.
+func (c *Collector) WithFailures(s beam.Scope, step string, fn interface{}, col beam.PCollection) beam.PCollection {
+	if c.steps[step] {
+		panic(fmt.Sprintf("duplicate step %v", step))
+	}
+	c.steps[step] = true
+	s = s.Scope(step)
+
+	t, _ := stepfn.Check(fn, col.Type().Type(), false)
+	out, failures := beam.ParDo2(s, &withFailuresFn{
+		Step:           step,
+		Fn:             beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)},
+		MaxFailureRate: c.opts.MaxFailureRate,
+		MinElements:    c.opts.MinElements,
+	}, col, beam.TypeDefinition{Var: beam.YType, T: t})
+	c.failures = append(c.failures, failures)
+	return out
+}
+
+// Add adds the PCollection<Failure> of the failures of the named step, such
+// as produced by transforms of other packages, to the failures of the
+// collector.
+func (c *Collector) Add(step string, failures beam.PCollection) {
+	if c.steps[step] {
+		panic(fmt.Sprintf("duplicate step %v", step))
+	}
+	if t := failures.Type().Type(); t != reflect.TypeOf(Failure{}) {
+		panic(fmt.Sprintf("PCollection<%v> is not a PCollection<Failure>", t))
+	}
+	c.steps[step] = true
+	c.failures = append(c.failures, failures)
+}
+
+// Failures returns the PCollection<Failure> of the failures of all steps
+// wrapped so far.
+func (c *Collector) Failures(s beam.Scope) beam.PCollection {
+	s = s.Scope("deadletter.Failures")
+
+	if len(c.failures) == 0 {
+		return stepfn.Empty(s, reflect.TypeOf(Failure{}))
+	}
+	return beam.Flatten(s, c.failures...)
+}
+
+// Write writes the failures of all steps wrapped so far as JSON with the
+// sink, which is given a PCollection<string>.
+func (c *Collector) Write(s beam.Scope, sink func(beam.Scope, beam.PCollection)) {
+	failures := c.Failures(s)
+	s = s.Scope("deadletter.Write")
+	stepfn.WriteJSON(s, failures, sink)
+}
+
+// withFailuresFn applies Fn and outputs its errors as failures.
+type withFailuresFn struct {
+	Step           string           `json:"step"`
+	Fn             beam.EncodedFunc `json:"fn"`
+	MaxFailureRate float64          `json:"max_failure_rate,omitempty"`
+	MinElements    int              `json:"min_elements"`
+
+	fn       reflectx.Func
+	elements beam.Counter
+	failures beam.Counter
+	// seen and failed are the number of elements and failures of the
+	// bundle.
+	seen, failed int
+}
+
+func (f *withFailuresFn) Setup() {
+	f.fn = f.Fn.Fn
+	f.elements = beam.NewCounter("deadletter", f.Step+".elements")
+	f.failures = beam.NewCounter("deadletter", f.Step+".failures")
+}
+
+func (f *withFailuresFn) StartBundle() {
+	f.seen, f.failed = 0, 0
+}
+
+func (f *withFailuresFn) ProcessElement(ctx context.Context, elm beam.X, emit func(beam.Y), fail func(Failure)) error {
+	f.elements.Inc(ctx, 1)
+	out, err := f.call(elm)
+	if err == nil {
+		emit(out)
+		return f.check(false)
+	}
+
+	f.failures.Inc(ctx, 1)
+	fail(Failure{Step: f.Step, Element: stepfn.Format(elm), Error: err.Error()})
+	return f.check(true)
+}
+
+// call calls the function and converts panics to errors.
+func (f *withFailuresFn) call(elm interface{}) (out interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	return stepfn.Call(f.fn, elm)
+}
+
+// check counts the element and returns an error if the failure rate of the
+// bundle is too high.
+func (f *withFailuresFn) check(failed bool) error {
+	if f.MaxFailureRate <= 0 {
+		return nil
+	}
+
+	f.seen++
+	if failed {
+		f.failed++
+	}
+	if f.seen >= f.MinElements && float64(f.failed) > f.MaxFailureRate*float64(f.seen) {
+		return errors.Errorf("step %v failed on %v of %v elements of the bundle, more than the maximum failure rate %v", f.Step, f.failed, f.seen, f.MaxFailureRate)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(strconv.Atoi)
+	beam.RegisterFunction(inverse)
+}
+
+func inverse(n int) int {
+	return 100 / n // panics for 0
+}
+
+func TestWithFailures(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	dl := NewCollector(Options{})
+	lines := beam.Create(s, "1", "x", "0", "4")
+	numbers := dl.WithFailures(s, "Parse", strconv.Atoi, lines)
+	inverses := dl.WithFailures(s, "Inverse", inverse, numbers)
+	passert.Equals(s, inverses, 100, 25)
+	passert.Equals(s, dl.Failures(s),
+		Failure{Step: "Parse", Element: `"x"`, Error: `strconv.Atoi: parsing "x": invalid syntax`},
+		Failure{Step: "Inverse", Element: "0", Error: "panic: runtime error: integer divide by zero"})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("WithFailures failed: %v", err)
+	}
+}
+
+func TestMaxFailureRate(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	dl := NewCollector(Options{MaxFailureRate: 0.5, MinElements: 2})
+	lines := beam.Create(s, "1", "x", "y", "z")
+	dl.WithFailures(s, "ParseMostlyBad", strconv.Atoi, lines)
+
+	err := ptest.Run(p)
+	if err == nil || !strings.Contains(err.Error(), "maximum failure rate") {
+		t.Errorf("WithFailures = %v, want failure rate error", err)
+	}
+}
+
+func TestNoFailures(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	passert.Empty(s, NewCollector(Options{}).Failures(s))
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Failures failed: %v", err)
+	}
+}
+
+// TestFailureRatePerBundle tests that the failure rate is checked for each
+// bundle, rather than across all bundles of the worker.
+func TestFailureRatePerBundle(t *testing.T) {
+	fn := &withFailuresFn{Step: "Parse", Fn: beam.EncodedFunc{Fn: reflectx.MakeFunc(strconv.Atoi)}, MaxFailureRate: 0.3, MinElements: 2}
+	fn.Setup()
+	ctx := context.Background()
+	emit, fail := func(beam.Y) {}, func(Failure) {}
+
+	fn.StartBundle()
+	if err := fn.ProcessElement(ctx, "x", emit, fail); err != nil {
+		t.Fatalf("first bundle failed: %v", err)
+	}
+	fn.StartBundle()
+	for _, elm := range []string{"1", "2"} {
+		if err := fn.ProcessElement(ctx, elm, emit, fail); err != nil {
+			t.Errorf("second bundle failed on %v: %v", elm, err)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dynproto
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// decode decodes a serialized message of the type.
+func (r *registry) decode(t *messageType, data []byte) (Message, error) {
+	ret := make(Message)
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("truncated tag")
+		}
+		data = data[n:]
+		num, wire := int32(tag>>3), int(tag&7)
+
+		f, ok := t.fields[num]
+		if !ok {
+			// Skip unknown fields, such as fields added by a newer schema.
+			var err error
+			if data, err = skip(data, wire); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		repeated := f.GetLabel() == descpb.FieldDescriptorProto_LABEL_REPEATED
+		var values []interface{}
+		if wire == wireBytes && repeated && isPackable(f.GetType()) {
+			payload, rest, err := lengthDelimited(data)
+			if err != nil {
+				return nil, err
+			}
+			data = rest
+			for len(payload) > 0 {
+				var v interface{}
+				if v, payload, err = r.scalar(f, wireType(f.GetType()), payload); err != nil {
+					return nil, errors.WithContextf(err, "decoding field %v", f.GetName())
+				}
+				values = append(values, v)
+			}
+		} else {
+			var v interface{}
+			var err error
+			if v, data, err = r.value(f, wire, data); err != nil {
+				return nil, errors.WithContextf(err, "decoding field %v", f.GetName())
+			}
+			values = append(values, v)
+		}
+
+		switch {
+		case r.isMap(f):
+			m, _ := ret[f.GetName()].(Message)
+			if m == nil {
+				m = make(Message)
+				ret[f.GetName()] = m
+			}
+			for _, v := range values {
+				entry := v.(Message)
+				m[mapKey(entry["key"])] = entry["value"]
+			}
+		case repeated:
+			list, _ := ret[f.GetName()].([]interface{})
+			ret[f.GetName()] = append(list, values...)
+		case f.GetType() == descpb.FieldDescriptorProto_TYPE_MESSAGE:
+			// Repeated occurrences of a message field are merged.
+			if prev, ok := ret[f.GetName()].(Message); ok {
+				merge(prev, values[0].(Message))
+				continue
+			}
+			ret[f.GetName()] = values[0]
+		default:
+			ret[f.GetName()] = values[0]
+		}
+	}
+
+	for _, f := range t.desc.GetField() {
+		if _, ok := ret[f.GetName()]; ok || f.OneofIndex != nil ||
+			f.GetLabel() == descpb.FieldDescriptorProto_LABEL_REPEATED || f.GetType() == descpb.FieldDescriptorProto_TYPE_MESSAGE {
+			continue
+		}
+		ret[f.GetName()] = r.zero(f)
+	}
+	return ret, nil
+}
+
+func merge(dst, src Message) {
+	for k, v := range src {
+		if d, ok := dst[k].(Message); ok {
+			if s, ok := v.(Message); ok {
+				merge(d, s)
+				continue
+			}
+		}
+		if d, ok := dst[k].([]interface{}); ok {
+			if s, ok := v.([]interface{}); ok {
+				dst[k] = append(d, s...)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func (r *registry) isMap(f *descpb.FieldDescriptorProto) bool {
+	if f.GetType() != descpb.FieldDescriptorProto_TYPE_MESSAGE || f.GetLabel() != descpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+	t, err := r.message(f.GetTypeName())
+	return err == nil && t.desc.GetOptions().GetMapEntry()
+}
+
+// mapKey formats a map key as in the JSON mapping of protocol buffers.
+func mapKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case bool:
+		return strconv.FormatBool(k)
+	case int32:
+		return strconv.FormatInt(int64(k), 10)
+	case int64:
+		return strconv.FormatInt(k, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(k), 10)
+	case uint64:
+		return strconv.FormatUint(k, 10)
+	default:
+		return ""
+	}
+}
+
+// value decodes a single value of the field.
+func (r *registry) value(f *descpb.FieldDescriptorProto, wire int, data []byte) (interface{}, []byte, error) {
+	switch f.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_MESSAGE:
+		if wire != wireBytes {
+			return nil, nil, errors.Errorf("invalid wire type %v", wire)
+		}
+		payload, rest, err := lengthDelimited(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		t, err := r.message(f.GetTypeName())
+		if err != nil {
+			return nil, nil, err
+		}
+		m, err := r.decode(t, payload)
+		return m, rest, err
+	case descpb.FieldDescriptorProto_TYPE_GROUP:
+		return nil, nil, errors.New("groups are not supported")
+	default:
+		return r.scalar(f, wire, data)
+	}
+}
+
+// scalar decodes a value of a scalar or enum field.
+func (r *registry) scalar(f *descpb.FieldDescriptorProto, wire int, data []byte) (interface{}, []byte, error) {
+	typ := f.GetType()
+	if wire != wireType(typ) {
+		return nil, nil, errors.Errorf("invalid wire type %v for %v", wire, typ)
+	}
+
+	switch wire {
+	case wireVarint:
+		x, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, errors.New("truncated varint")
+		}
+		data = data[n:]
+		switch typ {
+		case descpb.FieldDescriptorProto_TYPE_INT32:
+			return int32(x), data, nil
+		case descpb.FieldDescriptorProto_TYPE_INT64:
+			return int64(x), data, nil
+		case descpb.FieldDescriptorProto_TYPE_UINT32:
+			return uint32(x), data, nil
+		case descpb.FieldDescriptorProto_TYPE_UINT64:
+			return x, data, nil
+		case descpb.FieldDescriptorProto_TYPE_SINT32:
+			return int32(uint32(x)>>1) ^ -int32(x&1), data, nil
+		case descpb.FieldDescriptorProto_TYPE_SINT64:
+			return int64(x>>1) ^ -int64(x&1), data, nil
+		case descpb.FieldDescriptorProto_TYPE_BOOL:
+			return x != 0, data, nil
+		default: // enum
+			return r.enum(f, int32(x)), data, nil
+		}
+
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, nil, errors.New("truncated fixed64")
+		}
+		x := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+		switch typ {
+		case descpb.FieldDescriptorProto_TYPE_DOUBLE:
+			return math.Float64frombits(x), data, nil
+		case descpb.FieldDescriptorProto_TYPE_SFIXED64:
+			return int64(x), data, nil
+		default:
+			return x, data, nil
+		}
+
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, nil, errors.New("truncated fixed32")
+		}
+		x := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		switch typ {
+		case descpb.FieldDescriptorProto_TYPE_FLOAT:
+			return math.Float32frombits(x), data, nil
+		case descpb.FieldDescriptorProto_TYPE_SFIXED32:
+			return int32(x), data, nil
+		default:
+			return x, data, nil
+		}
+
+	default: // bytes
+		payload, rest, err := lengthDelimited(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if typ == descpb.FieldDescriptorProto_TYPE_STRING {
+			return string(payload), rest, nil
+		}
+		return append([]byte(nil), payload...), rest, nil
+	}
+}
+
+// enum returns the name of the enum value, or the number if the value is
+// unknown.
+func (r *registry) enum(f *descpb.FieldDescriptorProto, x int32) interface{} {
+	if name, ok := r.enums[strings.TrimPrefix(f.GetTypeName(), ".")][x]; ok {
+		return name
+	}
+	return x
+}
+
+// zero returns the default value of an absent scalar or enum field.
+func (r *registry) zero(f *descpb.FieldDescriptorProto) interface{} {
+	def := f.GetDefaultValue()
+	switch f.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_INT32, descpb.FieldDescriptorProto_TYPE_SINT32, descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		x, _ := strconv.ParseInt(def, 10, 32)
+		return int32(x)
+	case descpb.FieldDescriptorProto_TYPE_INT64, descpb.FieldDescriptorProto_TYPE_SINT64, descpb.FieldDescriptorProto_TYPE_SFIXED64:
+		x, _ := strconv.ParseInt(def, 10, 64)
+		return x
+	case descpb.FieldDescriptorProto_TYPE_UINT32, descpb.FieldDescriptorProto_TYPE_FIXED32:
+		x, _ := strconv.ParseUint(def, 10, 32)
+		return uint32(x)
+	case descpb.FieldDescriptorProto_TYPE_UINT64, descpb.FieldDescriptorProto_TYPE_FIXED64:
+		x, _ := strconv.ParseUint(def, 10, 64)
+		return x
+	case descpb.FieldDescriptorProto_TYPE_FLOAT:
+		x, _ := strconv.ParseFloat(def, 32)
+		return float32(x)
+	case descpb.FieldDescriptorProto_TYPE_DOUBLE:
+		x, _ := strconv.ParseFloat(def, 64)
+		return x
+	case descpb.FieldDescriptorProto_TYPE_BOOL:
+		return def == "true"
+	case descpb.FieldDescriptorProto_TYPE_STRING:
+		return def
+	case descpb.FieldDescriptorProto_TYPE_BYTES:
+		return []byte(def)
+	case descpb.FieldDescriptorProto_TYPE_ENUM:
+		if def != "" {
+			return def
+		}
+		return r.enum(f, 0)
+	default:
+		return nil
+	}
+}
+
+func wireType(t descpb.FieldDescriptorProto_Type) int {
+	switch t {
+	case descpb.FieldDescriptorProto_TYPE_DOUBLE, descpb.FieldDescriptorProto_TYPE_FIXED64, descpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return wireFixed64
+	case descpb.FieldDescriptorProto_TYPE_FLOAT, descpb.FieldDescriptorProto_TYPE_FIXED32, descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return wireFixed32
+	case descpb.FieldDescriptorProto_TYPE_STRING, descpb.FieldDescriptorProto_TYPE_BYTES, descpb.FieldDescriptorProto_TYPE_MESSAGE:
+		return wireBytes
+	default:
+		return wireVarint
+	}
+}
+
+func isPackable(t descpb.FieldDescriptorProto_Type) bool {
+	return wireType(t) != wireBytes && t != descpb.FieldDescriptorProto_TYPE_GROUP
+}
+
+func lengthDelimited(data []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < size {
+		return nil, nil, errors.New("truncated length-delimited field")
+	}
+	return data[n : n+int(size)], data[n+int(size):], nil
+}
+
+// skip skips a value of an unknown field.
+func skip(data []byte, wire int) ([]byte, error) {
+	switch wire {
+	case wireVarint:
+		_, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("truncated varint")
+		}
+		return data[n:], nil
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, errors.New("truncated fixed64")
+		}
+		return data[8:], nil
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, errors.New("truncated fixed32")
+		}
+		return data[4:], nil
+	case wireBytes:
+		_, rest, err := lengthDelimited(data)
+		return rest, err
+	default:
+		return nil, errors.Errorf("unsupported wire type %v", wire)
+	}
+}
@@ -0,0 +1,169 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dynproto contains transforms that parse serialized protocol buffer
+// messages with descriptors supplied at runtime, rather than with generated
+// Go types. This suits pipelines that consume many message types whose
+// schemas evolve independently of the pipeline code, such as topics with
+// schemas in a registry. For example:
+//
+//    set, _ := ioutil.ReadFile("events.desc")  // protoc --descriptor_set_out
+//    events := dynproto.Parse(s, set, "shop.v1.Purchase", payloads)
+//
+// Messages are parsed into a Message map keyed by field name. Scalar fields
+// are represented by the Go type of their kind, such as int64 for int64,
+// sint64 and sfixed64 fields, float32 for float fields, and []byte for bytes
+// fields. Enum values are represented by their name, or their number if
+// unknown. Repeated fields are []interface{}, nested messages and map fields
+// are Message, with map keys formatted as in the JSON mapping. Absent scalar
+// and enum fields are set to their default values, except members of a
+// oneof. Unknown fields are ignored.
+package dynproto
+
+import (
+	"bytes"
+	"encoding/gob"
+	"hash/fnv"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+func init() {
+	gob.Register(Message{})
+	gob.Register([]interface{}{})
+	beam.RegisterCoder(reflect.TypeOf((*Message)(nil)).Elem(), encodeMessage, decodeMessage)
+}
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=dynproto --identifiers=parseFn,parseDynamicFn
+//go:generate go fmt
+
+// Message is a parsed message, keyed by field name.
+type Message map[string]interface{}
+
+// encodeMessage encodes messages with gob, which unlike JSON preserves the
+// types of values.
+func encodeMessage(m Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeMessage(data []byte) (Message, error) {
+	var m Message
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Parse parses each element of a PCollection<[]byte> as a message of the
+// named type, such as "shop.v1.Purchase", and returns a PCollection<Message>.
+// The set is a serialized FileDescriptorSet with the type and its
+// dependencies.
+func Parse(s beam.Scope, set []byte, message string, col beam.PCollection) beam.PCollection {
+	s = s.Scope("dynproto.Parse")
+
+	r, err := newRegistry(set)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := r.message(message); err != nil {
+		panic(err)
+	}
+	return beam.ParDo(s, &parseFn{Set: set, Message: message}, col)
+}
+
+type parseFn struct {
+	// Set is the serialized FileDescriptorSet.
+	Set []byte `json:"set"`
+	// Message is the full name of the message type.
+	Message string `json:"message"`
+
+	registry *registry
+	t        *messageType
+}
+
+func (f *parseFn) Setup() error {
+	r, err := newRegistry(f.Set)
+	if err != nil {
+		return err
+	}
+	t, err := r.message(f.Message)
+	if err != nil {
+		return err
+	}
+	f.registry, f.t = r, t
+	return nil
+}
+
+func (f *parseFn) ProcessElement(data []byte) (Message, error) {
+	m, err := f.registry.decode(f.t, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", f.Message)
+	}
+	return m, nil
+}
+
+// ParseDynamic parses the messages of a PCollection<KV<string,[]byte>>, where
+// the key of each element is the full name of its message type, and returns
+// a PCollection<KV<string,Message>>. The types are defined by the serialized
+// FileDescriptorSets of sets, a PCollection<[]byte> used as a side input, so
+// that they may be read at runtime, such as from a schema registry. If a type
+// is defined by several sets, the definition of the last set read is used.
+func ParseDynamic(s beam.Scope, sets beam.PCollection, col beam.PCollection) beam.PCollection {
+	s = s.Scope("dynproto.ParseDynamic")
+	return beam.ParDo(s, &parseDynamicFn{}, col, beam.SideInput{Input: sets})
+}
+
+type parseDynamicFn struct {
+	registry *registry
+	// hash is the hash of the sets of the registry.
+	hash uint64
+}
+
+func (f *parseDynamicFn) ProcessElement(name string, data []byte, sets func(*[]byte) bool, emit func(string, Message)) error {
+	// Descriptor sets are only parsed again if the side input changed.
+	h := fnv.New64a()
+	var all [][]byte
+	var set []byte
+	for sets(&set) {
+		h.Write(set)
+		h.Write([]byte{0})
+		all = append(all, set)
+	}
+	if f.registry == nil || h.Sum64() != f.hash {
+		r, err := newRegistry(all...)
+		if err != nil {
+			return err
+		}
+		f.registry, f.hash = r, h.Sum64()
+	}
+
+	t, err := f.registry.message(name)
+	if err != nil {
+		return err
+	}
+	m, err := f.registry.decode(t, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %v", name)
+	}
+	emit(name, m)
+	return nil
+}
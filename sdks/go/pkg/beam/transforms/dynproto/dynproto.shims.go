@@ -0,0 +1,223 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: dynproto.shims.go
+
+package dynproto
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*Message)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*parseDynamicFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*parseFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parseDynamicFn)(nil)).Elem(), wrapMakerParseDynamicFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parseFn)(nil)).Elem(), wrapMakerParseFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte) (Message, error))(nil)).Elem(), funcMakerSliceOfByteГMessageError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string, []byte, func(*[]byte) bool, func(string, Message)) error)(nil)).Elem(), funcMakerStringSliceOfByteIterSliceOfByteEmitStringMessageГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+	exec.RegisterEmitter(reflect.TypeOf((*func(string, Message))(nil)).Elem(), emitMakerStringMessage)
+	exec.RegisterInput(reflect.TypeOf((*func(*[]byte) bool)(nil)).Elem(), iterMakerSliceOfByte)
+}
+
+func wrapMakerParseDynamicFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parseDynamicFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 string, a1 []byte, a2 func(*[]byte) bool, a3 func(string, Message)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+	}
+}
+
+func wrapMakerParseFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parseFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []byte) (Message, error) { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+type callerSliceOfByteГMessageError struct {
+	fn func([]byte) (Message, error)
+}
+
+func funcMakerSliceOfByteГMessageError(fn interface{}) reflectx.Func {
+	f := fn.(func([]byte) (Message, error))
+	return &callerSliceOfByteГMessageError{fn: f}
+}
+
+func (c *callerSliceOfByteГMessageError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfByteГMessageError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfByteГMessageError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].([]byte))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerSliceOfByteГMessageError) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.([]byte))
+}
+
+type callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError struct {
+	fn func(string, []byte, func(*[]byte) bool, func(string, Message)) error
+}
+
+func funcMakerStringSliceOfByteIterSliceOfByteEmitStringMessageГError(fn interface{}) reflectx.Func {
+	f := fn.(func(string, []byte, func(*[]byte) bool, func(string, Message)) error)
+	return &callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError{fn: f}
+}
+
+func (c *callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string), args[1].([]byte), args[2].(func(*[]byte) bool), args[3].(func(string, Message)))
+	return []interface{}{out0}
+}
+
+func (c *callerStringSliceOfByteIterSliceOfByteEmitStringMessageГError) Call4x1(arg0, arg1, arg2, arg3 interface{}) interface{} {
+	return c.fn(arg0.(string), arg1.([]byte), arg2.(func(*[]byte) bool), arg3.(func(string, Message)))
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerStringMessage(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeStringMessage
+	return ret
+}
+
+func (e *emitNative) invokeStringMessage(key string, val Message) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerSliceOfByte(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readSliceOfByte
+	return ret
+}
+
+func (v *iterNative) readSliceOfByte(value *[]byte) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.([]byte)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,114 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dynproto
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	pb "github.com/apache/beam/sdks/go/pkg/beam/model/pipeline_v1"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+func init() {
+	beam.RegisterFunction(lateness)
+}
+
+// lateness returns the allowed lateness of a parsed WindowingStrategy.
+func lateness(name string, m Message) int64 {
+	return m["allowed_lateness"].(int64)
+}
+
+func descriptorSet(t *testing.T) []byte {
+	fd, _ := descriptor.ForMessage(&pb.Components{})
+	data, err := proto.Marshal(&descpb.FileDescriptorSet{File: []*descpb.FileDescriptorProto{fd}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	r, err := newRegistry(descriptorSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(&pb.Components{
+		Coders: map[string]*pb.Coder{
+			"c1": {
+				Spec:              &pb.SdkFunctionSpec{Spec: &pb.FunctionSpec{Urn: "beam:coder:kv:v1", Payload: []byte{1}}},
+				ComponentCoderIds: []string{"a", "b"},
+			},
+		},
+		WindowingStrategies: map[string]*pb.WindowingStrategy{
+			"w1": {MergeStatus: pb.MergeStatus_NEEDS_MERGE, AllowedLateness: -5},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typ, err := r.message(".org.apache.beam.model.pipeline.v1.Components")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.decode(typ, data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	coder := got["coders"].(Message)["c1"].(Message)
+	if !reflect.DeepEqual(coder["component_coder_ids"], []interface{}{"a", "b"}) {
+		t.Errorf("component_coder_ids = %v, want [a b]", coder["component_coder_ids"])
+	}
+	spec := coder["spec"].(Message)["spec"].(Message)
+	if spec["urn"] != "beam:coder:kv:v1" || !reflect.DeepEqual(spec["payload"], []byte{1}) {
+		t.Errorf("spec = %v", spec)
+	}
+	ws := got["windowing_strategies"].(Message)["w1"].(Message)
+	if ws["merge_status"] != "NEEDS_MERGE" || ws["allowed_lateness"] != int64(-5) || ws["closing_behavior"] != "UNSPECIFIED" {
+		t.Errorf("windowing strategy = %v", ws)
+	}
+	if _, ok := got["transforms"]; ok {
+		t.Errorf("absent map field transforms is set: %v", got["transforms"])
+	}
+}
+
+func TestParse(t *testing.T) {
+	set := descriptorSet(t)
+	data, err := proto.Marshal(&pb.WindowingStrategy{AllowedLateness: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const name = "org.apache.beam.model.pipeline.v1.WindowingStrategy"
+
+	p, s := beam.NewPipelineWithRoot()
+	parsed := Parse(s, set, name, beam.Create(s, data))
+	passert.Equals(s, beam.ParDo(s, func(m Message) int64 { return lateness("", m) }, parsed), int64(42))
+
+	keyed := beam.ParDo(s, func(b []byte) (string, []byte) { return name, b }, beam.Create(s, data))
+	dynamic := ParseDynamic(s, beam.Create(s, set), keyed)
+	passert.Equals(s, beam.ParDo(s, lateness, dynamic), int64(42))
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("Parse failed: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dynproto
+
+import (
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/golang/protobuf/proto"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// registry holds the message and enum types of descriptor sets, by full
+// name without the leading dot.
+type registry struct {
+	messages map[string]*messageType
+	enums    map[string]map[int32]string
+}
+
+type messageType struct {
+	desc   *descpb.DescriptorProto
+	fields map[int32]*descpb.FieldDescriptorProto
+}
+
+// newRegistry parses the serialized FileDescriptorSets. Later definitions
+// of a type replace earlier ones, so that a newer version of a schema can
+// be appended.
+func newRegistry(sets ...[]byte) (*registry, error) {
+	r := &registry{messages: make(map[string]*messageType), enums: make(map[string]map[int32]string)}
+	for _, data := range sets {
+		var set descpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &set); err != nil {
+			return nil, errors.Wrap(err, "invalid FileDescriptorSet")
+		}
+		for _, f := range set.GetFile() {
+			prefix := f.GetPackage()
+			for _, m := range f.GetMessageType() {
+				r.addMessage(prefix, m)
+			}
+			for _, e := range f.GetEnumType() {
+				r.addEnum(prefix, e)
+			}
+		}
+	}
+	return r, nil
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func (r *registry) addMessage(prefix string, m *descpb.DescriptorProto) {
+	name := join(prefix, m.GetName())
+	t := &messageType{desc: m, fields: make(map[int32]*descpb.FieldDescriptorProto)}
+	for _, f := range m.GetField() {
+		t.fields[f.GetNumber()] = f
+	}
+	r.messages[name] = t
+
+	for _, nested := range m.GetNestedType() {
+		r.addMessage(name, nested)
+	}
+	for _, e := range m.GetEnumType() {
+		r.addEnum(name, e)
+	}
+}
+
+func (r *registry) addEnum(prefix string, e *descpb.EnumDescriptorProto) {
+	values := make(map[int32]string)
+	for _, v := range e.GetValue() {
+		values[v.GetNumber()] = v.GetName()
+	}
+	r.enums[join(prefix, e.GetName())] = values
+}
+
+// message returns the message type of the full name, which may have a
+// leading dot.
+func (r *registry) message(name string) (*messageType, error) {
+	t, ok := r.messages[strings.TrimPrefix(name, ".")]
+	if !ok {
+		return nil, errors.Errorf("unknown message type %v", name)
+	}
+	return t, nil
+}
@@ -0,0 +1,348 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope contains transforms that encrypt and decrypt elements
+// with envelope encryption. Elements are encrypted with AES-256-GCM by a data
+// key, which is itself encrypted ("wrapped") by a key management service and
+// stored with each ciphertext. For example:
+//
+//    kms := envelope.GCPKMS{Name: "projects/p/locations/global/keyRings/r/cryptoKeys/k"}
+//    sealed := envelope.Encrypt(s, kms, users, "Email", "Phone")
+//    ...
+//    users = envelope.Decrypt(s, kms, sealed, "Email", "Phone")
+//
+// Keys of AWS KMS are used the same way with envelope.AWSKMS, and local keys
+// with envelope.LocalKey.
+//
+// Each worker process generates a data key per key manager and uses it for
+// up to 2^32 encryptions or a day, whichever comes first, before generating
+// another, as GCM with random nonces must not use a key for more. The key
+// management service is thus called about once per worker and day to
+// encrypt, and once per distinct data key to decrypt.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=envelope --identifiers=cryptFn
+//go:generate go fmt
+
+// KeyManager wraps and unwraps data keys with a key encryption key, which
+// is commonly held by a key management service. A key manager is encoded as
+// JSON and sent to the workers, so its configuration must be in exported
+// fields and its type must be registered with beam.RegisterType.
+type KeyManager interface {
+	// WrapKey encrypts a data key.
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key encrypted by WrapKey.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// version is the format version of ciphertexts, which are the version, the
+// uvarint length of the wrapped data key, the wrapped data key, the nonce
+// and the sealed plaintext.
+const version = 1
+
+// Encrypt encrypts the given top-level fields of the struct elements of col,
+// which must be of type string or []byte. Encrypted string fields are base64
+// encoded. If col is a PCollection<[]byte> and no fields are given, the
+// elements are encrypted. It returns a PCollection of the same type. The
+// field name is authenticated with the ciphertext, so a ciphertext cannot
+// be moved to another field.
+func Encrypt(s beam.Scope, km KeyManager, col beam.PCollection, fields ...string) beam.PCollection {
+	s = s.Scope("envelope.Encrypt")
+	return beam.ParDo(s, newCryptFn(km, col.Type().Type(), fields, false), col)
+}
+
+// Decrypt decrypts the given fields, or elements, encrypted by Encrypt.
+func Decrypt(s beam.Scope, km KeyManager, col beam.PCollection, fields ...string) beam.PCollection {
+	s = s.Scope("envelope.Decrypt")
+	return beam.ParDo(s, newCryptFn(km, col.Type().Type(), fields, true), col)
+}
+
+func newCryptFn(km KeyManager, t reflect.Type, fields []string, decrypt bool) *cryptFn {
+	switch {
+	case t == reflectx.ByteSlice && len(fields) == 0:
+	case t.Kind() == reflect.Struct && len(fields) > 0:
+		for _, name := range fields {
+			f, ok := t.FieldByName(name)
+			if !ok || len(f.Index) != 1 || f.PkgPath != "" {
+				panic(fmt.Sprintf("no exported top-level field %v in %v", name, t))
+			}
+			if f.Type != reflectx.String && f.Type != reflectx.ByteSlice {
+				panic(fmt.Sprintf("field %v of type %v must be a string or []byte", name, f.Type))
+			}
+		}
+	default:
+		panic(fmt.Sprintf("cannot encrypt fields %v of %v", fields, t))
+	}
+
+	data, err := json.Marshal(km)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to encode key manager %T", km))
+	}
+	return &cryptFn{
+		KeyManager: beam.EncodedType{T: reflect.TypeOf(km)},
+		Config:     string(data),
+		Fields:     fields,
+		Decrypt:    decrypt,
+	}
+}
+
+// cryptFn encrypts or decrypts elements.
+type cryptFn struct {
+	// KeyManager is the type of the key manager and Config its JSON encoding.
+	KeyManager beam.EncodedType `json:"key_manager"`
+	Config     string           `json:"config"`
+	// Fields are the fields to encrypt, if elements are structs.
+	Fields  []string `json:"fields,omitempty"`
+	Decrypt bool     `json:"decrypt,omitempty"`
+
+	km  KeyManager
+	key string
+}
+
+func (f *cryptFn) Setup() error {
+	t := f.KeyManager.T
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Ptr {
+		ptr = reflect.New(t.Elem())
+	}
+	if err := json.Unmarshal([]byte(f.Config), ptr.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode key manager %v", t)
+	}
+	if t.Kind() == reflect.Ptr {
+		f.km = ptr.Interface().(KeyManager)
+	} else {
+		f.km = ptr.Elem().Interface().(KeyManager)
+	}
+	f.key = t.String() + ":" + f.Config
+	return nil
+}
+
+func (f *cryptFn) ProcessElement(ctx context.Context, elm beam.X) (beam.X, error) {
+	if len(f.Fields) == 0 {
+		return f.crypt(ctx, "", elm.([]byte))
+	}
+
+	v := reflect.New(reflect.TypeOf(elm)).Elem()
+	v.Set(reflect.ValueOf(elm))
+	for _, name := range f.Fields {
+		field := v.FieldByName(name)
+		var data []byte
+		if field.Kind() == reflect.String {
+			data = []byte(field.String())
+			if f.Decrypt {
+				var err error
+				if data, err = base64.StdEncoding.DecodeString(field.String()); err != nil {
+					return nil, errors.Wrapf(err, "field %v is not encrypted", name)
+				}
+			}
+		} else {
+			data = field.Bytes()
+		}
+
+		ret, err := f.crypt(ctx, name, data)
+		if err != nil {
+			return nil, errors.WithContextf(err, "field %v", name)
+		}
+		switch {
+		case field.Kind() != reflect.String:
+			field.SetBytes(ret)
+		case f.Decrypt:
+			field.SetString(string(ret))
+		default:
+			field.SetString(base64.StdEncoding.EncodeToString(ret))
+		}
+	}
+	return v.Interface(), nil
+}
+
+func (f *cryptFn) crypt(ctx context.Context, field string, data []byte) ([]byte, error) {
+	if f.Decrypt {
+		return f.open(ctx, field, data)
+	}
+	return f.seal(ctx, field, data)
+}
+
+func (f *cryptFn) seal(ctx context.Context, field string, plaintext []byte) ([]byte, error) {
+	dk, err := dataKey(ctx, f.key, f.km)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(version)
+	var size [binary.MaxVarintLen64]byte
+	buf.Write(size[:binary.PutUvarint(size[:], uint64(len(dk.wrapped)))])
+	buf.Write(dk.wrapped)
+
+	nonce := make([]byte, dk.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	buf.Write(nonce)
+	return dk.aead.Seal(buf.Bytes(), nonce, plaintext, []byte(field)), nil
+}
+
+func (f *cryptFn) open(ctx context.Context, field string, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || ciphertext[0] != version {
+		return nil, errors.New("invalid ciphertext version")
+	}
+	size, n := binary.Uvarint(ciphertext[1:])
+	if n <= 0 || uint64(len(ciphertext)-1-n) < size {
+		return nil, errors.New("truncated ciphertext")
+	}
+	rest := ciphertext[1+n:]
+	wrapped, rest := rest[:size], rest[size:]
+
+	aead, err := unwrap(ctx, f.key, f.km, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("truncated ciphertext")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt")
+	}
+	return plaintext, nil
+}
+
+// wrappedKey is a data key of a worker process.
+type wrappedKey struct {
+	aead    cipher.AEAD
+	wrapped []byte
+	// uses is the number of encryptions with the key and expires the time
+	// it is replaced.
+	uses    int64
+	expires time.Time
+}
+
+// keySlot holds the current data key of a key manager. Its lock is held
+// while a data key is wrapped, so that concurrent encryptions wait for the
+// same key rather than each wrapping their own.
+type keySlot struct {
+	mu sync.Mutex
+	dk *wrappedKey
+}
+
+// maxUnwrapped bounds the number of unwrapped data keys cached by a worker
+// process.
+const maxUnwrapped = 1024
+
+var (
+	// maxEncryptions and keyLifetime bound the use of a data key. The
+	// encryptions are bounded by the collision probability of random GCM
+	// nonces, NIST SP 800-38D section 8.3.
+	maxEncryptions int64 = 1 << 32
+	keyLifetime          = 24 * time.Hour
+)
+
+var (
+	// dataKeys holds the slot of the data key used to encrypt, by key
+	// manager.
+	dataKeys = make(map[string]*keySlot)
+	// unwrapped holds the unwrapped data keys, by key manager and wrapped key.
+	unwrapped = make(map[string]cipher.AEAD)
+	keysMu    sync.Mutex
+)
+
+// dataKey returns a data key of the key manager for one encryption,
+// generating and wrapping a new one on first use and once the current one
+// is used up or expired.
+func dataKey(ctx context.Context, key string, km KeyManager) (*wrappedKey, error) {
+	keysMu.Lock()
+	slot, ok := dataKeys[key]
+	if !ok {
+		slot = &keySlot{}
+		dataKeys[key] = slot
+	}
+	keysMu.Unlock()
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+
+	if dk := slot.dk; dk == nil || dk.uses >= maxEncryptions || !time.Now().Before(dk.expires) {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		wrapped, err := km.WrapKey(ctx, raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to wrap data key")
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, err
+		}
+		slot.dk = &wrappedKey{aead: aead, wrapped: wrapped, expires: time.Now().Add(keyLifetime)}
+	}
+	slot.dk.uses++
+	return slot.dk, nil
+}
+
+// unwrap returns the unwrapped data key, unwrapping it on first use.
+func unwrap(ctx context.Context, key string, km KeyManager, wrapped []byte) (cipher.AEAD, error) {
+	id := key + ":" + string(wrapped)
+
+	keysMu.Lock()
+	aead, ok := unwrapped[id]
+	keysMu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	raw, err := km.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap data key")
+	}
+	if aead, err = newAEAD(raw); err != nil {
+		return nil, err
+	}
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if len(unwrapped) >= maxUnwrapped {
+		unwrapped = make(map[string]cipher.AEAD)
+	}
+	unwrapped[id] = aead
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,99 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: envelope.shims.go
+
+package envelope
+
+import (
+	"reflect"
+
+	// Library imports
+	"context"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*cryptFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*cryptFn)(nil)).Elem(), wrapMakerCryptFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, typex.X) (typex.X, error))(nil)).Elem(), funcMakerContext۰ContextTypex۰XГTypex۰XError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+}
+
+func wrapMakerCryptFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*cryptFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 typex.X) (typex.X, error) { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+type callerContext۰ContextTypex۰XГTypex۰XError struct {
+	fn func(context.Context, typex.X) (typex.X, error)
+}
+
+func funcMakerContext۰ContextTypex۰XГTypex۰XError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, typex.X) (typex.X, error))
+	return &callerContext۰ContextTypex۰XГTypex۰XError{fn: f}
+}
+
+func (c *callerContext۰ContextTypex۰XГTypex۰XError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰XГTypex۰XError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰XГTypex۰XError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(context.Context), args[1].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerContext۰ContextTypex۰XГTypex۰XError) Call2x2(arg0, arg1 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(context.Context), arg1.(typex.X))
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
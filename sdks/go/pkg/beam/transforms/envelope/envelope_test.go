@@ -0,0 +1,235 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type user struct {
+	Name  string
+	Email string
+	Notes []byte
+}
+
+func TestRoundtrip(t *testing.T) {
+	km := LocalKey{Key: bytes.Repeat([]byte{7}, 32)}
+	in := []user{{"ann", "ann@example.com", []byte("a")}, {"bob", "bob@example.com", nil}}
+
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.CreateList(s, in)
+	sealed := Encrypt(s, km, col, "Email", "Notes")
+	passert.Empty(s, beam.ParDo(s, func(u user, emit func(user)) {
+		if strings.Contains(u.Email, "@") {
+			emit(u) // not encrypted
+		}
+	}, sealed))
+	passert.Equals(s, Decrypt(s, km, sealed, "Email", "Notes"), in[0], in[1])
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Encrypt and Decrypt failed: %v", err)
+	}
+}
+
+func TestSwappedFields(t *testing.T) {
+	ctx := context.Background()
+	km := LocalKey{Key: bytes.Repeat([]byte{1}, 32)}
+	enc := newCryptFn(km, reflectx.ByteSlice, nil, false)
+	dec := newCryptFn(km, reflectx.ByteSlice, nil, true)
+	if err := enc.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := enc.crypt(ctx, "Email", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.crypt(ctx, "Phone", ciphertext); err == nil {
+		t.Errorf("decrypting field Email as Phone succeeded")
+	}
+	if got, err := dec.crypt(ctx, "Email", ciphertext); err != nil || string(got) != "secret" {
+		t.Errorf("crypt(Email) = %q, %v, want secret", got, err)
+	}
+}
+
+func TestGCPKMS(t *testing.T) {
+	var decrypts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string][]byte
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/v1/projects/p/cryptoKeys/k:encrypt":
+			json.NewEncoder(w).Encode(map[string][]byte{"ciphertext": append([]byte("wrapped:"), req["plaintext"]...)})
+		case "/v1/projects/p/cryptoKeys/k:decrypt":
+			atomic.AddInt32(&decrypts, 1)
+			json.NewEncoder(w).Encode(map[string][]byte{"plaintext": bytes.TrimPrefix(req["ciphertext"], []byte("wrapped:"))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	km := GCPKMS{Name: "projects/p/cryptoKeys/k", Endpoint: srv.URL}
+
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, []byte("a"), []byte("b"), []byte("c"))
+	got := Decrypt(s, km, Encrypt(s, km, col))
+	passert.Equals(s, beam.ParDo(s, func(b []byte) string { return string(b) }, got), "a", "b", "c")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&decrypts); n != 1 {
+		t.Errorf("data key unwrapped %v times, want once", n)
+	}
+}
+
+func TestAWSKMS(t *testing.T) {
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(auth, "/us-east-1/kms/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-target, Signature=") {
+			http.Error(w, "bad authorization: "+auth, http.StatusForbidden)
+			return
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["KeyId"] != "alias/k" {
+			http.Error(w, "bad key", http.StatusBadRequest)
+			return
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			key, _ := base64.StdEncoding.DecodeString(req["Plaintext"].(string))
+			json.NewEncoder(w).Encode(map[string][]byte{"CiphertextBlob": append([]byte("wrapped:"), key...)})
+		case "TrentService.Decrypt":
+			wrapped, _ := base64.StdEncoding.DecodeString(req["CiphertextBlob"].(string))
+			json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": bytes.TrimPrefix(wrapped, []byte("wrapped:"))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	km := AWSKMS{KeyID: "alias/k", Region: "us-east-1", Endpoint: srv.URL}
+
+	ctx := context.Background()
+	wrapped, err := km.WrapKey(ctx, []byte("data key"))
+	if err != nil {
+		t.Fatalf("WrapKey failed: %v", err)
+	}
+	if got, err := km.UnwrapKey(ctx, wrapped); err != nil || string(got) != "data key" {
+		t.Errorf("UnwrapKey() = %q, %v, want data key", got, err)
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	// The example of the AWS Signature Version 4 documentation.
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got, want := hex.EncodeToString(key), "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"; got != want {
+		t.Errorf("signingKey() = %v, want %v", got, want)
+	}
+}
+
+func TestDataKeyRotation(t *testing.T) {
+	defer func(n int64) { maxEncryptions = n }(maxEncryptions)
+	maxEncryptions = 2
+
+	ctx := context.Background()
+	km := LocalKey{Key: bytes.Repeat([]byte{2}, 32)}
+	var keys [][]byte
+	for i := 0; i < 5; i++ {
+		dk, err := dataKey(ctx, "rotation", km)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, dk.wrapped)
+	}
+	if !bytes.Equal(keys[0], keys[1]) || !bytes.Equal(keys[2], keys[3]) {
+		t.Errorf("data key replaced before %v encryptions", maxEncryptions)
+	}
+	if bytes.Equal(keys[1], keys[2]) || bytes.Equal(keys[3], keys[4]) {
+		t.Errorf("data key not replaced after %v encryptions", maxEncryptions)
+	}
+
+	defer func(d time.Duration) { keyLifetime = d }(keyLifetime)
+	keyLifetime = 0
+	dk, err := dataKey(ctx, "expiry", km)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := dataKey(ctx, "expiry", km)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(dk.wrapped, next.wrapped) {
+		t.Errorf("expired data key not replaced")
+	}
+}
+
+func TestLocalKeySources(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{3}, 32)
+	dir, err := ioutil.TempDir("", "envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(file, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("ENVELOPE_TEST_KEY", base64.StdEncoding.EncodeToString(key))
+	defer os.Unsetenv("ENVELOPE_TEST_KEY")
+
+	wrapped, err := LocalKey{File: file}.WrapKey(ctx, []byte("data key"))
+	if err != nil {
+		t.Fatalf("WrapKey failed: %v", err)
+	}
+	for _, km := range []LocalKey{{Key: key}, {File: file}, {Env: "ENVELOPE_TEST_KEY"}} {
+		if got, err := km.UnwrapKey(ctx, wrapped); err != nil || string(got) != "data key" {
+			t.Errorf("%+v.UnwrapKey = %q, %v, want data key", km, got, err)
+		}
+	}
+	for _, km := range []LocalKey{{}, {Key: key, File: file}, {Env: "ENVELOPE_MISSING_KEY"}, {Key: key[:16]}} {
+		if _, err := km.WrapKey(ctx, []byte("data key")); err == nil {
+			t.Errorf("%+v.WrapKey succeeded, want error", km)
+		}
+	}
+}
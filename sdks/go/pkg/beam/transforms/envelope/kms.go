@@ -0,0 +1,325 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"golang.org/x/oauth2/google"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*LocalKey)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*GCPKMS)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*AWSKMS)(nil)).Elem())
+}
+
+// LocalKey wraps data keys with a local AES-256 key, which is read on the
+// workers from a file or an environment variable. For example:
+//
+//    km := envelope.LocalKey{File: "/etc/secrets/pipeline.key"}
+//
+// Exactly one of Key, File and Env must be set.
+type LocalKey struct {
+	// Key is the 32 byte key encryption key. Since it is then part of the
+	// pipeline, it should only be used for testing.
+	Key []byte `json:"key,omitempty"`
+	// File is the path of a file on the workers that holds the 32 byte key.
+	File string `json:"file,omitempty"`
+	// Env is the name of an environment variable of the workers that holds
+	// the base64 encoded key.
+	Env string `json:"env,omitempty"`
+}
+
+// aead returns the cipher of the key encryption key.
+func (k LocalKey) aead() (cipher.AEAD, error) {
+	var key []byte
+	switch {
+	case k.Key != nil && k.File == "" && k.Env == "":
+		key = k.Key
+	case k.Key == nil && k.File != "" && k.Env == "":
+		var err error
+		if key, err = ioutil.ReadFile(k.File); err != nil {
+			return nil, errors.Wrap(err, "failed to read key encryption key")
+		}
+	case k.Key == nil && k.File == "" && k.Env != "":
+		v, ok := os.LookupEnv(k.Env)
+		if !ok {
+			return nil, errors.Errorf("key encryption key variable %v not set", k.Env)
+		}
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return nil, errors.Wrapf(err, "invalid key encryption key in %v", k.Env)
+		}
+	default:
+		return nil, errors.New("exactly one of Key, File and Env must be set")
+	}
+	if len(key) != 32 {
+		return nil, errors.Errorf("key encryption key is %v bytes, want 32", len(key))
+	}
+	return newAEAD(key)
+}
+
+func (k LocalKey) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, key, nil), nil
+}
+
+func (k LocalKey) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("truncated wrapped key")
+	}
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+}
+
+// GCPKMS wraps data keys with a Google Cloud KMS symmetric key. Workers
+// authenticate with the application default credentials.
+type GCPKMS struct {
+	// Name is the resource name of the key, such as
+	// "projects/p/locations/global/keyRings/r/cryptoKeys/k".
+	Name string `json:"name"`
+	// Endpoint overrides the base URL of the API, for testing with a local
+	// server. Requests to it are not authenticated.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+const kmsScope = "https://www.googleapis.com/auth/cloudkms"
+
+func (k GCPKMS) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := k.call(ctx, "encrypt", map[string][]byte{"plaintext": key}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+func (k GCPKMS) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := k.call(ctx, "decrypt", map[string][]byte{"ciphertext": wrapped}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call calls a method of the key. Bytes are base64 encoded in requests and
+// responses, which encoding/json does for []byte.
+func (k GCPKMS) call(ctx context.Context, method string, body, ret interface{}) error {
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = "https://cloudkms.googleapis.com"
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint+"/v1/"+k.Name+":"+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := http.DefaultClient
+	if k.Endpoint == "" {
+		if client, err = google.DefaultClient(ctx, kmsScope); err != nil {
+			return errors.Wrap(err, "failed to create KMS client")
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("KMS %v of %v: %v: %s", method, k.Name, resp.Status, data)
+	}
+	return json.Unmarshal(data, ret)
+}
+
+// AWSKMS wraps data keys with an AWS KMS symmetric key. Workers sign the
+// requests with the credentials of the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and, if set, AWS_SESSION_TOKEN environment
+// variables.
+type AWSKMS struct {
+	// KeyID is the ID, ARN or alias of the key, such as "alias/pipeline".
+	KeyID string `json:"key_id"`
+	// Region is the region of the key, such as "us-east-1".
+	Region string `json:"region"`
+	// Endpoint overrides the base URL of the API, for testing with a local
+	// server.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+func (k AWSKMS) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	var resp struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	if err := k.call(ctx, "Encrypt", map[string]interface{}{"KeyId": k.KeyID, "Plaintext": key}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.CiphertextBlob, nil
+}
+
+func (k AWSKMS) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	if err := k.call(ctx, "Decrypt", map[string]interface{}{"KeyId": k.KeyID, "CiphertextBlob": wrapped}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call calls an action of the JSON API of KMS, signed with Signature
+// Version 4.
+func (k AWSKMS) call(ctx context.Context, action string, body, ret interface{}) error {
+	if k.Region == "" {
+		return errors.New("no AWS KMS region specified")
+	}
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + k.Region + ".amazonaws.com"
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint+"/", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	if err := k.sign(req, data, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("KMS %v of %v: %v: %s", action, k.KeyID, resp.Status, data)
+	}
+	return json.Unmarshal(data, ret)
+}
+
+// sign adds the Signature Version 4 authorization of the request with the
+// body at time t.
+func (k AWSKMS) sign(req *http.Request, body []byte, t time.Time) error {
+	id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if id == "" || secret == "" {
+		return errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for AWS KMS")
+	}
+	t = t.UTC()
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	// The signed headers are all headers set above, in sorted order.
+	names := []string{"content-type", "host", "x-amz-date", "x-amz-security-token", "x-amz-target"}
+	var headers, signed []string
+	for _, name := range names {
+		v := req.Header.Get(name)
+		if name == "host" {
+			v = req.URL.Host
+		}
+		if v == "" {
+			continue
+		}
+		headers = append(headers, name+":"+strings.TrimSpace(v)+"\n")
+		signed = append(signed, name)
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		(&url.URL{Path: req.URL.Path}).EscapedPath(),
+		req.URL.RawQuery,
+		strings.Join(headers, ""),
+		strings.Join(signed, ";"),
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + k.Region + "/kms/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(secret, date, k.Region, "kms"), toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+id+"/"+scope+
+		", SignedHeaders="+strings.Join(signed, ";")+", Signature="+signature)
+	return nil
+}
+
+// signingKey derives the Signature Version 4 key of the secret for the date,
+// region and service.
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
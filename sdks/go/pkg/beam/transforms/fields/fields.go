@@ -0,0 +1,308 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fields contains transformations that select, drop, rename and add
+// fields of struct elements, without per-type DoFns.
+//
+// Each transformation takes a PCollection of a struct type and returns a
+// PCollection of a new, unnamed struct type with the resulting fields. For
+// example:
+//
+//    type Purchase struct {
+//        User  User
+//        Item  string
+//        Price float64
+//        Notes string
+//    }
+//
+//    slim := fields.Drop(s, purchases, "Notes")
+//    pairs := fields.Select(s, purchases, "User.ID", "Price")
+//    renamed := fields.Rename(s, pairs, map[string]string{"ID": "UserID"})
+//
+// Fields are identified by their Go names. Select accepts nested fields as
+// dotted paths; the other transformations work on top-level fields.
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=fields --identifiers=projectFn,keyFn,aggregateFn,formatFn
+//go:generate go fmt
+
+// Field is a field added by Add.
+type Field struct {
+	// Name is the name of the field, which must be exported.
+	Name string
+	// Default is the value of the field in every element. Its type is the
+	// type of the field, so a nil default must be typed, such as
+	// (*string)(nil). It must be encodable as JSON.
+	Default interface{}
+}
+
+// Select returns a PCollection of structs with only the given fields of the
+// elements of col, in the given order. Nested fields are given as paths,
+// such as "User.ID", and become top-level fields named by their last
+// component. A nil pointer on the path selects the zero value.
+func Select(s beam.Scope, col beam.PCollection, fields ...string) beam.PCollection {
+	s = s.Scope("fields.Select")
+
+	t := mustStruct(col)
+	if len(fields) == 0 {
+		panic("no fields selected")
+	}
+	var out []reflect.StructField
+	var sources []source
+	for _, name := range fields {
+		path, f, err := lookup(t, name)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+		sources = append(sources, source{Path: path})
+	}
+	return project(s, col, out, sources)
+}
+
+// Drop returns a PCollection of structs with all fields of the elements of
+// col except the given top-level fields.
+func Drop(s beam.Scope, col beam.PCollection, fields ...string) beam.PCollection {
+	s = s.Scope("fields.Drop")
+
+	t := mustStruct(col)
+	drop := make(map[string]bool)
+	for _, name := range fields {
+		if _, ok := t.FieldByName(name); !ok || strings.Contains(name, ".") {
+			panic(fmt.Sprintf("no top-level field %v in %v", name, t))
+		}
+		drop[name] = true
+	}
+
+	var out []reflect.StructField
+	var sources []source
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if drop[f.Name] || f.PkgPath != "" {
+			continue
+		}
+		out = append(out, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+		sources = append(sources, source{Path: []int{i}})
+	}
+	if len(out) == 0 {
+		panic(fmt.Sprintf("all fields of %v dropped", t))
+	}
+	return project(s, col, out, sources)
+}
+
+// Rename returns a PCollection of structs with the top-level fields of the
+// elements of col renamed, by old name. The tags of renamed fields are
+// dropped, since they commonly refer to the old name. Fields promoted from
+// embedded structs are not top-level fields; the embedded struct field can
+// be renamed instead.
+func Rename(s beam.Scope, col beam.PCollection, renames map[string]string) beam.PCollection {
+	s = s.Scope("fields.Rename")
+
+	t := mustStruct(col)
+	for old, name := range renames {
+		if f, ok := t.FieldByName(old); !ok || len(f.Index) != 1 || f.PkgPath != "" {
+			panic(fmt.Sprintf("no exported top-level field %v in %v", old, t))
+		}
+		mustExported(name)
+	}
+
+	var out []reflect.StructField
+	var sources []source
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		field := reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}
+		if name, ok := renames[f.Name]; ok {
+			field.Name, field.Tag = name, ""
+		}
+		out = append(out, field)
+		sources = append(sources, source{Path: []int{i}})
+	}
+	return project(s, col, out, sources)
+}
+
+// Add returns a PCollection of structs with the fields of the elements of
+// col followed by the given fields, set to their defaults.
+func Add(s beam.Scope, col beam.PCollection, fields ...Field) beam.PCollection {
+	s = s.Scope("fields.Add")
+
+	t := mustStruct(col)
+	var out []reflect.StructField
+	var sources []source
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		out = append(out, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+		sources = append(sources, source{Path: []int{i}})
+	}
+	for _, f := range fields {
+		mustExported(f.Name)
+		if f.Default == nil {
+			panic(fmt.Sprintf("field %v has an untyped nil default", f.Name))
+		}
+		data, err := json.Marshal(f.Default)
+		if err != nil {
+			panic(errors.Wrapf(err, "failed to encode default of field %v", f.Name))
+		}
+		out = append(out, reflect.StructField{Name: f.Name, Type: reflect.TypeOf(f.Default)})
+		sources = append(sources, source{Default: string(data)})
+	}
+	return project(s, col, out, sources)
+}
+
+func mustStruct(col beam.PCollection) reflect.Type {
+	t := col.Type().Type()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("element type %v must be a struct", t))
+	}
+	return t
+}
+
+func mustExported(name string) {
+	r, _ := utf8.DecodeRuneInString(name)
+	if !unicode.IsUpper(r) {
+		panic(fmt.Sprintf("field name %q must be exported", name))
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			panic(fmt.Sprintf("invalid field name %q", name))
+		}
+	}
+}
+
+// lookup returns the index path and the field of the dotted field name,
+// descending through pointers to structs.
+func lookup(t reflect.Type, name string) ([]int, reflect.StructField, error) {
+	var path []int
+	var f reflect.StructField
+	for i, part := range strings.Split(name, ".") {
+		if i > 0 {
+			t = f.Type
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Struct {
+				return nil, f, errors.Errorf("field %v of %v is not a struct", strings.Join(strings.Split(name, ".")[:i], "."), t)
+			}
+		}
+		var ok bool
+		if f, ok = t.FieldByName(part); !ok || len(f.Index) != 1 || f.PkgPath != "" {
+			return nil, f, errors.Errorf("no field %v in %v", name, t)
+		}
+		path = append(path, f.Index[0])
+	}
+	return path, f, nil
+}
+
+// structOf returns the struct type with the fields, which must have unique
+// names.
+func structOf(fields []reflect.StructField) reflect.Type {
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		if seen[f.Name] {
+			panic(fmt.Sprintf("duplicate field %v", f.Name))
+		}
+		seen[f.Name] = true
+	}
+	return reflect.StructOf(fields)
+}
+
+// project builds the output struct type and applies projectFn.
+func project(s beam.Scope, col beam.PCollection, fields []reflect.StructField, sources []source) beam.PCollection {
+	out := structOf(fields)
+	fn := &projectFn{In: beam.EncodedType{T: col.Type().Type()}, Out: beam.EncodedType{T: out}, Sources: sources}
+	return beam.ParDo(s, fn, col, beam.TypeDefinition{Var: beam.YType, T: out})
+}
+
+// source is where a field of the output comes from.
+type source struct {
+	// Path is the field index path in the input, if copied.
+	Path []int `json:"path,omitempty"`
+	// Default is the JSON encoded value of an added field.
+	Default string `json:"default,omitempty"`
+}
+
+// projectFn builds an output struct from the fields of each input.
+type projectFn struct {
+	// In and Out are the input and output types.
+	In  beam.EncodedType `json:"in"`
+	Out beam.EncodedType `json:"out"`
+	// Sources are the sources of the output fields.
+	Sources []source `json:"sources"`
+
+	defaults []reflect.Value
+}
+
+func (f *projectFn) Setup() error {
+	f.defaults = make([]reflect.Value, len(f.Sources))
+	for i, src := range f.Sources {
+		if src.Path != nil {
+			continue
+		}
+		v := reflect.New(f.Out.T.Field(i).Type)
+		if err := json.Unmarshal([]byte(src.Default), v.Interface()); err != nil {
+			return errors.Wrapf(err, "failed to decode default of field %v", f.Out.T.Field(i).Name)
+		}
+		f.defaults[i] = v.Elem()
+	}
+	return nil
+}
+
+func (f *projectFn) ProcessElement(elm beam.X) beam.Y {
+	in := reflect.ValueOf(elm)
+	out := reflect.New(f.Out.T).Elem()
+	for i, src := range f.Sources {
+		if src.Path == nil {
+			out.Field(i).Set(f.defaults[i])
+			continue
+		}
+		if v, ok := field(in, src.Path); ok {
+			out.Field(i).Set(v)
+		}
+	}
+	return out.Interface()
+}
+
+// field returns the field at the index path, or false if a pointer on the
+// path is nil.
+func field(v reflect.Value, path []int) (reflect.Value, bool) {
+	for i, index := range path {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	return v, true
+}
@@ -0,0 +1,262 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: fields.shims.go
+
+package fields
+
+import (
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*aggregateFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*formatFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*groupAccum)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*keyFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*projectFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*aggregateFn)(nil)).Elem(), wrapMakerAggregateFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*formatFn)(nil)).Elem(), wrapMakerFormatFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*keyFn)(nil)).Elem(), wrapMakerKeyFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*projectFn)(nil)).Elem(), wrapMakerProjectFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(groupAccum, groupAccum) groupAccum)(nil)).Elem(), funcMakerGroupAccumGroupAccumГGroupAccum)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(groupAccum, typex.X) groupAccum)(nil)).Elem(), funcMakerGroupAccumTypex۰XГGroupAccum)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, groupAccum) typex.Y)(nil)).Elem(), funcMakerTypex۰XGroupAccumГTypex۰Y)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X) typex.Y)(nil)).Elem(), funcMakerTypex۰XГTypex۰Y)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X) (typex.Y, typex.X))(nil)).Elem(), funcMakerTypex۰XГTypex۰YTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() groupAccum)(nil)).Elem(), funcMakerГGroupAccum)
+}
+
+func wrapMakerAggregateFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*aggregateFn)
+	return map[string]reflectx.Func{
+		"AddInput":          reflectx.MakeFunc(func(a0 groupAccum, a1 typex.X) groupAccum { return dfn.AddInput(a0, a1) }),
+		"CreateAccumulator": reflectx.MakeFunc(func() groupAccum { return dfn.CreateAccumulator() }),
+		"MergeAccumulators": reflectx.MakeFunc(func(a0 groupAccum, a1 groupAccum) groupAccum { return dfn.MergeAccumulators(a0, a1) }),
+	}
+}
+
+func wrapMakerFormatFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*formatFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 groupAccum) typex.Y { return dfn.ProcessElement(a0, a1) }),
+	}
+}
+
+func wrapMakerKeyFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*keyFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X) (typex.Y, typex.X) { return dfn.ProcessElement(a0) }),
+	}
+}
+
+func wrapMakerProjectFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*projectFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X) typex.Y { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+type callerGroupAccumGroupAccumГGroupAccum struct {
+	fn func(groupAccum, groupAccum) groupAccum
+}
+
+func funcMakerGroupAccumGroupAccumГGroupAccum(fn interface{}) reflectx.Func {
+	f := fn.(func(groupAccum, groupAccum) groupAccum)
+	return &callerGroupAccumGroupAccumГGroupAccum{fn: f}
+}
+
+func (c *callerGroupAccumGroupAccumГGroupAccum) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerGroupAccumGroupAccumГGroupAccum) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerGroupAccumGroupAccumГGroupAccum) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(groupAccum), args[1].(groupAccum))
+	return []interface{}{out0}
+}
+
+func (c *callerGroupAccumGroupAccumГGroupAccum) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(groupAccum), arg1.(groupAccum))
+}
+
+type callerGroupAccumTypex۰XГGroupAccum struct {
+	fn func(groupAccum, typex.X) groupAccum
+}
+
+func funcMakerGroupAccumTypex۰XГGroupAccum(fn interface{}) reflectx.Func {
+	f := fn.(func(groupAccum, typex.X) groupAccum)
+	return &callerGroupAccumTypex۰XГGroupAccum{fn: f}
+}
+
+func (c *callerGroupAccumTypex۰XГGroupAccum) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerGroupAccumTypex۰XГGroupAccum) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerGroupAccumTypex۰XГGroupAccum) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(groupAccum), args[1].(typex.X))
+	return []interface{}{out0}
+}
+
+func (c *callerGroupAccumTypex۰XГGroupAccum) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(groupAccum), arg1.(typex.X))
+}
+
+type callerTypex۰XGroupAccumГTypex۰Y struct {
+	fn func(typex.X, groupAccum) typex.Y
+}
+
+func funcMakerTypex۰XGroupAccumГTypex۰Y(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, groupAccum) typex.Y)
+	return &callerTypex۰XGroupAccumГTypex۰Y{fn: f}
+}
+
+func (c *callerTypex۰XGroupAccumГTypex۰Y) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XGroupAccumГTypex۰Y) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XGroupAccumГTypex۰Y) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X), args[1].(groupAccum))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XGroupAccumГTypex۰Y) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(typex.X), arg1.(groupAccum))
+}
+
+type callerTypex۰XГTypex۰Y struct {
+	fn func(typex.X) typex.Y
+}
+
+func funcMakerTypex۰XГTypex۰Y(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X) typex.Y)
+	return &callerTypex۰XГTypex۰Y{fn: f}
+}
+
+func (c *callerTypex۰XГTypex۰Y) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XГTypex۰Y) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XГTypex۰Y) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XГTypex۰Y) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(typex.X))
+}
+
+type callerTypex۰XГTypex۰YTypex۰X struct {
+	fn func(typex.X) (typex.Y, typex.X)
+}
+
+func funcMakerTypex۰XГTypex۰YTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X) (typex.Y, typex.X))
+	return &callerTypex۰XГTypex۰YTypex۰X{fn: f}
+}
+
+func (c *callerTypex۰XГTypex۰YTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XГTypex۰YTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XГTypex۰YTypex۰X) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XГTypex۰YTypex۰X) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X))
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+type callerГGroupAccum struct {
+	fn func() groupAccum
+}
+
+func funcMakerГGroupAccum(fn interface{}) reflectx.Func {
+	f := fn.(func() groupAccum)
+	return &callerГGroupAccum{fn: f}
+}
+
+func (c *callerГGroupAccum) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГGroupAccum) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГGroupAccum) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГGroupAccum) Call0x1() interface{} {
+	return c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
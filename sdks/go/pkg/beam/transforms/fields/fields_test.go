@@ -0,0 +1,179 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fields
+
+import (
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type user struct {
+	ID   string
+	Name string
+}
+
+type purchase struct {
+	User  *user
+	Item  string `json:"item"`
+	Price float64
+}
+
+func TestSelect(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, purchase{&user{"u1", "Ann"}, "pen", 2}, purchase{nil, "ink", 5})
+	got := Select(s, col, "User.ID", "Price")
+	passert.Equals(s, got,
+		struct {
+			ID    string
+			Price float64
+		}{"u1", 2},
+		struct {
+			ID    string
+			Price float64
+		}{"", 5})
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("Select failed: %v", err)
+	}
+}
+
+func TestDropRename(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, purchase{&user{"u1", "Ann"}, "pen", 2})
+	got := Rename(s, Drop(s, col, "User"), map[string]string{"Item": "Product"})
+	passert.Equals(s, got, struct {
+		Product string
+		Price   float64
+	}{"pen", 2})
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("Drop and Rename failed: %v", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, user{"u1", "Ann"})
+	got := Add(s, col, Field{Name: "Region", Default: "eu"}, Field{Name: "Score", Default: (*int)(nil)})
+	passert.Equals(s, got, struct {
+		ID     string
+		Name   string
+		Region string
+		Score  *int
+	}{"u1", "Ann", "eu", nil})
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("Add failed: %v", err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(s beam.Scope, col beam.PCollection)
+	}{
+		{"missing", func(s beam.Scope, col beam.PCollection) { Select(s, col, "Missing") }},
+		{"not struct", func(s beam.Scope, col beam.PCollection) { Select(s, col, "Item.Length") }},
+		{"duplicate", func(s beam.Scope, col beam.PCollection) { Select(s, col, "Price", "Price") }},
+		{"nested drop", func(s beam.Scope, col beam.PCollection) { Drop(s, col, "User.ID") }},
+		{"unexported", func(s beam.Scope, col beam.PCollection) { Rename(s, col, map[string]string{"Item": "item"}) }},
+		{"untyped nil", func(s beam.Scope, col beam.PCollection) { Add(s, col, Field{Name: "Extra"}) }},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v: expected panic", test.name)
+				}
+			}()
+			_, s := beam.NewPipelineWithRoot()
+			test.fn(s, beam.Create(s, purchase{}))
+		}()
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	type order struct {
+		Item  string
+		Price float64
+		Qty   *int32
+	}
+	// total is unnamed, like the output of GroupBy.
+	type total = struct {
+		Item     string
+		SumPrice float64
+		AvgPrice float64
+		MaxQty   int32
+		Count    int64
+	}
+	one, three := int32(1), int32(3)
+
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, order{"pen", 2, &one}, order{"pen", 4, &three}, order{"ink", 5, nil})
+	got := GroupBy("Item").
+		Aggregate(Sum("Price"), Mean("Price").As("AvgPrice"), Max("Qty"), Count()).
+		Apply(s, col)
+	passert.Equals(s, got, total{"pen", 6, 3, 3, 2}, total{"ink", 5, 5, 0, 1})
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("GroupBy failed: %v", err)
+	}
+}
+
+type Base struct {
+	ID string
+}
+
+type account struct {
+	Base
+	Plan string
+}
+
+func TestRenamePromoted(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Rename of a promoted field succeeded, want panic")
+		}
+	}()
+	_, s := beam.NewPipelineWithRoot()
+	Rename(s, beam.Create(s, account{Base{"a"}, "pro"}), map[string]string{"ID": "AccountID"})
+}
+
+func TestGroupByUnsigned(t *testing.T) {
+	type counter struct {
+		Name  string
+		Value uint64
+	}
+	type total = struct {
+		Name      string
+		SumValue  uint64
+		MaxValue  uint64
+		MeanValue float64
+	}
+	const big = uint64(1) << 63
+
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, counter{"a", big}, counter{"a", 2}, counter{"b", big + 1})
+	got := GroupBy("Name").Aggregate(Sum("Value"), Max("Value"), Mean("Value")).Apply(s, col)
+	passert.Equals(s, got, total{"a", big + 2, big, float64(big+2) / 2}, total{"b", big + 1, big + 1, float64(big + 1)})
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("GroupBy failed: %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fields
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+// Grouping groups struct elements by key fields and aggregates the other
+// fields of each group. For example:
+//
+//    totals := fields.GroupBy("User.ID", "Item").
+//        Aggregate(fields.Sum("Price"), fields.Mean("Price").As("AvgPrice"), fields.Count()).
+//        Apply(s, purchases)
+//
+// returns a PCollection of structs with the fields ID, Item, SumPrice,
+// AvgPrice and Count. The aggregations are computed by a single CombineFn,
+// which runners can lift to partially combine before the shuffle.
+type Grouping struct {
+	keys []string
+	aggs []Aggregation
+}
+
+// GroupBy returns a Grouping by the given key fields. Nested fields are
+// given as paths, as for Select.
+func GroupBy(keys ...string) *Grouping {
+	return &Grouping{keys: keys}
+}
+
+// Aggregate adds aggregations to the grouping.
+func (g *Grouping) Aggregate(aggs ...Aggregation) *Grouping {
+	return &Grouping{keys: g.keys, aggs: append(append([]Aggregation(nil), g.aggs...), aggs...)}
+}
+
+// Aggregation is an aggregation of a numeric field.
+type Aggregation struct {
+	op    op
+	field string
+	name  string
+}
+
+// As returns the aggregation with the given output field name.
+func (a Aggregation) As(name string) Aggregation {
+	a.name = name
+	return a
+}
+
+type op int
+
+const (
+	opCount op = iota
+	opSum
+	opMean
+	opMin
+	opMax
+)
+
+var opNames = map[op]string{opCount: "Count", opSum: "Sum", opMean: "Mean", opMin: "Min", opMax: "Max"}
+
+// Count counts the elements of each group, as an int64 field named Count.
+func Count() Aggregation {
+	return Aggregation{op: opCount, name: "Count"}
+}
+
+// Sum sums the field, as an int64 field for signed integers, a uint64 field
+// for unsigned integers and a float64 field for floating point numbers. The
+// output field is named Sum<field>.
+func Sum(field string) Aggregation {
+	return newAggregation(opSum, field)
+}
+
+// Mean averages the field, as a float64 field named Mean<field>.
+func Mean(field string) Aggregation {
+	return newAggregation(opMean, field)
+}
+
+// Min returns the smallest value of the field, of the same type. The output
+// field is named Min<field>.
+func Min(field string) Aggregation {
+	return newAggregation(opMin, field)
+}
+
+// Max returns the largest value of the field, of the same type. The output
+// field is named Max<field>.
+func Max(field string) Aggregation {
+	return newAggregation(opMax, field)
+}
+
+func newAggregation(o op, field string) Aggregation {
+	return Aggregation{op: o, field: field, name: opNames[o] + leaf(field)}
+}
+
+func leaf(field string) string {
+	for i := len(field) - 1; i >= 0; i-- {
+		if field[i] == '.' {
+			return field[i+1:]
+		}
+	}
+	return field
+}
+
+// Apply applies the grouping to a PCollection of structs. It returns a
+// PCollection of structs with the key fields, named by their last
+// component, followed by the aggregations. Nil pointers on the path of an
+// aggregated field are skipped, like SQL NULLs.
+func (g *Grouping) Apply(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("fields.GroupBy")
+
+	t := mustStruct(col)
+	if len(g.keys) == 0 || len(g.aggs) == 0 {
+		panic("grouping needs key fields and aggregations")
+	}
+
+	var keys []reflect.StructField
+	var sources []source
+	for _, name := range g.keys {
+		path, f, err := lookup(t, name)
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+		sources = append(sources, source{Path: path})
+	}
+
+	out := append([]reflect.StructField(nil), keys...)
+	var specs []aggregation
+	for _, a := range g.aggs {
+		mustExported(a.name)
+		spec := aggregation{Op: a.op}
+		typ := reflectx.Int64
+		if a.op != opCount {
+			path, f, err := lookup(t, a.field)
+			if err != nil {
+				panic(err)
+			}
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			switch {
+			case isUnsigned(ft):
+				spec.Unsigned = true
+			case reflectx.IsInteger(ft):
+			case reflectx.IsFloat(ft):
+				spec.Float = true
+			default:
+				panic(fmt.Sprintf("field %v of type %v is not a number", a.field, f.Type))
+			}
+			spec.Path = path
+			switch a.op {
+			case opMean:
+				typ = reflectx.Float64
+			case opSum:
+				switch {
+				case spec.Float:
+					typ = reflectx.Float64
+				case spec.Unsigned:
+					typ = reflectx.Uint64
+				}
+			default:
+				typ = ft
+			}
+		}
+		out = append(out, reflect.StructField{Name: a.name, Type: typ})
+		specs = append(specs, spec)
+	}
+	outT := structOf(out)
+	keyT := structOf(keys)
+
+	key := &keyFn{projectFn{In: beam.EncodedType{T: t}, Out: beam.EncodedType{T: keyT}, Sources: sources}}
+	keyed := beam.ParDo(s, key, col, beam.TypeDefinition{Var: beam.YType, T: keyT})
+	combined := beam.CombinePerKey(s, &aggregateFn{Aggregations: specs}, keyed)
+	return beam.ParDo(s, &formatFn{Out: beam.EncodedType{T: outT}, Aggregations: specs}, combined, beam.TypeDefinition{Var: beam.YType, T: outT})
+}
+
+// aggregation is the serialized form of an Aggregation.
+type aggregation struct {
+	Op op `json:"op"`
+	// Path is the field index path of the aggregated field.
+	Path []int `json:"path,omitempty"`
+	// Float is true if the field is a floating point number.
+	Float bool `json:"float,omitempty"`
+	// Unsigned is true if the field is an unsigned integer, which is
+	// accumulated as a uint64 so that large values do not wrap.
+	Unsigned bool `json:"unsigned,omitempty"`
+}
+
+func isUnsigned(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// keyFn keys each element by its key fields.
+type keyFn struct {
+	projectFn
+}
+
+func (f *keyFn) ProcessElement(elm beam.X) (beam.Y, beam.X) {
+	return f.projectFn.ProcessElement(elm), elm
+}
+
+// partial is the accumulated state of an aggregation.
+type partial struct {
+	Count int64
+	Int   int64
+	Uint  uint64
+	Float float64
+}
+
+type groupAccum struct {
+	Partials []partial
+}
+
+// aggregateFn is a combineFn that accumulates all aggregations of a group.
+type aggregateFn struct {
+	Aggregations []aggregation `json:"aggregations"`
+}
+
+func (f *aggregateFn) CreateAccumulator() groupAccum {
+	return groupAccum{Partials: make([]partial, len(f.Aggregations))}
+}
+
+func (f *aggregateFn) AddInput(a groupAccum, elm beam.X) groupAccum {
+	in := reflect.ValueOf(elm)
+	for i, agg := range f.Aggregations {
+		if agg.Op == opCount {
+			a.Partials[i].Count++
+			continue
+		}
+		v, ok := field(in, agg.Path)
+		if ok && v.Kind() == reflect.Ptr {
+			if ok = !v.IsNil(); ok {
+				v = v.Elem()
+			}
+		}
+		if !ok {
+			continue
+		}
+		var x partial
+		x.Count = 1
+		switch {
+		case agg.Float:
+			x.Float = v.Convert(reflectx.Float64).Float()
+		case agg.Unsigned:
+			x.Uint = v.Convert(reflectx.Uint64).Uint()
+		default:
+			x.Int = v.Convert(reflectx.Int64).Int()
+		}
+		a.Partials[i] = merge(agg, a.Partials[i], x)
+	}
+	return a
+}
+
+func (f *aggregateFn) MergeAccumulators(a, b groupAccum) groupAccum {
+	for i, agg := range f.Aggregations {
+		a.Partials[i] = merge(agg, a.Partials[i], b.Partials[i])
+	}
+	return a
+}
+
+func merge(agg aggregation, a, b partial) partial {
+	switch {
+	case b.Count == 0:
+		return a
+	case a.Count == 0:
+		return b
+	}
+	ret := partial{Count: a.Count + b.Count}
+	switch agg.Op {
+	case opMin:
+		ret.Int, ret.Uint, ret.Float = min(a.Int, b.Int), a.Uint, a.Float
+		if b.Uint < a.Uint {
+			ret.Uint = b.Uint
+		}
+		if b.Float < a.Float {
+			ret.Float = b.Float
+		}
+	case opMax:
+		ret.Int, ret.Uint, ret.Float = max(a.Int, b.Int), a.Uint, a.Float
+		if b.Uint > a.Uint {
+			ret.Uint = b.Uint
+		}
+		if b.Float > a.Float {
+			ret.Float = b.Float
+		}
+	default:
+		ret.Int, ret.Uint, ret.Float = a.Int+b.Int, a.Uint+b.Uint, a.Float+b.Float
+	}
+	return ret
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// formatFn builds the output struct from the key and the accumulated
+// aggregations.
+type formatFn struct {
+	Out          beam.EncodedType `json:"out"`
+	Aggregations []aggregation    `json:"aggregations"`
+}
+
+func (f *formatFn) ProcessElement(key beam.X, a groupAccum) beam.Y {
+	k := reflect.ValueOf(key)
+	out := reflect.New(f.Out.T).Elem()
+	for i := 0; i < k.NumField(); i++ {
+		out.Field(i).Set(k.Field(i))
+	}
+	for i, agg := range f.Aggregations {
+		p, v := a.Partials[i], out.Field(k.NumField()+i)
+		switch {
+		case agg.Op == opCount:
+			v.SetInt(p.Count)
+		case agg.Op == opMean:
+			if p.Count > 0 {
+				switch {
+				case agg.Float:
+					v.SetFloat(p.Float / float64(p.Count))
+				case agg.Unsigned:
+					v.SetFloat(float64(p.Uint) / float64(p.Count))
+				default:
+					v.SetFloat(float64(p.Int) / float64(p.Count))
+				}
+			}
+		case agg.Float:
+			v.Set(reflect.ValueOf(p.Float).Convert(v.Type()))
+		case agg.Unsigned:
+			v.Set(reflect.ValueOf(p.Uint).Convert(v.Type()))
+		default:
+			v.Set(reflect.ValueOf(p.Int).Convert(v.Type()))
+		}
+	}
+	return out.Interface()
+}
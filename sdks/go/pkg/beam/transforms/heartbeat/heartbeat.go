@@ -0,0 +1,262 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package heartbeat lets long-running steps report their progress as
+// heartbeat records in a monitoring PCollection, so that stalled partitions
+// can be alerted on from within the pipeline. For example:
+//
+//    out, beats := heartbeat.ParDo(s, "Export", export, partitions, heartbeat.Options{
+//        Interval: time.Minute,
+//    })
+//    textio.Write(s, "gs://bucket/heartbeats.json", beam.ParDo(s, encode, beats))
+//
+// where export reports its progress while it runs:
+//
+//    func export(ctx context.Context, p *heartbeat.Progress, part Partition) (Summary, error) {
+//        p.SetPartition(part.Name)
+//        for i, batch := range part.Batches {
+//            ...
+//            p.Report(int64(i+1), "exported batch")
+//        }
+//        ...
+//    }
+//
+// The heartbeat records are outputs of the step, so runners only make them
+// visible downstream once the bundle that outputs them commits: they report
+// the progress of completed work, not the liveness of a stalled element.
+// For liveness, each heartbeat is also reported while the element is being
+// processed, as user metrics of the step in the "heartbeat" namespace and
+// as a worker log entry. Runners receive the metrics with the progress of
+// the bundle, so a stall can be alerted on from the runner's monitoring,
+// such as a <step>.heartbeats counter that stops increasing.
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=heartbeat --identifiers=heartbeatFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Heartbeat)(nil)).Elem())
+}
+
+// Heartbeat is a progress record of an element being processed.
+type Heartbeat struct {
+	// Step is the name of the step.
+	Step string `json:"step"`
+	// Partition is the partition set with Progress.SetPartition, if any.
+	Partition string `json:"partition,omitempty"`
+	// Worker is the host name of the worker.
+	Worker string `json:"worker"`
+	// Processed and Message are the last values reported with
+	// Progress.Report.
+	Processed int64  `json:"processed"`
+	Message   string `json:"message,omitempty"`
+	// ElapsedMillis is the time since processing of the element started.
+	ElapsedMillis int64 `json:"elapsed_millis"`
+	// Done is set on the last heartbeat of an element, once it has been
+	// processed, and Error is the error it failed with, if any.
+	Done  bool   `json:"done,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Time is the processing time of the heartbeat, in RFC 3339 format.
+	Time string `json:"time"`
+}
+
+// Progress is the progress of an element, which is reported by the
+// function applied with ParDo. It is safe for concurrent use.
+type Progress struct {
+	mu        sync.Mutex
+	partition string
+	processed int64
+	message   string
+}
+
+// SetPartition sets the partition that the heartbeats of the element are
+// reported for, such as the shard or file being processed.
+func (p *Progress) SetPartition(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.partition = name
+}
+
+// Report reports the amount of work done so far, in units chosen by the
+// function, with a message.
+func (p *Progress) Report(processed int64, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed = processed
+	p.message = message
+}
+
+func (p *Progress) heartbeat(step, worker string, start time.Time) Heartbeat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	return Heartbeat{
+		Step:          step,
+		Partition:     p.partition,
+		Worker:        worker,
+		Processed:     p.processed,
+		Message:       p.message,
+		ElapsedMillis: int64(now.Sub(start) / time.Millisecond),
+		Time:          now.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// Options configure ParDo.
+type Options struct {
+	// Interval is the interval between heartbeats of an element. If zero,
+	// 30 seconds is used.
+	Interval time.Duration
+}
+
+var (
+	contextType  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	progressType = reflect.TypeOf((*Progress)(nil))
+)
+
+// ParDo applies fn to each element of the PCollection<A> as the named step,
+// where fn is of the form (context.Context, *Progress, A) -> (B, error). It
+// returns the PCollection<B> of the outputs and the PCollection<Heartbeat>
+// of the heartbeats of the elements. While fn runs, a heartbeat with its
+// last reported progress is output at every interval, and a last heartbeat
+// once it returns. Errors returned by fn fail the bundle.
+//
+// Each heartbeat also increments the <step>.heartbeats counter and sets the
+// <step>.processed and <step>.elapsed_millis gauges as soon as it is taken,
+// rather than when the bundle commits.
+func ParDo(s beam.Scope, step string, fn interface{}, col beam.PCollection, opts Options) (beam.PCollection, beam.PCollection) {
+	s = s.Scope(step)
+
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 3 || t.In(0) != contextType || t.In(1) != progressType ||
+		t.NumOut() != 2 || t.Out(1) != reflectx.Error {
+		panic(fmt.Sprintf("%v is not of the form (context.Context, *heartbeat.Progress, A) -> (B, error)", t))
+	}
+	if in := col.Type().Type(); !in.AssignableTo(t.In(2)) {
+		panic(fmt.Sprintf("%v cannot be applied to PCollection<%v>", t, in))
+	}
+	if opts.Interval < 0 {
+		panic(fmt.Sprintf("invalid heartbeat interval: %v", opts.Interval))
+	}
+	if opts.Interval == 0 {
+		opts.Interval = 30 * time.Second
+	}
+
+	return beam.ParDo2(s, &heartbeatFn{
+		Step:     step,
+		Fn:       beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)},
+		Interval: opts.Interval,
+	}, col, beam.TypeDefinition{Var: beam.YType, T: t.Out(0)})
+}
+
+// heartbeatFn calls Fn in a goroutine and outputs heartbeats until it
+// returns. The output and heartbeats are only emitted from ProcessElement,
+// while the metrics and logs of the heartbeats are reported right away.
+type heartbeatFn struct {
+	Step     string           `json:"step"`
+	Fn       beam.EncodedFunc `json:"fn"`
+	Interval time.Duration    `json:"interval"`
+
+	fn        reflectx.Func
+	worker    string
+	beats     beam.Counter
+	processed beam.Gauge
+	elapsed   beam.Gauge
+}
+
+func (f *heartbeatFn) Setup() {
+	f.fn = f.Fn.Fn
+	f.worker, _ = os.Hostname()
+	f.beats = beam.NewCounter("heartbeat", f.Step+".heartbeats")
+	f.processed = beam.NewGauge("heartbeat", f.Step+".processed")
+	f.elapsed = beam.NewGauge("heartbeat", f.Step+".elapsed_millis")
+}
+
+// report reports the heartbeat out of band, as metrics and a log entry.
+func (f *heartbeatFn) report(ctx context.Context, hb Heartbeat) {
+	f.beats.Inc(ctx, 1)
+	f.processed.Set(ctx, hb.Processed)
+	f.elapsed.Set(ctx, hb.ElapsedMillis)
+	if !hb.Done {
+		log.Infof(ctx, "Heartbeat of %v: partition %q processed %v after %vms: %v",
+			f.Step, hb.Partition, hb.Processed, hb.ElapsedMillis, hb.Message)
+	}
+}
+
+type result struct {
+	out interface{}
+	err error
+}
+
+func (f *heartbeatFn) ProcessElement(ctx context.Context, elm beam.X, emit func(beam.Y), beat func(Heartbeat)) error {
+	p := &Progress{}
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		done <- f.call(ctx, p, elm)
+	}()
+
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			hb := p.heartbeat(f.Step, f.worker, start)
+			f.report(ctx, hb)
+			beat(hb)
+		case res := <-done:
+			hb := p.heartbeat(f.Step, f.worker, start)
+			hb.Done = true
+			if res.err != nil {
+				hb.Error = res.err.Error()
+			}
+			f.report(ctx, hb)
+			beat(hb)
+			if res.err != nil {
+				return res.err
+			}
+			emit(res.out)
+			return nil
+		}
+	}
+}
+
+// call calls the function and converts panics to errors.
+func (f *heartbeatFn) call(ctx context.Context, p *Progress, elm interface{}) (res result) {
+	defer func() {
+		if r := recover(); r != nil {
+			res.err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	ret := f.fn.Call([]interface{}{ctx, p, elm})
+	if ret[1] != nil {
+		return result{err: ret[1].(error)}
+	}
+	return result{out: ret[0]}
+}
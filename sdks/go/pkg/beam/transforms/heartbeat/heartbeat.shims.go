@@ -0,0 +1,152 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: heartbeat.shims.go
+
+package heartbeat
+
+import (
+	"context"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*Heartbeat)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*heartbeatFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*heartbeatFn)(nil)).Elem(), wrapMakerHeartbeatFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, typex.X, func(typex.Y), func(Heartbeat)) error)(nil)).Elem(), funcMakerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(Heartbeat))(nil)).Elem(), emitMakerHeartbeat)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.Y))(nil)).Elem(), emitMakerTypex۰Y)
+}
+
+func wrapMakerHeartbeatFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*heartbeatFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 typex.X, a2 func(typex.Y), a3 func(Heartbeat)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError struct {
+	fn func(context.Context, typex.X, func(typex.Y), func(Heartbeat)) error
+}
+
+func funcMakerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, typex.X, func(typex.Y), func(Heartbeat)) error)
+	return &callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError{fn: f}
+}
+
+func (c *callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(typex.X), args[2].(func(typex.Y)), args[3].(func(Heartbeat)))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextTypex۰XEmitTypex۰YEmitHeartbeatГError) Call4x1(arg0, arg1, arg2, arg3 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(typex.X), arg2.(func(typex.Y)), arg3.(func(Heartbeat)))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerHeartbeat(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeHeartbeat
+	return ret
+}
+
+func (e *emitNative) invokeHeartbeat(val Heartbeat) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰Y(val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+// DO NOT MODIFY: GENERATED CODE
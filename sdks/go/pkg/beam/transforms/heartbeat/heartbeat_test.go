@@ -0,0 +1,130 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/metrics"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/filter"
+)
+
+func init() {
+	beam.RegisterFunction(work)
+	beam.RegisterFunction(fail)
+	beam.RegisterFunction(formatDone)
+	beam.RegisterFunction(periodic)
+}
+
+func work(ctx context.Context, p *Progress, n int) (string, error) {
+	p.SetPartition(fmt.Sprintf("p%v", n))
+	for i := 1; i <= 3; i++ {
+		time.Sleep(20 * time.Millisecond)
+		p.Report(int64(i), "slept")
+	}
+	return fmt.Sprintf("done %v", n), nil
+}
+
+func fail(ctx context.Context, p *Progress, n int) (string, error) {
+	return "", errors.New("failed")
+}
+
+func formatDone(hb Heartbeat, emit func(string)) {
+	if hb.Done {
+		emit(fmt.Sprintf("%v:%v:%v:%v", hb.Step, hb.Partition, hb.Processed, hb.Message))
+	}
+}
+
+func periodic(hb Heartbeat, emit func(string)) {
+	if !hb.Done {
+		emit(hb.Partition)
+	}
+}
+
+func TestParDo(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	out, beats := ParDo(s, "Work", work, beam.Create(s, 1, 2), Options{Interval: 10 * time.Millisecond})
+	passert.Equals(s, out, "done 1", "done 2")
+	passert.Equals(s, beam.ParDo(s, formatDone, beats), "Work:p1:3:slept", "Work:p2:3:slept")
+	passert.Equals(s, filter.Distinct(s, beam.ParDo(s, periodic, beats)), "p1", "p2")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestParDoError(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	ParDo(s, "Fail", fail, beam.Create(s, 1), Options{})
+
+	if err := ptest.Run(p); err == nil {
+		t.Fatalf("pipeline succeeded, want error")
+	}
+}
+
+var release = make(chan struct{})
+
+func block(ctx context.Context, p *Progress, n int) (int, error) {
+	p.Report(int64(n), "blocked")
+	<-release
+	return n, nil
+}
+
+// TestParDoLiveness verifies that heartbeats are reported as metrics while
+// the element is still being processed.
+func TestParDoLiveness(t *testing.T) {
+	ctx := metrics.SetBundleID(metrics.SetPTransformID(context.Background(), "block"), "liveness")
+	fn := &heartbeatFn{Step: "Block", Fn: beam.EncodedFunc{Fn: reflectx.MakeFunc(block)}, Interval: time.Millisecond}
+	fn.Setup()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn.ProcessElement(ctx, 7, func(beam.Y) {}, func(Heartbeat) {})
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		beats, processed := int64(0), int64(0)
+		for _, m := range metrics.ToProto("liveness", "block") {
+			switch m.GetMetricName().GetName() {
+			case "Block.heartbeats":
+				beats = m.GetCounterData().GetValue()
+			case "Block.processed":
+				processed = m.GetGaugeData().GetValue()
+			}
+		}
+		if beats > 0 && processed == 7 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no heartbeat metrics while processing: heartbeats %v, processed %v", beats, processed)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessElement failed: %v", err)
+	}
+}
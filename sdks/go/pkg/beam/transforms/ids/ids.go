@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ids contains a transform that attaches stable unique IDs to
+// elements, for idempotent writes to systems without natural keys.
+package ids
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=ids --identifiers=hashFn,numberFn
+//go:generate go fmt
+
+// Options configure WithUniqueIDs.
+type Options struct {
+	// Namespace is hashed with the elements, so that pipelines that write
+	// the same elements to one system can be given distinct IDs, such as
+	// by using the name and date of the pipeline.
+	Namespace string
+}
+
+// WithUniqueIDs returns a PCollection<KV<string,V>> of the elements of the
+// PCollection<V> keyed by unique IDs. The ID of an element is the hex
+// encoded, truncated SHA-256 hash of the namespace, its window and its
+// encoding, so retries assign the same IDs. Equal elements in a window are
+// numbered, and all but the first have the ID "<hash>-<n>". For example:
+//
+//    keyed := ids.WithUniqueIDs(s, rows, ids.Options{Namespace: "export-" + *date})
+//
+// The elements are grouped by their hash, so their timestamps are reset
+// to the end of their window.
+func WithUniqueIDs(s beam.Scope, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope("ids.WithUniqueIDs")
+
+	hashed := beam.ParDo(s, &hashFn{
+		Namespace: opts.Namespace,
+		Type:      beam.EncodedType{T: col.Type().Type()},
+	}, col)
+	return beam.ParDo(s, numberFn, beam.GroupByKey(s, hashed))
+}
+
+// hashFn keys elements by their hash.
+type hashFn struct {
+	Namespace string           `json:"namespace,omitempty"`
+	Type      beam.EncodedType `json:"type"`
+
+	enc beam.ElementEncoder
+}
+
+func (f *hashFn) Setup() {
+	f.enc = beam.NewElementEncoder(f.Type.T)
+}
+
+func (f *hashFn) ProcessElement(w beam.Window, elm beam.X) (string, beam.X, error) {
+	var buf bytes.Buffer
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(w.MaxTimestamp()))
+	buf.WriteString(f.Namespace)
+	buf.WriteByte(0)
+	buf.Write(ts[:])
+	if err := f.enc.Encode(elm, &buf); err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:16]), elm, nil
+}
+
+// numberFn numbers equal elements.
+func numberFn(hash string, values func(*beam.X) bool, emit func(string, beam.X)) {
+	var value beam.X
+	for n := 0; values(&value); n++ {
+		id := hash
+		if n > 0 {
+			id += "-" + strconv.Itoa(n)
+		}
+		emit(id, value)
+	}
+}
@@ -0,0 +1,212 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: ids.shims.go
+
+package ids
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(numberFn)
+	runtime.RegisterType(reflect.TypeOf((*hashFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*hashFn)(nil)).Elem(), wrapMakerHashFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string, func(*typex.X) bool, func(string, typex.X)))(nil)).Elem(), funcMakerStringIterTypex۰XEmitStringTypex۰XГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.Window, typex.X) (string, typex.X, error))(nil)).Elem(), funcMakerTypex۰WindowTypex۰XГStringTypex۰XError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(string, typex.X))(nil)).Elem(), emitMakerStringTypex۰X)
+	exec.RegisterInput(reflect.TypeOf((*func(*typex.X) bool)(nil)).Elem(), iterMakerTypex۰X)
+}
+
+func wrapMakerHashFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*hashFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.Window, a1 typex.X) (string, typex.X, error) { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerStringIterTypex۰XEmitStringTypex۰XГ struct {
+	fn func(string, func(*typex.X) bool, func(string, typex.X))
+}
+
+func funcMakerStringIterTypex۰XEmitStringTypex۰XГ(fn interface{}) reflectx.Func {
+	f := fn.(func(string, func(*typex.X) bool, func(string, typex.X)))
+	return &callerStringIterTypex۰XEmitStringTypex۰XГ{fn: f}
+}
+
+func (c *callerStringIterTypex۰XEmitStringTypex۰XГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringIterTypex۰XEmitStringTypex۰XГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringIterTypex۰XEmitStringTypex۰XГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(string), args[1].(func(*typex.X) bool), args[2].(func(string, typex.X)))
+	return []interface{}{}
+}
+
+func (c *callerStringIterTypex۰XEmitStringTypex۰XГ) Call3x0(arg0, arg1, arg2 interface{}) {
+	c.fn(arg0.(string), arg1.(func(*typex.X) bool), arg2.(func(string, typex.X)))
+}
+
+type callerTypex۰WindowTypex۰XГStringTypex۰XError struct {
+	fn func(typex.Window, typex.X) (string, typex.X, error)
+}
+
+func funcMakerTypex۰WindowTypex۰XГStringTypex۰XError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.Window, typex.X) (string, typex.X, error))
+	return &callerTypex۰WindowTypex۰XГStringTypex۰XError{fn: f}
+}
+
+func (c *callerTypex۰WindowTypex۰XГStringTypex۰XError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰WindowTypex۰XГStringTypex۰XError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰WindowTypex۰XГStringTypex۰XError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(typex.Window), args[1].(typex.X))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerTypex۰WindowTypex۰XГStringTypex۰XError) Call2x3(arg0, arg1 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(typex.Window), arg1.(typex.X))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerStringTypex۰X(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeStringTypex۰X
+	return ret
+}
+
+func (e *emitNative) invokeStringTypex۰X(key string, val typex.X) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerTypex۰X(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readTypex۰X
+	return ret
+}
+
+func (v *iterNative) readTypex۰X(value *typex.X) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(typex.X)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,69 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ids
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(format)
+}
+
+// format formats an element with whether its ID is numbered.
+func format(id, elm string) string {
+	return fmt.Sprintf("%v:%v:%v", elm, len(strings.SplitN(id, "-", 2)[0]), strings.Contains(id, "-"))
+}
+
+func TestWithUniqueIDs(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	keyed := WithUniqueIDs(s, beam.Create(s, "a", "b", "a"), Options{})
+	passert.Equals(s, beam.ParDo(s, format, keyed), "a:32:false", "a:32:true", "b:32:false")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := func(namespace, elm string) string {
+		fn := &hashFn{Namespace: namespace, Type: beam.EncodedType{T: reflect.TypeOf("")}}
+		fn.Setup()
+		id, _, err := fn.ProcessElement(window.GlobalWindow{}, elm)
+		if err != nil {
+			t.Fatalf("hash(%v, %v) failed: %v", namespace, elm, err)
+		}
+		return id
+	}
+
+	if hash("", "a") != hash("", "a") {
+		t.Errorf("hash of a is not stable")
+	}
+	if hash("", "a") == hash("", "b") {
+		t.Errorf("hashes of a and b are equal")
+	}
+	if hash("", "a") == hash("other", "a") {
+		t.Errorf("hashes of a in different namespaces are equal")
+	}
+}
@@ -0,0 +1,334 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inference contains a transform that runs machine learning models
+// on the elements of a PCollection. Models are supplied by a ModelHandler,
+// which loads a model once per worker process, and elements are passed to
+// the model in batches. For example:
+//
+//    type scorer struct {
+//        Path string `json:"path"`  // exported fields are the handler configuration
+//    }
+//
+//    func (h scorer) Load(ctx context.Context) (inference.Model, error) { ... }
+//
+//    scores := inference.RunInference(s, scorer{Path: "gs://models/scorer"}, features,
+//        reflect.TypeOf(float32(0)), inference.Options{MaxBatchSize: 64})
+//
+// The metrics of the "inference" namespace report the number of inferences
+// and batches, and the latencies of loading models and of predicting
+// batches.
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=inference --identifiers=batchKeyFn,bundleInferenceFn,runInferenceFn
+//go:generate go fmt
+
+// ModelHandler loads a model. A handler is encoded as JSON and sent to the
+// workers, so its configuration must be in exported fields and its type
+// must be registered with beam.RegisterType.
+type ModelHandler interface {
+	// Load loads the model. It is called once per worker process for each
+	// distinct handler configuration.
+	Load(ctx context.Context) (Model, error)
+}
+
+// Model is a loaded model. It must be safe for concurrent use.
+type Model interface {
+	// Predict returns the predictions for a batch of examples, in the order
+	// of the examples.
+	Predict(ctx context.Context, examples []interface{}) ([]interface{}, error)
+}
+
+// Options configure RunInference.
+type Options struct {
+	// MaxBatchSize is the maximum number of examples passed to Predict at
+	// once. If zero, it defaults to 100.
+	MaxBatchSize int
+	// Shards is the number of keys the examples of a window other than the
+	// global window are grouped by into batches, which bounds the
+	// parallelism of the predictions of a window. If zero, it defaults to
+	// 16.
+	Shards int
+}
+
+const (
+	defaultBatchSize = 100
+	defaultShards    = 16
+)
+
+// RunInference runs the model of the handler on the elements of col and
+// returns a PCollection<KV<X,P>> of each element paired with its prediction,
+// where P is the given prediction type, t.
+//
+// Elements in the global window are batched within each bundle, so the
+// predictions run with the parallelism of the input and, if it is unbounded,
+// as elements arrive. Elements in other windows are grouped into batches per
+// window by a GroupByKey over Options.Shards keys, so a batch never mixes
+// windows, and predicted once their window closes. Predictions keep the
+// windows and timestamps of their elements.
+func RunInference(s beam.Scope, handler ModelHandler, col beam.PCollection, t reflect.Type, opts Options) beam.PCollection {
+	s = s.Scope("inference.RunInference")
+
+	if opts.MaxBatchSize < 0 {
+		panic(fmt.Sprintf("invalid batch size: %v", opts.MaxBatchSize))
+	}
+	if opts.MaxBatchSize == 0 {
+		opts.MaxBatchSize = defaultBatchSize
+	}
+	if opts.Shards < 0 {
+		panic(fmt.Sprintf("invalid number of shards: %v", opts.Shards))
+	}
+	if opts.Shards == 0 {
+		opts.Shards = defaultShards
+	}
+	data, err := json.Marshal(handler)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to encode model handler %T", handler))
+	}
+
+	mf := modelFn{
+		Handler:      beam.EncodedType{T: reflect.TypeOf(handler)},
+		Config:       string(data),
+		MaxBatchSize: opts.MaxBatchSize,
+	}
+	if col.WindowingStrategy().Fn.Kind == window.GlobalWindows {
+		return beam.ParDo(s, &bundleInferenceFn{modelFn: mf}, col, beam.TypeDefinition{Var: beam.YType, T: t})
+	}
+
+	keyed := beam.ParDo(s, &batchKeyFn{Shards: opts.Shards}, col)
+	batches, v := stamp.GroupByKey(s, keyed)
+	fn := &runInferenceFn{modelFn: mf, Example: beam.EncodedType{T: v}}
+	return beam.ParDo(s, fn, batches, beam.TypeDefinition{Var: beam.XType, T: v}, beam.TypeDefinition{Var: beam.YType, T: t})
+}
+
+var (
+	inferences   = beam.NewCounter("inference", "num_inferences")
+	batches      = beam.NewCounter("inference", "num_batches")
+	loadLatency  = beam.NewDistribution("inference", "load_model_latency_msecs")
+	batchLatency = beam.NewDistribution("inference", "inference_batch_latency_msecs")
+)
+
+// loaded is a model loaded by a worker process, shared by all DoFn
+// instances with the same handler.
+type loaded struct {
+	once  sync.Once
+	model Model
+	err   error
+}
+
+var (
+	models   = make(map[string]*loaded)
+	modelsMu sync.Mutex
+)
+
+// load returns the model of the handler, loading it on first use.
+func load(ctx context.Context, key string, handler ModelHandler) (Model, error) {
+	modelsMu.Lock()
+	l, ok := models[key]
+	if !ok {
+		l = &loaded{}
+		models[key] = l
+	}
+	modelsMu.Unlock()
+
+	l.once.Do(func() {
+		start := time.Now()
+		l.model, l.err = handler.Load(ctx)
+		if l.err != nil {
+			l.err = errors.Wrapf(l.err, "failed to load model with %T", handler)
+			return
+		}
+		loadLatency.Update(ctx, int64(time.Since(start)/time.Millisecond))
+		log.Infof(ctx, "Loaded model with %T in %v", handler, time.Since(start))
+	})
+	if l.err != nil {
+		// Let a later bundle retry the load.
+		modelsMu.Lock()
+		if models[key] == l {
+			delete(models, key)
+		}
+		modelsMu.Unlock()
+	}
+	return l.model, l.err
+}
+
+// batchKeyFn assigns examples to batch keys round-robin, starting at a
+// random key in each bundle.
+type batchKeyFn struct {
+	Shards int `json:"shards"`
+
+	next int
+}
+
+func (f *batchKeyFn) StartBundle() {
+	f.next = rand.Intn(f.Shards)
+}
+
+func (f *batchKeyFn) ProcessElement(elm beam.X) (int, beam.X) {
+	key := f.next
+	f.next = (f.next + 1) % f.Shards
+	return key, elm
+}
+
+// modelFn holds the model of a handler, which it loads in Setup.
+type modelFn struct {
+	// Handler is the type of the model handler and Config its JSON encoding.
+	Handler beam.EncodedType `json:"handler"`
+	Config  string           `json:"config"`
+	// MaxBatchSize is the maximum number of examples of a batch.
+	MaxBatchSize int `json:"max_batch_size"`
+
+	model Model
+}
+
+func (f *modelFn) Setup(ctx context.Context) error {
+	t := f.Handler.T
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Ptr {
+		ptr = reflect.New(t.Elem())
+	}
+	if err := json.Unmarshal([]byte(f.Config), ptr.Interface()); err != nil {
+		return errors.Wrapf(err, "failed to decode model handler %v", t)
+	}
+	handler := ptr
+	if t.Kind() != reflect.Ptr {
+		handler = ptr.Elem()
+	}
+
+	model, err := load(ctx, t.String()+":"+f.Config, handler.Interface().(ModelHandler))
+	if err != nil {
+		return err
+	}
+	f.model = model
+	return nil
+}
+
+// predict predicts a batch of examples and emits them with their
+// predictions and timestamps.
+func (f *modelFn) predict(ctx context.Context, times []beam.EventTime, examples []interface{}, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	start := time.Now()
+	predictions, err := f.model.Predict(ctx, examples)
+	if err != nil {
+		return errors.Wrapf(err, "failed to predict batch of %v examples", len(examples))
+	}
+	if len(predictions) != len(examples) {
+		return errors.Errorf("model returned %v predictions for %v examples", len(predictions), len(examples))
+	}
+	batchLatency.Update(ctx, int64(time.Since(start)/time.Millisecond))
+	batches.Inc(ctx, 1)
+	inferences.Inc(ctx, int64(len(examples)))
+
+	for i, e := range examples {
+		emit(times[i], e, predictions[i])
+	}
+	return nil
+}
+
+// bundleInferenceFn predicts the examples of a bundle in the global window
+// with the model, in batches of at most MaxBatchSize. The last batch of a
+// bundle is predicted when it finishes.
+type bundleInferenceFn struct {
+	modelFn
+
+	times    []beam.EventTime
+	examples []interface{}
+}
+
+func (f *bundleInferenceFn) Setup(ctx context.Context) error {
+	return f.modelFn.Setup(ctx)
+}
+
+func (f *bundleInferenceFn) StartBundle() {
+	f.times, f.examples = nil, nil
+}
+
+func (f *bundleInferenceFn) ProcessElement(ctx context.Context, t beam.EventTime, elm beam.X, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	f.times = append(f.times, t)
+	f.examples = append(f.examples, elm)
+	if len(f.examples) < f.MaxBatchSize {
+		return nil
+	}
+	return f.flush(ctx, emit)
+}
+
+func (f *bundleInferenceFn) FinishBundle(ctx context.Context, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	if len(f.examples) == 0 {
+		return nil
+	}
+	return f.flush(ctx, emit)
+}
+
+func (f *bundleInferenceFn) flush(ctx context.Context, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	times, examples := f.times, f.examples
+	f.times, f.examples = nil, nil
+	return f.predict(ctx, times, examples, emit)
+}
+
+// runInferenceFn predicts the examples of a batch key and window with the
+// model, in batches of at most MaxBatchSize.
+type runInferenceFn struct {
+	modelFn
+	// Example is the type of the examples.
+	Example beam.EncodedType `json:"example"`
+
+	dec beam.ElementDecoder
+}
+
+func (f *runInferenceFn) Setup(ctx context.Context) error {
+	f.dec = beam.NewElementDecoder(f.Example.T)
+	return f.modelFn.Setup(ctx)
+}
+
+func (f *runInferenceFn) ProcessElement(ctx context.Context, _ int, values func(*stamp.Value) bool, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	var times []beam.EventTime
+	var examples []interface{}
+	var value stamp.Value
+	for values(&value) {
+		elm, err := stamp.Decode(f.dec, value)
+		if err != nil {
+			return err
+		}
+		times = append(times, value.EventTime())
+		examples = append(examples, elm)
+		if len(examples) < f.MaxBatchSize {
+			continue
+		}
+		if err := f.predict(ctx, times, examples, emit); err != nil {
+			return err
+		}
+		times, examples = nil, nil
+	}
+	if len(examples) == 0 {
+		return nil
+	}
+	return f.predict(ctx, times, examples, emit)
+}
@@ -0,0 +1,323 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: inference.shims.go
+
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*batchKeyFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*bundleInferenceFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*runInferenceFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*stamp.Value)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*batchKeyFn)(nil)).Elem(), wrapMakerBatchKeyFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*bundleInferenceFn)(nil)).Elem(), wrapMakerBundleInferenceFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*runInferenceFn)(nil)).Elem(), wrapMakerRunInferenceFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, func(mtime.Time, typex.X, typex.Y)) error)(nil)).Elem(), funcMakerContext۰ContextEmitETTypex۰XTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, int, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Y)) error)(nil)).Elem(), funcMakerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, mtime.Time, typex.X, func(mtime.Time, typex.X, typex.Y)) error)(nil)).Elem(), funcMakerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context) error)(nil)).Elem(), funcMakerContext۰ContextГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X) (int, typex.X))(nil)).Elem(), funcMakerTypex۰XГIntTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Y))(nil)).Elem(), emitMakerETTypex۰XTypex۰Y)
+	exec.RegisterInput(reflect.TypeOf((*func(*stamp.Value) bool)(nil)).Elem(), iterMakerStamp۰Value)
+}
+
+func wrapMakerBatchKeyFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*batchKeyFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X) (int, typex.X) { return dfn.ProcessElement(a0) }),
+		"StartBundle":    reflectx.MakeFunc(func() { dfn.StartBundle() }),
+	}
+}
+
+func wrapMakerBundleInferenceFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*bundleInferenceFn)
+	return map[string]reflectx.Func{
+		"FinishBundle": reflectx.MakeFunc(func(a0 context.Context, a1 func(mtime.Time, typex.X, typex.Y)) error { return dfn.FinishBundle(a0, a1) }),
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 mtime.Time, a2 typex.X, a3 func(mtime.Time, typex.X, typex.Y)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+		"Setup":       reflectx.MakeFunc(func(a0 context.Context) error { return dfn.Setup(a0) }),
+		"StartBundle": reflectx.MakeFunc(func() { dfn.StartBundle() }),
+	}
+}
+
+func wrapMakerRunInferenceFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*runInferenceFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 int, a2 func(*stamp.Value) bool, a3 func(mtime.Time, typex.X, typex.Y)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+		"Setup": reflectx.MakeFunc(func(a0 context.Context) error { return dfn.Setup(a0) }),
+	}
+}
+
+type callerContext۰ContextEmitETTypex۰XTypex۰YГError struct {
+	fn func(context.Context, func(mtime.Time, typex.X, typex.Y)) error
+}
+
+func funcMakerContext۰ContextEmitETTypex۰XTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, func(mtime.Time, typex.X, typex.Y)) error)
+	return &callerContext۰ContextEmitETTypex۰XTypex۰YГError{fn: f}
+}
+
+func (c *callerContext۰ContextEmitETTypex۰XTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextEmitETTypex۰XTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextEmitETTypex۰XTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(func(mtime.Time, typex.X, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextEmitETTypex۰XTypex۰YГError) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(func(mtime.Time, typex.X, typex.Y)))
+}
+
+type callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError struct {
+	fn func(context.Context, int, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Y)) error
+}
+
+func funcMakerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, int, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Y)) error)
+	return &callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError{fn: f}
+}
+
+func (c *callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(int), args[2].(func(*stamp.Value) bool), args[3].(func(mtime.Time, typex.X, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextIntIterStamp۰ValueEmitETTypex۰XTypex۰YГError) Call4x1(arg0, arg1, arg2, arg3 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(int), arg2.(func(*stamp.Value) bool), arg3.(func(mtime.Time, typex.X, typex.Y)))
+}
+
+type callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError struct {
+	fn func(context.Context, mtime.Time, typex.X, func(mtime.Time, typex.X, typex.Y)) error
+}
+
+func funcMakerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, mtime.Time, typex.X, func(mtime.Time, typex.X, typex.Y)) error)
+	return &callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError{fn: f}
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(mtime.Time), args[2].(typex.X), args[3].(func(mtime.Time, typex.X, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XEmitETTypex۰XTypex۰YГError) Call4x1(arg0, arg1, arg2, arg3 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(mtime.Time), arg2.(typex.X), arg3.(func(mtime.Time, typex.X, typex.Y)))
+}
+
+type callerContext۰ContextГError struct {
+	fn func(context.Context) error
+}
+
+func funcMakerContext۰ContextГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context) error)
+	return &callerContext۰ContextГError{fn: f}
+}
+
+func (c *callerContext۰ContextГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextГError) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(context.Context))
+}
+
+type callerTypex۰XГIntTypex۰X struct {
+	fn func(typex.X) (int, typex.X)
+}
+
+func funcMakerTypex۰XГIntTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X) (int, typex.X))
+	return &callerTypex۰XГIntTypex۰X{fn: f}
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerETTypex۰XTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeETTypex۰XTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeETTypex۰XTypex۰Y(t typex.EventTime, key typex.X, val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: t, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerStamp۰Value(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readStamp۰Value
+	return ret
+}
+
+func (v *iterNative) readStamp۰Value(value *stamp.Value) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(stamp.Value)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*scaleHandler)(nil)).Elem())
+	beam.RegisterFunction(formatKV)
+	beam.RegisterFunction(exampleTime)
+	beam.RegisterFunction(formatWindowedKV)
+}
+
+var loads int32
+
+// scaleHandler loads a model that multiplies examples by Factor.
+type scaleHandler struct {
+	Factor int `json:"factor"`
+}
+
+func (h scaleHandler) Load(ctx context.Context) (Model, error) {
+	atomic.AddInt32(&loads, 1)
+	return scaleModel(h.Factor), nil
+}
+
+type scaleModel int
+
+func (m scaleModel) Predict(ctx context.Context, examples []interface{}) ([]interface{}, error) {
+	var ret []interface{}
+	for _, x := range examples {
+		ret = append(ret, x.(int)*int(m))
+	}
+	return ret, nil
+}
+
+func formatKV(x, y int) string {
+	return fmt.Sprintf("%v:%v", x, y)
+}
+
+// exampleTime returns the timestamp of an example, which is its value in
+// minutes.
+func exampleTime(x int) beam.EventTime {
+	return mtime.FromMilliseconds(int64(x) * 60000)
+}
+
+func formatWindowedKV(w beam.Window, t beam.EventTime, x, y int) string {
+	return fmt.Sprintf("%v:%v@%v/%v", x, y, t.Milliseconds()/60000, w.MaxTimestamp()/3600000)
+}
+
+func TestRunInference(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, 1, 2, 3, 4, 5)
+	predictions := RunInference(s, scaleHandler{Factor: 10}, col, reflect.TypeOf(0), Options{MaxBatchSize: 2})
+	passert.Equals(s, beam.ParDo(s, formatKV, predictions), "1:10", "2:20", "3:30", "4:40", "5:50")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("model loaded %v times, want once", n)
+	}
+}
+
+// TestRunInferenceWindowed verifies that predictions keep the windows and
+// timestamps of their examples.
+func TestRunInferenceWindowed(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, 1, 2, 61, 62, 63)
+	stamped := beam.WithTimestamps(s, exampleTime, col)
+	hourly := beam.WindowInto(s, window.NewFixedWindows(time.Hour), stamped)
+	predictions := RunInference(s, scaleHandler{Factor: 10}, hourly, reflect.TypeOf(0), Options{MaxBatchSize: 2, Shards: 1})
+	passert.Equals(s, beam.ParDo(s, formatWindowedKV, predictions),
+		"1:10@1/0", "2:20@2/0", "61:610@61/1", "62:620@62/1", "63:630@63/1")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("RunInference failed: %v", err)
+	}
+}
+
+// TestRunInferenceGlobalBatches verifies that examples in the global window
+// are batched within bundles, without a GroupByKey.
+func TestRunInferenceGlobalBatches(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, 1, 2, 3)
+	RunInference(s, scaleHandler{Factor: 10}, col, reflect.TypeOf(0), Options{})
+
+	edges, _, err := p.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, edge := range edges {
+		if edge.Op == graph.CoGBK {
+			t.Errorf("RunInference in the global window has a GroupByKey: %v", edge)
+		}
+	}
+}
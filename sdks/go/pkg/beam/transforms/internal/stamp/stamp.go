@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stamp groups the values of a PCollection<KV<K,V>> by key with
+// their timestamps, which grouping would otherwise reset, for transforms
+// that buffer the values of a key and window.
+package stamp
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=stamp --identifiers=encodeFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Value)(nil)).Elem())
+}
+
+// Value is an encoded value and its timestamp.
+type Value struct {
+	// Timestamp is the timestamp in milliseconds since the epoch.
+	Timestamp int64
+	Data      []byte
+}
+
+// EventTime returns the timestamp of the value.
+func (v Value) EventTime() beam.EventTime {
+	return mtime.FromMilliseconds(v.Timestamp)
+}
+
+// GroupByKey groups the values of a PCollection<KV<K,V>> per key and
+// window. It returns a PCollection<KV<K,Value>> and the type of the values,
+// V, to decode them with.
+func GroupByKey(s beam.Scope, col beam.PCollection) (beam.PCollection, reflect.Type) {
+	_, v := beam.ValidateKVType(col)
+	stamps := beam.ParDo(s, &encodeFn{Value: beam.EncodedType{T: v.Type()}}, col)
+	return beam.GroupByKey(s, stamps), v.Type()
+}
+
+// Sorted returns the grouped values in order of their timestamps and, for
+// equal timestamps, their encodings, so the order is deterministic.
+func Sorted(values func(*Value) bool) []Value {
+	var all []Value
+	var value Value
+	for values(&value) {
+		all = append(all, value)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Timestamp != all[j].Timestamp {
+			return all[i].Timestamp < all[j].Timestamp
+		}
+		return bytes.Compare(all[i].Data, all[j].Data) < 0
+	})
+	return all
+}
+
+// Decode decodes the value with the decoder of its type.
+func Decode(dec beam.ElementDecoder, v Value) (interface{}, error) {
+	return dec.Decode(bytes.NewReader(v.Data))
+}
+
+// encodeFn encodes values with their timestamps.
+type encodeFn struct {
+	Value beam.EncodedType `json:"value"`
+
+	enc beam.ElementEncoder
+}
+
+func (f *encodeFn) Setup() {
+	f.enc = beam.NewElementEncoder(f.Value.T)
+}
+
+func (f *encodeFn) ProcessElement(t beam.EventTime, key beam.X, value beam.Y) (beam.X, Value, error) {
+	var buf bytes.Buffer
+	if err := f.enc.Encode(value, &buf); err != nil {
+		return nil, Value{}, err
+	}
+	return key, Value{Timestamp: t.Milliseconds(), Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: stamp.shims.go
+
+package stamp
+
+import (
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*Value)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*encodeFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*encodeFn)(nil)).Elem(), wrapMakerEncodeFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Y) (typex.X, Value, error))(nil)).Elem(), funcMakerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+}
+
+func wrapMakerEncodeFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*encodeFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 mtime.Time, a1 typex.X, a2 typex.Y) (typex.X, Value, error) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError struct {
+	fn func(mtime.Time, typex.X, typex.Y) (typex.X, Value, error)
+}
+
+func funcMakerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError(fn interface{}) reflectx.Func {
+	f := fn.(func(mtime.Time, typex.X, typex.Y) (typex.X, Value, error))
+	return &callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError{fn: f}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(mtime.Time), args[1].(typex.X), args[2].(typex.Y))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerMtime۰TimeTypex۰XTypex۰YГTypex۰XValueError) Call3x3(arg0, arg1, arg2 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(mtime.Time), arg1.(typex.X), arg2.(typex.Y))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stepfn contains the helpers of the transforms that wrap a step of
+// a pipeline around a user function and collect side records of it, such
+// as the failures of the deadletter package and the records of the lineage
+// package.
+package stepfn
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=stepfn --identifiers=emptyFn,encodeFn
+//go:generate go fmt
+
+// Check panics if fn is not of the form A -> B or A -> (B, error), where
+// elements of type in are assignable to A. If withContext, fn may take a
+// context.Context first. It returns B and whether fn takes a context.
+func Check(fn interface{}, in reflect.Type, withContext bool) (reflect.Type, bool) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		panic(fmt.Sprintf("%v is not a function", t))
+	}
+	ctx := withContext && t.NumIn() == 2 && t.In(0) == reflectx.Context
+	if t.NumIn() != 1 && !ctx || t.NumOut() < 1 || t.NumOut() > 2 ||
+		t.NumOut() == 2 && t.Out(1) != reflectx.Error || t.Out(0) == reflectx.Error {
+		panic(fmt.Sprintf("%v is not of the form A -> B or A -> (B, error)", t))
+	}
+	if !in.AssignableTo(t.In(t.NumIn() - 1)) {
+		panic(fmt.Sprintf("%v cannot be applied to PCollection<%v>", t, in))
+	}
+	return t.Out(0), ctx
+}
+
+// Call calls fn, which is checked by Check, and returns its output and
+// error.
+func Call(fn reflectx.Func, args ...interface{}) (interface{}, error) {
+	ret := fn.Call(args)
+	if len(ret) == 2 && ret[1] != nil {
+		return nil, ret[1].(error)
+	}
+	return ret[0], nil
+}
+
+// Empty returns an empty PCollection<T>.
+func Empty(s beam.Scope, t reflect.Type) beam.PCollection {
+	return beam.ParDo(s, emptyFn, beam.Impulse(s), beam.TypeDefinition{Var: beam.TType, T: t})
+}
+
+// WriteJSON writes the elements of the PCollection as JSON with the sink,
+// which is given a PCollection<string>.
+func WriteJSON(s beam.Scope, col beam.PCollection, sink func(beam.Scope, beam.PCollection)) {
+	sink(s, beam.ParDo(s, encodeFn, col))
+}
+
+// Format returns the element encoded as JSON if possible and otherwise
+// formatted with fmt.Sprint.
+func Format(elm interface{}) string {
+	if data, err := json.Marshal(elm); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(elm)
+}
+
+// Truncate truncates the string to at most n bytes without splitting UTF-8
+// sequences.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func emptyFn(_ []byte, _ func(beam.T)) {}
+
+func encodeFn(elm beam.T) (string, error) {
+	data, err := json.Marshal(elm)
+	return string(data), err
+}
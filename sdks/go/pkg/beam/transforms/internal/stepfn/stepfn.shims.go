@@ -0,0 +1,126 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: stepfn.shims.go
+
+package stepfn
+
+import (
+	"context"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(emptyFn)
+	runtime.RegisterFunction(encodeFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(typex.T)))(nil)).Elem(), funcMakerSliceOfByteEmitTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) (string, error))(nil)).Elem(), funcMakerTypex۰TГStringError)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.T))(nil)).Elem(), emitMakerTypex۰T)
+}
+
+type callerSliceOfByteEmitTypex۰TГ struct {
+	fn func([]byte, func(typex.T))
+}
+
+func funcMakerSliceOfByteEmitTypex۰TГ(fn interface{}) reflectx.Func {
+	f := fn.(func([]byte, func(typex.T)))
+	return &callerSliceOfByteEmitTypex۰TГ{fn: f}
+}
+
+func (c *callerSliceOfByteEmitTypex۰TГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfByteEmitTypex۰TГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfByteEmitTypex۰TГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].([]byte), args[1].(func(typex.T)))
+	return []interface{}{}
+}
+
+func (c *callerSliceOfByteEmitTypex۰TГ) Call2x0(arg0, arg1 interface{}) {
+	c.fn(arg0.([]byte), arg1.(func(typex.T)))
+}
+
+type callerTypex۰TГStringError struct {
+	fn func(typex.T) (string, error)
+}
+
+func funcMakerTypex۰TГStringError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.T) (string, error))
+	return &callerTypex۰TГStringError{fn: f}
+}
+
+func (c *callerTypex۰TГStringError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰TГStringError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰TГStringError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.T))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰TГStringError) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.T))
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerTypex۰T(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰T
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰T(val typex.T) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,100 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stepfn
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type record struct {
+	Name string `json:"name"`
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		fn          interface{}
+		withContext bool
+		out         reflect.Type
+		ctx         bool
+		err         string
+	}{
+		{fn: strings.ToUpper, out: reflectx.String},
+		{fn: func(string) (int, error) { return 0, nil }, out: reflectx.Int},
+		{fn: func(context.Context, string) int { return 0 }, withContext: true, out: reflectx.Int, ctx: true},
+		{fn: func(context.Context, string) int { return 0 }, err: "is not of the form"},
+		{fn: func(string) error { return nil }, err: "is not of the form"},
+		{fn: func(int) int { return 0 }, err: "cannot be applied"},
+		{fn: "fn", err: "is not a function"},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				r := recover()
+				if test.err == "" && r != nil {
+					t.Errorf("Check(%T) panicked: %v", test.fn, r)
+				}
+				if test.err != "" && (r == nil || !strings.Contains(r.(string), test.err)) {
+					t.Errorf("Check(%T) panicked with %v, want %q", test.fn, r, test.err)
+				}
+			}()
+			out, ctx := Check(test.fn, reflectx.String, test.withContext)
+			if out != test.out || ctx != test.ctx {
+				t.Errorf("Check(%T) = (%v, %v), want (%v, %v)", test.fn, out, ctx, test.out, test.ctx)
+			}
+		}()
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	empty := Empty(s, reflect.TypeOf(record{}))
+	if got := empty.Type().Type(); got != reflect.TypeOf(record{}) {
+		t.Errorf("Empty() = PCollection<%v>, want PCollection<record>", got)
+	}
+	passert.Empty(s, empty)
+	WriteJSON(s, beam.Create(s, record{Name: "a"}), func(s beam.Scope, col beam.PCollection) {
+		passert.Equals(s, col, `{"name":"a"}`)
+	})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s   string
+		n   int
+		exp string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"aé", 2, "a"},
+	}
+	for _, test := range tests {
+		if got := Truncate(test.s, test.n); got != test.exp {
+			t.Errorf("Truncate(%q, %v) = %q, want %q", test.s, test.n, got, test.exp)
+		}
+	}
+}
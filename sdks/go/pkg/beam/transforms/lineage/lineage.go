@@ -0,0 +1,247 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lineage records the lineage of a sample of elements, which is the
+// steps that touched each element and what it looked like after each step,
+// to debug wrong outputs. For example:
+//
+//    l := lineage.New(lineage.Options{SampleRate: 0.001})
+//    orders := l.Sample(s, "Read", lines)
+//    parsed := l.ParDo(s, "ParseOrder", parseOrder, orders)
+//    priced := l.ParDo(s, "Price", price, parsed)
+//    ...
+//    totals := lineage.Values(s, priced)
+//    l.Write(s, func(s beam.Scope, col beam.PCollection) {
+//        textio.Write(s, "gs://bucket/lineage.json", col)
+//    })
+//
+// writes the records of 0.1% of the lines as JSON lines. Lineage is opt-in:
+// with a zero sample rate, no elements are sampled and only the empty keys
+// of the elements are added.
+package lineage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stepfn"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=lineage --identifiers=sampleFn,parDoFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Record)(nil)).Elem())
+}
+
+// Record is an element of the lineage of a sampled element after a step.
+type Record struct {
+	// ID identifies the sampled element and the elements derived from it.
+	ID string `json:"id"`
+	// Hop is the number of steps the element went through, starting at 0
+	// for the step in which it was sampled.
+	Hop int `json:"hop"`
+	// Step is the name of the step.
+	Step string `json:"step"`
+	// Element is the output of the step, encoded as JSON if possible and
+	// otherwise formatted with fmt.Sprint, and truncated to the maximum
+	// element size.
+	Element string `json:"element"`
+	// Time is the processing time of the element, in RFC 3339 format.
+	Time string `json:"time"`
+}
+
+// Options configure a Lineage.
+type Options struct {
+	// SampleRate is the fraction of elements that their lineage is recorded
+	// for. If zero, no elements are sampled.
+	SampleRate float64
+	// MaxElementSize is the maximum size of the elements of records, in
+	// bytes. If zero, 4096 is used.
+	MaxElementSize int
+}
+
+// Lineage records the lineage of the elements sampled by Sample through the
+// steps applied with ParDo.
+type Lineage struct {
+	opts    Options
+	steps   map[string]bool
+	records []beam.PCollection
+}
+
+// New returns a new Lineage.
+func New(opts Options) *Lineage {
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		panic(fmt.Sprintf("invalid sample rate: %v", opts.SampleRate))
+	}
+	if opts.MaxElementSize == 0 {
+		opts.MaxElementSize = 4096
+	}
+	return &Lineage{opts: opts, steps: make(map[string]bool)}
+}
+
+func (l *Lineage) addStep(step string) {
+	if l.steps[step] {
+		panic(fmt.Sprintf("duplicate step %v", step))
+	}
+	l.steps[step] = true
+}
+
+// Sample samples the elements of the PCollection<V> as the named step. It
+// returns a PCollection<KV<string,V>> of the elements keyed by their
+// lineage, which is empty for elements that are not sampled.
+func (l *Lineage) Sample(s beam.Scope, step string, col beam.PCollection) beam.PCollection {
+	l.addStep(step)
+	s = s.Scope(step)
+
+	out, records := beam.ParDo2(s, &sampleFn{
+		Step:           step,
+		SampleRate:     l.opts.SampleRate,
+		MaxElementSize: l.opts.MaxElementSize,
+	}, col)
+	l.records = append(l.records, records)
+	return out
+}
+
+// ParDo applies fn to the values of the PCollection<KV<string,A>> returned
+// by Sample or ParDo as the named step, where fn is of the form A -> B or
+// A -> (B, error). It returns the PCollection<KV<string,B>> of the outputs,
+// which keep the lineage of their inputs. The outputs of sampled elements
+// are recorded.
+func (l *Lineage) ParDo(s beam.Scope, step string, fn interface{}, col beam.PCollection) beam.PCollection {
+	l.addStep(step)
+	s = s.Scope(step)
+
+	if !typex.IsKV(col.Type()) || col.Type().Components()[0].Type() != reflectx.String {
+		panic(fmt.Sprintf("%v is not a sampled PCollection<KV<string,A>>", col.Type()))
+	}
+	t, _ := stepfn.Check(fn, col.Type().Components()[1].Type(), false)
+
+	out, records := beam.ParDo2(s, &parDoFn{
+		Step:           step,
+		Fn:             beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)},
+		MaxElementSize: l.opts.MaxElementSize,
+	}, col, beam.TypeDefinition{Var: beam.YType, T: t})
+	l.records = append(l.records, records)
+	return out
+}
+
+// Values returns the PCollection<V> of the values of the sampled
+// PCollection<KV<string,V>>.
+func Values(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("lineage.Values")
+	return beam.DropKey(s, col)
+}
+
+// Records returns the PCollection<Record> of the records of all steps
+// added so far.
+func (l *Lineage) Records(s beam.Scope) beam.PCollection {
+	s = s.Scope("lineage.Records")
+
+	if len(l.records) == 0 {
+		return stepfn.Empty(s, reflect.TypeOf(Record{}))
+	}
+	return beam.Flatten(s, l.records...)
+}
+
+// Write writes the records of all steps added so far as JSON with the sink,
+// which is given a PCollection<string>.
+func (l *Lineage) Write(s beam.Scope, sink func(beam.Scope, beam.PCollection)) {
+	records := l.Records(s)
+	s = s.Scope("lineage.Write")
+	stepfn.WriteJSON(s, records, sink)
+}
+
+// The key of a sampled element is its ID and hop, as "<id>:<hop>".
+
+func key(id string, hop int) string {
+	return id + ":" + strconv.Itoa(hop)
+}
+
+func parseKey(k string) (string, int) {
+	i := strings.LastIndex(k, ":")
+	if i < 0 {
+		return "", 0
+	}
+	hop, _ := strconv.Atoi(k[i+1:])
+	return k[:i], hop
+}
+
+// sampleFn samples elements.
+type sampleFn struct {
+	Step           string  `json:"step"`
+	SampleRate     float64 `json:"sample_rate,omitempty"`
+	MaxElementSize int     `json:"max_element_size"`
+}
+
+func (f *sampleFn) ProcessElement(elm beam.X, emit func(string, beam.X), record func(Record)) {
+	if f.SampleRate == 0 || mrand.Float64() >= f.SampleRate {
+		emit("", elm)
+		return
+	}
+	var id [8]byte
+	rand.Read(id[:])
+	r := newRecord(hex.EncodeToString(id[:]), 0, f.Step, elm, f.MaxElementSize)
+	record(r)
+	emit(key(r.ID, 0), elm)
+}
+
+// parDoFn applies Fn and records the outputs of sampled elements.
+type parDoFn struct {
+	Step           string           `json:"step"`
+	Fn             beam.EncodedFunc `json:"fn"`
+	MaxElementSize int              `json:"max_element_size"`
+
+	fn reflectx.Func
+}
+
+func (f *parDoFn) Setup() {
+	f.fn = f.Fn.Fn
+}
+
+func (f *parDoFn) ProcessElement(k string, elm beam.X, emit func(string, beam.Y), record func(Record)) error {
+	out, err := stepfn.Call(f.fn, elm)
+	if err != nil {
+		return err
+	}
+	if k == "" {
+		emit("", out)
+		return nil
+	}
+	id, hop := parseKey(k)
+	record(newRecord(id, hop+1, f.Step, out, f.MaxElementSize))
+	emit(key(id, hop+1), out)
+	return nil
+}
+
+func newRecord(id string, hop int, step string, elm interface{}, max int) Record {
+	return Record{
+		ID:      id,
+		Hop:     hop,
+		Step:    step,
+		Element: stepfn.Truncate(stepfn.Format(elm), max),
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,201 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: lineage.shims.go
+
+package lineage
+
+import (
+	"context"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*Record)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*parDoFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*sampleFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parDoFn)(nil)).Elem(), wrapMakerParDoFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*sampleFn)(nil)).Elem(), wrapMakerSampleFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string, typex.X, func(string, typex.Y), func(Record)) error)(nil)).Elem(), funcMakerStringTypex۰XEmitStringTypex۰YEmitRecordГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, func(string, typex.X), func(Record)))(nil)).Elem(), funcMakerTypex۰XEmitStringTypex۰XEmitRecordГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(Record))(nil)).Elem(), emitMakerRecord)
+	exec.RegisterEmitter(reflect.TypeOf((*func(string, typex.X))(nil)).Elem(), emitMakerStringTypex۰X)
+	exec.RegisterEmitter(reflect.TypeOf((*func(string, typex.Y))(nil)).Elem(), emitMakerStringTypex۰Y)
+}
+
+func wrapMakerParDoFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parDoFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 string, a1 typex.X, a2 func(string, typex.Y), a3 func(Record)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerSampleFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*sampleFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 func(string, typex.X), a2 func(Record)) { dfn.ProcessElement(a0, a1, a2) }),
+	}
+}
+
+type callerStringTypex۰XEmitStringTypex۰YEmitRecordГError struct {
+	fn func(string, typex.X, func(string, typex.Y), func(Record)) error
+}
+
+func funcMakerStringTypex۰XEmitStringTypex۰YEmitRecordГError(fn interface{}) reflectx.Func {
+	f := fn.(func(string, typex.X, func(string, typex.Y), func(Record)) error)
+	return &callerStringTypex۰XEmitStringTypex۰YEmitRecordГError{fn: f}
+}
+
+func (c *callerStringTypex۰XEmitStringTypex۰YEmitRecordГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringTypex۰XEmitStringTypex۰YEmitRecordГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringTypex۰XEmitStringTypex۰YEmitRecordГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string), args[1].(typex.X), args[2].(func(string, typex.Y)), args[3].(func(Record)))
+	return []interface{}{out0}
+}
+
+func (c *callerStringTypex۰XEmitStringTypex۰YEmitRecordГError) Call4x1(arg0, arg1, arg2, arg3 interface{}) interface{} {
+	return c.fn(arg0.(string), arg1.(typex.X), arg2.(func(string, typex.Y)), arg3.(func(Record)))
+}
+
+type callerTypex۰XEmitStringTypex۰XEmitRecordГ struct {
+	fn func(typex.X, func(string, typex.X), func(Record))
+}
+
+func funcMakerTypex۰XEmitStringTypex۰XEmitRecordГ(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, func(string, typex.X), func(Record)))
+	return &callerTypex۰XEmitStringTypex۰XEmitRecordГ{fn: f}
+}
+
+func (c *callerTypex۰XEmitStringTypex۰XEmitRecordГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XEmitStringTypex۰XEmitRecordГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XEmitStringTypex۰XEmitRecordГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(typex.X), args[1].(func(string, typex.X)), args[2].(func(Record)))
+	return []interface{}{}
+}
+
+func (c *callerTypex۰XEmitStringTypex۰XEmitRecordГ) Call3x0(arg0, arg1, arg2 interface{}) {
+	c.fn(arg0.(typex.X), arg1.(func(string, typex.X)), arg2.(func(Record)))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerRecord(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeRecord
+	return ret
+}
+
+func (e *emitNative) invokeRecord(val Record) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerStringTypex۰X(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeStringTypex۰X
+	return ret
+}
+
+func (e *emitNative) invokeStringTypex۰X(key string, val typex.X) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerStringTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeStringTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeStringTypex۰Y(key string, val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,71 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lineage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(double)
+	beam.RegisterFunction(format)
+	beam.RegisterFunction(formatRecord)
+}
+
+func double(x int) int {
+	return 2 * x
+}
+
+func format(x int) string {
+	return fmt.Sprintf("x%v", x)
+}
+
+func formatRecord(r Record) string {
+	return fmt.Sprintf("%v:%v:%v", r.Hop, r.Step, r.Element)
+}
+
+func TestLineage(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	l := New(Options{SampleRate: 1, MaxElementSize: 3})
+	sampled := l.Sample(s, "Create", beam.Create(s, 1, 2))
+	doubled := l.ParDo(s, "Double", double, sampled)
+	formatted := l.ParDo(s, "Format", format, doubled)
+	passert.Equals(s, Values(s, formatted), "x2", "x4")
+	passert.Equals(s, beam.ParDo(s, formatRecord, l.Records(s)),
+		"0:Create:1", "0:Create:2", "1:Double:2", "1:Double:4", `2:Format:"x2`, `2:Format:"x4`)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestLineageDisabled(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	l := New(Options{})
+	sampled := l.Sample(s, "Create", beam.Create(s, 1, 2))
+	doubled := l.ParDo(s, "Double", double, sampled)
+	passert.Equals(s, Values(s, doubled), 2, 4)
+	passert.Empty(s, l.Records(s))
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
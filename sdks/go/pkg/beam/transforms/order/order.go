@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package order contains a transform that delivers the values of each key
+// in event-time order, for consumers that require in-order processing per
+// entity, such as applying account updates.
+package order
+
+import (
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=order --identifiers=releaseFn
+//go:generate go fmt
+
+// PerKey returns the elements of the PCollection<KV<K,V>>, with the values
+// of each key and window in order of their event time. Values with equal
+// timestamps are ordered by their encoding. The elements keep their
+// timestamps. For example:
+//
+//    windowed := beam.WindowInto(s, window.NewFixedWindows(time.Minute), updates)
+//    beam.ParDo0(s, &applyFn{}, order.PerKey(s, windowed))
+//
+// applies the updates of each account in order within each minute.
+//
+// The values of a key are buffered until the watermark passes the end of
+// their window, so the window bounds the delay and all values of a key and
+// window must fit in memory. Elements later than the end of their window
+// are dropped by the runner. The order holds for the DoFns applied directly
+// to the output, but not after another grouping.
+func PerKey(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("order.PerKey")
+
+	grouped, v := stamp.GroupByKey(s, col)
+	return beam.ParDo(s, &releaseFn{Value: beam.EncodedType{T: v}}, grouped,
+		beam.TypeDefinition{Var: beam.YType, T: v})
+}
+
+// releaseFn emits the values of a key in order of their timestamps.
+type releaseFn struct {
+	Value beam.EncodedType `json:"value"`
+
+	dec beam.ElementDecoder
+}
+
+func (f *releaseFn) Setup() {
+	f.dec = beam.NewElementDecoder(f.Value.T)
+}
+
+func (f *releaseFn) ProcessElement(key beam.X, values func(*stamp.Value) bool, emit func(beam.EventTime, beam.X, beam.Y)) error {
+	for _, e := range stamp.Sorted(values) {
+		v, err := stamp.Decode(f.dec, e)
+		if err != nil {
+			return err
+		}
+		emit(e.EventTime(), key, v)
+	}
+	return nil
+}
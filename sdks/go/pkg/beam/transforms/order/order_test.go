@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package order
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(updateTime)
+	beam.RegisterFunction(keyUpdate)
+	beam.RegisterType(reflect.TypeOf((*checkFn)(nil)).Elem())
+	beam.RegisterFunction(formatFn)
+}
+
+// updateTime returns the timestamp of an "<account>:<seconds>:<value>"
+// update.
+func updateTime(update string) beam.EventTime {
+	sec, _ := strconv.Atoi(strings.Split(update, ":")[1])
+	return mtime.FromMilliseconds(int64(sec) * 1000)
+}
+
+func keyUpdate(update string) (string, string) {
+	return strings.Split(update, ":")[0], update
+}
+
+// checkFn outputs the updates that it receives before an update of the
+// same account and window with a later timestamp.
+type checkFn struct {
+	last map[string]string
+}
+
+func (f *checkFn) StartBundle(_ func(string)) {
+	f.last = make(map[string]string)
+}
+
+func (f *checkFn) ProcessElement(w beam.Window, account, update string, emit func(string)) {
+	key := fmt.Sprintf("%v/%v", w, account)
+	if prev, ok := f.last[key]; ok && prev > update {
+		emit(prev + " before " + update)
+	}
+	f.last[key] = update
+}
+
+func formatFn(t beam.EventTime, account, update string) string {
+	return fmt.Sprintf("%v@%v", update, t.Milliseconds()/1000)
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+func TestPerKey(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	updates := beam.Create(s, "a:3:z", "b:2:y", "a:1:x", "a:2:y", "b:1:x", "a:2:w", "a:70:late")
+	stamped := beam.WithTimestamps(s, updateTime, updates)
+	windowed := beam.WindowInto(s, window.NewFixedWindows(time.Minute), stamped)
+	ordered := PerKey(s, beam.ParDo(s, keyUpdate, windowed))
+
+	passert.Equals(s, beam.ParDo(s, formatFn, ordered),
+		"a:1:x@1", "a:2:w@2", "a:2:y@2", "a:3:z@3", "a:70:late@70", "b:1:x@1", "b:2:y@2")
+
+	passert.Empty(s, beam.ParDo(s, &checkFn{}, ordered))
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oversize contains a transform that enforces a limit on the encoded
+// size of elements, so that a single huge record is handled by a policy
+// rather than failing the bundles that try to shuffle or write it. For
+// example:
+//
+//    dl := deadletter.NewCollector(deadletter.Options{})
+//    small := oversize.Limit(s, "LimitRecords", records, oversize.Options{
+//        MaxBytes:   10 << 20,
+//        Policy:     oversize.DeadLetter,
+//        DeadLetter: dl,
+//    })
+//
+// routes the records whose encoding exceeds 10MB to the dead-letter
+// collection.
+//
+// Limit checks the elements where it is applied. Enforce also limits the
+// elements at the coder boundaries of the pipeline, where the workers encode
+// them for the runner, such as for shuffles, so that elements that are
+// enlarged after Limit are dropped instead of failing their bundles:
+//
+//    oversize.Enforce(20 << 20)
+//
+// drops the elements whose windowed encoding exceeds 20MB at any boundary.
+package oversize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/deadletter"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stepfn"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=oversize --identifiers=limitFn
+//go:generate go fmt
+
+// Policy is the handling of oversized elements.
+type Policy int
+
+const (
+	// Drop drops oversized elements. They are counted by the counter
+	// "<step>.oversized" in the "oversize" namespace.
+	Drop Policy = iota
+	// DeadLetter adds oversized elements, truncated to the limit, to the
+	// failures of a dead-letter collector.
+	DeadLetter
+	// Split splits oversized elements with a user function. The pieces
+	// that are still oversized are added to the failures of a dead-letter
+	// collector, as for DeadLetter.
+	Split
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Drop:
+		return "Drop"
+	case DeadLetter:
+		return "DeadLetter"
+	case Split:
+		return "Split"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+// Options configure Limit.
+type Options struct {
+	// MaxBytes is the maximum size of the encoding of an element with the
+	// coder of the PCollection. It is required.
+	MaxBytes int
+	// Policy is the handling of elements larger than MaxBytes.
+	Policy Policy
+	// DeadLetter is the collector of the oversized elements for the
+	// DeadLetter policy, and of the oversized pieces for the Split policy.
+	DeadLetter *deadletter.Collector
+	// SplitFn is the function of the form A -> []A that splits oversized
+	// elements for the Split policy, such as by chunking a list field.
+	SplitFn interface{}
+}
+
+// Limit returns the elements of the PCollection<A> whose encoding is at
+// most opts.MaxBytes, as the named step. Larger elements are handled by
+// opts.Policy. The elements are counted by the counters "<step>.elements"
+// and "<step>.oversized" and split elements by "<step>.split", in the
+// "oversize" namespace.
+func Limit(s beam.Scope, step string, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope(step)
+
+	if opts.MaxBytes <= 0 {
+		panic(fmt.Sprintf("invalid maximum size: %v", opts.MaxBytes))
+	}
+	t := col.Type().Type()
+	fn := &limitFn{
+		Step:     step,
+		Type:     beam.EncodedType{T: t},
+		MaxBytes: opts.MaxBytes,
+		Policy:   opts.Policy,
+	}
+
+	switch opts.Policy {
+	case Drop:
+	case DeadLetter:
+		if opts.DeadLetter == nil {
+			panic("no dead-letter collector for the DeadLetter policy")
+		}
+	case Split:
+		if opts.DeadLetter == nil {
+			panic("no dead-letter collector for the Split policy")
+		}
+		ft := reflect.TypeOf(opts.SplitFn)
+		if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 ||
+			!t.AssignableTo(ft.In(0)) || ft.Out(0) != reflect.SliceOf(t) {
+			panic(fmt.Sprintf("split function %v is not of the form %v -> []%v", ft, t, t))
+		}
+		fn.SplitFn = &beam.EncodedFunc{Fn: reflectx.MakeFunc(opts.SplitFn)}
+	default:
+		panic(fmt.Sprintf("invalid policy: %v", opts.Policy))
+	}
+
+	out, failures := beam.ParDo2(s, fn, col)
+	if opts.Policy != Drop {
+		opts.DeadLetter.Add(step, failures)
+	}
+	return out
+}
+
+// Enforce makes the workers drop the elements whose windowed encoding
+// exceeds maxBytes at the coder boundaries of the pipeline, where elements
+// are encoded to be sent to the runner. The dropped elements are counted by
+// the counter "oversized" in the "oversize" namespace and logged. Only the
+// Drop policy is available there, since the boundaries have no outputs for
+// other policies: use Limit before to handle the elements by a policy. It
+// must be called before the pipeline is run.
+func Enforce(maxBytes int) {
+	if maxBytes <= 0 {
+		panic(fmt.Sprintf("invalid maximum size: %v", maxBytes))
+	}
+	runtime.GlobalOptions.Set(exec.MaxElementBytesOption, strconv.Itoa(maxBytes))
+}
+
+// limitFn outputs the elements within the limit and handles the others by
+// the policy.
+type limitFn struct {
+	Step     string            `json:"step"`
+	Type     beam.EncodedType  `json:"type"`
+	MaxBytes int               `json:"max_bytes"`
+	Policy   Policy            `json:"policy"`
+	SplitFn  *beam.EncodedFunc `json:"split_fn,omitempty"`
+
+	enc       beam.ElementEncoder
+	split     reflectx.Func1x1
+	elements  beam.Counter
+	oversized beam.Counter
+	splits    beam.Counter
+}
+
+func (f *limitFn) Setup() {
+	f.enc = beam.NewElementEncoder(f.Type.T)
+	if f.SplitFn != nil {
+		f.split = reflectx.ToFunc1x1(f.SplitFn.Fn)
+	}
+	f.elements = beam.NewCounter("oversize", f.Step+".elements")
+	f.oversized = beam.NewCounter("oversize", f.Step+".oversized")
+	f.splits = beam.NewCounter("oversize", f.Step+".split")
+}
+
+func (f *limitFn) ProcessElement(ctx context.Context, elm beam.X, emit func(beam.X), fail func(deadletter.Failure)) error {
+	f.elements.Inc(ctx, 1)
+	size, err := f.size(elm)
+	if err != nil {
+		return err
+	}
+	if size <= f.MaxBytes {
+		emit(elm)
+		return nil
+	}
+	f.oversized.Inc(ctx, 1)
+
+	switch f.Policy {
+	case DeadLetter:
+		fail(f.failure(elm, size, "element"))
+	case Split:
+		f.splits.Inc(ctx, 1)
+		pieces := reflect.ValueOf(f.split.Call1x1(elm))
+		for i := 0; i < pieces.Len(); i++ {
+			piece := pieces.Index(i).Interface()
+			size, err := f.size(piece)
+			if err != nil {
+				return err
+			}
+			if size > f.MaxBytes {
+				f.oversized.Inc(ctx, 1)
+				fail(f.failure(piece, size, "split piece"))
+				continue
+			}
+			emit(piece)
+		}
+	}
+	return nil
+}
+
+// failure returns the failure of an oversized element or piece, truncated to
+// the limit.
+func (f *limitFn) failure(elm interface{}, size int, what string) deadletter.Failure {
+	return deadletter.Failure{
+		Step:    f.Step,
+		Element: stepfn.Truncate(stepfn.Format(elm), f.MaxBytes),
+		Error:   fmt.Sprintf("%v of %v bytes exceeds the limit of %v bytes", what, size, f.MaxBytes),
+	}
+}
+
+func (f *limitFn) size(elm interface{}) (int, error) {
+	var buf bytes.Buffer
+	if err := f.enc.Encode(elm, &buf); err != nil {
+		return 0, err
+	}
+	return buf.Len(), nil
+}
@@ -0,0 +1,95 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oversize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/deadletter"
+)
+
+func init() {
+	beam.RegisterFunction(splitWords)
+	beam.RegisterFunction(failureError)
+}
+
+func splitWords(s string) []string {
+	return strings.Fields(s)
+}
+
+func failureError(f deadletter.Failure) string {
+	return f.Step + ": " + f.Error + ": " + f.Element
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+// The strings are encoded with a one byte length prefix.
+
+func TestLimitDrop(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "a", "bb", "ccccccccc", "dd")
+	out := Limit(s, "Limit", col, Options{MaxBytes: 5})
+	passert.Equals(s, out, "a", "bb", "dd")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestLimitDeadLetter(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	dl := deadletter.NewCollector(deadletter.Options{})
+	col := beam.Create(s, "a", "ccccccccc")
+	out := Limit(s, "Limit", col, Options{MaxBytes: 5, Policy: DeadLetter, DeadLetter: dl})
+	passert.Equals(s, out, "a")
+	failures := beam.ParDo(s, failureError, dl.Failures(s))
+	passert.Equals(s, failures, `Limit: element of 10 bytes exceeds the limit of 5 bytes: "cccc`)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestLimitSplit(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	dl := deadletter.NewCollector(deadletter.Options{})
+	col := beam.Create(s, "a b", "ccc dd eeeeeeeeee")
+	out := Limit(s, "Limit", col, Options{MaxBytes: 5, Policy: Split, SplitFn: splitWords, DeadLetter: dl})
+	passert.Equals(s, out, "a b", "ccc", "dd")
+	failures := beam.ParDo(s, failureError, dl.Failures(s))
+	passert.Equals(s, failures, `Limit: split piece of 11 bytes exceeds the limit of 5 bytes: "eeee`)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	defer runtime.GlobalOptions.Set(exec.MaxElementBytesOption, "")
+
+	Enforce(1 << 20)
+	if got := runtime.GlobalOptions.Get(exec.MaxElementBytesOption); got != "1048576" {
+		t.Errorf("option %v = %q, want 1048576", exec.MaxElementBytesOption, got)
+	}
+}
@@ -0,0 +1,377 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quality contains a transform that validates elements against
+// declarative data-quality rules. For example:
+//
+//    res := quality.Validate(s, orders, []quality.Rule{
+//        quality.NotNull("Customer"),
+//        quality.Range("Amount", 0, 10000),
+//        quality.Matches("Email", `^[^@]+@[^@]+$`).As("valid_email"),
+//        quality.InReference("Country", "countries"),
+//    }, quality.Options{References: map[string]beam.PCollection{"countries": countries}})
+//
+// validates each order, where countries is a PCollection<string> of the
+// known country codes. The valid orders are in res.Passed and the others,
+// with the rules they violate, in res.Failed.
+package quality
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/stats"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=quality --identifiers=validateFn,tagFn,emptyFn,ruleNamesFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Violation)(nil)).Elem())
+}
+
+// Kind is the kind of check of a rule.
+type Kind string
+
+const (
+	// KindNotNull checks that the field is not a nil pointer, slice, map
+	// or interface, nor an empty string.
+	KindNotNull Kind = "not_null"
+	// KindRange checks that a numeric field is within [Min, Max].
+	KindRange Kind = "range"
+	// KindRegex checks that a string field matches Pattern.
+	KindRegex Kind = "regex"
+	// KindReference checks that the field, formatted with fmt.Sprint, is a
+	// value of the Reference side input.
+	KindReference Kind = "reference"
+)
+
+// Rule is a data-quality rule. Checks other than KindNotNull pass for null
+// fields, so that optional fields can be validated if present.
+type Rule struct {
+	// Name identifies the rule in violations and metrics. If empty, it is
+	// "<kind>(<field>)".
+	Name string `json:"name"`
+	// Field is the field checked, where nested fields are given as "A.B".
+	// If empty, the element itself is checked.
+	Field string `json:"field,omitempty"`
+	Kind  Kind   `json:"kind"`
+
+	Min       float64 `json:"min,omitempty"`
+	Max       float64 `json:"max,omitempty"`
+	Pattern   string  `json:"pattern,omitempty"`
+	Reference string  `json:"reference,omitempty"`
+}
+
+// NotNull returns a rule that checks that the field is not null.
+func NotNull(field string) Rule {
+	return Rule{Field: field, Kind: KindNotNull}
+}
+
+// Range returns a rule that checks that the numeric field is at least min
+// and at most max. NaN values violate the rule.
+func Range(field string, min, max float64) Rule {
+	return Rule{Field: field, Kind: KindRange, Min: min, Max: max}
+}
+
+// Matches returns a rule that checks that the string field matches the
+// regular expression.
+func Matches(field, pattern string) Rule {
+	return Rule{Field: field, Kind: KindRegex, Pattern: pattern}
+}
+
+// InReference returns a rule that checks that the field is a value of the
+// named reference, given in Options.References.
+func InReference(field, reference string) Rule {
+	return Rule{Field: field, Kind: KindReference, Reference: reference}
+}
+
+// As returns the rule with the given name.
+func (r Rule) As(name string) Rule {
+	r.Name = name
+	return r
+}
+
+func (r Rule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%v(%v)", r.Kind, r.Field)
+}
+
+// Violation is a violated rule.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Options configure Validate.
+type Options struct {
+	// References are the PCollection<string> side inputs of the
+	// KindReference rules, by name.
+	References map[string]beam.PCollection
+}
+
+// Result is the output of Validate.
+type Result struct {
+	// Passed are the elements that pass all rules.
+	Passed beam.PCollection
+	// Failed are the other elements, as KV<X,[]Violation>.
+	Failed beam.PCollection
+	// Violations is a PCollection<KV<string,int>> of the number of
+	// violations of each rule in each window.
+	Violations beam.PCollection
+}
+
+// Validate checks each element of the PCollection<X> against the rules.
+// Elements that violate any rule are output with all rules they violate.
+// The number of violations of each rule is also reported as the counter
+// "violations.<rule>" in the "quality" namespace.
+func Validate(s beam.Scope, col beam.PCollection, rules []Rule, opts Options) Result {
+	s = s.Scope("quality.Validate")
+
+	if len(rules) == 0 {
+		panic("no rules")
+	}
+	rules = append([]Rule(nil), rules...)
+	t := col.Type().Type()
+	names := make(map[string]bool)
+	for i, r := range rules {
+		if err := validateRule(t, r, opts.References); err != nil {
+			panic(fmt.Sprintf("invalid rule %v: %v", r.name(), err))
+		}
+		if names[r.name()] {
+			panic(fmt.Sprintf("duplicate rule %v", r.name()))
+		}
+		names[r.name()] = true
+		rules[i].Name = r.name()
+	}
+
+	var refs []beam.PCollection
+	for name, ref := range opts.References {
+		if ref.Type().Type().Kind() != reflect.String {
+			panic(fmt.Sprintf("reference %v is not a PCollection<string>: %v", name, ref.Type()))
+		}
+		refs = append(refs, beam.ParDo(s, &tagFn{Name: name}, ref))
+	}
+	if len(refs) == 0 {
+		refs = append(refs, beam.ParDo(s, emptyFn, beam.Impulse(s)))
+	}
+	side := beam.Flatten(s, refs...)
+
+	passed, failed := beam.ParDo2(s, &validateFn{Rules: rules}, col, beam.SideInput{Input: side})
+	violations := stats.Count(s, beam.ParDo(s, ruleNamesFn, failed))
+	return Result{Passed: passed, Failed: failed, Violations: violations}
+}
+
+// validateRule checks that the rule applies to the field of t.
+func validateRule(t reflect.Type, r Rule, refs map[string]beam.PCollection) error {
+	if r.Field != "" {
+		for _, name := range strings.Split(r.Field, ".") {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Struct {
+				return fmt.Errorf("%v is not a struct", t)
+			}
+			f, ok := t.FieldByName(name)
+			if !ok || f.PkgPath != "" {
+				return fmt.Errorf("no exported field %v in %v", name, t)
+			}
+			t = f.Type
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch r.Kind {
+	case KindNotNull:
+	case KindRange:
+		if _, ok := toFloat(reflect.Zero(t)); !ok {
+			return fmt.Errorf("%v is not numeric", t)
+		}
+		if r.Min > r.Max {
+			return fmt.Errorf("empty range [%v, %v]", r.Min, r.Max)
+		}
+	case KindRegex:
+		if t.Kind() != reflect.String {
+			return fmt.Errorf("%v is not a string", t)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return err
+		}
+	case KindReference:
+		if _, ok := refs[r.Reference]; !ok {
+			return fmt.Errorf("no reference %v", r.Reference)
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	return nil
+}
+
+// tagFn prefixes the values of a reference with its name.
+type tagFn struct {
+	Name string `json:"name"`
+}
+
+func (f *tagFn) ProcessElement(value string) string {
+	return f.Name + "\x00" + value
+}
+
+func emptyFn(_ []byte, _ func(string)) {}
+
+// validateFn checks the rules and outputs the passed and failed elements.
+type validateFn struct {
+	Rules []Rule `json:"rules"`
+
+	patterns []*regexp.Regexp
+	counters []beam.Counter
+	// refs are the reference values of the window refWindow, which are read
+	// once per bundle and window, since they may change between bundles.
+	refs      map[string]bool
+	refWindow beam.Window
+}
+
+func (f *validateFn) Setup() {
+	f.patterns = make([]*regexp.Regexp, len(f.Rules))
+	f.counters = make([]beam.Counter, len(f.Rules))
+	for i, r := range f.Rules {
+		if r.Kind == KindRegex {
+			f.patterns[i] = regexp.MustCompile(r.Pattern)
+		}
+		f.counters[i] = beam.NewCounter("quality", "violations."+r.Name)
+	}
+}
+
+func (f *validateFn) StartBundle() {
+	f.refs, f.refWindow = nil, nil
+}
+
+func (f *validateFn) ProcessElement(ctx context.Context, w beam.Window, elm beam.X, refs func(*string) bool, passed func(beam.X), failed func(beam.X, []Violation)) {
+	if f.refWindow == nil || !f.refWindow.Equals(w) {
+		f.refs = make(map[string]bool)
+		var ref string
+		for refs(&ref) {
+			f.refs[ref] = true
+		}
+		f.refWindow = w
+	}
+
+	var violations []Violation
+	v := reflect.ValueOf(elm)
+	for i, r := range f.Rules {
+		msg := f.check(i, field(v, r.Field))
+		if msg == "" {
+			continue
+		}
+		f.counters[i].Inc(ctx, 1)
+		violations = append(violations, Violation{Rule: r.Name, Field: r.Field, Message: msg})
+	}
+	if len(violations) == 0 {
+		passed(elm)
+	} else {
+		failed(elm, violations)
+	}
+}
+
+// check checks the ith rule against the value and returns the reason of the
+// violation, if any.
+func (f *validateFn) check(i int, v reflect.Value) string {
+	r := f.Rules[i]
+	if isNull(v) {
+		if r.Kind == KindNotNull {
+			return "is null"
+		}
+		return ""
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch r.Kind {
+	case KindRange:
+		if x, _ := toFloat(v); math.IsNaN(x) || x < r.Min || x > r.Max {
+			return fmt.Sprintf("%v is not in [%v, %v]", x, r.Min, r.Max)
+		}
+	case KindRegex:
+		if !f.patterns[i].MatchString(v.String()) {
+			return fmt.Sprintf("does not match %v", r.Pattern)
+		}
+	case KindReference:
+		if !f.refs[r.Reference+"\x00"+fmt.Sprint(v.Interface())] {
+			return fmt.Sprintf("%v is not in %v", v.Interface(), r.Reference)
+		}
+	}
+	return ""
+}
+
+// field returns the field at the path of v, or an invalid value if a
+// pointer on the path is nil.
+func field(v reflect.Value, path string) reflect.Value {
+	if path == "" {
+		return v
+	}
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByName(name)
+	}
+	return v
+}
+
+func isNull(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return v.Len() == 0
+	default:
+		return false
+	}
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func ruleNamesFn(_ beam.X, violations []Violation, emit func(string)) {
+	for _, v := range violations {
+		emit(v.Rule)
+	}
+}
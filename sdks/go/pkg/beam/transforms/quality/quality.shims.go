@@ -0,0 +1,308 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: quality.shims.go
+
+package quality
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(emptyFn)
+	runtime.RegisterFunction(ruleNamesFn)
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*tagFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*validateFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*tagFn)(nil)).Elem(), wrapMakerTagFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*validateFn)(nil)).Elem(), wrapMakerValidateFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, typex.Window, typex.X, func(*string) bool, func(typex.X), func(typex.X, []Violation)))(nil)).Elem(), funcMakerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(string)))(nil)).Elem(), funcMakerSliceOfByteEmitStringГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string) string)(nil)).Elem(), funcMakerStringГString)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, []Violation, func(string)))(nil)).Elem(), funcMakerTypex۰XSliceOfViolationEmitStringГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(string))(nil)).Elem(), emitMakerString)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X))(nil)).Elem(), emitMakerTypex۰X)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X, []Violation))(nil)).Elem(), emitMakerTypex۰XSliceOfViolation)
+	exec.RegisterInput(reflect.TypeOf((*func(*string) bool)(nil)).Elem(), iterMakerString)
+}
+
+func wrapMakerTagFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*tagFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 string) string { return dfn.ProcessElement(a0) }),
+	}
+}
+
+func wrapMakerValidateFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*validateFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 typex.Window, a2 typex.X, a3 func(*string) bool, a4 func(typex.X), a5 func(typex.X, []Violation)) {
+			dfn.ProcessElement(a0, a1, a2, a3, a4, a5)
+		}),
+		"Setup":       reflectx.MakeFunc(func() { dfn.Setup() }),
+		"StartBundle": reflectx.MakeFunc(func() { dfn.StartBundle() }),
+	}
+}
+
+type callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ struct {
+	fn func(context.Context, typex.Window, typex.X, func(*string) bool, func(typex.X), func(typex.X, []Violation))
+}
+
+func funcMakerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, typex.Window, typex.X, func(*string) bool, func(typex.X), func(typex.X, []Violation)))
+	return &callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ{fn: f}
+}
+
+func (c *callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(context.Context), args[1].(typex.Window), args[2].(typex.X), args[3].(func(*string) bool), args[4].(func(typex.X)), args[5].(func(typex.X, []Violation)))
+	return []interface{}{}
+}
+
+func (c *callerContext۰ContextTypex۰WindowTypex۰XIterStringEmitTypex۰XEmitTypex۰XSliceOfViolationГ) Call6x0(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) {
+	c.fn(arg0.(context.Context), arg1.(typex.Window), arg2.(typex.X), arg3.(func(*string) bool), arg4.(func(typex.X)), arg5.(func(typex.X, []Violation)))
+}
+
+type callerSliceOfByteEmitStringГ struct {
+	fn func([]byte, func(string))
+}
+
+func funcMakerSliceOfByteEmitStringГ(fn interface{}) reflectx.Func {
+	f := fn.(func([]byte, func(string)))
+	return &callerSliceOfByteEmitStringГ{fn: f}
+}
+
+func (c *callerSliceOfByteEmitStringГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfByteEmitStringГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfByteEmitStringГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].([]byte), args[1].(func(string)))
+	return []interface{}{}
+}
+
+func (c *callerSliceOfByteEmitStringГ) Call2x0(arg0, arg1 interface{}) {
+	c.fn(arg0.([]byte), arg1.(func(string)))
+}
+
+type callerStringГString struct {
+	fn func(string) string
+}
+
+func funcMakerStringГString(fn interface{}) reflectx.Func {
+	f := fn.(func(string) string)
+	return &callerStringГString{fn: f}
+}
+
+func (c *callerStringГString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringГString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringГString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string))
+	return []interface{}{out0}
+}
+
+func (c *callerStringГString) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(string))
+}
+
+type callerTypex۰XSliceOfViolationEmitStringГ struct {
+	fn func(typex.X, []Violation, func(string))
+}
+
+func funcMakerTypex۰XSliceOfViolationEmitStringГ(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, []Violation, func(string)))
+	return &callerTypex۰XSliceOfViolationEmitStringГ{fn: f}
+}
+
+func (c *callerTypex۰XSliceOfViolationEmitStringГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XSliceOfViolationEmitStringГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XSliceOfViolationEmitStringГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(typex.X), args[1].([]Violation), args[2].(func(string)))
+	return []interface{}{}
+}
+
+func (c *callerTypex۰XSliceOfViolationEmitStringГ) Call3x0(arg0, arg1, arg2 interface{}) {
+	c.fn(arg0.(typex.X), arg1.([]Violation), arg2.(func(string)))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerString(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeString
+	return ret
+}
+
+func (e *emitNative) invokeString(val string) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerTypex۰X(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰X
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰X(val typex.X) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerTypex۰XSliceOfViolation(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰XSliceOfViolation
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰XSliceOfViolation(key typex.X, val []Violation) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerString(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readString
+	return ret
+}
+
+func (v *iterNative) readString(value *string) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(string)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
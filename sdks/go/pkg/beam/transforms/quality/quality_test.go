@@ -0,0 +1,136 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quality
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(formatCount)
+}
+
+func formatCount(rule string, n int) string {
+	return fmt.Sprintf("%v=%v", rule, n)
+}
+
+type address struct {
+	Country string
+}
+
+type order struct {
+	ID       int
+	Customer *string
+	Amount   float64
+	Email    string
+	Address  *address
+}
+
+func TestValidate(t *testing.T) {
+	jane := "jane"
+	orders := []order{
+		{1, &jane, 10, "jane@example.com", &address{"DK"}},
+		{2, nil, 10, "", nil},
+		{3, &jane, -5, "jane", &address{"XX"}},
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	countries := beam.Create(s, "DK", "SE")
+	res := Validate(s, beam.CreateList(s, orders), []Rule{
+		NotNull("Customer"),
+		Range("Amount", 0, 100),
+		Matches("Email", `^[^@]+@[^@]+$`).As("valid_email"),
+		InReference("Address.Country", "countries"),
+	}, Options{References: map[string]beam.PCollection{"countries": countries}})
+
+	passert.Equals(s, res.Passed, orders[0])
+	passert.Equals(s, beam.DropValue(s, res.Failed), orders[1], orders[2])
+	passert.Equals(s, beam.ParDo(s, formatCount, res.Violations),
+		"not_null(Customer)=1", "range(Amount)=1", "valid_email=1", "reference(Address.Country)=1")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	fn := &validateFn{Rules: []Rule{
+		NotNull("").As("a"),
+		Range("", 1, 2).As("b"),
+		Matches("", "^x").As("c"),
+		InReference("", "r").As("d"),
+	}}
+	fn.Setup()
+	fn.refs = map[string]bool{"r\x00x": true}
+
+	tests := []struct {
+		rule  int
+		value interface{}
+		exp   bool
+	}{
+		{0, "", false},
+		{0, []int{}, true},
+		{1, 3, false},
+		{1, uint8(2), true},
+		{1, math.NaN(), false},
+		{1, math.Inf(1), false},
+		{2, "y", false},
+		{2, "xy", true},
+		{3, "y", false},
+		{3, "x", true},
+	}
+	for _, test := range tests {
+		msg := fn.check(test.rule, field(reflect.ValueOf(test.value), ""))
+		if got := msg == ""; got != test.exp {
+			t.Errorf("check(%v, %v) = %q, want pass = %v", fn.Rules[test.rule].Name, test.value, msg, test.exp)
+		}
+	}
+}
+
+// TestReferencesPerBundle tests that the references are read again in each
+// bundle.
+func TestReferencesPerBundle(t *testing.T) {
+	fn := &validateFn{Rules: []Rule{InReference("", "r").As("r")}}
+	fn.Setup()
+
+	for _, ref := range []string{"x", "y"} {
+		read := false
+		refs := func(v *string) bool {
+			if read {
+				return false
+			}
+			*v, read = "r\x00"+ref, true
+			return true
+		}
+		var passed []interface{}
+		fn.StartBundle()
+		fn.ProcessElement(context.Background(), window.GlobalWindow{}, ref, refs, func(x beam.X) {
+			passed = append(passed, x)
+		}, func(beam.X, []Violation) {})
+		if len(passed) != 1 {
+			t.Errorf("%v did not pass with reference %v", ref, ref)
+		}
+	}
+}
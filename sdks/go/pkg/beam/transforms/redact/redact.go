@@ -0,0 +1,358 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact contains a transform that detects and masks personally
+// identifiable information (PII) in text. For example:
+//
+//    clean, findings := redact.Redact(s, tickets, redact.Options{
+//        Detectors: []redact.Detector{redact.Email, redact.CreditCard,
+//            &redact.Dictionary{Type: "CODENAME", Words: []string{"bluebird"}}},
+//        Fields: []string{"Subject", "Body"},
+//    })
+//
+// replaces e-mail addresses, credit card numbers and code names in the
+// Subject and Body fields of each ticket by "[EMAIL]", "[CREDIT_CARD]" and
+// "[CODENAME]", and returns a PCollection<Finding> that describes each
+// match, without the matched text, for auditing.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=redact --identifiers=redactFn
+//go:generate go fmt
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*Finding)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*Regex)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*Dictionary)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*creditCard)(nil)).Elem())
+}
+
+// Span is a match of a detector in a text, as byte offsets.
+type Span struct {
+	Start, End int
+	// Type is the kind of information, such as "EMAIL".
+	Type string
+}
+
+// Detector detects PII in text. A detector is encoded as JSON and sent to
+// the workers, so its configuration must be in exported fields and its type
+// must be registered with beam.RegisterType.
+type Detector interface {
+	Detect(text string) []Span
+}
+
+// Finding describes a match in an element.
+type Finding struct {
+	// Field is the name of the field of the match, or empty if the
+	// elements are strings.
+	Field string `json:"field,omitempty"`
+	// Type is the kind of information matched.
+	Type string `json:"type"`
+	// Offset and Length are the byte offset and length of the match in the
+	// original text.
+	Offset int `json:"offset"`
+	Length int `json:"length"`
+}
+
+// Options configure Redact.
+type Options struct {
+	// Detectors detect the PII to redact.
+	Detectors []Detector
+	// Fields are the top-level string fields to redact, if the elements are
+	// structs.
+	Fields []string
+	// Mask, if set, replaces each character of a match, such as "*".
+	// Otherwise, matches are replaced by their type in brackets.
+	Mask string
+}
+
+// Redact masks the matches of the detectors in a PCollection<string>, or in
+// the given string fields of a PCollection of structs. It returns the masked
+// PCollection, of the same type, and a PCollection<Finding> of the matches.
+// Overlapping matches are merged into the earliest match.
+func Redact(s beam.Scope, col beam.PCollection, opts Options) (beam.PCollection, beam.PCollection) {
+	s = s.Scope("redact.Redact")
+
+	t := col.Type().Type()
+	switch {
+	case t == reflectx.String && len(opts.Fields) == 0:
+	case t.Kind() == reflect.Struct && len(opts.Fields) > 0:
+		for _, name := range opts.Fields {
+			f, ok := t.FieldByName(name)
+			if !ok || len(f.Index) != 1 || f.PkgPath != "" || f.Type != reflectx.String {
+				panic(fmt.Sprintf("no exported top-level string field %v in %v", name, t))
+			}
+		}
+	default:
+		panic(fmt.Sprintf("cannot redact fields %v of %v", opts.Fields, t))
+	}
+	if len(opts.Detectors) == 0 {
+		panic("no detectors")
+	}
+
+	fn := &redactFn{Fields: opts.Fields, Mask: opts.Mask}
+	for _, d := range opts.Detectors {
+		data, err := json.Marshal(d)
+		if err != nil {
+			panic(errors.Wrapf(err, "failed to encode detector %T", d))
+		}
+		fn.Detectors = append(fn.Detectors, encodedDetector{Type: beam.EncodedType{T: reflect.TypeOf(d)}, Config: string(data)})
+	}
+	return beam.ParDo2(s, fn, col)
+}
+
+// encodedDetector is the type and JSON encoding of a detector.
+type encodedDetector struct {
+	Type   beam.EncodedType `json:"type"`
+	Config string           `json:"config"`
+}
+
+func (e encodedDetector) decode() (Detector, error) {
+	t := e.Type.T
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Ptr {
+		ptr = reflect.New(t.Elem())
+	}
+	if err := json.Unmarshal([]byte(e.Config), ptr.Interface()); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode detector %v", t)
+	}
+	if t.Kind() == reflect.Ptr {
+		return ptr.Interface().(Detector), nil
+	}
+	return ptr.Elem().Interface().(Detector), nil
+}
+
+// redactFn masks the matches of the detectors.
+type redactFn struct {
+	Detectors []encodedDetector `json:"detectors"`
+	Fields    []string          `json:"fields,omitempty"`
+	Mask      string            `json:"mask,omitempty"`
+
+	detectors []Detector
+}
+
+func (f *redactFn) Setup() error {
+	for _, e := range f.Detectors {
+		d, err := e.decode()
+		if err != nil {
+			return err
+		}
+		if p, ok := d.(interface{ compile() error }); ok {
+			if err := p.compile(); err != nil {
+				return err
+			}
+		}
+		f.detectors = append(f.detectors, d)
+	}
+	return nil
+}
+
+func (f *redactFn) ProcessElement(elm beam.X, emit func(Finding)) beam.X {
+	if len(f.Fields) == 0 {
+		return f.redact("", elm.(string), emit)
+	}
+	v := reflect.New(reflect.TypeOf(elm)).Elem()
+	v.Set(reflect.ValueOf(elm))
+	for _, name := range f.Fields {
+		field := v.FieldByName(name)
+		field.SetString(f.redact(name, field.String(), emit))
+	}
+	return v.Interface()
+}
+
+func (f *redactFn) redact(field, text string, emit func(Finding)) string {
+	var spans []Span
+	for _, d := range f.detectors {
+		for _, span := range d.Detect(text) {
+			if span.End <= span.Start || span.Start < 0 || span.End > len(text) {
+				continue // invalid
+			}
+			emit(Finding{Field: field, Type: span.Type, Offset: span.Start, Length: span.End - span.Start})
+			spans = append(spans, span)
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, span := range merge(spans) {
+		b.WriteString(text[pos:span.Start])
+		if f.Mask != "" {
+			b.WriteString(strings.Repeat(f.Mask, utf8.RuneCountInString(text[span.Start:span.End])))
+		} else {
+			b.WriteString("[" + span.Type + "]")
+		}
+		pos = span.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// merge returns the spans sorted by start, with overlapping and adjacent
+// spans merged so that no part of a detected span is left unmasked. A
+// merged span has the type of its longest span.
+func merge(spans []Span) []Span {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var ret []Span
+	var longest int
+	for _, span := range spans {
+		if n := len(ret); n > 0 && span.Start <= ret[n-1].End {
+			last := &ret[n-1]
+			if span.End-span.Start > longest {
+				last.Type, longest = span.Type, span.End-span.Start
+			}
+			if span.End > last.End {
+				last.End = span.End
+			}
+			continue
+		}
+		ret = append(ret, span)
+		longest = span.End - span.Start
+	}
+	return ret
+}
+
+// Regex detects matches of a regular expression.
+type Regex struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+func (r *Regex) compile() error {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pattern for %v", r.Type)
+	}
+	r.re = re
+	return nil
+}
+
+func (r *Regex) Detect(text string) []Span {
+	if r.re == nil {
+		r.re = regexp.MustCompile(r.Pattern)
+	}
+	var ret []Span
+	for _, m := range r.re.FindAllStringIndex(text, -1) {
+		ret = append(ret, Span{Start: m[0], End: m[1], Type: r.Type})
+	}
+	return ret
+}
+
+// Dictionary detects whole words of a list, ignoring case.
+type Dictionary struct {
+	Type  string   `json:"type"`
+	Words []string `json:"words"`
+
+	re *regexp.Regexp
+}
+
+func (d *Dictionary) compile() error {
+	var quoted []string
+	for _, w := range d.Words {
+		quoted = append(quoted, regexp.QuoteMeta(w))
+	}
+	// Longer words first, so that the longest word matches.
+	sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	re, err := regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	if err != nil {
+		return errors.Wrapf(err, "invalid dictionary for %v", d.Type)
+	}
+	d.re = re
+	return nil
+}
+
+func (d *Dictionary) Detect(text string) []Span {
+	if len(d.Words) == 0 {
+		return nil
+	}
+	if d.re == nil {
+		if err := d.compile(); err != nil {
+			panic(err)
+		}
+	}
+	var ret []Span
+	for _, m := range d.re.FindAllStringIndex(text, -1) {
+		ret = append(ret, Span{Start: m[0], End: m[1], Type: d.Type})
+	}
+	return ret
+}
+
+var (
+	// Email detects e-mail addresses.
+	Email Detector = &Regex{Type: "EMAIL", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`}
+	// Phone detects phone numbers in international or common North
+	// American formats.
+	Phone Detector = &Regex{Type: "PHONE", Pattern: `(?:\+\d{1,3}[ .-]?)?\(?\d{3}\)?[ .-]?\d{3}[ .-]?\d{4}\b`}
+	// SSN detects US social security numbers.
+	SSN Detector = &Regex{Type: "SSN", Pattern: `\b\d{3}-\d{2}-\d{4}\b`}
+	// IPv4 detects IPv4 addresses.
+	IPv4 Detector = &Regex{Type: "IP_ADDRESS", Pattern: `\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`}
+	// CreditCard detects credit card numbers of 13 to 19 digits, which may
+	// be separated by spaces or dashes, with a valid Luhn checksum.
+	CreditCard Detector = &creditCard{}
+)
+
+type creditCard struct{}
+
+var cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+func (c *creditCard) Detect(text string) []Span {
+	var ret []Span
+	for _, m := range cardPattern.FindAllStringIndex(text, -1) {
+		if luhn(text[m[0]:m[1]]) {
+			ret = append(ret, Span{Start: m[0], End: m[1], Type: "CREDIT_CARD"})
+		}
+	}
+	return ret
+}
+
+// luhn returns whether the digits of the number have a valid Luhn checksum.
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,135 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: redact.shims.go
+
+package redact
+
+import (
+	"context"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*Finding)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*redactFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*redactFn)(nil)).Elem(), wrapMakerRedactFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, func(Finding)) typex.X)(nil)).Elem(), funcMakerTypex۰XEmitFindingГTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+	exec.RegisterEmitter(reflect.TypeOf((*func(Finding))(nil)).Elem(), emitMakerFinding)
+}
+
+func wrapMakerRedactFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*redactFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 func(Finding)) typex.X { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+type callerTypex۰XEmitFindingГTypex۰X struct {
+	fn func(typex.X, func(Finding)) typex.X
+}
+
+func funcMakerTypex۰XEmitFindingГTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, func(Finding)) typex.X)
+	return &callerTypex۰XEmitFindingГTypex۰X{fn: f}
+}
+
+func (c *callerTypex۰XEmitFindingГTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XEmitFindingГTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XEmitFindingГTypex۰X) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X), args[1].(func(Finding)))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XEmitFindingГTypex۰X) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(typex.X), arg1.(func(Finding)))
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerFinding(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeFinding
+	return ret
+}
+
+func (e *emitNative) invokeFinding(val Finding) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type ticket struct {
+	ID      int
+	Subject string
+	Body    string
+}
+
+func TestRedact(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	tickets := beam.Create(s,
+		ticket{1, "Refund", "Card 4111 1111 1111 1111 of jane@example.com"},
+		ticket{2, "Bluebird launch", "Call +1 555-123-4567"},
+		ticket{3, "Hello", "Order 1234 5678 9012 3456"})
+	clean, findings := Redact(s, tickets, Options{
+		Detectors: []Detector{Email, Phone, CreditCard, &Dictionary{Type: "CODENAME", Words: []string{"bluebird"}}},
+		Fields:    []string{"Subject", "Body"},
+	})
+	passert.Equals(s, clean,
+		ticket{1, "Refund", "Card [CREDIT_CARD] of [EMAIL]"},
+		ticket{2, "[CODENAME] launch", "Call [PHONE]"},
+		ticket{3, "Hello", "Order 1234 5678 9012 3456"})
+	passert.Equals(s, findings,
+		Finding{Field: "Body", Type: "CREDIT_CARD", Offset: 5, Length: 19},
+		Finding{Field: "Body", Type: "EMAIL", Offset: 28, Length: 16},
+		Finding{Field: "Subject", Type: "CODENAME", Offset: 0, Length: 8},
+		Finding{Field: "Body", Type: "PHONE", Offset: 5, Length: 15})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Redact failed: %v", err)
+	}
+}
+
+func TestRedactStrings(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	lines := beam.Create(s, "ssn 123-45-6789", "from 10.0.0.1")
+	clean, findings := Redact(s, lines, Options{Detectors: []Detector{SSN, IPv4}, Mask: "*"})
+	passert.Equals(s, clean, "ssn ***********", "from ********")
+	passert.Equals(s, findings,
+		Finding{Type: "SSN", Offset: 4, Length: 11},
+		Finding{Type: "IP_ADDRESS", Offset: 5, Length: 8})
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Redact failed: %v", err)
+	}
+}
+
+func TestRedactOverlapping(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	lines := beam.Create(s, "mail jane.doe@example.com now", "ab cd")
+	detectors := []Detector{
+		Email,
+		&Regex{Type: "NAME", Pattern: "l jane"},
+		&Regex{Type: "A", Pattern: "ab"},
+		&Regex{Type: "B", Pattern: " cd"},
+	}
+	clean, _ := Redact(s, lines, Options{Detectors: detectors})
+	passert.Equals(s, clean, "mai[EMAIL] now", "[B]")
+	masked, _ := Redact(s, lines, Options{Detectors: detectors, Mask: "*"})
+	passert.Equals(s, masked, "mai********************** now", "*****")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Redact failed: %v", err)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		exp    bool
+	}{
+		{"4111 1111 1111 1111", true},
+		{"5500-0000-0000-0004", true},
+		{"1234 5678 9012 3456", false},
+	}
+	for _, test := range tests {
+		if got := luhn(test.number); got != test.exp {
+			t.Errorf("luhn(%v) = %v, want %v", test.number, got, test.exp)
+		}
+	}
+}
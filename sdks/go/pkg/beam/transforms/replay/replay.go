@@ -0,0 +1,201 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay contains a transform that replays archived data with its
+// original event timestamps, so that backfills can run through the same
+// pipeline as live traffic. For example:
+//
+//    var events beam.PCollection
+//    if *backfill != "" {
+//        lines := textio.Read(s, *backfill)
+//        parsed := beam.ParDo(s, parseEvent, lines)
+//        events = replay.Replay(s, eventTime, parsed, replay.Options{
+//            Start:   start,
+//            Speedup: 60,
+//        })
+//    } else {
+//        events = beam.ParDo(s, parseEvent, pubsubio.Read(s, project, topic, nil))
+//    }
+//    // The same transforms for both.
+//
+// replays an hour of archived events per minute.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/stats"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=replay --identifiers=bucketFn,originFn,paceFn
+//go:generate go fmt
+
+// Options configure Replay.
+type Options struct {
+	// Speedup is the factor by which the replay is faster than the original
+	// traffic. If zero, elements are emitted as fast as possible, only with
+	// their original timestamps.
+	Speedup float64
+	// Start is the event time that the replay starts at, which is required
+	// if Speedup is set. Elements before Start are emitted immediately.
+	Start time.Time
+	// Bucket is the span of event time of the elements that are ordered
+	// together. If zero, one minute (of event time) is used.
+	Bucket time.Duration
+}
+
+// Replay emits the elements of the PCollection<T> with the timestamps given
+// by fn, of the form T -> time.Time or T -> beam.EventTime. If a speedup is
+// set, each element is emitted when the processing time since the replay
+// started, multiplied by the speedup, reaches its event time since
+// opts.Start. The replay starts once the elements are grouped into buckets
+// of event time, at a processing time that all workers share.
+//
+// The elements of each bucket are ordered by event time, so a bucket must
+// fit in memory. Buckets are paced independently as they are processed, so
+// a bucket processed after its time is emitted immediately. The output is
+// in the global window, as live sources are, whatever the windows of the
+// input.
+//
+// Pacing requires a streaming runner. Batch runners pass the output of a
+// step downstream only once the step completes, so there the replay only
+// gives the elements their original timestamps, after the wait.
+func Replay(s beam.Scope, fn interface{}, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope("replay.Replay")
+
+	if opts.Speedup < 0 {
+		panic(fmt.Sprintf("invalid speedup: %v", opts.Speedup))
+	}
+	if opts.Speedup == 0 {
+		return beam.WithTimestamps(s, fn, col)
+	}
+	if opts.Start.IsZero() {
+		panic("replay with a speedup requires a start time")
+	}
+	if opts.Bucket == 0 {
+		opts.Bucket = time.Minute
+	}
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 ||
+		t.Out(0) != reflect.TypeOf(time.Time{}) && t.Out(0) != beam.EventTimeType {
+		panic(fmt.Sprintf("timestamp function must be of the form T -> time.Time or T -> beam.EventTime: %v", t))
+	}
+	if in := col.Type().Type(); !in.AssignableTo(t.In(0)) {
+		panic(fmt.Sprintf("%v cannot be applied to PCollection<%v>", t, in))
+	}
+
+	timestamp := beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)}
+	global := beam.WindowInto(s, window.NewGlobalWindows(), col)
+	buckets := beam.GroupByKey(s, beam.ParDo(s, &bucketFn{Fn: timestamp, Bucket: opts.Bucket}, global))
+	// The origin is taken once all buckets are grouped, so that it does
+	// not include the time to read and group the input.
+	origin := stats.Min(s, beam.ParDo(s, originFn, buckets))
+	return beam.ParDo(s, &paceFn{
+		Fn:      timestamp,
+		Start:   mtime.FromTime(opts.Start),
+		Speedup: opts.Speedup,
+	}, buckets, beam.SideInput{Input: origin})
+}
+
+// eventTime returns the timestamp of the element given by fn.
+func eventTime(fn reflectx.Func1x1, elm interface{}) beam.EventTime {
+	switch v := fn.Call1x1(elm).(type) {
+	case time.Time:
+		return mtime.FromTime(v)
+	default:
+		return v.(beam.EventTime)
+	}
+}
+
+// bucketFn keys elements by the start of their bucket of event time.
+type bucketFn struct {
+	Fn     beam.EncodedFunc `json:"fn"`
+	Bucket time.Duration    `json:"bucket"`
+
+	fn reflectx.Func1x1
+}
+
+func (f *bucketFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+}
+
+func (f *bucketFn) ProcessElement(elm beam.X) (int64, beam.X) {
+	ms := eventTime(f.fn, elm).Milliseconds()
+	size := int64(f.Bucket / time.Millisecond)
+	bucket := ms - ms%size
+	if ms < 0 && ms%size != 0 {
+		bucket -= size
+	}
+	return bucket, elm
+}
+
+// originFn returns the processing time at which a bucket is grouped, in
+// milliseconds since the epoch. The earliest one is the origin of the
+// replay. It is rounded up, so that elements are never emitted early.
+func originFn(_ int64, _ func(*beam.X) bool) int64 {
+	ms := int64(time.Millisecond)
+	return (time.Now().UnixNano() + ms - 1) / ms
+}
+
+// paceFn emits the elements of each bucket in order of event time, at the
+// pace of the speedup.
+type paceFn struct {
+	Fn      beam.EncodedFunc `json:"fn"`
+	Start   beam.EventTime   `json:"start"`
+	Speedup float64          `json:"speedup"`
+
+	fn reflectx.Func1x1
+}
+
+func (f *paceFn) Setup() {
+	f.fn = reflectx.ToFunc1x1(f.Fn.Fn)
+}
+
+type timed struct {
+	t     beam.EventTime
+	value interface{}
+}
+
+func (f *paceFn) ProcessElement(ctx context.Context, _ int64, values func(*beam.Y) bool, origin int64, emit func(beam.EventTime, beam.Y)) error {
+	var all []timed
+	var value beam.Y
+	for values(&value) {
+		all = append(all, timed{t: eventTime(f.fn, value), value: value})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].t < all[j].t })
+
+	start := time.Unix(0, origin*int64(time.Millisecond))
+	for _, e := range all {
+		offset := time.Duration(float64(e.t-f.Start) * float64(time.Millisecond) / f.Speedup)
+		if wait := time.Until(start.Add(offset)); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		emit(e.t, e.value)
+	}
+	return nil
+}
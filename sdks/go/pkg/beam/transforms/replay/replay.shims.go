@@ -0,0 +1,273 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: replay.shims.go
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(originFn)
+	runtime.RegisterType(reflect.TypeOf((*bucketFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*paceFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*bucketFn)(nil)).Elem(), wrapMakerBucketFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*paceFn)(nil)).Elem(), wrapMakerPaceFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, int64, func(*typex.Y) bool, int64, func(mtime.Time, typex.Y)) error)(nil)).Elem(), funcMakerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(int64, func(*typex.X) bool) int64)(nil)).Elem(), funcMakerInt64IterTypex۰XГInt64)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X) (int64, typex.X))(nil)).Elem(), funcMakerTypex۰XГInt64Typex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(mtime.Time, typex.Y))(nil)).Elem(), emitMakerETTypex۰Y)
+	exec.RegisterInput(reflect.TypeOf((*func(*typex.X) bool)(nil)).Elem(), iterMakerTypex۰X)
+	exec.RegisterInput(reflect.TypeOf((*func(*typex.Y) bool)(nil)).Elem(), iterMakerTypex۰Y)
+}
+
+func wrapMakerBucketFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*bucketFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X) (int64, typex.X) { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerPaceFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*paceFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 int64, a2 func(*typex.Y) bool, a3 int64, a4 func(mtime.Time, typex.Y)) error {
+			return dfn.ProcessElement(a0, a1, a2, a3, a4)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError struct {
+	fn func(context.Context, int64, func(*typex.Y) bool, int64, func(mtime.Time, typex.Y)) error
+}
+
+func funcMakerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, int64, func(*typex.Y) bool, int64, func(mtime.Time, typex.Y)) error)
+	return &callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError{fn: f}
+}
+
+func (c *callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(int64), args[2].(func(*typex.Y) bool), args[3].(int64), args[4].(func(mtime.Time, typex.Y)))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextInt64IterTypex۰YInt64EmitETTypex۰YГError) Call5x1(arg0, arg1, arg2, arg3, arg4 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(int64), arg2.(func(*typex.Y) bool), arg3.(int64), arg4.(func(mtime.Time, typex.Y)))
+}
+
+type callerInt64IterTypex۰XГInt64 struct {
+	fn func(int64, func(*typex.X) bool) int64
+}
+
+func funcMakerInt64IterTypex۰XГInt64(fn interface{}) reflectx.Func {
+	f := fn.(func(int64, func(*typex.X) bool) int64)
+	return &callerInt64IterTypex۰XГInt64{fn: f}
+}
+
+func (c *callerInt64IterTypex۰XГInt64) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerInt64IterTypex۰XГInt64) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerInt64IterTypex۰XГInt64) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(int64), args[1].(func(*typex.X) bool))
+	return []interface{}{out0}
+}
+
+func (c *callerInt64IterTypex۰XГInt64) Call2x1(arg0, arg1 interface{}) interface{} {
+	return c.fn(arg0.(int64), arg1.(func(*typex.X) bool))
+}
+
+type callerTypex۰XГInt64Typex۰X struct {
+	fn func(typex.X) (int64, typex.X)
+}
+
+func funcMakerTypex۰XГInt64Typex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X) (int64, typex.X))
+	return &callerTypex۰XГInt64Typex۰X{fn: f}
+}
+
+func (c *callerTypex۰XГInt64Typex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XГInt64Typex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XГInt64Typex۰X) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XГInt64Typex۰X) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerETTypex۰Y(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeETTypex۰Y
+	return ret
+}
+
+func (e *emitNative) invokeETTypex۰Y(t typex.EventTime, val typex.Y) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: t, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerTypex۰X(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readTypex۰X
+	return ret
+}
+
+func (v *iterNative) readTypex۰X(value *typex.X) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(typex.X)
+	return true
+}
+
+func iterMakerTypex۰Y(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readTypex۰Y
+	return ret
+}
+
+func (v *iterNative) readTypex۰Y(value *typex.Y) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(typex.Y)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,109 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(seconds)
+	beam.RegisterFunction(formatTimestamp)
+}
+
+var start = time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// seconds returns the time of an element, which is its number of seconds
+// since start.
+func seconds(elm string) time.Time {
+	n, _ := strconv.Atoi(elm)
+	return start.Add(time.Duration(n) * time.Second)
+}
+
+func formatTimestamp(et beam.EventTime, elm string) string {
+	return fmt.Sprintf("%v@%v", elm, et.Milliseconds())
+}
+
+func TestReplay(t *testing.T) {
+	base := start.UnixNano() / int64(time.Millisecond)
+	want := []interface{}{
+		fmt.Sprintf("0@%v", base),
+		fmt.Sprintf("1@%v", base+1000),
+		fmt.Sprintf("2@%v", base+2000),
+	}
+
+	for _, speedup := range []float64{0, 10} {
+		p, s := beam.NewPipelineWithRoot()
+		replayed := Replay(s, seconds, beam.Create(s, "2", "0", "1"), Options{Start: start, Speedup: speedup})
+		passert.Equals(s, beam.ParDo(s, formatTimestamp, replayed), want...)
+
+		begin := time.Now()
+		if err := ptest.Run(p); err != nil {
+			t.Fatalf("Replay(speedup %v) failed: %v", speedup, err)
+		}
+		if elapsed := time.Since(begin); speedup > 0 && elapsed < 2*time.Second/time.Duration(speedup) {
+			t.Errorf("Replay(speedup %v) took %v, want at least %v", speedup, elapsed, 2*time.Second/time.Duration(speedup))
+		}
+	}
+}
+
+// TestPaceBucket verifies that each bucket is emitted in order of event
+// time while it is processed.
+func TestPaceBucket(t *testing.T) {
+	fn := &paceFn{Fn: beam.EncodedFunc{Fn: reflectx.MakeFunc(seconds)}, Start: mtime.FromTime(start), Speedup: 1000}
+	fn.Setup()
+
+	origin := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, test := range []struct {
+		bucket, want []string
+	}{
+		{[]string{"121", "120"}, []string{"120", "121"}},
+		{[]string{"1", "0"}, []string{"0", "1"}},
+	} {
+		key := seconds(test.bucket[0]).Truncate(time.Minute).UnixNano() / int64(time.Millisecond)
+		values := test.bucket
+		var got []string
+		err := fn.ProcessElement(context.Background(), key, func(v *beam.Y) bool {
+			if len(values) == 0 {
+				return false
+			}
+			*v, values = values[0], values[1:]
+			return true
+		}, origin, func(_ beam.EventTime, v beam.Y) {
+			got = append(got, v.(string))
+		})
+		if err != nil {
+			t.Fatalf("ProcessElement failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("paced %v, want %v", got, test.want)
+		}
+	}
+	if elapsed := time.Since(time.Unix(0, origin*int64(time.Millisecond))); elapsed < 120*time.Millisecond {
+		t.Errorf("paced in %v, want at least 120ms", elapsed)
+	}
+}
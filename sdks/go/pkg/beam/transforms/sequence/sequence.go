@@ -0,0 +1,116 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sequence contains a transform that assigns contiguous sequence
+// numbers to the values of each key.
+package sequence
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=sequence --identifiers=assignFn
+//go:generate go fmt
+
+// Assign assigns the sequence numbers 1, 2, ..., n to the n values of each
+// key and window of a PCollection<KV<K,V>>. It returns a PCollection<KV<K,T>>,
+// where t is a struct type with a Seq int64 field and a Value field of type V,
+// that holds the values and their numbers. For example:
+//
+//    type Numbered struct {
+//        Seq   int64
+//        Value Event
+//    }
+//
+//    numbered := sequence.Assign(s, reflect.TypeOf(Numbered{}), events)
+//
+// The values of a key are numbered in the order of their encoding, so the
+// numbers are a function of the values alone and retries assign the same
+// numbers, without gaps or duplicates. All values of a key and window must
+// fit in memory. Since the numbers are assigned after a GroupByKey, each
+// window is numbered from 1.
+func Assign(s beam.Scope, t reflect.Type, col beam.PCollection) beam.PCollection {
+	s = s.Scope("sequence.Assign")
+
+	_, v := beam.ValidateKVType(col)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("output type %v is not a struct", t))
+	}
+	seq, ok := t.FieldByName("Seq")
+	if !ok || seq.Type != reflectx.Int64 || len(seq.Index) != 1 {
+		panic(fmt.Sprintf("output type %v has no Seq int64 field", t))
+	}
+	value, ok := t.FieldByName("Value")
+	if !ok || value.Type != v.Type() || len(value.Index) != 1 {
+		panic(fmt.Sprintf("output type %v has no Value field of type %v", t, v))
+	}
+
+	fn := &assignFn{
+		Type:  beam.EncodedType{T: t},
+		Value: beam.EncodedType{T: v.Type()},
+		Seq:   seq.Index[0],
+		Field: value.Index[0],
+	}
+	return beam.ParDo(s, fn, beam.GroupByKey(s, col), beam.TypeDefinition{Var: beam.ZType, T: t})
+}
+
+// assignFn numbers the values of a key in the order of their encoding.
+type assignFn struct {
+	// Type is the output type and Value the value type.
+	Type  beam.EncodedType `json:"type"`
+	Value beam.EncodedType `json:"value"`
+	// Seq and Field are the indices of the Seq and Value fields of Type.
+	Seq   int `json:"seq"`
+	Field int `json:"field"`
+
+	enc beam.ElementEncoder
+}
+
+func (f *assignFn) Setup() {
+	f.enc = beam.NewElementEncoder(f.Value.T)
+}
+
+type encoded struct {
+	data  []byte
+	value interface{}
+}
+
+func (f *assignFn) ProcessElement(key beam.X, values func(*beam.Y) bool, emit func(beam.X, beam.Z)) error {
+	var all []encoded
+	var value beam.Y
+	for values(&value) {
+		var buf bytes.Buffer
+		if err := f.enc.Encode(value, &buf); err != nil {
+			return err
+		}
+		all = append(all, encoded{data: buf.Bytes(), value: value})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return bytes.Compare(all[i].data, all[j].data) < 0 })
+
+	for i, e := range all {
+		out := reflect.New(f.Type.T).Elem()
+		out.Field(f.Seq).SetInt(int64(i + 1))
+		out.Field(f.Field).Set(reflect.ValueOf(e.value))
+		emit(key, out.Interface())
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequence
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*numbered)(nil)).Elem())
+	beam.RegisterFunction(firstLetter)
+	beam.RegisterFunction(formatNumbered)
+}
+
+type numbered struct {
+	Seq   int64
+	Value string
+}
+
+func firstLetter(v string) (string, string) {
+	return v[:1], v
+}
+
+func formatNumbered(key string, n numbered) string {
+	return fmt.Sprintf("%v:%v:%v", key, n.Seq, n.Value)
+}
+
+func TestAssign(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.ParDo(s, firstLetter, beam.Create(s, "a2", "b1", "a1", "a3", "a1"))
+	out := Assign(s, reflect.TypeOf(numbered{}), col)
+	passert.Equals(s, beam.ParDo(s, formatNumbered, out), "a:1:a1", "a:2:a1", "a:3:a2", "a:4:a3", "b:1:b1")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sessions contains a transform that splits the events of each user
+// into sessions of activity and computes their aggregates, such as for user
+// journey analysis. The sessions are computed by the transform itself, so
+// it is also a fallback for runners without the merging windows capability
+// that window.NewSessions requires.
+package sessions
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=sessions --identifiers=analyzeFn
+//go:generate go fmt
+
+var sessionType = reflect.TypeOf(Session{})
+
+func init() {
+	beam.RegisterType(sessionType)
+}
+
+// Session holds the aggregates of a session.
+type Session struct {
+	// Start and End are the event times of the first and last events.
+	Start time.Time
+	End   time.Time
+	// Duration is the time between the first and last events.
+	Duration time.Duration
+	// Events is the number of events.
+	Events int
+	// Converted is whether any event satisfies the conversion predicate.
+	Converted bool
+}
+
+// Options configure Analyze.
+type Options struct {
+	// Gap is the maximum time between the events of a session. It is
+	// required.
+	Gap time.Duration
+	// Converted, if set, is the predicate of the form V -> bool of the
+	// events that convert, such as purchases.
+	Converted interface{}
+}
+
+// Analyze splits the events of each key of the PCollection<KV<K,V>> into
+// sessions, which end when a key has no events for opts.Gap of event time.
+// It returns a PCollection<KV<K,T>> of the sessions, where t is Session or
+// a struct type that embeds Session. If t has First and Last fields of type
+// V, they are set to the first and last events. For example:
+//
+//    type Journey struct {
+//        sessions.Session
+//        First, Last PageView
+//    }
+//
+//    journeys := sessions.Analyze(s, reflect.TypeOf(Journey{}), views, sessions.Options{
+//        Gap:       30 * time.Minute,
+//        Converted: isCheckout,
+//    })
+//
+// The sessions are output with the timestamp of their last event. They are
+// computed within the windows of the input, such as the global window of a
+// bounded PCollection, so sessions do not span windows. All events of a key
+// and window must fit in memory. Events with equal timestamps are ordered by
+// their encoding.
+func Analyze(s beam.Scope, t reflect.Type, col beam.PCollection, opts Options) beam.PCollection {
+	s = s.Scope("sessions.Analyze")
+
+	_, v := beam.ValidateKVType(col)
+	if opts.Gap <= 0 {
+		panic(fmt.Sprintf("invalid session gap: %v", opts.Gap))
+	}
+	fn := &analyzeFn{
+		Type:  beam.EncodedType{T: t},
+		Value: beam.EncodedType{T: v.Type()},
+		Gap:   opts.Gap,
+		First: -1,
+		Last:  -1,
+	}
+	if t != sessionType {
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("output type %v is not a struct", t))
+		}
+		f, ok := t.FieldByName("Session")
+		if !ok || f.Type != sessionType || !f.Anonymous || len(f.Index) != 1 {
+			panic(fmt.Sprintf("output type %v does not embed Session", t))
+		}
+		fn.Session = f.Index[0]
+		if f, ok := t.FieldByName("First"); ok && f.Type == v.Type() && len(f.Index) == 1 {
+			fn.First = f.Index[0]
+		}
+		if f, ok := t.FieldByName("Last"); ok && f.Type == v.Type() && len(f.Index) == 1 {
+			fn.Last = f.Index[0]
+		}
+	}
+	if opts.Converted != nil {
+		pt := reflect.TypeOf(opts.Converted)
+		if pt.Kind() != reflect.Func || pt.NumIn() != 1 || pt.NumOut() != 1 ||
+			!v.Type().AssignableTo(pt.In(0)) || pt.Out(0) != reflectx.Bool {
+			panic(fmt.Sprintf("conversion predicate %v is not of the form %v -> bool", pt, v))
+		}
+		fn.Converted = &beam.EncodedFunc{Fn: reflectx.MakeFunc(opts.Converted)}
+	}
+
+	grouped, _ := stamp.GroupByKey(s, col)
+	return beam.ParDo(s, fn, grouped, beam.TypeDefinition{Var: beam.ZType, T: t})
+}
+
+// analyzeFn splits the events of a key into sessions.
+type analyzeFn struct {
+	// Type is the output type and Value the event type.
+	Type  beam.EncodedType `json:"type"`
+	Value beam.EncodedType `json:"value"`
+	Gap   time.Duration    `json:"gap"`
+	// Session, First and Last are the indices of the fields of Type, if it
+	// is not Session. First and Last are -1 if Type has no such field.
+	Session   int               `json:"session"`
+	First     int               `json:"first"`
+	Last      int               `json:"last"`
+	Converted *beam.EncodedFunc `json:"converted,omitempty"`
+
+	dec       beam.ElementDecoder
+	converted reflectx.Func1x1
+}
+
+func (f *analyzeFn) Setup() {
+	f.dec = beam.NewElementDecoder(f.Value.T)
+	if f.Converted != nil {
+		f.converted = reflectx.ToFunc1x1(f.Converted.Fn)
+	}
+}
+
+func (f *analyzeFn) ProcessElement(key beam.X, values func(*stamp.Value) bool, emit func(beam.EventTime, beam.X, beam.Z)) error {
+	all := stamp.Sorted(values)
+
+	gap := int64(f.Gap / time.Millisecond)
+	start := 0
+	for i := 1; i <= len(all); i++ {
+		if i < len(all) && all[i].Timestamp-all[i-1].Timestamp <= gap {
+			continue
+		}
+		out, err := f.session(all[start:i])
+		if err != nil {
+			return err
+		}
+		emit(all[i-1].EventTime(), key, out)
+		start = i
+	}
+	return nil
+}
+
+// session returns the output for the events of a session.
+func (f *analyzeFn) session(events []stamp.Value) (interface{}, error) {
+	first, last := events[0], events[len(events)-1]
+	session := Session{
+		Start:    toTime(first.Timestamp),
+		End:      toTime(last.Timestamp),
+		Duration: time.Duration(last.Timestamp-first.Timestamp) * time.Millisecond,
+		Events:   len(events),
+	}
+	if f.converted != nil {
+		for _, e := range events {
+			v, err := f.decode(e)
+			if err != nil {
+				return nil, err
+			}
+			if f.converted.Call1x1(v).(bool) {
+				session.Converted = true
+				break
+			}
+		}
+	}
+	if f.Type.T == sessionType {
+		return session, nil
+	}
+
+	out := reflect.New(f.Type.T).Elem()
+	out.Field(f.Session).Set(reflect.ValueOf(session))
+	for _, field := range []struct {
+		index int
+		event stamp.Value
+	}{{f.First, first}, {f.Last, last}} {
+		if field.index < 0 {
+			continue
+		}
+		v, err := f.decode(field.event)
+		if err != nil {
+			return nil, err
+		}
+		out.Field(field.index).Set(reflect.ValueOf(v))
+	}
+	return out.Interface(), nil
+}
+
+func (f *analyzeFn) decode(e stamp.Value) (interface{}, error) {
+	return stamp.Decode(f.dec, e)
+}
+
+func toTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
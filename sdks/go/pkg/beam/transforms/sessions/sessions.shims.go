@@ -0,0 +1,190 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: sessions.shims.go
+
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*analyzeFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*stamp.Value)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*analyzeFn)(nil)).Elem(), wrapMakerAnalyzeFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Z)) error)(nil)).Elem(), funcMakerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	exec.RegisterEmitter(reflect.TypeOf((*func(mtime.Time, typex.X, typex.Z))(nil)).Elem(), emitMakerETTypex۰XTypex۰Z)
+	exec.RegisterInput(reflect.TypeOf((*func(*stamp.Value) bool)(nil)).Elem(), iterMakerStamp۰Value)
+}
+
+func wrapMakerAnalyzeFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*analyzeFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 func(*stamp.Value) bool, a2 func(mtime.Time, typex.X, typex.Z)) error {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError struct {
+	fn func(typex.X, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Z)) error
+}
+
+func funcMakerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, func(*stamp.Value) bool, func(mtime.Time, typex.X, typex.Z)) error)
+	return &callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError{fn: f}
+}
+
+func (c *callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(typex.X), args[1].(func(*stamp.Value) bool), args[2].(func(mtime.Time, typex.X, typex.Z)))
+	return []interface{}{out0}
+}
+
+func (c *callerTypex۰XIterStamp۰ValueEmitETTypex۰XTypex۰ZГError) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(typex.X), arg1.(func(*stamp.Value) bool), arg2.(func(mtime.Time, typex.X, typex.Z)))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerETTypex۰XTypex۰Z(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeETTypex۰XTypex۰Z
+	return ret
+}
+
+func (e *emitNative) invokeETTypex۰XTypex۰Z(t typex.EventTime, key typex.X, val typex.Z) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: t, Elm: key, Elm2: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+type iterNative struct {
+	s  exec.ReStream
+	fn interface{}
+
+	// cur is the "current" stream, if any.
+	cur exec.Stream
+}
+
+func (v *iterNative) Init() error {
+	cur, err := v.s.Open()
+	if err != nil {
+		return err
+	}
+	v.cur = cur
+	return nil
+}
+
+func (v *iterNative) Value() interface{} {
+	return v.fn
+}
+
+func (v *iterNative) Reset() error {
+	if err := v.cur.Close(); err != nil {
+		return err
+	}
+	v.cur = nil
+	return nil
+}
+
+func iterMakerStamp۰Value(s exec.ReStream) exec.ReusableInput {
+	ret := &iterNative{s: s}
+	ret.fn = ret.readStamp۰Value
+	return ret
+}
+
+func (v *iterNative) readStamp۰Value(value *stamp.Value) bool {
+	elm, err := v.cur.Read()
+	if err != nil {
+		if err == io.EOF {
+			return false
+		}
+		panic(fmt.Sprintf("broken stream: %v", err))
+	}
+	*value = elm.Elm.(stamp.Value)
+	return true
+}
+
+// DO NOT MODIFY: GENERATED CODE
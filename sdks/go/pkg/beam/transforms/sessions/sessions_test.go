@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessions
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+type journey struct {
+	Session
+	First, Last string
+}
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*journey)(nil)).Elem())
+	beam.RegisterFunction(viewTime)
+	beam.RegisterFunction(keyView)
+	beam.RegisterFunction(isCheckout)
+	beam.RegisterFunction(formatSession)
+	beam.RegisterFunction(formatJourney)
+}
+
+// viewTime returns the timestamp of a "<user>:<minutes>:<page>" view.
+func viewTime(view string) beam.EventTime {
+	min, _ := strconv.Atoi(strings.Split(view, ":")[1])
+	return mtime.FromMilliseconds(int64(min) * 60000)
+}
+
+func keyView(view string) (string, string) {
+	return strings.Split(view, ":")[0], view
+}
+
+func isCheckout(view string) bool {
+	return strings.HasSuffix(view, ":checkout")
+}
+
+func formatSession(t beam.EventTime, user string, s Session) string {
+	return fmt.Sprintf("%v %v-%v %v %v %v @%v", user, s.Start.Format("15:04"), s.End.Format("15:04"),
+		s.Duration, s.Events, s.Converted, t.Milliseconds()/60000)
+}
+
+func formatJourney(user string, j journey) string {
+	return fmt.Sprintf("%v %v %v %v", user, j.Events, j.First, j.Last)
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+var views = []interface{}{
+	"a:0:home", "a:10:item", "a:25:checkout", "a:70:home",
+	"b:5:home", "b:6:item",
+}
+
+func TestAnalyze(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.ParDo(s, keyView, beam.WithTimestamps(s, viewTime, beam.Create(s, views...)))
+	out := Analyze(s, reflect.TypeOf(Session{}), col, Options{Gap: 20 * time.Minute, Converted: isCheckout})
+	passert.Equals(s, beam.ParDo(s, formatSession, out),
+		"a 00:00-00:25 25m0s 3 true @25",
+		"a 01:10-01:10 0s 1 false @70",
+		"b 00:05-00:06 1m0s 2 false @6")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestAnalyzeFirstLast(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.ParDo(s, keyView, beam.WithTimestamps(s, viewTime, beam.Create(s, views...)))
+	out := Analyze(s, reflect.TypeOf(journey{}), col, Options{Gap: 20 * time.Minute})
+	passert.Equals(s, beam.ParDo(s, formatJourney, out),
+		"a 3 a:0:home a:25:checkout",
+		"a 1 a:70:home a:70:home",
+		"b 2 b:5:home b:6:item")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tenant contains transforms that fan a PCollection out by tenant,
+// for pipelines that ingest the data of many customers. For example:
+//
+//    parts := tenant.Partition(s, tenantOf, events, []tenant.Tenant{
+//        {ID: "acme", Window: window.NewFixedWindows(time.Hour), Quota: 100000},
+//        {ID: "globex"},
+//    })
+//    parts.Write(s, func(s beam.Scope, id string, col beam.PCollection) {
+//        textio.Write(s, "gs://ingest/"+id+"/events.json", beam.ParDo(s, toJSON, col))
+//    })
+//
+// writes the events of each tenant to its own destination, with at most
+// 100000 events of acme per hour.
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/throttle"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=tenant --identifiers=makeSplitFn,shardFn,countFn
+//go:generate go fmt
+
+// Tenant configures the elements of a tenant.
+type Tenant struct {
+	// ID is the tenant ID, as returned by the tenant function.
+	ID string
+	// Window, if set, is the windowing of the elements of the tenant.
+	Window *window.Fn
+	// Quota, if positive, is the maximum number of elements of the tenant
+	// per window, which requires Window to be set. The earliest elements in
+	// event time are kept, and the others are added to the overflow of the
+	// tenant.
+	Quota int
+}
+
+// Partitioned holds the elements of each tenant.
+type Partitioned struct {
+	// Tenants are the PCollections of the elements of each tenant within
+	// its quota, by tenant ID.
+	Tenants map[string]beam.PCollection
+	// Overflow are the PCollections of the elements of each tenant with a
+	// quota that exceed it, by tenant ID.
+	Overflow map[string]beam.PCollection
+	// Unknown is the PCollection of the elements whose tenant is not
+	// listed.
+	Unknown beam.PCollection
+
+	ids []string
+}
+
+// Partition splits the PCollection<V> by the tenant IDs returned by fn, of
+// the form V -> string. It returns a PCollection<V> for each listed tenant
+// and one for the elements of unknown tenants. The elements of each tenant
+// are counted by the counter "<id>.elements" in the "tenant" namespace, and
+// the elements that exceed its quota by the counter "<id>.overflow". The
+// elements of unknown tenants are counted by "unknown.elements".
+func Partition(s beam.Scope, fn interface{}, col beam.PCollection, tenants []Tenant) Partitioned {
+	s = s.Scope("tenant.Partition")
+
+	t := col.Type().Type()
+	ft := reflect.TypeOf(fn)
+	if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 ||
+		!t.AssignableTo(ft.In(0)) || ft.Out(0) != reflectx.String {
+		panic(fmt.Sprintf("tenant function %v is not of the form %v -> string", ft, t))
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, tenant := range tenants {
+		if tenant.ID == "" || seen[tenant.ID] {
+			panic(fmt.Sprintf("invalid or duplicate tenant ID: %q", tenant.ID))
+		}
+		if tenant.Quota < 0 {
+			panic(fmt.Sprintf("invalid quota of tenant %v: %v", tenant.ID, tenant.Quota))
+		}
+		if tenant.Quota > 0 && tenant.Window == nil {
+			panic(fmt.Sprintf("quota of tenant %v requires a window", tenant.ID))
+		}
+		seen[tenant.ID] = true
+		ids = append(ids, tenant.ID)
+	}
+
+	// The elements are split by a single DoFn with an output for each
+	// tenant and a last one for unknown tenants. Its signature depends on
+	// the number of tenants, so it is a dynamic function, as that of
+	// beam.Partition.
+	emit := reflect.FuncOf([]reflect.Type{beam.EventTimeType, t}, nil, false)
+	in := []reflect.Type{reflectx.Context, beam.EventTimeType, t}
+	for i := 0; i <= len(ids); i++ {
+		in = append(in, emit)
+	}
+	fnT := reflect.FuncOf(in, []reflect.Type{reflectx.Error}, false)
+	data, err := json.Marshal(splitData{Fn: beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)}, Tenants: ids})
+	if err != nil {
+		panic(errors.WithContext(err, "encoding tenant function"))
+	}
+	parts := beam.ParDoN(s, &graph.DynFn{Name: "tenant.splitFn", Data: data, T: fnT, Gen: makeSplitFn}, col)
+
+	ret := Partitioned{
+		Tenants:  make(map[string]beam.PCollection),
+		Overflow: make(map[string]beam.PCollection),
+		Unknown:  parts[len(ids)],
+		ids:      ids,
+	}
+	for i, tenant := range tenants {
+		ts := s.Scope(tenant.ID)
+		elms := parts[i]
+		if tenant.Window != nil {
+			elms = beam.WindowInto(ts, tenant.Window, elms)
+		}
+		if tenant.Quota > 0 {
+			var overflow beam.PCollection
+			elms, overflow = quota(ts, tenant.Quota, elms)
+			ret.Overflow[tenant.ID] = beam.ParDo(ts, &countFn{Name: tenant.ID + ".overflow"}, overflow)
+		}
+		ret.Tenants[tenant.ID] = elms
+	}
+	return ret
+}
+
+// quotaShards is the number of shards of the elements of a tenant that
+// the quota is first applied to.
+var quotaShards = 16
+
+// quota limits the PCollection<V> to the earliest n elements of each
+// window. The quota is first applied to shards of the elements and then to
+// the at most quotaShards*n elements within the quota of their shard, so
+// that no single key holds all the elements of a window.
+func quota(s beam.Scope, n int, col beam.PCollection) (beam.PCollection, beam.PCollection) {
+	sharded, excess := throttle.PerKey(s, n, beam.ParDo(s, &shardFn{Shards: quotaShards}, col))
+	within, overflow := throttle.PerKey(s, n, beam.AddFixedKey(s, beam.DropKey(s, sharded)))
+	return beam.DropKey(s, within), beam.Flatten(s, excess, overflow)
+}
+
+// Write applies the sink to the PCollection<V> of each tenant, in the order
+// the tenants were listed.
+func (p Partitioned) Write(s beam.Scope, sink func(s beam.Scope, tenant string, col beam.PCollection)) {
+	s = s.Scope("tenant.Write")
+
+	for _, id := range p.ids {
+		sink(s.Scope(id), id, p.Tenants[id])
+	}
+}
+
+// splitData contains the data needed for the split DoFn generator.
+type splitData struct {
+	Fn      beam.EncodedFunc `json:"fn"`
+	Tenants []string         `json:"tenants"`
+}
+
+// splitFn is a Func with the following underlying type:
+//
+//     fn : (context.Context, EventTime, V, emit_1, ..., emit_N, emit_unknown) -> error
+//
+// where emit_i : (EventTime, V) -> () and N is the number of tenants of the
+// encoded splitData value. It emits each element to the output of its
+// tenant and counts it.
+type splitFn struct {
+	name     string
+	t        reflect.Type
+	fn       reflectx.Func1x1
+	index    map[string]int
+	counters []beam.Counter
+}
+
+func (f *splitFn) Name() string {
+	return f.name
+}
+
+func (f *splitFn) Type() reflect.Type {
+	return f.t
+}
+
+func (f *splitFn) Call(args []interface{}) []interface{} {
+	ctx := args[0].(context.Context)
+	timestamp := args[1]
+	value := args[2]
+
+	i, ok := f.index[f.fn.Call1x1(value).(string)]
+	if !ok {
+		i = len(f.index)
+	}
+	f.counters[i].Inc(ctx, 1)
+	reflectx.MakeFunc2x0(args[i+3]).Call2x0(timestamp, value)
+
+	var err error
+	return []interface{}{err}
+}
+
+func makeSplitFn(name string, t reflect.Type, enc []byte) reflectx.Func {
+	var data splitData
+	if err := json.Unmarshal(enc, &data); err != nil {
+		panic(errors.WithContext(err, "unmarshalling splitFn data"))
+	}
+	f := &splitFn{
+		name:  name,
+		t:     t,
+		fn:    reflectx.ToFunc1x1(data.Fn.Fn),
+		index: make(map[string]int),
+	}
+	for i, id := range data.Tenants {
+		f.index[id] = i
+		f.counters = append(f.counters, beam.NewCounter("tenant", id+".elements"))
+	}
+	f.counters = append(f.counters, beam.NewCounter("tenant", "unknown.elements"))
+	return f
+}
+
+// shardFn keys elements by a random shard.
+type shardFn struct {
+	Shards int `json:"shards"`
+}
+
+func (f *shardFn) ProcessElement(elm beam.X) (int, beam.X) {
+	return rand.Intn(f.Shards), elm
+}
+
+// countFn counts and drops the keys of the overflow of a tenant.
+type countFn struct {
+	Name string `json:"name"`
+
+	counter beam.Counter
+}
+
+func (f *countFn) Setup() {
+	f.counter = beam.NewCounter("tenant", f.Name)
+}
+
+func (f *countFn) ProcessElement(ctx context.Context, _ int, elm beam.X) beam.X {
+	f.counter.Inc(ctx, 1)
+	return elm
+}
@@ -0,0 +1,165 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: tenant.shims.go
+
+package tenant
+
+import (
+	"reflect"
+
+	// Library imports
+	"context"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterFunction(makeSplitFn)
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*countFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*reflect.Type)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*reflectx.Func)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*shardFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*countFn)(nil)).Elem(), wrapMakerCountFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*shardFn)(nil)).Elem(), wrapMakerShardFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, int, typex.X) typex.X)(nil)).Elem(), funcMakerContext۰ContextIntTypex۰XГTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string, reflect.Type, []byte) reflectx.Func)(nil)).Elem(), funcMakerStringReflect۰TypeSliceOfByteГReflectx۰Func)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X) (int, typex.X))(nil)).Elem(), funcMakerTypex۰XГIntTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+}
+
+func wrapMakerCountFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*countFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 int, a2 typex.X) typex.X { return dfn.ProcessElement(a0, a1, a2) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerShardFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*shardFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X) (int, typex.X) { return dfn.ProcessElement(a0) }),
+	}
+}
+
+type callerContext۰ContextIntTypex۰XГTypex۰X struct {
+	fn func(context.Context, int, typex.X) typex.X
+}
+
+func funcMakerContext۰ContextIntTypex۰XГTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, int, typex.X) typex.X)
+	return &callerContext۰ContextIntTypex۰XГTypex۰X{fn: f}
+}
+
+func (c *callerContext۰ContextIntTypex۰XГTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextIntTypex۰XГTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextIntTypex۰XГTypex۰X) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(context.Context), args[1].(int), args[2].(typex.X))
+	return []interface{}{out0}
+}
+
+func (c *callerContext۰ContextIntTypex۰XГTypex۰X) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(context.Context), arg1.(int), arg2.(typex.X))
+}
+
+type callerStringReflect۰TypeSliceOfByteГReflectx۰Func struct {
+	fn func(string, reflect.Type, []byte) reflectx.Func
+}
+
+func funcMakerStringReflect۰TypeSliceOfByteГReflectx۰Func(fn interface{}) reflectx.Func {
+	f := fn.(func(string, reflect.Type, []byte) reflectx.Func)
+	return &callerStringReflect۰TypeSliceOfByteГReflectx۰Func{fn: f}
+}
+
+func (c *callerStringReflect۰TypeSliceOfByteГReflectx۰Func) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringReflect۰TypeSliceOfByteГReflectx۰Func) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringReflect۰TypeSliceOfByteГReflectx۰Func) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string), args[1].(reflect.Type), args[2].([]byte))
+	return []interface{}{out0}
+}
+
+func (c *callerStringReflect۰TypeSliceOfByteГReflectx۰Func) Call3x1(arg0, arg1, arg2 interface{}) interface{} {
+	return c.fn(arg0.(string), arg1.(reflect.Type), arg2.([]byte))
+}
+
+type callerTypex۰XГIntTypex۰X struct {
+	fn func(typex.X) (int, typex.X)
+}
+
+func funcMakerTypex۰XГIntTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X) (int, typex.X))
+	return &callerTypex۰XГIntTypex۰X{fn: f}
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XГIntTypex۰X) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
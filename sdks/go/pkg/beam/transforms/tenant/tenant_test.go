@@ -0,0 +1,118 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(eventTime)
+	beam.RegisterFunction(tenantOf)
+}
+
+// eventTime returns the timestamp of a "<tenant>:<hours>" event.
+func eventTime(e string) beam.EventTime {
+	h, _ := strconv.Atoi(strings.Split(e, ":")[1])
+	return mtime.FromMilliseconds(int64(h) * 3600000)
+}
+
+func tenantOf(e string) string {
+	return strings.Split(e, ":")[0]
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+func TestPartition(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "a:1", "a:2", "a:3", "a:30", "b:1", "b:2", "c:1")
+	stamped := beam.WithTimestamps(s, eventTime, col)
+	parts := Partition(s, tenantOf, stamped, []Tenant{
+		{ID: "a", Window: window.NewFixedWindows(24 * time.Hour), Quota: 2},
+		{ID: "b"},
+	})
+
+	passert.Equals(s, parts.Tenants["a"], "a:1", "a:2", "a:30")
+	passert.Equals(s, parts.Overflow["a"], "a:3")
+	passert.Equals(s, parts.Tenants["b"], "b:1", "b:2")
+	passert.Equals(s, parts.Unknown, "c:1")
+	if _, ok := parts.Overflow["b"]; ok {
+		t.Errorf("Partition returned an overflow for tenant b without a quota")
+	}
+
+	var written []string
+	parts.Write(s, func(s beam.Scope, id string, col beam.PCollection) {
+		written = append(written, id)
+	})
+	if strings.Join(written, ",") != "a,b" {
+		t.Errorf("Write wrote tenants %v, want a,b", written)
+	}
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+// TestPartitionQuotaShards verifies that the quota is exact when the
+// elements of a window are spread over several shards.
+func TestPartitionQuotaShards(t *testing.T) {
+	defer func(old int) { quotaShards = old }(quotaShards)
+	quotaShards = 3
+
+	p, s := beam.NewPipelineWithRoot()
+	var events, want, overflow []interface{}
+	for h := 0; h < 20; h++ {
+		e := "a:" + strconv.Itoa(h)
+		events = append(events, e)
+		if h < 5 {
+			want = append(want, e)
+		} else {
+			overflow = append(overflow, e)
+		}
+	}
+	stamped := beam.WithTimestamps(s, eventTime, beam.CreateList(s, events))
+	parts := Partition(s, tenantOf, stamped, []Tenant{
+		{ID: "a", Window: window.NewFixedWindows(24 * time.Hour), Quota: 5},
+	})
+
+	passert.Equals(s, parts.Tenants["a"], want...)
+	passert.Equals(s, parts.Overflow["a"], overflow...)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
+
+func TestPartitionQuotaWithoutWindow(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Partition with a quota and no window did not panic")
+		}
+	}()
+	_, s := beam.NewPipelineWithRoot()
+	Partition(s, tenantOf, beam.Create(s, "a:1"), []Tenant{{ID: "a", Quota: 1}})
+}
@@ -0,0 +1,244 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package textproc contains transforms for preprocessing natural language
+// text, such as normalization, tokenization, n-grams and stop-word removal.
+// The transforms are deterministic: the same input always results in the
+// same output, independent of the worker environment. For example:
+//
+//    normalized := textproc.Normalize(s, lines, textproc.NormalizeOptions{Language: "en", Lowercase: true})
+//    tokens := textproc.Tokenize(s, normalized)
+//    words := textproc.RemoveStopWords(s, tokens, textproc.StopWordOptions{Language: "en"})
+//    bigrams := textproc.NGrams(s, words, 2)
+package textproc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=textproc --identifiers=normalizeFn,tokenizeFn,nGramsFn,stopWordsFn
+//go:generate go fmt
+
+// NormalizeOptions configure Normalize.
+type NormalizeOptions struct {
+	// Language is the ISO 639-1 code of the language of the text, such as
+	// "en". It selects the case mapping used by Lowercase. Turkish ("tr")
+	// and Azerbaijani ("az") map dotted and dotless i; other languages use
+	// the Unicode default.
+	Language string
+	// Lowercase maps the text to lower case.
+	Lowercase bool
+	// StripPunctuation removes punctuation and symbols.
+	StripPunctuation bool
+}
+
+// Normalize normalizes the whitespace of a PCollection<string>, replacing
+// each run of whitespace with a single space and trimming leading and
+// trailing whitespace. Control characters are removed.
+func Normalize(s beam.Scope, col beam.PCollection, opts NormalizeOptions) beam.PCollection {
+	s = s.Scope("textproc.Normalize")
+	return beam.ParDo(s, &normalizeFn{Language: opts.Language, Lowercase: opts.Lowercase, StripPunctuation: opts.StripPunctuation}, col)
+}
+
+type normalizeFn struct {
+	Language         string `json:"language,omitempty"`
+	Lowercase        bool   `json:"lowercase,omitempty"`
+	StripPunctuation bool   `json:"strip_punctuation,omitempty"`
+}
+
+func (f *normalizeFn) ProcessElement(text string) string {
+	if f.Lowercase {
+		text = lower(f.Language, text)
+	}
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			continue
+		case f.StripPunctuation && (unicode.IsPunct(r) || unicode.IsSymbol(r)):
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lower(language, text string) string {
+	switch language {
+	case "tr", "az":
+		return strings.ToLowerSpecial(unicode.TurkishCase, text)
+	default:
+		return strings.ToLower(text)
+	}
+}
+
+// Tokenize splits each element of a PCollection<string> into words and
+// returns a PCollection<[]string>. A word is a run of letters, digits and
+// marks, which may contain single apostrophes or hyphens, such as "don't"
+// and "state-of-the-art". Since Chinese and Japanese are written without
+// spaces, each of their ideographs and kana is a separate word. Other
+// characters separate words.
+func Tokenize(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("textproc.Tokenize")
+	return beam.ParDo(s, &tokenizeFn{}, col)
+}
+
+type tokenizeFn struct{}
+
+func (f *tokenizeFn) ProcessElement(text string) []string {
+	return tokenize(text)
+}
+
+func tokenize(text string) []string {
+	var ret []string
+	runes := []rune(text)
+	start := -1
+	for i, r := range runes {
+		switch {
+		case isSingleton(r):
+			if start >= 0 {
+				ret = append(ret, string(runes[start:i]))
+				start = -1
+			}
+			ret = append(ret, string(r))
+		case isWord(r):
+			if start < 0 {
+				start = i
+			}
+		case (r == '\'' || r == '’' || r == '-') && start >= 0 && i+1 < len(runes) && isWord(runes[i+1]) && !isSingleton(runes[i+1]):
+			// An inner apostrophe or hyphen.
+		default:
+			if start >= 0 {
+				ret = append(ret, string(runes[start:i]))
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		ret = append(ret, string(runes[start:]))
+	}
+	return ret
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isSingleton(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// NGrams returns the n-grams of each element of a PCollection<[]string>, as
+// a PCollection<[]string> of the tokens of each n-gram joined by spaces.
+// Elements with fewer than n tokens have no n-grams.
+func NGrams(s beam.Scope, col beam.PCollection, n int) beam.PCollection {
+	s = s.Scope("textproc.NGrams")
+
+	if n < 1 {
+		panic(fmt.Sprintf("invalid n-gram size: %v", n))
+	}
+	return beam.ParDo(s, &nGramsFn{N: n}, col)
+}
+
+type nGramsFn struct {
+	N int `json:"n"`
+}
+
+func (f *nGramsFn) ProcessElement(tokens []string) []string {
+	var ret []string
+	for i := 0; i+f.N <= len(tokens); i++ {
+		ret = append(ret, strings.Join(tokens[i:i+f.N], " "))
+	}
+	return ret
+}
+
+// StopWordOptions configure RemoveStopWords.
+type StopWordOptions struct {
+	// Language is the ISO 639-1 code of the built-in stop words, which are
+	// available for "en", "de", "fr" and "es". If empty, only Extra are
+	// removed.
+	Language string
+	// Extra are additional stop words.
+	Extra []string
+}
+
+// RemoveStopWords removes stop words from each element of a
+// PCollection<[]string>. Words are compared case-insensitively, with the
+// case mapping of the language.
+func RemoveStopWords(s beam.Scope, col beam.PCollection, opts StopWordOptions) beam.PCollection {
+	s = s.Scope("textproc.RemoveStopWords")
+
+	if opts.Language != "" {
+		if _, ok := stopWords[opts.Language]; !ok {
+			panic(fmt.Sprintf("no stop words for language %q", opts.Language))
+		}
+	}
+	return beam.ParDo(s, &stopWordsFn{Language: opts.Language, Extra: opts.Extra}, col)
+}
+
+type stopWordsFn struct {
+	Language string   `json:"language,omitempty"`
+	Extra    []string `json:"extra,omitempty"`
+
+	words map[string]bool
+}
+
+func (f *stopWordsFn) Setup() {
+	f.words = make(map[string]bool)
+	for _, w := range strings.Fields(stopWords[f.Language]) {
+		f.words[lower(f.Language, w)] = true
+	}
+	for _, w := range f.Extra {
+		f.words[lower(f.Language, w)] = true
+	}
+}
+
+func (f *stopWordsFn) ProcessElement(tokens []string) []string {
+	ret := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !f.words[lower(f.Language, t)] {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// stopWords are the built-in stop words, by language.
+var stopWords = map[string]string{
+	"en": `a an and are as at be but by for from has have he her his i in is it its
+		me my not of on or our she so that the their them they this to was we were
+		what when which who will with you your`,
+	"de": `aber als am an auch auf aus bei bin bis das dass dem den der des die du
+		ein eine einem einen einer es für hat ich ihr im in ist ja mit nicht noch
+		oder sich sie sind so und von war was wie wir zu zum zur`,
+	"fr": `au aux avec ce ces dans de des du elle en est et il ils je la le les leur
+		lui ma mais me mes moi mon ne nous on ou par pas pour qu que qui sa se ses
+		son sur ta te tes toi ton tu un une vous`,
+	"es": `al como con de del el en es esta este la las le lo los me mi no nos o para
+		pero por que se si su sus te tu un una uno y ya yo`,
+}
@@ -0,0 +1,177 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: textproc.shims.go
+
+package textproc
+
+import (
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*nGramsFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*normalizeFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*stopWordsFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*tokenizeFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*nGramsFn)(nil)).Elem(), wrapMakerNGramsFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*normalizeFn)(nil)).Elem(), wrapMakerNormalizeFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*stopWordsFn)(nil)).Elem(), wrapMakerStopWordsFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*tokenizeFn)(nil)).Elem(), wrapMakerTokenizeFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]string) []string)(nil)).Elem(), funcMakerSliceOfStringГSliceOfString)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string) []string)(nil)).Elem(), funcMakerStringГSliceOfString)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(string) string)(nil)).Elem(), funcMakerStringГString)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+}
+
+func wrapMakerNGramsFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*nGramsFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []string) []string { return dfn.ProcessElement(a0) }),
+	}
+}
+
+func wrapMakerNormalizeFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*normalizeFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 string) string { return dfn.ProcessElement(a0) }),
+	}
+}
+
+func wrapMakerStopWordsFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*stopWordsFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []string) []string { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerTokenizeFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*tokenizeFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 string) []string { return dfn.ProcessElement(a0) }),
+	}
+}
+
+type callerSliceOfStringГSliceOfString struct {
+	fn func([]string) []string
+}
+
+func funcMakerSliceOfStringГSliceOfString(fn interface{}) reflectx.Func {
+	f := fn.(func([]string) []string)
+	return &callerSliceOfStringГSliceOfString{fn: f}
+}
+
+func (c *callerSliceOfStringГSliceOfString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfStringГSliceOfString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfStringГSliceOfString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].([]string))
+	return []interface{}{out0}
+}
+
+func (c *callerSliceOfStringГSliceOfString) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.([]string))
+}
+
+type callerStringГSliceOfString struct {
+	fn func(string) []string
+}
+
+func funcMakerStringГSliceOfString(fn interface{}) reflectx.Func {
+	f := fn.(func(string) []string)
+	return &callerStringГSliceOfString{fn: f}
+}
+
+func (c *callerStringГSliceOfString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringГSliceOfString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringГSliceOfString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string))
+	return []interface{}{out0}
+}
+
+func (c *callerStringГSliceOfString) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(string))
+}
+
+type callerStringГString struct {
+	fn func(string) string
+}
+
+func funcMakerStringГString(fn interface{}) reflectx.Func {
+	f := fn.(func(string) string)
+	return &callerStringГString{fn: f}
+}
+
+func (c *callerStringГString) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerStringГString) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerStringГString) Call(args []interface{}) []interface{} {
+	out0 := c.fn(args[0].(string))
+	return []interface{}{out0}
+}
+
+func (c *callerStringГString) Call1x1(arg0 interface{}) interface{} {
+	return c.fn(arg0.(string))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
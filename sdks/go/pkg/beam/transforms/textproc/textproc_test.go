@@ -0,0 +1,82 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textproc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(join)
+}
+
+func join(tokens []string) string {
+	return strings.Join(tokens, "|")
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		opts NormalizeOptions
+		in   string
+		exp  string
+	}{
+		{NormalizeOptions{}, "  Hello,\t\n World!​ ", "Hello, World!"},
+		{NormalizeOptions{Lowercase: true, StripPunctuation: true}, "Hello, World!", "hello world"},
+		{NormalizeOptions{Language: "tr", Lowercase: true}, "İSTANBUL ILIK", "istanbul ılık"},
+	}
+	for _, test := range tests {
+		fn := &normalizeFn{Language: test.opts.Language, Lowercase: test.opts.Lowercase, StripPunctuation: test.opts.StripPunctuation}
+		if got := fn.ProcessElement(test.in); got != test.exp {
+			t.Errorf("Normalize(%q, %+v) = %q, want %q", test.in, test.opts, got, test.exp)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{"Don't stop-believing, 42 times!", "Don't|stop-believing|42|times"},
+		{"'quoted' -dash- end-", "quoted|dash|end"},
+		{"東京タワーへ行く", "東|京|タ|ワ|ー|へ|行|く"},
+		{"café naïve", "café|naïve"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := join(tokenize(test.in)); got != test.exp {
+			t.Errorf("tokenize(%q) = %q, want %q", test.in, got, test.exp)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	lines := beam.Create(s, "The quick brown fox", "A FOX and the dog")
+	normalized := Normalize(s, lines, NormalizeOptions{Language: "en", Lowercase: true})
+	words := RemoveStopWords(s, Tokenize(s, normalized), StopWordOptions{Language: "en", Extra: []string{"DOG"}})
+	bigrams := NGrams(s, words, 2)
+	passert.Equals(s, beam.ParDo(s, join, bigrams), "quick brown|brown fox", "")
+
+	if err := ptest.Run(p); err != nil {
+		t.Errorf("pipeline failed: %v", err)
+	}
+}
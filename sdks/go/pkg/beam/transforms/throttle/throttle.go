@@ -0,0 +1,83 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package throttle contains a transform that caps the number of elements
+// of each key per window, such as the notifications sent to each user per
+// day.
+package throttle
+
+import (
+	"fmt"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stamp"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=throttle --identifiers=limitFn
+//go:generate go fmt
+
+// PerKey limits the PCollection<KV<K,V>> to at most n values of each key
+// per window. It returns the PCollection<KV<K,V>> of the values within the
+// limit and the PCollection<KV<K,V>> of the excess values, which may be
+// retried or summarized later. For example:
+//
+//    daily := beam.WindowInto(s, window.NewFixedWindows(24*time.Hour), notifications)
+//    send, overflow := throttle.PerKey(s, 3, daily)
+//
+// sends at most 3 notifications per user and day. The earliest values of a
+// key in event time are within the limit, and values with equal timestamps
+// are ordered by their encoding, so retries make the same choice. The
+// values keep their timestamps. The values of a key are buffered until the
+// watermark passes the end of their window, and all values of a key and
+// window must fit in memory.
+func PerKey(s beam.Scope, n int, col beam.PCollection) (beam.PCollection, beam.PCollection) {
+	s = s.Scope("throttle.PerKey")
+
+	if n < 0 {
+		panic(fmt.Sprintf("invalid limit: %v", n))
+	}
+	grouped, v := stamp.GroupByKey(s, col)
+	return beam.ParDo2(s, &limitFn{Value: beam.EncodedType{T: v}, Limit: n}, grouped,
+		beam.TypeDefinition{Var: beam.YType, T: v})
+}
+
+// limitFn emits the earliest values of a key within the limit and the
+// others as overflow.
+type limitFn struct {
+	Value beam.EncodedType `json:"value"`
+	Limit int              `json:"limit"`
+
+	dec beam.ElementDecoder
+}
+
+func (f *limitFn) Setup() {
+	f.dec = beam.NewElementDecoder(f.Value.T)
+}
+
+func (f *limitFn) ProcessElement(key beam.X, values func(*stamp.Value) bool, emit, overflow func(beam.EventTime, beam.X, beam.Y)) error {
+	for i, e := range stamp.Sorted(values) {
+		v, err := stamp.Decode(f.dec, e)
+		if err != nil {
+			return err
+		}
+		if i < f.Limit {
+			emit(e.EventTime(), key, v)
+		} else {
+			overflow(e.EventTime(), key, v)
+		}
+	}
+	return nil
+}
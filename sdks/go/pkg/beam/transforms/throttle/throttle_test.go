@@ -0,0 +1,69 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package throttle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(notificationTime)
+	beam.RegisterFunction(keyNotification)
+	beam.RegisterFunction(formatFn)
+}
+
+// notificationTime returns the timestamp of a "<user>:<hours>" notification.
+func notificationTime(n string) beam.EventTime {
+	h, _ := strconv.Atoi(strings.Split(n, ":")[1])
+	return mtime.FromMilliseconds(int64(h) * 3600000)
+}
+
+func keyNotification(n string) (string, string) {
+	return strings.Split(n, ":")[0], n
+}
+
+func formatFn(t beam.EventTime, user, n string) string {
+	return fmt.Sprintf("%v@%v", n, t.Milliseconds()/3600000)
+}
+
+func TestMain(m *testing.M) {
+	ptest.Main(m)
+}
+
+func TestPerKey(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	col := beam.Create(s, "a:5", "a:1", "a:3", "a:2", "b:4", "a:30")
+	stamped := beam.WithTimestamps(s, notificationTime, col)
+	daily := beam.WindowInto(s, window.NewFixedWindows(24*time.Hour), stamped)
+	send, overflow := PerKey(s, 2, beam.ParDo(s, keyNotification, daily))
+
+	passert.Equals(s, beam.ParDo(s, formatFn, send), "a:1@1", "a:2@2", "b:4@4", "a:30@30")
+	passert.Equals(s, beam.ParDo(s, formatFn, overflow), "a:3@3", "a:5@5")
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+}
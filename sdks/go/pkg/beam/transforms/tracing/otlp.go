@@ -0,0 +1,219 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+)
+
+// The OTLP/HTTP JSON encoding of spans. IDs are hex encoded and times are
+// nanoseconds since the epoch, encoded as strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// Status codes of spans, as defined by OTLP.
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+// exportTimeout is the timeout of an export request.
+const exportTimeout = 30 * time.Second
+
+// exporter exports batches of spans to the OTLP/HTTP endpoint of the
+// options in a background goroutine.
+type exporter struct {
+	opts    Options
+	headers map[string]string
+	batches chan []finishedSpan
+	done    chan struct{}
+}
+
+// newExporter returns a running exporter, with the header values read
+// from the environment and the files of the options.
+func newExporter(opts Options) (*exporter, error) {
+	headers := make(map[string]string)
+	for k, env := range opts.HeaderEnv {
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return nil, errors.Errorf("variable %v of header %v not set", env, k)
+		}
+		headers[k] = v
+	}
+	for k, file := range opts.HeaderFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read header %v", k)
+		}
+		headers[k] = strings.TrimSpace(string(data))
+	}
+	e := &exporter{
+		opts:    opts,
+		headers: headers,
+		batches: make(chan []finishedSpan, maxBatches),
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	for spans := range e.batches {
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		if err := export(ctx, e.opts, e.headers, spans); err != nil {
+			log.Warnf(ctx, "dropped %v spans: %v", len(spans), err)
+		}
+		cancel()
+	}
+}
+
+// send queues the spans to be exported. Tracing is only a debugging aid, so
+// spans that cannot be queued or exported are dropped rather than failing
+// or holding back the bundle.
+func (e *exporter) send(ctx context.Context, spans []finishedSpan) {
+	if len(spans) == 0 {
+		return
+	}
+	select {
+	case e.batches <- spans:
+	default:
+		log.Warnf(ctx, "dropped %v spans: too many spans waiting to be exported", len(spans))
+	}
+}
+
+// close exports the queued spans and stops the exporter.
+func (e *exporter) close() {
+	close(e.batches)
+	<-e.done
+}
+
+// export sends the spans to the OTLP/HTTP endpoint of the options.
+func export(ctx context.Context, opts Options, headers map[string]string, spans []finishedSpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/apache/beam/sdks/go/pkg/beam/transforms/tracing"}}
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.context.TraceID[:]),
+			SpanID:            hex.EncodeToString(s.context.SpanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        attributes(s.attrs),
+			Status:            otlpStatus{Code: statusOK},
+		}
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		if s.err != nil {
+			span.Status = otlpStatus{Code: statusError, Message: s.err.Error()}
+		}
+		scope.Spans = append(scope.Spans, span)
+	}
+	req := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: attributes(map[string]string{"service.name": opts.service()})},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(opts.Endpoint, "/") + "/v1/traces"
+	r, err := http.NewRequest("POST", u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("POST %v: %v: %s", u, resp.Status, body)
+	}
+	return nil
+}
+
+func attributes(attrs map[string]string) []otlpAttribute {
+	var ret []otlpAttribute
+	for k, v := range attrs {
+		ret = append(ret, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
@@ -0,0 +1,218 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+// SpanContext identifies a span of a trace. It is propagated with elements
+// in the W3C traceparent format.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	// Sampled is set if the spans of the trace are recorded.
+	Sampled bool
+}
+
+// ParseSpanContext parses a W3C traceparent, such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseSpanContext(traceparent string) (SpanContext, error) {
+	var ret SpanContext
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return ret, errors.Errorf("invalid traceparent %q", traceparent)
+	}
+	var flags [1]byte
+	if err := decodeHex(ret.TraceID[:], parts[1]); err != nil {
+		return ret, errors.Wrapf(err, "invalid trace ID in traceparent %q", traceparent)
+	}
+	if err := decodeHex(ret.SpanID[:], parts[2]); err != nil {
+		return ret, errors.Wrapf(err, "invalid span ID in traceparent %q", traceparent)
+	}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return ret, errors.Wrapf(err, "invalid flags in traceparent %q", traceparent)
+	}
+	if !ret.IsValid() {
+		return ret, errors.Errorf("invalid traceparent %q: zero ID", traceparent)
+	}
+	ret.Sampled = flags[0]&1 != 0
+	return ret, nil
+}
+
+func decodeHex(dst []byte, s string) error {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return errors.Errorf("want %v lowercase hex digits", 2*len(dst))
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// IsValid returns true if the trace and span IDs are set.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// String returns the context in the W3C traceparent format.
+func (c SpanContext) String() string {
+	flags := "00"
+	if c.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(c.TraceID[:]) + "-" + hex.EncodeToString(c.SpanID[:]) + "-" + flags
+}
+
+// Kinds of spans, as defined by OTLP.
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// Span is an operation of a trace, which is recorded when it ends.
+type Span struct {
+	sc     SpanContext
+	parent [8]byte
+	name   string
+	kind   int
+	start  time.Time
+	attrs  map[string]string
+	rec    *recorder
+	ended  bool
+}
+
+// Context returns the span context of the span.
+func (s *Span) Context() SpanContext {
+	return s.sc
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *Span) SetAttribute(key, value string) {
+	if s.attrs == nil {
+		s.attrs = make(map[string]string)
+	}
+	s.attrs[key] = value
+}
+
+// End ends the span, with the error of the operation if any. The span is
+// recorded if its trace is sampled and it was started within a traced step.
+func (s *Span) End(err error) {
+	if s.ended {
+		return
+	}
+	s.ended = true
+	if s.rec == nil || !s.sc.Sampled {
+		return
+	}
+	s.rec.add(finishedSpan{
+		context: s.sc,
+		parent:  s.parent,
+		name:    s.name,
+		kind:    s.kind,
+		start:   s.start,
+		end:     time.Now(),
+		attrs:   s.attrs,
+		err:     err,
+	})
+}
+
+type spanKey struct{}
+
+// StartSpan starts a span of the given name, which is a child of the span of
+// ctx. It is meant to trace calls to external services from functions
+// applied with a Tracer, which are given the context of their span:
+//
+//    ctx, span := tracing.StartSpan(ctx, "bigtable.ReadRow")
+//    row, err := table.ReadRow(ctx, key)
+//    span.End(err)
+//
+// If ctx has no span, the span is not recorded.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+	if parent == nil {
+		return ctx, &Span{name: name}
+	}
+	span := newSpan(parent.rec, parent.sc, name, kindClient)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the span context of the span of ctx, if any. It can
+// be used to propagate the trace to external services.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	span, ok := ctx.Value(spanKey{}).(*Span)
+	if !ok {
+		return SpanContext{}, false
+	}
+	return span.sc, true
+}
+
+// newSpan starts a child span of the parent context, or the root span of a
+// new trace if the parent context is not valid.
+func newSpan(rec *recorder, parent SpanContext, name string, kind int) *Span {
+	span := &Span{name: name, kind: kind, start: time.Now(), rec: rec}
+	if parent.IsValid() {
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.sc.TraceID[:])
+	}
+	rand.Read(span.sc.SpanID[:])
+	return span
+}
+
+type finishedSpan struct {
+	context    SpanContext
+	parent     [8]byte
+	name       string
+	kind       int
+	start, end time.Time
+	attrs      map[string]string
+	err        error
+}
+
+// recorder holds the finished spans of a bundle until they are exported.
+type recorder struct {
+	mu    sync.Mutex
+	spans []finishedSpan
+}
+
+func (r *recorder) add(span finishedSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.spans)
+}
+
+// take returns the recorded spans and resets the recorder.
+func (r *recorder) take() []finishedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := r.spans
+	r.spans = nil
+	return ret
+}
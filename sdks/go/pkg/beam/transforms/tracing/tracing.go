@@ -0,0 +1,294 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing traces individual elements through the steps of a
+// pipeline with OpenTelemetry spans, to debug the end-to-end latency of
+// events. Traced elements carry the W3C trace context of their event as the
+// key of a KV, and each traced step records a span around the processing of
+// each element. For example:
+//
+//    t := tracing.New(tracing.Options{Endpoint: "http://collector:4318"})
+//    orders := t.StartFrom(s, traceparent, messages)
+//    parsed := t.ParDo(s, "ParseOrder", parseOrder, orders)
+//    priced := t.ParDo(s, "Price", price, parsed)
+//    ...
+//    totals := tracing.Values(s, priced)
+//
+// traces each message from its event time, continuing the trace of its
+// producer if traceparent returns one. Spans are exported to an OTLP/HTTP
+// collector in the background, in batches of up to maxSpans spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+	"github.com/apache/beam/sdks/go/pkg/beam/transforms/internal/stepfn"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=tracing --identifiers=startFn,parDoFn
+//go:generate go fmt
+
+// Options configure a Tracer.
+type Options struct {
+	// Endpoint is the base URL of the OTLP/HTTP collector that spans are
+	// exported to, such as "http://localhost:4318". If empty, trace contexts
+	// are propagated but spans are not exported.
+	Endpoint string `json:"endpoint,omitempty"`
+	// HeaderEnv names, by header, the environment variables of the workers
+	// that hold the values of headers added to the export requests, such as
+	// for authentication. The values are not part of the pipeline.
+	HeaderEnv map[string]string `json:"header_env,omitempty"`
+	// HeaderFiles names, by header, the files of the workers that hold the
+	// values of headers added to the export requests.
+	HeaderFiles map[string]string `json:"header_files,omitempty"`
+	// Service is the service name of the spans. If empty, "beam" is used.
+	Service string `json:"service,omitempty"`
+	// SampleRate is the fraction of new traces that are sampled. If zero,
+	// all traces are sampled. Traces continued from elements keep their
+	// sampling decision.
+	SampleRate float64 `json:"sample_rate,omitempty"`
+}
+
+func (o Options) service() string {
+	if o.Service == "" {
+		return "beam"
+	}
+	return o.Service
+}
+
+// maxSpans is the number of spans a bundle records before they are
+// exported.
+const maxSpans = 512
+
+// maxBatches is the number of batches of spans that wait to be exported
+// by a DoFn instance. Further batches are dropped, so that a slow collector
+// does not hold back the pipeline.
+const maxBatches = 16
+
+// Tracer adds tracing to steps of a pipeline.
+type Tracer struct {
+	opts Options
+}
+
+// New returns a new tracer.
+func New(opts Options) *Tracer {
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		panic(fmt.Sprintf("invalid sample rate: %v", opts.SampleRate))
+	}
+	return &Tracer{opts: opts}
+}
+
+// Start starts a trace for each element of the PCollection<V>. It returns
+// a PCollection<KV<string,V>> of the elements keyed by their traceparent.
+// The root span of each trace covers the time from the event time of the
+// element until it is processed.
+func (t *Tracer) Start(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("tracing.Start")
+	return beam.ParDo(s, &startFn{Options: t.opts}, col)
+}
+
+// StartFrom is like Start, but continues the trace of an element if
+// traceparent, of the form V -> string, returns a valid W3C traceparent for
+// it. This is typically an attribute of the message an element was read
+// from.
+func (t *Tracer) StartFrom(s beam.Scope, traceparent interface{}, col beam.PCollection) beam.PCollection {
+	s = s.Scope("tracing.StartFrom")
+
+	ft := reflect.TypeOf(traceparent)
+	if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 || ft.Out(0) != reflectx.String {
+		panic(fmt.Sprintf("%v is not of the form V -> string", ft))
+	}
+	if in := col.Type().Type(); !in.AssignableTo(ft.In(0)) {
+		panic(fmt.Sprintf("%v cannot be applied to PCollection<%v>", ft, in))
+	}
+	return beam.ParDo(s, &startFn{
+		Options:     t.opts,
+		Traceparent: &beam.EncodedFunc{Fn: reflectx.MakeFunc(traceparent)},
+	}, col)
+}
+
+// ParDo applies fn to the values of the traced PCollection<KV<string,A>> as
+// the named step, recording a span around each call. fn is of the form
+// A -> B or A -> (B, error), and may take a context.Context first, which
+// holds the span for StartSpan. It returns the traced PCollection<KV<string,B>>
+// of the outputs, which are keyed by the span of the step so that spans of
+// later steps are its children. Errors returned by fn are recorded with the
+// span and fail the bundle.
+func (t *Tracer) ParDo(s beam.Scope, name string, fn interface{}, col beam.PCollection) beam.PCollection {
+	s = s.Scope(name)
+
+	if !typex.IsKV(col.Type()) || col.Type().Components()[0].Type() != reflectx.String {
+		panic(fmt.Sprintf("%v is not a traced PCollection<KV<string,A>>", col.Type()))
+	}
+	out, withContext := stepfn.Check(fn, col.Type().Components()[1].Type(), true)
+	return beam.ParDo(s, &parDoFn{
+		Options:     t.opts,
+		Step:        name,
+		Fn:          beam.EncodedFunc{Fn: reflectx.MakeFunc(fn)},
+		WithContext: withContext,
+	}, col, beam.TypeDefinition{Var: beam.YType, T: out})
+}
+
+// Values returns the PCollection<V> of the values of the traced
+// PCollection<KV<string,V>>, which ends their tracing.
+func Values(s beam.Scope, col beam.PCollection) beam.PCollection {
+	s = s.Scope("tracing.Values")
+	return beam.DropKey(s, col)
+}
+
+// tracedFn records the spans of a bundle and exports them.
+type tracedFn struct {
+	rec *recorder
+	exp *exporter
+}
+
+func (f *tracedFn) setup(opts Options) error {
+	if opts.Endpoint == "" {
+		return nil
+	}
+	exp, err := newExporter(opts)
+	if err != nil {
+		return err
+	}
+	f.exp = exp
+	return nil
+}
+
+func (f *tracedFn) record(ctx context.Context) {
+	if f.rec == nil {
+		f.rec = &recorder{}
+	}
+	if f.rec.len() >= maxSpans {
+		f.flush(ctx)
+	}
+}
+
+// flush hands the recorded spans to the exporter.
+func (f *tracedFn) flush(ctx context.Context) {
+	if f.rec == nil {
+		return
+	}
+	spans := f.rec.take()
+	if f.exp != nil {
+		f.exp.send(ctx, spans)
+	}
+}
+
+// teardown exports the spans that wait to be exported.
+func (f *tracedFn) teardown() {
+	if f.exp != nil {
+		f.exp.close()
+	}
+}
+
+// startFn starts the traces of elements.
+type startFn struct {
+	Options     Options           `json:"options"`
+	Traceparent *beam.EncodedFunc `json:"traceparent,omitempty"`
+
+	tracedFn
+	traceparent reflectx.Func1x1
+}
+
+func (f *startFn) Setup() error {
+	if f.Traceparent != nil {
+		f.traceparent = reflectx.ToFunc1x1(f.Traceparent.Fn)
+	}
+	return f.setup(f.Options)
+}
+
+func (f *startFn) ProcessElement(ctx context.Context, et beam.EventTime, elm beam.X) (string, beam.X) {
+	f.record(ctx)
+
+	var parent SpanContext
+	if f.traceparent != nil {
+		parent, _ = ParseSpanContext(f.traceparent.Call1x1(elm).(string))
+	}
+	span := newSpan(f.rec, parent, "tracing.Start", kindInternal)
+	if !parent.IsValid() {
+		span.sc.Sampled = f.Options.SampleRate == 0 || rand.Float64() < f.Options.SampleRate
+	}
+	eventTime := time.Unix(0, et.Milliseconds()*int64(time.Millisecond))
+	if eventTime.Before(span.start) {
+		span.start = eventTime // include the latency before the pipeline
+	}
+	span.SetAttribute("beam.event_time", eventTime.UTC().Format(time.RFC3339Nano))
+	span.End(nil)
+	return span.sc.String(), elm
+}
+
+func (f *startFn) FinishBundle(ctx context.Context) {
+	f.flush(ctx)
+}
+
+func (f *startFn) Teardown() {
+	f.teardown()
+}
+
+// parDoFn applies Fn within a span.
+type parDoFn struct {
+	Options     Options          `json:"options"`
+	Step        string           `json:"step"`
+	Fn          beam.EncodedFunc `json:"fn"`
+	WithContext bool             `json:"with_context,omitempty"`
+
+	tracedFn
+	fn reflectx.Func
+}
+
+func (f *parDoFn) Setup() error {
+	f.fn = f.Fn.Fn
+	return f.setup(f.Options)
+}
+
+func (f *parDoFn) ProcessElement(ctx context.Context, traceparent string, elm beam.X) (string, beam.Y, error) {
+	f.record(ctx)
+
+	parent, _ := ParseSpanContext(traceparent)
+	span := newSpan(f.rec, parent, f.Step, kindInternal)
+	if !parent.IsValid() {
+		span.sc.Sampled = false // untraced elements stay untraced
+	}
+	span.SetAttribute("beam.step", f.Step)
+
+	args := []interface{}{elm}
+	if f.WithContext {
+		args = []interface{}{context.WithValue(ctx, spanKey{}, span), elm}
+	}
+	out, err := stepfn.Call(f.fn, args...)
+	if err != nil {
+		span.End(err)
+		f.flush(ctx)
+		return "", nil, err
+	}
+	span.End(nil)
+	return span.sc.String(), out, nil
+}
+
+func (f *parDoFn) FinishBundle(ctx context.Context) {
+	f.flush(ctx)
+}
+
+func (f *parDoFn) Teardown() {
+	f.teardown()
+}
@@ -0,0 +1,200 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: tracing.shims.go
+
+package tracing
+
+import (
+	"reflect"
+
+	// Library imports
+	"context"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*parDoFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*startFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*parDoFn)(nil)).Elem(), wrapMakerParDoFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*startFn)(nil)).Elem(), wrapMakerStartFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, mtime.Time, typex.X) (string, typex.X))(nil)).Elem(), funcMakerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context, string, typex.X) (string, typex.Y, error))(nil)).Elem(), funcMakerContext۰ContextStringTypex۰XГStringTypex۰YError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(context.Context))(nil)).Elem(), funcMakerContext۰ContextГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+}
+
+func wrapMakerParDoFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*parDoFn)
+	return map[string]reflectx.Func{
+		"FinishBundle": reflectx.MakeFunc(func(a0 context.Context) { dfn.FinishBundle(a0) }),
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 string, a2 typex.X) (string, typex.Y, error) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup":    reflectx.MakeFunc(func() error { return dfn.Setup() }),
+		"Teardown": reflectx.MakeFunc(func() { dfn.Teardown() }),
+	}
+}
+
+func wrapMakerStartFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*startFn)
+	return map[string]reflectx.Func{
+		"FinishBundle": reflectx.MakeFunc(func(a0 context.Context) { dfn.FinishBundle(a0) }),
+		"ProcessElement": reflectx.MakeFunc(func(a0 context.Context, a1 mtime.Time, a2 typex.X) (string, typex.X) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup":    reflectx.MakeFunc(func() error { return dfn.Setup() }),
+		"Teardown": reflectx.MakeFunc(func() { dfn.Teardown() }),
+	}
+}
+
+type callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X struct {
+	fn func(context.Context, mtime.Time, typex.X) (string, typex.X)
+}
+
+func funcMakerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, mtime.Time, typex.X) (string, typex.X))
+	return &callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X{fn: f}
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(context.Context), args[1].(mtime.Time), args[2].(typex.X))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerContext۰ContextMtime۰TimeTypex۰XГStringTypex۰X) Call3x2(arg0, arg1, arg2 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(context.Context), arg1.(mtime.Time), arg2.(typex.X))
+}
+
+type callerContext۰ContextStringTypex۰XГStringTypex۰YError struct {
+	fn func(context.Context, string, typex.X) (string, typex.Y, error)
+}
+
+func funcMakerContext۰ContextStringTypex۰XГStringTypex۰YError(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context, string, typex.X) (string, typex.Y, error))
+	return &callerContext۰ContextStringTypex۰XГStringTypex۰YError{fn: f}
+}
+
+func (c *callerContext۰ContextStringTypex۰XГStringTypex۰YError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextStringTypex۰XГStringTypex۰YError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextStringTypex۰XГStringTypex۰YError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(context.Context), args[1].(string), args[2].(typex.X))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerContext۰ContextStringTypex۰XГStringTypex۰YError) Call3x3(arg0, arg1, arg2 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(context.Context), arg1.(string), arg2.(typex.X))
+}
+
+type callerContext۰ContextГ struct {
+	fn func(context.Context)
+}
+
+func funcMakerContext۰ContextГ(fn interface{}) reflectx.Func {
+	f := fn.(func(context.Context))
+	return &callerContext۰ContextГ{fn: f}
+}
+
+func (c *callerContext۰ContextГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerContext۰ContextГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerContext۰ContextГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(context.Context))
+	return []interface{}{}
+}
+
+func (c *callerContext۰ContextГ) Call1x0(arg0 interface{}) {
+	c.fn(arg0.(context.Context))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
@@ -0,0 +1,171 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func init() {
+	beam.RegisterFunction(producer)
+	beam.RegisterFunction(lookup)
+}
+
+const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// producer returns the trace of the producer of "a".
+func producer(s string) string {
+	if s == "a" {
+		return parent
+	}
+	return ""
+}
+
+func lookup(ctx context.Context, s string) (int, error) {
+	_, span := StartSpan(ctx, "lookup")
+	span.SetAttribute("key", s)
+	span.End(nil)
+	return len(s), nil
+}
+
+func TestParseSpanContext(t *testing.T) {
+	sc, err := ParseSpanContext(parent)
+	if err != nil {
+		t.Fatalf("ParseSpanContext(%v) failed: %v", parent, err)
+	}
+	if !sc.Sampled || sc.String() != parent {
+		t.Errorf("ParseSpanContext(%v) = %v, sampled %v", parent, sc, sc.Sampled)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for _, tp := range invalid {
+		if _, err := ParseSpanContext(tp); err == nil {
+			t.Errorf("ParseSpanContext(%q) succeeded, want error", tp)
+		}
+	}
+}
+
+func TestTracer(t *testing.T) {
+	var mu sync.Mutex
+	var spans []otlpSpan
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer token" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	os.Setenv("TRACING_TEST_AUTHORIZATION", "Bearer token")
+	defer os.Unsetenv("TRACING_TEST_AUTHORIZATION")
+
+	p, s := beam.NewPipelineWithRoot()
+	tr := New(Options{Endpoint: srv.URL, HeaderEnv: map[string]string{"Authorization": "TRACING_TEST_AUTHORIZATION"}})
+	traced := tr.StartFrom(s, producer, beam.Create(s, "a", "bb"))
+	out := tr.ParDo(s, "Lookup", lookup, traced)
+	passert.Equals(s, Values(s, out), 1, 2)
+
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+
+	byName := make(map[string][]otlpSpan)
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	if len(spans) != 6 || len(byName["tracing.Start"]) != 2 || len(byName["Lookup"]) != 2 || len(byName["lookup"]) != 2 {
+		t.Fatalf("exported spans %v, want a tracing.Start, Lookup and lookup span per element", byName)
+	}
+	ids := make(map[string]otlpSpan)
+	for _, span := range spans {
+		ids[span.SpanID] = span
+	}
+	for _, span := range byName["lookup"] {
+		step, ok := ids[span.ParentSpanID]
+		if !ok || step.Name != "Lookup" {
+			t.Errorf("parent of lookup span is %v, want Lookup span", step)
+			continue
+		}
+		start, ok := ids[step.ParentSpanID]
+		if !ok || start.Name != "tracing.Start" || start.TraceID != span.TraceID {
+			t.Errorf("parent of Lookup span is %v, want tracing.Start span of the same trace", start)
+		}
+	}
+	continued := 0
+	for _, span := range byName["tracing.Start"] {
+		if strings.HasPrefix(parent[3:], span.TraceID) && span.ParentSpanID == "00f067aa0ba902b7" {
+			continued++
+		}
+	}
+	if continued != 1 {
+		t.Errorf("%v traces continued from the producer, want 1", continued)
+	}
+}
+
+// TestExporterSend verifies that queueing spans does not wait for a slow
+// collector, and that the queued spans are exported when it is closed.
+func TestExporterSend(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		mu.Lock()
+		requests++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	e, err := newExporter(Options{Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxBatches+5; i++ {
+		e.send(context.Background(), []finishedSpan{{name: "span"}})
+	}
+	close(release)
+	e.close()
+
+	// One batch may be taken by the exporter before the queue fills up.
+	if requests < maxBatches || requests > maxBatches+1 {
+		t.Errorf("exported %v batches, want %v or %v", requests, maxBatches, maxBatches+1)
+	}
+}
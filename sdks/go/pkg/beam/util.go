@@ -16,7 +16,7 @@
 package beam
 
 //go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
-//go:generate starcgen --package=beam --identifiers=addFixedKeyFn,dropKeyFn,dropValueFn,swapKVFn,explodeFn,jsonDec,jsonEnc,protoEnc,protoDec,makePartitionFn,createFn
+//go:generate starcgen --package=beam --identifiers=addFixedKeyFn,dropKeyFn,dropValueFn,swapKVFn,explodeFn,jsonDec,jsonEnc,protoEnc,protoDec,makePartitionFn,createFn,mapValuesFn,flatMapValuesFn,withTimestampsFn,boundedTimestampsFn,materializeFn,materializeKVFn,materializeByKeyFn,restoreFn,restoreKVFn,restoreByKeyFn
 //go:generate go fmt
 
 // We have some freedom to create various utilities, users can use depending on
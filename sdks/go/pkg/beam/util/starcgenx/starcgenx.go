@@ -499,14 +499,11 @@ func (e *Extractor) makeEmitter(sig *types.Signature) (shimx.Emitter, bool) {
 		emt.Time = false
 		emt.Val = e.varString(p.At(0))
 	case 2:
-		// TODO(rebo): Fix this when imports are resolved.
-		// This is the tricky one... Need to verify what happens with aliases.
-		// And get a candle to compare this against somehwere. isEventTime(p.At(0)) maybe.
-		// if p.At(0) == typex.EventTimeType {
-		// 	emt.Time = true
-		// } else {
-		emt.Key = e.varString(p.At(0))
-		//}
+		if isEventTime(p.At(0).Type()) {
+			emt.Time = true
+		} else {
+			emt.Key = e.varString(p.At(0))
+		}
 		emt.Val = e.varString(p.At(1))
 	case 3:
 		// If there's 3, the first one must be typex.EventTime.
@@ -551,14 +548,11 @@ func (e *Extractor) makeInput(sig *types.Signature) (shimx.Input, bool) {
 		itr.Time = false
 		itr.Val = e.deref(p.At(0))
 	case 2:
-		// TODO(rebo): Fix this when imports are resolved.
-		// This is the tricky one... Need to verify what happens with aliases.
-		// And get a candle to compare this against somehwere. isEventTime(p.At(0)) maybe.
-		// if p.At(0) == typex.EventTimeType {
-		// 	itr.Time = true
-		// } else {
-		itr.Key = e.deref(p.At(0))
-		//}
+		if isEventTime(p.At(0).Type().(*types.Pointer).Elem()) {
+			itr.Time = true
+		} else {
+			itr.Key = e.deref(p.At(0))
+		}
 		itr.Val = e.deref(p.At(1))
 	case 3:
 		// If there's 3, the first one must be typex.EventTime.
@@ -576,6 +570,19 @@ func (e *Extractor) makeInput(sig *types.Signature) (shimx.Input, bool) {
 	return itr, true
 }
 
+// isEventTime reports whether t is typex.EventTime. As beam.EventTime and
+// typex.EventTime are aliases, the type checker resolves them to mtime.Time.
+func isEventTime(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return false
+	}
+	return n.Obj().Name() == "Time" && n.Obj().Pkg().Path() == mtimePkg
+}
+
+// mtimePkg is the import path of the package of typex.EventTime.
+const mtimePkg = "github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+
 // deref returns the string identifier for the element type of a pointer var.
 // deref panics if the var type is not a pointer.
 func (e *Extractor) deref(v *types.Var) string {
@@ -62,6 +62,10 @@ func TestExtractor(t *testing.T) {
 			expected: []string{"runtime.RegisterType(reflect.TypeOf((*io.Reader)(nil)).Elem())"},
 			excluded: []string{"runtime.RegisterType(reflect.TypeOf((*myType)(nil)).Elem())", "runtime.RegisterType(reflect.TypeOf((*error)(nil)).Elem())", "runtime.RegisterType(reflect.TypeOf((*myError)(nil)).Elem())"},
 		},
+		{name: "eventtime", files: []string{eventtime}, pkg: "eventtime", imports: []string{"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"},
+			expected: []string{"runtime.RegisterFunction(stampFn)", "funcMakerStringEmitETStringГ", "emitMakerETString", "iterMakerETString"},
+			excluded: []string{"emitMakerMtime۰TimeString", "iterMakerMtime۰TimeString"},
+		},
 		{name: "vars", files: []string{vars}, pkg: "vars", imports: []string{"strings"},
 			excluded: []string{"runtime.RegisterFunction(strings.MyTitle)", "runtime.RegisterFunction(anonFunction)"},
 		},
@@ -199,6 +203,18 @@ var myTitle = strings.Title
 var anonFunction = func(int) int {return 0}
 `
 
+const eventtime = `
+package eventtime
+
+import "github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+
+func stampFn(s string, emit func(mtime.Time, string)) {
+	emit(mtime.Now(), s)
+}
+
+func readFn(iter func(*mtime.Time, *string) bool) {}
+`
+
 const emits = `
 package emits
 
@@ -34,11 +34,15 @@ import (
 func init() {
 	runtime.RegisterFunction(discardFn)
 	runtime.RegisterType(reflect.TypeOf((*context.Context)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*dumpFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*dumpKVFn)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*headFn)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*headKVFn)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*printFn)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*printGBKFn)(nil)).Elem())
 	runtime.RegisterType(reflect.TypeOf((*printKVFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*dumpFn)(nil)).Elem(), wrapMakerDumpFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*dumpKVFn)(nil)).Elem(), wrapMakerDumpKVFn)
 	reflectx.RegisterStructWrapper(reflect.TypeOf((*headFn)(nil)).Elem(), wrapMakerHeadFn)
 	reflectx.RegisterStructWrapper(reflect.TypeOf((*headKVFn)(nil)).Elem(), wrapMakerHeadKVFn)
 	reflectx.RegisterStructWrapper(reflect.TypeOf((*printFn)(nil)).Elem(), wrapMakerPrintFn)
@@ -50,6 +54,9 @@ func init() {
 	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(*typex.T) bool, func(typex.T)))(nil)).Elem(), funcMakerSliceOfByteIterTypex۰TEmitTypex۰TГ)
 	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(*typex.X, *typex.Y) bool, func(typex.X, typex.Y)))(nil)).Elem(), funcMakerSliceOfByteIterTypex۰XTypex۰YEmitTypex۰XTypex۰YГ)
 	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T))(nil)).Elem(), funcMakerTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T) (string, error))(nil)).Elem(), funcMakerTypex۰TГStringError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.X, typex.Y) (string, error))(nil)).Elem(), funcMakerTypex۰XTypex۰YГStringError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
 	exec.RegisterEmitter(reflect.TypeOf((*func(typex.T))(nil)).Elem(), emitMakerTypex۰T)
 	exec.RegisterEmitter(reflect.TypeOf((*func(typex.X, typex.Y))(nil)).Elem(), emitMakerTypex۰XTypex۰Y)
 	exec.RegisterInput(reflect.TypeOf((*func(*typex.T) bool)(nil)).Elem(), iterMakerTypex۰T)
@@ -57,6 +64,22 @@ func init() {
 	exec.RegisterInput(reflect.TypeOf((*func(*typex.Y) bool)(nil)).Elem(), iterMakerTypex۰Y)
 }
 
+func wrapMakerDumpFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*dumpFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.T) (string, error) { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerDumpKVFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*dumpKVFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.X, a1 typex.Y) (string, error) { return dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
 func wrapMakerHeadFn(fn interface{}) map[string]reflectx.Func {
 	dfn := fn.(*headFn)
 	return map[string]reflectx.Func{
@@ -254,6 +277,84 @@ func (c *callerTypex۰TГ) Call1x0(arg0 interface{}) {
 	c.fn(arg0.(typex.T))
 }
 
+type callerTypex۰TГStringError struct {
+	fn func(typex.T) (string, error)
+}
+
+func funcMakerTypex۰TГStringError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.T) (string, error))
+	return &callerTypex۰TГStringError{fn: f}
+}
+
+func (c *callerTypex۰TГStringError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰TГStringError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰TГStringError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.T))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰TГStringError) Call1x2(arg0 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.T))
+}
+
+type callerTypex۰XTypex۰YГStringError struct {
+	fn func(typex.X, typex.Y) (string, error)
+}
+
+func funcMakerTypex۰XTypex۰YГStringError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.X, typex.Y) (string, error))
+	return &callerTypex۰XTypex۰YГStringError{fn: f}
+}
+
+func (c *callerTypex۰XTypex۰YГStringError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГStringError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰XTypex۰YГStringError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.X), args[1].(typex.Y))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰XTypex۰YГStringError) Call2x2(arg0, arg1 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.X), arg1.(typex.Y))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
 type emitNative struct {
 	n  exec.ElementProcessor
 	fn interface{}
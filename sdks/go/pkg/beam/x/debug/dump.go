@@ -0,0 +1,239 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/avroio"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/filesystem"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/textio"
+)
+
+// dump enables DumpPCollection. It is off by default, so dumps can be left
+// in a pipeline and turned on when running it.
+var dump = flag.Bool("debug_dump", false, "Write the PCollections given to debug.DumpPCollection (optional).")
+
+// Format encodes and writes the elements of a dump.
+type Format interface {
+	// Encode encodes an element as a line.
+	Encode(elm interface{}) (string, error)
+	// Write writes the PCollection<string> of encoded elements of type t
+	// to files named by the path. The elements of a PCollection<KV<K,V>>
+	// are of a struct type with Key and Value fields of types K and V.
+	Write(s beam.Scope, path string, t reflect.Type, lines beam.PCollection)
+}
+
+var formats = make(map[string]Format)
+
+// RegisterFormat registers a dump format under the given name. The "json",
+// "text" and "avro" formats are registered by default.
+func RegisterFormat(name string, f Format) {
+	if _, ok := formats[name]; ok {
+		panic(fmt.Sprintf("format %v already registered", name))
+	}
+	formats[name] = f
+}
+
+func init() {
+	RegisterFormat("json", jsonFormat{})
+	RegisterFormat("text", textFormat{})
+	RegisterFormat("avro", avroFormat{})
+}
+
+// DumpPCollection writes the elements of the PCollection to files named by
+// the path in the given format, if the --debug_dump flag is set. For
+// example:
+//
+//	debug.DumpPCollection(s, "gs://bucket/debug/orders", "json", orders)
+//
+// writes the orders as JSON lines to files such as
+// orders-00000-of-00004.json. The "json" format uses the JSON encoding of
+// the elements, and "text" formats them with fmt.Sprint. The "avro" format
+// writes a single Avro file with a schema derived from the element type,
+// which must be a struct of strings, bools, integers, floats and such
+// structs. Elements of a PCollection<KV<K,V>> are dumped as key-value pairs,
+// with an Avro schema derived from K and V. Windowed elements are dumped
+// together.
+func DumpPCollection(s beam.Scope, path, format string, col beam.PCollection) {
+	if !*dump {
+		return
+	}
+	s = s.Scope("debug.DumpPCollection")
+
+	filesystem.ValidateScheme(path)
+	f, ok := formats[format]
+	if !ok {
+		panic(fmt.Sprintf("format %v not registered", format))
+	}
+
+	var lines beam.PCollection
+	var t reflect.Type
+	switch {
+	case typex.IsKV(col.Type()):
+		lines = beam.ParDo(s, &dumpKVFn{Format: format}, col)
+		t = kvType(col.Type().Components()[0].Type(), col.Type().Components()[1].Type())
+	case typex.IsCoGBK(col.Type()):
+		panic(fmt.Sprintf("cannot dump grouped PCollection %v", col))
+	default:
+		lines = beam.ParDo(s, &dumpFn{Format: format}, col)
+		t = col.Type().Type()
+	}
+	f.Write(s, path, t, beam.WindowInto(s, window.NewGlobalWindows(), lines))
+}
+
+type dumpFn struct {
+	Format string `json:"format"`
+
+	f Format
+}
+
+func (f *dumpFn) Setup() {
+	f.f = formats[f.Format]
+}
+
+func (f *dumpFn) ProcessElement(t beam.T) (string, error) {
+	return f.f.Encode(t)
+}
+
+type dumpKVFn struct {
+	Format string `json:"format"`
+
+	f Format
+}
+
+func (f *dumpKVFn) Setup() {
+	f.f = formats[f.Format]
+}
+
+func (f *dumpKVFn) ProcessElement(x beam.X, y beam.Y) (string, error) {
+	return f.f.Encode(kvPair{Key: x, Value: y})
+}
+
+// kvPair is a dumped key-value pair.
+type kvPair struct {
+	Key   interface{} `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+func (p kvPair) String() string {
+	return fmt.Sprintf("(%v,%v)", p.Key, p.Value)
+}
+
+// kvType returns the struct type of the key-value pairs of KV<K,V>, which
+// encodes as kvPair does.
+func kvType(k, v reflect.Type) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: k, Tag: `json:"key"`},
+		{Name: "Value", Type: v, Tag: `json:"value"`},
+	})
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Encode(elm interface{}) (string, error) {
+	data, err := json.Marshal(elm)
+	return string(data), err
+}
+
+func (jsonFormat) Write(s beam.Scope, path string, _ reflect.Type, lines beam.PCollection) {
+	textio.WriteSharded(s, path, ".json", 0, lines)
+}
+
+type textFormat struct{}
+
+func (textFormat) Encode(elm interface{}) (string, error) {
+	return fmt.Sprint(elm), nil
+}
+
+func (textFormat) Write(s beam.Scope, path string, _ reflect.Type, lines beam.PCollection) {
+	textio.WriteSharded(s, path, ".txt", 0, lines)
+}
+
+// avroFormat encodes elements as JSON, which avroio converts to Avro.
+type avroFormat struct {
+	jsonFormat
+}
+
+func (avroFormat) Write(s beam.Scope, path string, t reflect.Type, lines beam.PCollection) {
+	avroio.Write(s, path+".avro", avroSchema(t), lines)
+}
+
+// avroSchema returns the Avro schema of the records of the struct type t.
+// Key-value pairs, of unnamed struct types, are records named KV.
+func avroSchema(t reflect.Type) string {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cannot derive Avro schema of non-struct type %v", t))
+	}
+	return avroRecord(t, make(map[reflect.Type]string))
+}
+
+// avroRecord returns the Avro schema of the struct type t. Records that
+// are already defined are referred to by name.
+func avroRecord(t reflect.Type, defined map[reflect.Type]string) string {
+	if name, ok := defined[t]; ok {
+		return fmt.Sprintf("%q", name)
+	}
+	name := t.Name()
+	if name == "" {
+		name = "KV"
+	}
+	defined[t] = name
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields = append(fields, fmt.Sprintf(`{"name":%q,"type":%v}`, name, avroType(t, field, defined)))
+	}
+	return fmt.Sprintf(`{"type":"record","name":%q,"fields":[%v]}`, name, strings.Join(fields, ","))
+}
+
+func avroType(t reflect.Type, field reflect.StructField, defined map[reflect.Type]string) string {
+	switch field.Type.Kind() {
+	case reflect.String:
+		return `"string"`
+	case reflect.Bool:
+		return `"boolean"`
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return `"int"`
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return `"long"`
+	case reflect.Float32:
+		return `"float"`
+	case reflect.Float64:
+		return `"double"`
+	case reflect.Struct:
+		return avroRecord(field.Type, defined)
+	default:
+		panic(fmt.Sprintf("cannot derive Avro type of field %v of type %v in %v", field.Name, field.Type, t))
+	}
+}
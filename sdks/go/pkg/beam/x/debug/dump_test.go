@@ -0,0 +1,181 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	_ "github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/local"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+	"github.com/linkedin/goavro"
+)
+
+func init() {
+	beam.RegisterType(reflect.TypeOf((*order)(nil)).Elem())
+	beam.RegisterFunction(keyByCustomer)
+}
+
+type item struct {
+	Name  string `json:"name"`
+	Price float64
+}
+
+type order struct {
+	ID   int64 `json:"id"`
+	Item item  `json:"item"`
+}
+
+func keyByCustomer(o order) (string, order) {
+	return "c" + o.Item.Name, o
+}
+
+// TestDumpPCollection verifies that each format writes the elements, and
+// key-value pairs with a schema derived from their key and value types.
+func TestDumpPCollection(t *testing.T) {
+	defer func(old bool) { *dump = old }(*dump)
+	*dump = true
+
+	orders := []interface{}{
+		order{ID: 1, Item: item{Name: "a", Price: 1.5}},
+		order{ID: 2, Item: item{Name: "b", Price: 2}},
+	}
+	tests := []struct {
+		format string
+		kv     bool
+		ext    string
+		want   []string
+	}{
+		{"json", false, ".json", []string{
+			`{"id":1,"item":{"name":"a","Price":1.5}}`,
+			`{"id":2,"item":{"name":"b","Price":2}}`,
+		}},
+		{"json", true, ".json", []string{
+			`{"key":"ca","value":{"id":1,"item":{"name":"a","Price":1.5}}}`,
+			`{"key":"cb","value":{"id":2,"item":{"name":"b","Price":2}}}`,
+		}},
+		{"text", false, ".txt", []string{"{1 {a 1.5}}", "{2 {b 2}}"}},
+		{"text", true, ".txt", []string{"(ca,{1 {a 1.5}})", "(cb,{2 {b 2}})"}},
+		{"avro", false, ".avro", []string{
+			`{"id":1,"item":{"Price":1.5,"name":"a"}}`,
+			`{"id":2,"item":{"Price":2,"name":"b"}}`,
+		}},
+		{"avro", true, ".avro", []string{
+			`{"key":"ca","value":{"id":1,"item":{"Price":1.5,"name":"a"}}}`,
+			`{"key":"cb","value":{"id":2,"item":{"Price":2,"name":"b"}}}`,
+		}},
+	}
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "debug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		p, s := beam.NewPipelineWithRoot()
+		col := beam.CreateList(s, orders)
+		if test.kv {
+			col = beam.ParDo(s, keyByCustomer, col)
+		}
+		DumpPCollection(s, filepath.Join(dir, "orders"), test.format, col)
+		if err := ptest.Run(p); err != nil {
+			t.Fatalf("DumpPCollection(%v, kv=%v) failed: %v", test.format, test.kv, err)
+		}
+
+		var got []string
+		if test.format == "avro" {
+			got = readAvro(t, filepath.Join(dir, "orders.avro"))
+		} else {
+			got = readLines(t, filepath.Join(dir, "orders-*"+test.ext))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("DumpPCollection(%v, kv=%v) wrote %q, want %q", test.format, test.kv, got, test.want)
+		}
+	}
+}
+
+func readLines(t *testing.T, glob string) []string {
+	files, err := filepath.Glob(glob)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no files match %v: %v", glob, err)
+	}
+	var lines []string
+	for _, name := range files {
+		fd, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(fd)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		fd.Close()
+	}
+	return lines
+}
+
+func readAvro(t *testing.T, name string) []string {
+	fd, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	r, err := goavro.NewOCFReader(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []string
+	for r.Scan() {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, string(data))
+	}
+	return records
+}
+
+func TestAvroSchemaUnsupported(t *testing.T) {
+	tests := []reflect.Type{
+		reflect.TypeOf(0),
+		reflect.TypeOf(kvPair{}),
+		kvType(reflect.TypeOf(""), reflect.TypeOf([]int{})),
+	}
+	for _, typ := range tests {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil || !strings.HasPrefix(r.(string), "cannot derive Avro") {
+					t.Errorf("avroSchema(%v) = %v, want a panic", typ, r)
+				}
+			}()
+			avroSchema(typ)
+		}()
+	}
+}
@@ -0,0 +1,291 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interactive contains an interactive execution mode for notebooks
+// and REPLs. A Session caches the contents of named PCollections between
+// pipeline executions, so that subsequent pipelines built from the same
+// session replay the cached contents instead of recomputing them. For
+// example:
+//
+//    session := interactive.NewSession()
+//
+//    // First cell: read and parse the input once.
+//    p, s := beam.NewPipelineWithRoot()
+//    session.Cache(s, "events", func(s beam.Scope) beam.PCollection {
+//        return beam.ParDo(s, parseFn, textio.Read(s, "gs://bucket/events-*"))
+//    })
+//    err := session.Run(ctx, p)
+//
+//    // Later cells: build on the cached events without reading them again.
+//    p, s = beam.NewPipelineWithRoot()
+//    events := session.Cache(s, "events", nil)
+//    session.Cache(s, "errors", func(s beam.Scope) beam.PCollection {
+//        return filter.Include(s, events, isError)
+//    })
+//    err = session.Run(ctx, p)
+//
+//    var errs []Event
+//    err = session.Into("errors", &errs)
+//
+// Pipelines are executed in the current process by the direct runner, and
+// cached contents are kept in memory. Only PCollections in the global window
+// can be cached; elements are replayed with their timestamps, so they can be
+// windowed again.
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/runners/direct"
+	"github.com/apache/beam/sdks/go/pkg/beam/x/local"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=interactive --identifiers=stampFn,replayFn
+//go:generate go fmt
+
+// Session holds the cached PCollection contents of an interactive session.
+// It is safe for concurrent use.
+type Session struct {
+	// cache holds the contents of PCollections of successful executions.
+	cache map[string]*entry
+	// pending holds the PCollections to cache when the pipeline executes.
+	pending map[string]*pending
+	// sources holds the sources of the replays of the pipeline, which are
+	// released when it executes.
+	sources []*local.Source
+	mu      sync.Mutex
+}
+
+// entry holds the encoded elements of a cached PCollection, which are
+// decoded when they are read.
+type entry struct {
+	t        reflect.Type
+	elements []element
+}
+
+type pending struct {
+	t      reflect.Type
+	result *local.Result
+}
+
+// element is a cached element with its timestamp, in milliseconds since
+// the epoch.
+type element struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     []byte `json:"value"`
+}
+
+// NewSession returns a new session with an empty cache.
+func NewSession() *Session {
+	return &Session{cache: make(map[string]*entry), pending: make(map[string]*pending)}
+}
+
+// Cache returns the PCollection named key. If its contents are cached, they
+// are replayed and build is not called. Otherwise, build constructs the
+// PCollection in s and its contents are cached when the pipeline is
+// executed with Run. The build function may be nil, if the contents are
+// known to be cached. The PCollection must be in the global window, or
+// the pipeline fails.
+func (ss *Session) Cache(s beam.Scope, key string, build func(beam.Scope) beam.PCollection) beam.PCollection {
+	ss.mu.Lock()
+	e, ok := ss.cache[key]
+	ss.mu.Unlock()
+
+	if ok {
+		s = s.Scope("interactive.Replay")
+		elements, src := local.CreateSource(s, e.elements)
+		ss.mu.Lock()
+		ss.sources = append(ss.sources, src)
+		ss.mu.Unlock()
+		return beam.ParDo(s, &replayFn{Type: beam.EncodedType{T: e.t}}, elements, beam.TypeDefinition{Var: beam.TType, T: e.t})
+	}
+	if build == nil {
+		panic(errors.Errorf("%v is not cached", key))
+	}
+
+	col := build(s)
+	t := beam.ValidateNonCompositeType(col).Type()
+	cs := s.Scope("interactive.Cache")
+	result := local.Collect(cs, beam.ParDo(cs, &stampFn{Type: beam.EncodedType{T: t}}, col))
+
+	ss.mu.Lock()
+	ss.pending[key] = &pending{t: t, result: result}
+	ss.mu.Unlock()
+	return col
+}
+
+// Run executes the pipeline with the direct runner. If it succeeds, the
+// contents of the PCollections given to Cache since the last Run are
+// cached. The replays of the pipeline are released, so it cannot be run
+// again.
+func (ss *Session) Run(ctx context.Context, p *beam.Pipeline) error {
+	err := direct.Execute(ctx, p)
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for _, src := range ss.sources {
+		src.Release()
+	}
+	ss.sources = nil
+
+	for key, pc := range ss.pending {
+		pc.result.Release()
+		if err != nil {
+			continue
+		}
+		e, derr := newEntry(pc.t, pc.result.Values())
+		if derr != nil {
+			err = errors.Wrapf(derr, "failed to cache %v", key)
+			continue
+		}
+		ss.cache[key] = e
+	}
+	ss.pending = make(map[string]*pending)
+	return err
+}
+
+// newEntry returns the cache entry of the collected elements of type t. It
+// checks that the elements can be decoded.
+func newEntry(t reflect.Type, collected []interface{}) (*entry, error) {
+	e := &entry{t: t}
+	for _, c := range collected {
+		e.elements = append(e.elements, c.(element))
+	}
+	if _, err := e.values(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// values returns the decoded elements.
+func (e *entry) values() ([]interface{}, error) {
+	dec := beam.NewElementDecoder(e.t)
+	var ret []interface{}
+	for _, elm := range e.elements {
+		value, err := dec.Decode(bytes.NewBuffer(elm.Value))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, value)
+	}
+	return ret, nil
+}
+
+// stampFn encodes the elements to cache with their timestamps.
+type stampFn struct {
+	Type beam.EncodedType `json:"type"`
+
+	enc beam.ElementEncoder
+}
+
+func (f *stampFn) Setup() {
+	f.enc = beam.NewElementEncoder(f.Type.T)
+}
+
+func (f *stampFn) ProcessElement(w beam.Window, et beam.EventTime, elm beam.T) (element, error) {
+	if _, ok := w.(window.GlobalWindow); !ok {
+		return element{}, errors.Errorf("Cache supports only the global window, not %v", w)
+	}
+	var buf bytes.Buffer
+	if err := f.enc.Encode(elm, &buf); err != nil {
+		return element{}, err
+	}
+	return element{Timestamp: et.Milliseconds(), Value: buf.Bytes()}, nil
+}
+
+// replayFn emits the cached elements with their timestamps.
+type replayFn struct {
+	Type beam.EncodedType `json:"type"`
+
+	dec beam.ElementDecoder
+}
+
+func (f *replayFn) Setup() {
+	f.dec = beam.NewElementDecoder(f.Type.T)
+}
+
+func (f *replayFn) ProcessElement(elm element) (beam.EventTime, beam.T, error) {
+	value, err := f.dec.Decode(bytes.NewBuffer(elm.Value))
+	return mtime.FromMilliseconds(elm.Timestamp), value, err
+}
+
+// Keys returns the keys of the cached PCollections.
+func (ss *Session) Keys() []string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var ret []string
+	for key := range ss.cache {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+// Values returns the cached contents of the PCollection named key, in no
+// particular order.
+func (ss *Session) Values(key string) ([]interface{}, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	e, ok := ss.cache[key]
+	if !ok {
+		return nil, false
+	}
+	values, err := e.values()
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to decode %v", key)) // checked by newEntry
+	}
+	return values, true
+}
+
+// Into sets the slice pointed to by ptr to the cached contents of the
+// PCollection named key.
+func (ss *Session) Into(key string, ptr interface{}) error {
+	values, ok := ss.Values(key)
+	if !ok {
+		return errors.Errorf("%v is not cached", key)
+	}
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("%T is not a pointer to a slice", ptr)
+	}
+	ret := reflect.MakeSlice(v.Elem().Type(), len(values), len(values))
+	for i, value := range values {
+		val := reflect.ValueOf(value)
+		if !val.Type().AssignableTo(ret.Type().Elem()) {
+			return errors.Errorf("cannot read elements of type %v into %T", val.Type(), ptr)
+		}
+		ret.Index(i).Set(val)
+	}
+	v.Elem().Set(ret)
+	return nil
+}
+
+// Invalidate removes the PCollection named key from the cache, so that it
+// is recomputed by the next pipeline.
+func (ss *Session) Invalidate(key string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	delete(ss.cache, key)
+}
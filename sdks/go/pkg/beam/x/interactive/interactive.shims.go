@@ -0,0 +1,139 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: interactive.shims.go
+
+package interactive
+
+import (
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*element)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*mtime.Time)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*replayFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*stampFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*replayFn)(nil)).Elem(), wrapMakerReplayFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*stampFn)(nil)).Elem(), wrapMakerStampFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(element) (mtime.Time, typex.T, error))(nil)).Elem(), funcMakerElementГMtime۰TimeTypex۰TError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.Window, mtime.Time, typex.T) (element, error))(nil)).Elem(), funcMakerTypex۰WindowMtime۰TimeTypex۰TГElementError)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+}
+
+func wrapMakerReplayFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*replayFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 element) (mtime.Time, typex.T, error) { return dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+func wrapMakerStampFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*stampFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.Window, a1 mtime.Time, a2 typex.T) (element, error) {
+			return dfn.ProcessElement(a0, a1, a2)
+		}),
+		"Setup": reflectx.MakeFunc(func() { dfn.Setup() }),
+	}
+}
+
+type callerElementГMtime۰TimeTypex۰TError struct {
+	fn func(element) (mtime.Time, typex.T, error)
+}
+
+func funcMakerElementГMtime۰TimeTypex۰TError(fn interface{}) reflectx.Func {
+	f := fn.(func(element) (mtime.Time, typex.T, error))
+	return &callerElementГMtime۰TimeTypex۰TError{fn: f}
+}
+
+func (c *callerElementГMtime۰TimeTypex۰TError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerElementГMtime۰TimeTypex۰TError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerElementГMtime۰TimeTypex۰TError) Call(args []interface{}) []interface{} {
+	out0, out1, out2 := c.fn(args[0].(element))
+	return []interface{}{out0, out1, out2}
+}
+
+func (c *callerElementГMtime۰TimeTypex۰TError) Call1x3(arg0 interface{}) (interface{}, interface{}, interface{}) {
+	return c.fn(arg0.(element))
+}
+
+type callerTypex۰WindowMtime۰TimeTypex۰TГElementError struct {
+	fn func(typex.Window, mtime.Time, typex.T) (element, error)
+}
+
+func funcMakerTypex۰WindowMtime۰TimeTypex۰TГElementError(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.Window, mtime.Time, typex.T) (element, error))
+	return &callerTypex۰WindowMtime۰TimeTypex۰TГElementError{fn: f}
+}
+
+func (c *callerTypex۰WindowMtime۰TimeTypex۰TГElementError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰WindowMtime۰TimeTypex۰TГElementError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰WindowMtime۰TimeTypex۰TГElementError) Call(args []interface{}) []interface{} {
+	out0, out1 := c.fn(args[0].(typex.Window), args[1].(mtime.Time), args[2].(typex.T))
+	return []interface{}{out0, out1}
+}
+
+func (c *callerTypex۰WindowMtime۰TimeTypex۰TГElementError) Call3x2(arg0, arg1, arg2 interface{}) (interface{}, interface{}) {
+	return c.fn(arg0.(typex.Window), arg1.(mtime.Time), arg2.(typex.T))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+// DO NOT MODIFY: GENERATED CODE
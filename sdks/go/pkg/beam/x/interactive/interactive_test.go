@@ -0,0 +1,131 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/mtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/graph/window"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+)
+
+func init() {
+	beam.RegisterFunction(double)
+	beam.RegisterFunction(minutes)
+	beam.RegisterFunction(formatWindow)
+}
+
+func double(x int) int {
+	return 2 * x
+}
+
+func minutes(x int) beam.EventTime {
+	return mtime.FromMilliseconds(int64(x) * 60000)
+}
+
+func formatWindow(w beam.Window, x int) string {
+	return fmt.Sprintf("%v@%v", x, w.MaxTimestamp()/3600000)
+}
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	session := NewSession()
+
+	builds := 0
+	build := func(s beam.Scope) beam.PCollection {
+		builds++
+		return beam.ParDo(s, double, beam.Create(s, 1, 2, 3))
+	}
+
+	p, s := beam.NewPipelineWithRoot()
+	session.Cache(s, "doubled", build)
+	if err := session.Run(ctx, p); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	doubled := session.Cache(s, "doubled", build)
+	passert.Equals(s, doubled, 2, 4, 6)
+	session.Cache(s, "quadrupled", func(s beam.Scope) beam.PCollection {
+		return beam.ParDo(s, double, doubled)
+	})
+	if err := session.Run(ctx, p); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if builds != 1 {
+		t.Errorf("doubled built %v times, want once", builds)
+	}
+
+	var got []int
+	if err := session.Into("quadrupled", &got); err != nil {
+		t.Fatalf("Into failed: %v", err)
+	}
+	sort.Ints(got)
+	if len(got) != 3 || got[0] != 4 || got[1] != 8 || got[2] != 12 {
+		t.Errorf("Into(quadrupled) = %v, want [4 8 12]", got)
+	}
+
+	if len(session.sources) != 0 {
+		t.Errorf("Run kept %v replay sources, want none", len(session.sources))
+	}
+	if err := session.Run(ctx, p); err == nil {
+		t.Errorf("Run of a released replay succeeded, want error")
+	}
+
+	session.Invalidate("doubled")
+	if _, ok := session.Values("doubled"); ok {
+		t.Errorf("Values(doubled) succeeded after Invalidate")
+	}
+}
+
+// TestSessionTimestamps verifies that cached elements are replayed with
+// their timestamps, and that windowed PCollections are not cached.
+func TestSessionTimestamps(t *testing.T) {
+	ctx := context.Background()
+	session := NewSession()
+
+	p, s := beam.NewPipelineWithRoot()
+	session.Cache(s, "stamped", func(s beam.Scope) beam.PCollection {
+		return beam.WithTimestamps(s, minutes, beam.Create(s, 1, 61, 62))
+	})
+	if err := session.Run(ctx, p); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	hourly := beam.WindowInto(s, window.NewFixedWindows(time.Hour), session.Cache(s, "stamped", nil))
+	passert.Equals(s, beam.ParDo(s, formatWindow, hourly), "1@0", "61@1", "62@1")
+	if err := session.Run(ctx, p); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	session.Cache(s, "hourly", func(s beam.Scope) beam.PCollection {
+		return beam.WindowInto(s, window.NewFixedWindows(time.Hour), session.Cache(s, "stamped", nil))
+	})
+	if err := session.Run(ctx, p); err == nil {
+		t.Errorf("Run of a windowed cache succeeded, want error")
+	}
+	if _, ok := session.Values("hourly"); ok {
+		t.Errorf("windowed PCollection was cached")
+	}
+}
@@ -0,0 +1,264 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package local contains helpers to move data between in-memory slices and
+// PCollections at the edges of a pipeline, such as in notebooks and
+// experiments. For example:
+//
+//    p, s := beam.NewPipelineWithRoot()
+//    words := local.CreateLarge(s, words)  // words is a large []string
+//    result := local.Collect(s, filter.Distinct(s, words))
+//    if err := direct.Execute(ctx, p); err != nil {
+//        ...
+//    }
+//    var distinct []string
+//    err := result.Into(&distinct)
+//
+// CreateLarge and Collect only work with runners that execute the pipeline
+// in the current process, such as the direct runner. The values given to
+// CreateLarge and the results of Collect are kept in memory, so that the
+// pipeline can be executed again, until they are released or Clear is
+// called.
+package local
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+)
+
+//go:generate go install github.com/apache/beam/sdks/go/cmd/starcgen
+//go:generate starcgen --package=local --identifiers=splitFn,shardFn,collectFn
+//go:generate go fmt
+
+// shardSize is the number of values of a shard created by CreateLarge.
+var shardSize = 10000
+
+// CreateLarge inserts the values of a slice into the pipeline, like
+// beam.CreateList. Unlike beam.CreateList, the values are not part of the
+// pipeline, but are read from the slice when the pipeline is executed in
+// the current process. They are read in shards, which are redistributed so
+// that downstream processing is not confined to a single worker. The slice
+// must not be modified until the pipeline is executed. An empty slice
+// results in an empty PCollection.
+func CreateLarge(s beam.Scope, list interface{}) beam.PCollection {
+	col, _ := CreateSource(s, list)
+	return col
+}
+
+// Source is a slice inserted into a pipeline by CreateSource.
+type Source struct {
+	id string
+}
+
+// CreateSource is like CreateLarge, but also returns the source of the
+// values, so that the slice can be released once the pipeline is executed.
+func CreateSource(s beam.Scope, list interface{}) (beam.PCollection, *Source) {
+	s = s.Scope("local.CreateLarge")
+
+	val := reflect.ValueOf(list)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		panic(fmt.Sprintf("input %v must be a slice or array", list))
+	}
+	t := val.Type().Elem()
+
+	registryMu.Lock()
+	id := fmt.Sprintf("%016x", random.Uint64())
+	sources[id] = val
+	registryMu.Unlock()
+
+	shards := (val.Len() + shardSize - 1) / shardSize
+	indices := beam.ParDo(s, &splitFn{Shards: shards}, beam.Impulse(s))
+	if shards > 1 {
+		indices = beam.Reshuffle(s, indices)
+	}
+	col := beam.ParDo(s, &shardFn{ID: id, Size: shardSize}, indices, beam.TypeDefinition{Var: beam.TType, T: t})
+	return col, &Source{id: id}
+}
+
+// Release releases the slice of the source, like Clear does for all
+// sources. The pipeline cannot be executed again.
+func (src *Source) Release() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(sources, src.id)
+}
+
+// splitFn emits the indices of the shards.
+type splitFn struct {
+	Shards int `json:"shards"`
+}
+
+func (f *splitFn) ProcessElement(_ []byte, emit func(int)) {
+	for i := 0; i < f.Shards; i++ {
+		emit(i)
+	}
+}
+
+// shardFn emits the values of a shard of the slice of its ID.
+type shardFn struct {
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+
+	list reflect.Value
+}
+
+func (f *shardFn) Setup() error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	list, ok := sources[f.ID]
+	if !ok {
+		return errors.Errorf("no values %v: CreateLarge requires a runner in the current process", f.ID)
+	}
+	f.list = list
+	return nil
+}
+
+func (f *shardFn) ProcessElement(shard int, emit func(beam.T)) {
+	end := (shard + 1) * f.Size
+	if end > f.list.Len() {
+		end = f.list.Len()
+	}
+	for i := shard * f.Size; i < end; i++ {
+		emit(f.list.Index(i).Interface())
+	}
+}
+
+// Result holds the elements of a PCollection collected by Collect.
+type Result struct {
+	t  reflect.Type
+	id string
+
+	values []interface{}
+	mu     sync.Mutex
+}
+
+var (
+	// sources holds the slices of CreateLarge, by ID.
+	sources = make(map[string]reflect.Value)
+	// results holds the results of Collect, by ID.
+	results    = make(map[string]*Result)
+	registryMu sync.Mutex
+	random     = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Clear releases the values given to CreateLarge and the results of
+// Collect. The Results that are held may still be read, but pipelines that
+// use them cannot be executed again.
+func Clear() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	sources = make(map[string]reflect.Value)
+	results = make(map[string]*Result)
+}
+
+// Collect collects the elements of col into the returned Result, when the
+// pipeline is executed in the current process. The elements are available
+// once execution finishes, in no particular order. KV elements are not
+// supported. Executing the pipeline again appends the elements again, until
+// the result is released.
+func Collect(s beam.Scope, col beam.PCollection) *Result {
+	s = s.Scope("local.Collect")
+
+	t := beam.ValidateNonCompositeType(col)
+
+	registryMu.Lock()
+	id := fmt.Sprintf("%016x", random.Uint64())
+	ret := &Result{t: t.Type(), id: id}
+	results[id] = ret
+	registryMu.Unlock()
+
+	beam.ParDo0(s, &collectFn{ID: id}, col)
+	return ret
+}
+
+// Release releases the result, like Clear does for all results. Its
+// elements may still be read, but the pipeline cannot be executed again.
+func (r *Result) Release() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(results, r.id)
+}
+
+// Type returns the type of the collected elements.
+func (r *Result) Type() reflect.Type {
+	return r.t
+}
+
+// Values returns the collected elements.
+func (r *Result) Values() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]interface{}(nil), r.values...)
+}
+
+// Into sets the slice pointed to by ptr to the collected elements. The
+// elements must be assignable to the elements of the slice.
+func (r *Result) Into(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("%T is not a pointer to a slice", ptr)
+	}
+	if !r.t.AssignableTo(v.Elem().Type().Elem()) {
+		return errors.Errorf("cannot collect elements of type %v into %T", r.t, ptr)
+	}
+	values := r.Values()
+	ret := reflect.MakeSlice(v.Elem().Type(), len(values), len(values))
+	for i, value := range values {
+		ret.Index(i).Set(reflect.ValueOf(value))
+	}
+	v.Elem().Set(ret)
+	return nil
+}
+
+// collectFn appends the elements of each bundle to the Result of its ID.
+type collectFn struct {
+	ID string `json:"id"`
+
+	result *Result
+	values []interface{}
+}
+
+func (f *collectFn) Setup() error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	r, ok := results[f.ID]
+	if !ok {
+		return errors.Errorf("no result %v: Collect requires a runner in the current process", f.ID)
+	}
+	f.result = r
+	return nil
+}
+
+func (f *collectFn) StartBundle() {
+	f.values = nil
+}
+
+func (f *collectFn) ProcessElement(elm beam.T) {
+	f.values = append(f.values, elm)
+}
+
+func (f *collectFn) FinishBundle() {
+	f.result.mu.Lock()
+	defer f.result.mu.Unlock()
+	f.result.values = append(f.result.values, f.values...)
+}
@@ -0,0 +1,250 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by starcgen. DO NOT EDIT.
+// File: local.shims.go
+
+package local
+
+import (
+	"context"
+	"reflect"
+
+	// Library imports
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/runtime/exec"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/typex"
+	"github.com/apache/beam/sdks/go/pkg/beam/core/util/reflectx"
+)
+
+func init() {
+	runtime.RegisterType(reflect.TypeOf((*collectFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*shardFn)(nil)).Elem())
+	runtime.RegisterType(reflect.TypeOf((*splitFn)(nil)).Elem())
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*collectFn)(nil)).Elem(), wrapMakerCollectFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*shardFn)(nil)).Elem(), wrapMakerShardFn)
+	reflectx.RegisterStructWrapper(reflect.TypeOf((*splitFn)(nil)).Elem(), wrapMakerSplitFn)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(int, func(typex.T)))(nil)).Elem(), funcMakerIntEmitTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func([]byte, func(int)))(nil)).Elem(), funcMakerSliceOfByteEmitIntГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func(typex.T))(nil)).Elem(), funcMakerTypex۰TГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func())(nil)).Elem(), funcMakerГ)
+	reflectx.RegisterFunc(reflect.TypeOf((*func() error)(nil)).Elem(), funcMakerГError)
+	exec.RegisterEmitter(reflect.TypeOf((*func(int))(nil)).Elem(), emitMakerInt)
+	exec.RegisterEmitter(reflect.TypeOf((*func(typex.T))(nil)).Elem(), emitMakerTypex۰T)
+}
+
+func wrapMakerCollectFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*collectFn)
+	return map[string]reflectx.Func{
+		"FinishBundle":   reflectx.MakeFunc(func() { dfn.FinishBundle() }),
+		"ProcessElement": reflectx.MakeFunc(func(a0 typex.T) { dfn.ProcessElement(a0) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+		"StartBundle":    reflectx.MakeFunc(func() { dfn.StartBundle() }),
+	}
+}
+
+func wrapMakerShardFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*shardFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 int, a1 func(typex.T)) { dfn.ProcessElement(a0, a1) }),
+		"Setup":          reflectx.MakeFunc(func() error { return dfn.Setup() }),
+	}
+}
+
+func wrapMakerSplitFn(fn interface{}) map[string]reflectx.Func {
+	dfn := fn.(*splitFn)
+	return map[string]reflectx.Func{
+		"ProcessElement": reflectx.MakeFunc(func(a0 []byte, a1 func(int)) { dfn.ProcessElement(a0, a1) }),
+	}
+}
+
+type callerIntEmitTypex۰TГ struct {
+	fn func(int, func(typex.T))
+}
+
+func funcMakerIntEmitTypex۰TГ(fn interface{}) reflectx.Func {
+	f := fn.(func(int, func(typex.T)))
+	return &callerIntEmitTypex۰TГ{fn: f}
+}
+
+func (c *callerIntEmitTypex۰TГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerIntEmitTypex۰TГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerIntEmitTypex۰TГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(int), args[1].(func(typex.T)))
+	return []interface{}{}
+}
+
+func (c *callerIntEmitTypex۰TГ) Call2x0(arg0, arg1 interface{}) {
+	c.fn(arg0.(int), arg1.(func(typex.T)))
+}
+
+type callerSliceOfByteEmitIntГ struct {
+	fn func([]byte, func(int))
+}
+
+func funcMakerSliceOfByteEmitIntГ(fn interface{}) reflectx.Func {
+	f := fn.(func([]byte, func(int)))
+	return &callerSliceOfByteEmitIntГ{fn: f}
+}
+
+func (c *callerSliceOfByteEmitIntГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerSliceOfByteEmitIntГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerSliceOfByteEmitIntГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].([]byte), args[1].(func(int)))
+	return []interface{}{}
+}
+
+func (c *callerSliceOfByteEmitIntГ) Call2x0(arg0, arg1 interface{}) {
+	c.fn(arg0.([]byte), arg1.(func(int)))
+}
+
+type callerTypex۰TГ struct {
+	fn func(typex.T)
+}
+
+func funcMakerTypex۰TГ(fn interface{}) reflectx.Func {
+	f := fn.(func(typex.T))
+	return &callerTypex۰TГ{fn: f}
+}
+
+func (c *callerTypex۰TГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerTypex۰TГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerTypex۰TГ) Call(args []interface{}) []interface{} {
+	c.fn(args[0].(typex.T))
+	return []interface{}{}
+}
+
+func (c *callerTypex۰TГ) Call1x0(arg0 interface{}) {
+	c.fn(arg0.(typex.T))
+}
+
+type callerГ struct {
+	fn func()
+}
+
+func funcMakerГ(fn interface{}) reflectx.Func {
+	f := fn.(func())
+	return &callerГ{fn: f}
+}
+
+func (c *callerГ) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГ) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГ) Call(args []interface{}) []interface{} {
+	c.fn()
+	return []interface{}{}
+}
+
+func (c *callerГ) Call0x0() {
+	c.fn()
+}
+
+type callerГError struct {
+	fn func() error
+}
+
+func funcMakerГError(fn interface{}) reflectx.Func {
+	f := fn.(func() error)
+	return &callerГError{fn: f}
+}
+
+func (c *callerГError) Name() string {
+	return reflectx.FunctionName(c.fn)
+}
+
+func (c *callerГError) Type() reflect.Type {
+	return reflect.TypeOf(c.fn)
+}
+
+func (c *callerГError) Call(args []interface{}) []interface{} {
+	out0 := c.fn()
+	return []interface{}{out0}
+}
+
+func (c *callerГError) Call0x1() interface{} {
+	return c.fn()
+}
+
+type emitNative struct {
+	n  exec.ElementProcessor
+	fn interface{}
+
+	ctx   context.Context
+	ws    []typex.Window
+	et    typex.EventTime
+	value exec.FullValue
+}
+
+func (e *emitNative) Init(ctx context.Context, ws []typex.Window, et typex.EventTime) error {
+	e.ctx = ctx
+	e.ws = ws
+	e.et = et
+	return nil
+}
+
+func (e *emitNative) Value() interface{} {
+	return e.fn
+}
+
+func emitMakerInt(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeInt
+	return ret
+}
+
+func (e *emitNative) invokeInt(val int) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+func emitMakerTypex۰T(n exec.ElementProcessor) exec.ReusableEmitter {
+	ret := &emitNative{n: n}
+	ret.fn = ret.invokeTypex۰T
+	return ret
+}
+
+func (e *emitNative) invokeTypex۰T(val typex.T) {
+	e.value = exec.FullValue{Windows: e.ws, Timestamp: e.et, Elm: val}
+	if err := e.n.ProcessElement(e.ctx, &e.value); err != nil {
+		panic(err)
+	}
+}
+
+// DO NOT MODIFY: GENERATED CODE
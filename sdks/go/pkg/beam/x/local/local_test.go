@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/ptest"
+)
+
+func TestCreateLargeCollect(t *testing.T) {
+	defer func(old int) { shardSize = old }(shardSize)
+	shardSize = 1000
+
+	tests := []struct {
+		name string
+		n    int
+		size int
+	}{
+		{"empty", 0, 1},
+		{"small", 3, 1},
+		{"sharded", 2500, 10}, // 3 shards
+	}
+	for _, test := range tests {
+		var values []string
+		for i := 0; i < test.n; i++ {
+			values = append(values, strings.Repeat(string(rune('a'+i%26)), test.size))
+		}
+
+		p, s := beam.NewPipelineWithRoot()
+		result := Collect(s, CreateLarge(s, values))
+		if err := ptest.Run(p); err != nil {
+			t.Fatalf("%v: pipeline failed: %v", test.name, err)
+		}
+
+		var got []string
+		if err := result.Into(&got); err != nil {
+			t.Fatalf("%v: Into failed: %v", test.name, err)
+		}
+		sort.Strings(got)
+		sort.Strings(values)
+		if strings.Join(got, ",") != strings.Join(values, ",") {
+			t.Errorf("%v: collected %v values, want %v", test.name, len(got), len(values))
+		}
+
+		var wrong []int
+		if err := result.Into(&wrong); err == nil {
+			t.Errorf("%v: Into(*[]int) succeeded, want error", test.name)
+		}
+	}
+}
+
+func TestRelease(t *testing.T) {
+	p, s := beam.NewPipelineWithRoot()
+	result := Collect(s, CreateLarge(s, []int{1, 2, 3}))
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	result.Release()
+	if got := len(result.Values()); got != 3 {
+		t.Errorf("released result has %v values, want 3", got)
+	}
+	if err := ptest.Run(p); err == nil {
+		t.Errorf("pipeline with a released result succeeded, want error")
+	}
+
+	p, s = beam.NewPipelineWithRoot()
+	col, src := CreateSource(s, []int{1, 2, 3})
+	Collect(s, col)
+	if err := ptest.Run(p); err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	src.Release()
+	if err := ptest.Run(p); err == nil {
+		t.Errorf("pipeline with a released source succeeded, want error")
+	}
+
+	Clear()
+	p, s = beam.NewPipelineWithRoot()
+	col = CreateLarge(s, []int{1, 2, 3})
+	Clear()
+	Collect(s, col)
+	if err := ptest.Run(p); err == nil {
+		t.Errorf("pipeline with cleared values succeeded, want error")
+	}
+}
@@ -0,0 +1,268 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package orchestrate runs several dependent pipelines as one workflow.
+// Each stage of a workflow builds a pipeline and returns the location of
+// its output, which is passed to the stages that run after it. For example:
+//
+//    w := orchestrate.New()
+//    w.Add("extract", func(s beam.Scope, _ map[string]string) (string, error) {
+//        out := "gs://bucket/extract/" + *date
+//        textio.Write(s, out, extract(s))
+//        return out, nil
+//    })
+//    w.Add("report", func(s beam.Scope, in map[string]string) (string, error) {
+//        lines := textio.Read(s, in["extract"]+"*")
+//        ...
+//    }, "extract")
+//    report, err := w.Run(ctx, *runner)
+//    log.Info(ctx, report)
+//
+// All stages share the command-line options, which a stage can override
+// with Stage.Options. A stage runs only once all stages it runs after have
+// succeeded, so pipelines must run synchronously: a stage that would run
+// with --async fails instead.
+package orchestrate
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	"github.com/apache/beam/sdks/go/pkg/beam/internal/errors"
+	"github.com/apache/beam/sdks/go/pkg/beam/log"
+	"github.com/apache/beam/sdks/go/pkg/beam/options/jobopts"
+)
+
+// BuildFn constructs the pipeline of a stage in the scope. It is given the
+// outputs of the stages the stage runs after, by name, and returns the
+// location of its own output, if any.
+type BuildFn func(s beam.Scope, inputs map[string]string) (string, error)
+
+// Stage is a pipeline of a workflow.
+type Stage struct {
+	// Name is the name of the stage.
+	Name string
+	// Build constructs the pipeline of the stage.
+	Build BuildFn
+	// After are the names of the stages that must succeed before the stage
+	// runs.
+	After []string
+	// Options are flags that are set while the stage is built and run, by
+	// name, such as {"num_workers": "10"}. Unless overridden, the job name
+	// of a stage is the --job_name flag, if set, followed by "-<stage>".
+	Options map[string]string
+}
+
+// Workflow is a set of dependent pipelines.
+type Workflow struct {
+	stages []*Stage
+	names  map[string]bool
+}
+
+// New returns a new, empty workflow.
+func New() *Workflow {
+	return &Workflow{names: make(map[string]bool)}
+}
+
+// Add adds a stage of the given name that runs after the given stages,
+// which must have been added before. It returns the stage, so that its
+// options can be set.
+func (w *Workflow) Add(name string, build BuildFn, after ...string) *Stage {
+	return w.AddStage(&Stage{Name: name, Build: build, After: after})
+}
+
+// AddStage adds the stage, whose stages to run after must have been added
+// before. It returns the stage.
+func (w *Workflow) AddStage(stage *Stage) *Stage {
+	if stage.Name == "" || w.names[stage.Name] {
+		panic(fmt.Sprintf("invalid or duplicate stage name: %q", stage.Name))
+	}
+	if stage.Build == nil {
+		panic(fmt.Sprintf("stage %v has no build function", stage.Name))
+	}
+	for _, dep := range stage.After {
+		if !w.names[dep] {
+			panic(fmt.Sprintf("stage %v runs after unknown stage %v; stages must be added after the stages they run after", stage.Name, dep))
+		}
+	}
+	w.names[stage.Name] = true
+	w.stages = append(w.stages, stage)
+	return stage
+}
+
+// Status is the status of a stage of a run.
+type Status int
+
+const (
+	// Succeeded is the status of a stage whose pipeline succeeded.
+	Succeeded Status = iota
+	// Failed is the status of a stage whose pipeline could not be built or
+	// failed.
+	Failed
+	// Skipped is the status of a stage that did not run because a stage it
+	// runs after did not succeed.
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "SUCCEEDED"
+	case Failed:
+		return "FAILED"
+	case Skipped:
+		return "SKIPPED"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Result is the result of a stage of a run.
+type Result struct {
+	Stage  string
+	Status Status
+	// Output is the output location returned by the build function.
+	Output string
+	// Err is the error of a failed stage.
+	Err error
+	// Duration is the time taken to build and run the pipeline.
+	Duration time.Duration
+}
+
+// Report is the combined status of the stages of a run, in the order they
+// were added.
+type Report struct {
+	Results []Result
+}
+
+// Err returns an error listing the failed stages, if any.
+func (r *Report) Err() error {
+	var failed []string
+	for _, res := range r.Results {
+		if res.Status == Failed {
+			failed = append(failed, fmt.Sprintf("%v: %v", res.Stage, res.Err))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("%v of %v stages failed:\n\t%v", len(failed), len(r.Results), strings.Join(failed, "\n\t"))
+}
+
+// String formats the report as a table.
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tSTATUS\tDURATION\tOUTPUT")
+	for _, res := range r.Results {
+		out := res.Output
+		if res.Err != nil {
+			out = strings.SplitN(res.Err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", res.Stage, res.Status, res.Duration.Round(time.Millisecond), out)
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// Run runs the stages of the workflow with the runner, in the order they
+// were added. Stages that run after a stage that did not succeed are
+// skipped, but other stages still run. It returns the report of the run,
+// and the error of the report, if any.
+func (w *Workflow) Run(ctx context.Context, runner string) (*Report, error) {
+	report := &Report{}
+	results := make(map[string]Result)
+	for _, stage := range w.stages {
+		res := Result{Stage: stage.Name}
+		inputs := make(map[string]string)
+		for _, dep := range stage.After {
+			if results[dep].Status != Succeeded {
+				res.Status = Skipped
+				break
+			}
+			inputs[dep] = results[dep].Output
+		}
+		if res.Status != Skipped {
+			log.Infof(ctx, "Running stage %v", stage.Name)
+			start := time.Now()
+			res.Output, res.Err = run(ctx, runner, stage, inputs)
+			res.Duration = time.Since(start)
+			if res.Err != nil {
+				res.Status = Failed
+				log.Errorf(ctx, "Stage %v failed: %v", stage.Name, res.Err)
+			}
+		}
+		results[stage.Name] = res
+		report.Results = append(report.Results, res)
+	}
+	return report, report.Err()
+}
+
+// run builds and runs the pipeline of a stage with its options set.
+func run(ctx context.Context, runner string, stage *Stage, inputs map[string]string) (string, error) {
+	opts := make(map[string]string)
+	if *jobopts.JobName != "" {
+		opts["job_name"] = *jobopts.JobName + "-" + stage.Name
+	}
+	for k, v := range stage.Options {
+		opts[k] = v
+	}
+	restore, err := setFlags(opts)
+	defer restore()
+	if err != nil {
+		return "", err
+	}
+	if *jobopts.Async {
+		return "", errors.New("stages cannot run with --async, since later stages must wait for the pipeline to finish")
+	}
+
+	p := beam.NewPipeline()
+	output, err := stage.Build(p.Root(), inputs)
+	if err != nil {
+		return "", errors.WithContext(err, "building pipeline")
+	}
+	if err := beam.Run(ctx, runner, p); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// setFlags sets the flags and returns a function that restores their
+// previous values.
+func setFlags(opts map[string]string) (func(), error) {
+	old := make(map[string]string)
+	restore := func() {
+		for name, v := range old {
+			flag.Set(name, v)
+		}
+	}
+	for name, v := range opts {
+		f := flag.Lookup(name)
+		if f == nil {
+			return restore, errors.Errorf("unknown option %v", name)
+		}
+		old[name] = f.Value.String()
+		if err := flag.Set(name, v); err != nil {
+			return restore, errors.Wrapf(err, "invalid option %v", name)
+		}
+	}
+	return restore, nil
+}
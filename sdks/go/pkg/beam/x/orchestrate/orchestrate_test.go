@@ -0,0 +1,96 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/apache/beam/sdks/go/pkg/beam"
+	_ "github.com/apache/beam/sdks/go/pkg/beam/io/filesystem/memfs"
+	"github.com/apache/beam/sdks/go/pkg/beam/io/textio"
+	"github.com/apache/beam/sdks/go/pkg/beam/options/jobopts"
+	_ "github.com/apache/beam/sdks/go/pkg/beam/runners/direct"
+	"github.com/apache/beam/sdks/go/pkg/beam/testing/passert"
+)
+
+func TestRun(t *testing.T) {
+	var jobNames []string
+	w := New()
+	w.Add("extract", func(s beam.Scope, _ map[string]string) (string, error) {
+		jobNames = append(jobNames, *jobopts.JobName)
+		textio.Write(s, "memfs://orchestrate/extract.txt", beam.Create(s, "a", "b"))
+		return "memfs://orchestrate/extract.txt", nil
+	})
+	w.Add("broken", func(s beam.Scope, _ map[string]string) (string, error) {
+		return "", errors.New("no source")
+	})
+	w.Add("count", func(s beam.Scope, in map[string]string) (string, error) {
+		jobNames = append(jobNames, *jobopts.JobName)
+		passert.Equals(s, textio.Read(s, in["extract"]), "a", "b")
+		return "", nil
+	}, "extract").Options = map[string]string{"job_name": "override"}
+	w.Add("join", func(s beam.Scope, _ map[string]string) (string, error) {
+		t.Errorf("stage join ran after a failed stage")
+		return "", nil
+	}, "extract", "broken")
+
+	*jobopts.JobName = "daily"
+	defer func() { *jobopts.JobName = "" }()
+	report, err := w.Run(context.Background(), "direct")
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "no source") {
+		t.Errorf("Run() = %v, want error of stage broken", err)
+	}
+
+	want := []Status{Succeeded, Failed, Succeeded, Skipped}
+	for i, res := range report.Results {
+		if res.Status != want[i] {
+			t.Errorf("stage %v = %v, want %v", res.Stage, res.Status, want[i])
+		}
+	}
+	if got := strings.Join(jobNames, ","); got != "daily-extract,override" {
+		t.Errorf("job names = %v, want daily-extract,override", got)
+	}
+	if *jobopts.JobName != "daily" {
+		t.Errorf("job name after run = %v, want daily", *jobopts.JobName)
+	}
+	if lines := strings.Split(report.String(), "\n"); len(lines) < 5 || !strings.HasPrefix(strings.Join(strings.Fields(lines[4]), " "), "join SKIPPED") {
+		t.Errorf("report is missing skipped stage:\n%v", report)
+	}
+}
+
+func TestRunAsync(t *testing.T) {
+	w := New()
+	w.Add("extract", func(s beam.Scope, _ map[string]string) (string, error) {
+		t.Errorf("stage extract was built with --async")
+		return "", nil
+	})
+	w.Add("report", func(s beam.Scope, _ map[string]string) (string, error) {
+		return "", nil
+	}, "extract")
+
+	*jobopts.Async = true
+	defer func() { *jobopts.Async = false }()
+	report, err := w.Run(context.Background(), "direct")
+	if err == nil || !strings.Contains(err.Error(), "--async") {
+		t.Errorf("Run() = %v, want --async error", err)
+	}
+	if report.Results[0].Status != Failed || report.Results[1].Status != Skipped {
+		t.Errorf("Run() = %v, want extract failed and report skipped", report)
+	}
+}